@@ -0,0 +1,158 @@
+// Command oneroster exports and imports OneRoster 1.2 CSV bundles.
+//
+// Usage:
+//
+//	go run cmd/oneroster/main.go export -o oneroster.zip [-school 1] [-start-date 2025-01-01] [-end-date 2025-06-30]
+//	go run cmd/oneroster/main.go import -f oneroster.zip -school 1
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/michaelwp/student_attendance/internal/config"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/oneroster"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+func runExport(service *oneroster.Service, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "oneroster.zip", "output zip file")
+	school := fs.Uint("school", 0, "school to export (default every school)")
+	startDate := fs.String("start-date", "", "first attendance and enrollment date to include (YYYY-MM-DD)")
+	endDate := fs.String("end-date", "", "last attendance and enrollment date to include (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var opts oneroster.ExportOptions
+	var err error
+	if opts.StartDate, err = parseDate(*startDate); err != nil {
+		return fmt.Errorf("invalid start date: %v", err)
+	}
+	if opts.EndDate, err = parseDate(*endDate); err != nil {
+		return fmt.Errorf("invalid end date: %v", err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", *output, err)
+	}
+	defer file.Close()
+
+	if err := service.Export(schoolContext(*school), file, opts); err != nil {
+		return err
+	}
+
+	log.Printf("OneRoster bundle written to %s", *output)
+	return nil
+}
+
+func runImport(service *oneroster.Service, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	input := fs.String("f", "", "OneRoster zip file to import")
+	school := fs.Uint("school", 0, "school to import into")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *input == "" {
+		return fmt.Errorf("input file is required")
+	}
+
+	if *school == 0 {
+		return fmt.Errorf("school is required")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", *input, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %v", *input, err)
+	}
+
+	result, err := service.Import(schoolContext(*school), file, info.Size())
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(output))
+	return nil
+}
+
+// schoolContext limits the repositories to the school, or leaves every school reachable for 0
+func schoolContext(schoolID uint) context.Context {
+	ctx := context.Background()
+	if schoolID == 0 {
+		return models.WithAllSchools(ctx)
+	}
+
+	return models.WithSchool(ctx, schoolID)
+}
+
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
+
+func main() {
+	// Load environment variables from .env file
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Error loading .env file: %v", err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatal("Command (export/import) is required")
+	}
+
+	postgresConfig := config.NewPostgresConfig()
+	postgresClient, err := postgresConfig.ConnectDB()
+	if err != nil {
+		log.Fatalf("Error connecting to postgres database: %v", err)
+	}
+	defer func() {
+		if err := postgresConfig.CloseDB(postgresClient); err != nil {
+			log.Printf("Error closing PostgreSQL connection: %v\n", err)
+		}
+	}()
+
+	service := oneroster.NewService(repository.NewOneRosterRepository(postgresClient))
+
+	command := os.Args[1]
+	switch command {
+	case "export":
+		err = runExport(service, os.Args[2:])
+	case "import":
+		err = runImport(service, os.Args[2:])
+	default:
+		err = fmt.Errorf("invalid command: %s", command)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
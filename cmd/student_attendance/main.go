@@ -20,12 +20,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 	_ "github.com/michaelwp/student_attendance/docs"
 	"github.com/michaelwp/student_attendance/internal/api"
 	"github.com/michaelwp/student_attendance/internal/config"
+	"github.com/michaelwp/student_attendance/internal/jobs"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/notify"
+	"github.com/michaelwp/student_attendance/internal/repository"
 	"github.com/redis/go-redis/v9"
 	"log"
 	"os"
@@ -42,13 +47,16 @@ func init() {
 	}
 }
 
-func gracefulShutdown(app *fiber.App, postgresClient *sql.DB, postgresConfig *config.PostgresConfig, redisClient *redis.Client) {
+func gracefulShutdown(app *fiber.App, postgresClient *sql.DB, postgresConfig *config.PostgresConfig, redisClient *redis.Client, stopJobs context.CancelFunc) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
 
+	// stop background jobs before closing their connections
+	stopJobs()
+
 	if err := postgresConfig.CloseDB(postgresClient); err != nil {
 		log.Printf("Error closing PostgreSQL connection: %v\n", err)
 	}
@@ -101,6 +109,8 @@ func main() {
 		},
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
+		// leave room for several absent request attachments in one upload
+		BodyLimit: 30 * 1024 * 1024,
 	})
 
 	app.Static("/", "./web/dist")
@@ -108,11 +118,25 @@ func main() {
 	// Setup routes
 	api.SetupRoutes(app, postgresClient, s3Client, s3Config, redisClient)
 
+	// Start background jobs. They work across every school of the deployment.
+	jobsCtx, stopJobs := context.WithCancel(models.WithAllSchools(context.Background()))
+	anomalyJob := jobs.NewAttendanceAnomalyJob(repository.NewAttendanceAlertRepository(postgresClient))
+	go anomalyJob.Start(jobsCtx)
+	escalationJob := jobs.NewAbsentRequestEscalationJob(
+		repository.NewAbsentRequestRepository(postgresClient),
+		repository.NewAdminRepository(postgresClient),
+		notify.NewNotifier(),
+	)
+	go escalationJob.Start(jobsCtx)
+	if trashPurgeJob := jobs.NewTrashPurgeJob(repository.NewTrashRepository(postgresClient), s3Client, s3Config); trashPurgeJob != nil {
+		go trashPurgeJob.Start(jobsCtx)
+	}
+
 	port := os.Getenv("PORT")
 
 	go func() {
 		// Wait for a shutdown signal
-		gracefulShutdown(app, postgresClient, postgresConfig, redisClient)
+		gracefulShutdown(app, postgresClient, postgresConfig, redisClient, stopJobs)
 	}()
 
 	log.Printf("Student Attendance API listening on port %s", port)
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS attendance_alerts (
+    id SERIAL PRIMARY KEY,
+    class_id INTEGER NOT NULL REFERENCES classes (id),
+    alert_date DATE NOT NULL,
+    total_students INTEGER NOT NULL,
+    attendance_rate NUMERIC(5, 2) NOT NULL,
+    baseline_rate NUMERIC(5, 2) NOT NULL,
+    drop_percentage NUMERIC(5, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    acknowledged_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    acknowledged_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    resolved_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (class_id, alert_date)
+);
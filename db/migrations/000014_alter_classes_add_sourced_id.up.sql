@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS classes
+    ADD COLUMN IF NOT EXISTS sourced_id VARCHAR(255) NULL DEFAULT NULL UNIQUE;
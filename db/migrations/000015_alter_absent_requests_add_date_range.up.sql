@@ -0,0 +1,20 @@
+ALTER TABLE IF EXISTS absent_requests
+    ADD COLUMN IF NOT EXISTS start_date              DATE    NULL DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS end_date                DATE    NULL DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS exclude_non_school_days BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS total_days              INTEGER NOT NULL DEFAULT 1
+;
+
+UPDATE absent_requests
+SET start_date = request_date,
+    end_date   = request_date
+WHERE start_date IS NULL;
+
+ALTER TABLE IF EXISTS absent_requests
+    ALTER COLUMN start_date SET NOT NULL,
+    ALTER COLUMN end_date SET NOT NULL,
+    ADD CONSTRAINT absent_requests_date_range_check CHECK (end_date >= start_date)
+;
+
+CREATE INDEX IF NOT EXISTS idx_absent_requests_student_date_range
+    ON absent_requests (student_id, start_date, end_date);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS absent_request_attachments
+(
+    id                SERIAL PRIMARY KEY,
+    absent_request_id INTEGER      NOT NULL REFERENCES absent_requests (id),
+    file_name         VARCHAR(255) NOT NULL,
+    file_path         VARCHAR(255) NOT NULL,
+    content_type      VARCHAR(100) NOT NULL,
+    file_size         BIGINT       NOT NULL,
+    uploaded_by       INTEGER      NOT NULL REFERENCES students (id),
+    created_at        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at        TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_absent_request_attachments_request
+    ON absent_request_attachments (absent_request_id);
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS absent_request_categories
+(
+    id                  SERIAL PRIMARY KEY,
+    code                VARCHAR(50)  NOT NULL UNIQUE,
+    name                VARCHAR(100) NOT NULL,
+    description         TEXT         NULL,
+    requires_attachment BOOLEAN      NOT NULL DEFAULT FALSE,
+    max_days            INTEGER      NULL CHECK (max_days IS NULL OR max_days > 0),
+    min_notice_days     INTEGER      NOT NULL DEFAULT 0 CHECK (min_notice_days >= 0),
+    marks_excused       BOOLEAN      NOT NULL DEFAULT TRUE,
+    is_active           BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by          INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by          INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at          TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by          INTEGER REFERENCES admins (id) DEFAULT NULL
+);
+
+INSERT INTO absent_request_categories (code, name, description, requires_attachment, max_days, min_notice_days, marks_excused)
+VALUES ('sick', 'Sick', 'Illness or medical appointment', TRUE, NULL, 0, TRUE),
+       ('family', 'Family', 'Family matters such as weddings or bereavement', FALSE, 3, 1, TRUE),
+       ('religious', 'Religious', 'Religious observance', FALSE, NULL, 3, TRUE),
+       ('competition', 'Competition', 'Representing the school in a competition', TRUE, NULL, 7, TRUE)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES absent_request_categories (id) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_absent_requests_category ON absent_requests (category_id);
+
+-- approved requests of an excusing category record the absence as excused
+ALTER TABLE attendances DROP CONSTRAINT IF EXISTS attendances_status_check;
+ALTER TABLE attendances
+    ADD CONSTRAINT attendances_status_check CHECK (status IN ('present', 'absent', 'late', 'excused'));
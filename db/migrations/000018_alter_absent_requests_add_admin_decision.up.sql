@@ -0,0 +1,3 @@
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS admin_decided_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS admin_decided_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
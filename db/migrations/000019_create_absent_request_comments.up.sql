@@ -0,0 +1,16 @@
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS reviewer_note TEXT DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS absent_request_comments
+(
+    id                SERIAL PRIMARY KEY,
+    absent_request_id INTEGER     NOT NULL REFERENCES absent_requests (id),
+    author_type       VARCHAR(20) NOT NULL CHECK (author_type IN ('student', 'teacher', 'admin')),
+    author_id         INTEGER     NOT NULL,
+    body              TEXT        NOT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at        TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_absent_request_comments_request
+    ON absent_request_comments (absent_request_id, created_at);
@@ -0,0 +1,6 @@
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS escalated_to INTEGER REFERENCES admins (id) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_absent_requests_escalated_at ON absent_requests (escalated_at)
+    WHERE escalated_at IS NOT NULL;
@@ -0,0 +1,25 @@
+ALTER TABLE absent_requests DROP CONSTRAINT IF EXISTS absent_requests_status_check;
+
+ALTER TABLE absent_requests
+    ADD CONSTRAINT absent_requests_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'withdrawn'));
+
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS version      INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS withdrawn_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- each row keeps a request as it stood before a student amendment replaced it
+CREATE TABLE IF NOT EXISTS absent_request_versions
+(
+    id                      SERIAL PRIMARY KEY,
+    absent_request_id       INTEGER NOT NULL REFERENCES absent_requests (id),
+    version                 INTEGER NOT NULL,
+    category_id             INTEGER REFERENCES absent_request_categories (id) DEFAULT NULL,
+    start_date              DATE    NOT NULL,
+    end_date                DATE    NOT NULL,
+    exclude_non_school_days BOOLEAN NOT NULL,
+    total_days              INTEGER NOT NULL,
+    reason                  TEXT    NOT NULL,
+    superseded_by           INTEGER REFERENCES students (id) DEFAULT NULL,
+    superseded_at           TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (absent_request_id, version)
+);
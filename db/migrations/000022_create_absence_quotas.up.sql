@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS absence_quotas
+(
+    id          SERIAL PRIMARY KEY,
+    category_id INTEGER      NOT NULL REFERENCES absent_request_categories (id),
+    term_name   VARCHAR(100) NOT NULL,
+    start_date  DATE         NOT NULL,
+    end_date    DATE         NOT NULL,
+    max_days    INTEGER      NOT NULL CHECK (max_days > 0),
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at  TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT absence_quotas_term_range_check CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_absence_quotas_category_term
+    ON absence_quotas (category_id, start_date, end_date)
+    WHERE deleted_at IS NULL;
+
+-- set when a teacher's approval was held back because it would exceed the quota
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS quota_exceeded_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
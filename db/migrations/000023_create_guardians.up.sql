@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS guardians
+(
+    id         SERIAL PRIMARY KEY,
+    first_name VARCHAR(100) NOT NULL,
+    last_name  VARCHAR(100) NOT NULL,
+    email      VARCHAR(255) NOT NULL UNIQUE,
+    phone      VARCHAR(20)  NULL,
+    password   VARCHAR(255) NOT NULL,
+    is_active  BOOLEAN      NOT NULL DEFAULT TRUE,
+    last_login TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by INTEGER REFERENCES admins (id) DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS guardian_students
+(
+    guardian_id  INTEGER     NOT NULL REFERENCES guardians (id),
+    student_id   INTEGER     NOT NULL REFERENCES students (id),
+    relationship VARCHAR(50) NULL,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by   INTEGER REFERENCES admins (id) DEFAULT NULL,
+    PRIMARY KEY (guardian_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_guardian_students_student ON guardian_students (student_id);
+
+-- requests a guardian files on a child's behalf remember who filed them
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS submitted_by_guardian INTEGER REFERENCES guardians (id) DEFAULT NULL;
@@ -0,0 +1,107 @@
+CREATE TABLE IF NOT EXISTS permissions
+(
+    id          SERIAL PRIMARY KEY,
+    code        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT         NULL
+);
+
+CREATE TABLE IF NOT EXISTS roles
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT         NULL,
+    -- every user of this type holds the role without being assigned it
+    default_for VARCHAR(20)  NULL UNIQUE CHECK (default_for IN ('admin', 'teacher', 'student', 'guardian')),
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at  TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by  INTEGER REFERENCES admins (id) DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id       INTEGER NOT NULL REFERENCES roles (id),
+    permission_id INTEGER NOT NULL REFERENCES permissions (id),
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles
+(
+    user_type  VARCHAR(20) NOT NULL CHECK (user_type IN ('admin', 'teacher', 'student', 'guardian')),
+    user_id    INTEGER     NOT NULL,
+    role_id    INTEGER     NOT NULL REFERENCES roles (id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    PRIMARY KEY (user_type, user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles (role_id);
+
+INSERT INTO permissions (code, description)
+VALUES ('teacher.read', 'View teachers'),
+       ('teacher.create', 'Create teachers'),
+       ('teacher.update', 'Update teachers and their photos'),
+       ('teacher.delete', 'Delete teachers'),
+       ('teacher.reset_password', 'Reset teacher passwords'),
+       ('teacher.update_password', 'Change a teacher password with the old password'),
+       ('class.read', 'View classes'),
+       ('class.create', 'Create classes'),
+       ('class.update', 'Update classes'),
+       ('class.delete', 'Delete classes'),
+       ('student.read', 'View students'),
+       ('student.create', 'Create students'),
+       ('student.update', 'Update students and their photos'),
+       ('student.delete', 'Delete students'),
+       ('student.reset_password', 'Reset student passwords'),
+       ('student.update_password', 'Change a student password with the old password'),
+       ('attendance.read', 'View attendance records'),
+       ('attendance.write', 'Create and update attendance records'),
+       ('attendance.delete', 'Delete attendance records'),
+       ('absent_request.read', 'View absent requests'),
+       ('absent_request.create', 'File absent requests'),
+       ('absent_request.amend', 'Amend, withdraw and attach files to own absent requests'),
+       ('absent_request.comment', 'Comment on absent requests'),
+       ('absent_request.decide', 'Approve and reject absent requests'),
+       ('absent_request.override', 'Change decided absent requests and approve beyond quotas'),
+       ('absent_request.escalation', 'View the absent request escalation queue'),
+       ('absent_request_category.read', 'View active absent request categories'),
+       ('absent_request_category.manage', 'Manage absent request categories'),
+       ('absence_quota.manage', 'Manage absence quotas'),
+       ('attendance_alert.read', 'View attendance alerts'),
+       ('attendance_alert.manage', 'Acknowledge and resolve attendance alerts'),
+       ('report.read', 'View reports'),
+       ('dashboard.read', 'View dashboard statistics'),
+       ('admin.read', 'View admins'),
+       ('admin.manage', 'Create, update and delete admins'),
+       ('admin.update_password', 'Change own admin password'),
+       ('guardian.manage', 'Manage guardians and their links to students'),
+       ('oneroster.export', 'Export the roster'),
+       ('oneroster.import', 'Import a roster'),
+       ('role.manage', 'Manage roles and role assignments'),
+       ('profile.self', 'Use the own profile dashboard'),
+       ('guardian_portal.access', 'Use the guardian portal')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO roles (name, description, default_for)
+VALUES ('admin', 'Default role of every admin', 'admin'),
+       ('teacher', 'Default role of every teacher', 'teacher'),
+       ('student', 'Default role of every student', 'student'),
+       ('guardian', 'Default role of every guardian', 'guardian')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         CROSS JOIN permissions p
+WHERE r.name = 'admin'
+   OR (r.name = 'teacher' AND p.code IN ('teacher.read', 'teacher.update_password', 'class.read', 'student.read',
+                                         'attendance.read', 'attendance.write', 'absent_request.read',
+                                         'absent_request.comment', 'absent_request.decide',
+                                         'absent_request_category.read', 'profile.self'))
+   OR (r.name = 'student' AND p.code IN ('student.read', 'student.update_password', 'attendance.read',
+                                         'absent_request.read', 'absent_request.create', 'absent_request.amend',
+                                         'absent_request.comment', 'absent_request_category.read', 'profile.self'))
+   OR (r.name = 'guardian' AND p.code IN ('guardian_portal.access'))
+ON CONFLICT DO NOTHING;
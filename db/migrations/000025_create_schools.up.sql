@@ -0,0 +1,82 @@
+CREATE TABLE IF NOT EXISTS schools
+(
+    id         SERIAL PRIMARY KEY,
+    code       VARCHAR(50)  NOT NULL UNIQUE,
+    name       VARCHAR(255) NOT NULL,
+    address    TEXT         NULL,
+    is_active  BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by INTEGER REFERENCES admins (id) DEFAULT NULL
+);
+
+-- everything recorded before tenancy belongs to the first school
+INSERT INTO schools (id, code, name)
+VALUES (1, 'default', 'Default School')
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('schools', 'id'), GREATEST((SELECT MAX(id) FROM schools), 1));
+
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS school_id      INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id),
+    ADD COLUMN IF NOT EXISTS is_super_admin BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE teachers
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+ALTER TABLE classes
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+ALTER TABLE students
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+ALTER TABLE attendances
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+ALTER TABLE absent_requests
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+
+-- new rows must name their school
+ALTER TABLE admins ALTER COLUMN school_id DROP DEFAULT;
+ALTER TABLE teachers ALTER COLUMN school_id DROP DEFAULT;
+ALTER TABLE classes ALTER COLUMN school_id DROP DEFAULT;
+ALTER TABLE students ALTER COLUMN school_id DROP DEFAULT;
+ALTER TABLE attendances ALTER COLUMN school_id DROP DEFAULT;
+ALTER TABLE absent_requests ALTER COLUMN school_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_admins_school ON admins (school_id);
+CREATE INDEX IF NOT EXISTS idx_teachers_school ON teachers (school_id);
+CREATE INDEX IF NOT EXISTS idx_classes_school ON classes (school_id);
+CREATE INDEX IF NOT EXISTS idx_students_school ON students (school_id);
+CREATE INDEX IF NOT EXISTS idx_attendances_school ON attendances (school_id);
+CREATE INDEX IF NOT EXISTS idx_absent_requests_school ON absent_requests (school_id);
+
+-- records may only point at records of the same school
+ALTER TABLE teachers
+    ADD CONSTRAINT teachers_teacher_id_school_key UNIQUE (teacher_id, school_id);
+ALTER TABLE classes
+    ADD CONSTRAINT classes_id_school_key UNIQUE (id, school_id),
+    ADD CONSTRAINT classes_homeroom_teacher_school_fkey
+        FOREIGN KEY (homeroom_teacher, school_id) REFERENCES teachers (teacher_id, school_id);
+ALTER TABLE students
+    ADD CONSTRAINT students_student_id_school_key UNIQUE (student_id, school_id),
+    ADD CONSTRAINT students_classes_id_school_fkey
+        FOREIGN KEY (classes_id, school_id) REFERENCES classes (id, school_id);
+ALTER TABLE attendances
+    ADD CONSTRAINT attendances_student_id_school_fkey
+        FOREIGN KEY (student_id, school_id) REFERENCES students (student_id, school_id),
+    ADD CONSTRAINT attendances_class_id_school_fkey
+        FOREIGN KEY (class_id, school_id) REFERENCES classes (id, school_id);
+ALTER TABLE absent_requests
+    ADD CONSTRAINT absent_requests_student_id_school_fkey
+        FOREIGN KEY (student_id, school_id) REFERENCES students (student_id, school_id),
+    ADD CONSTRAINT absent_requests_class_id_school_fkey
+        FOREIGN KEY (class_id, school_id) REFERENCES classes (id, school_id);
+
+-- only super-admins may use it, whatever their roles allow
+INSERT INTO permissions (code, description)
+VALUES ('school.manage', 'Manage schools and switch between them')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'school.manage'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,90 @@
+CREATE TABLE IF NOT EXISTS academic_years
+(
+    id         SERIAL PRIMARY KEY,
+    school_id  INTEGER     NOT NULL REFERENCES schools (id),
+    name       VARCHAR(50) NOT NULL,
+    start_date DATE        NOT NULL,
+    end_date   DATE        NOT NULL,
+    is_current BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT academic_years_range_check CHECK (end_date > start_date)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_academic_years_school_name
+    ON academic_years (school_id, LOWER(name))
+    WHERE deleted_at IS NULL;
+
+-- a school has at most one current academic year
+CREATE UNIQUE INDEX IF NOT EXISTS uq_academic_years_school_current
+    ON academic_years (school_id)
+    WHERE is_current AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS terms
+(
+    id               SERIAL PRIMARY KEY,
+    academic_year_id INTEGER      NOT NULL REFERENCES academic_years (id),
+    name             VARCHAR(100) NOT NULL,
+    start_date       DATE         NOT NULL,
+    end_date         DATE         NOT NULL,
+    created_at       TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by       INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by       INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at       TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by       INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT terms_range_check CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terms_academic_year ON terms (academic_year_id) WHERE deleted_at IS NULL;
+
+-- classes created before academic years existed keep no year until an admin assigns one
+ALTER TABLE classes
+    ADD COLUMN IF NOT EXISTS academic_year_id INTEGER REFERENCES academic_years (id) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_classes_academic_year ON classes (academic_year_id);
+
+-- an enrollment covers the days from start_date up to, but not including, end_date;
+-- the open enrollment of a student is the class they are in now
+CREATE TABLE IF NOT EXISTS enrollments
+(
+    id               SERIAL PRIMARY KEY,
+    school_id        INTEGER     NOT NULL REFERENCES schools (id),
+    student_id       INTEGER     NOT NULL REFERENCES students (id),
+    class_id         INTEGER     NOT NULL REFERENCES classes (id),
+    academic_year_id INTEGER     NULL REFERENCES academic_years (id),
+    start_date       DATE        NOT NULL,
+    end_date         DATE        NULL,
+    start_reason     VARCHAR(20) NOT NULL DEFAULT 'enrolled' CHECK (start_reason IN ('enrolled', 'moved', 'promoted')),
+    end_reason       VARCHAR(20) NULL CHECK (end_reason IN ('moved', 'promoted', 'graduated')),
+    created_at       TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by       INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT enrollments_range_check CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_enrollments_student_open ON enrollments (student_id) WHERE end_date IS NULL;
+CREATE INDEX IF NOT EXISTS idx_enrollments_class ON enrollments (class_id, start_date);
+CREATE INDEX IF NOT EXISTS idx_enrollments_school ON enrollments (school_id);
+
+-- every current student starts with the class they are in today
+INSERT INTO enrollments (school_id, student_id, class_id, start_date)
+SELECT s.school_id, s.id, s.classes_id, DATE(s.created_at)
+FROM students s
+WHERE s.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.student_id = s.id);
+
+INSERT INTO permissions (code, description)
+VALUES ('academic_year.manage', 'Manage academic years and their terms'),
+       ('student.promote', 'Promote students to the classes of the next academic year')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code IN ('academic_year.manage', 'student.promote')
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
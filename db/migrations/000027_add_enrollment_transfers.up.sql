@@ -0,0 +1,22 @@
+-- a transfer moves a student to another class from an effective date chosen by an admin
+ALTER TABLE enrollments
+    DROP CONSTRAINT IF EXISTS enrollments_start_reason_check,
+    DROP CONSTRAINT IF EXISTS enrollments_end_reason_check,
+    ADD CONSTRAINT enrollments_start_reason_check
+        CHECK (start_reason IN ('enrolled', 'moved', 'promoted', 'transferred')),
+    ADD CONSTRAINT enrollments_end_reason_check
+        CHECK (end_reason IN ('moved', 'promoted', 'graduated', 'transferred')),
+    ADD COLUMN IF NOT EXISTS note TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_enrollments_student ON enrollments (student_id, start_date);
+
+INSERT INTO permissions (code, description)
+VALUES ('student.transfer', 'Transfer students to another class from an effective date')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'student.transfer'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
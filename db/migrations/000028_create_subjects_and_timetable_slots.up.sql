@@ -0,0 +1,74 @@
+CREATE TABLE IF NOT EXISTS subjects
+(
+    id          SERIAL PRIMARY KEY,
+    school_id   INTEGER      NOT NULL REFERENCES schools (id),
+    code        VARCHAR(50)  NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NULL,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at  TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT subjects_id_school_key UNIQUE (id, school_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_subjects_school_code
+    ON subjects (school_id, LOWER(code))
+    WHERE deleted_at IS NULL;
+
+-- a weekly lesson: weekday follows ISO 8601, Monday is 1 and Sunday is 7
+CREATE TABLE IF NOT EXISTS timetable_slots
+(
+    id         SERIAL PRIMARY KEY,
+    school_id  INTEGER      NOT NULL REFERENCES schools (id),
+    class_id   INTEGER      NOT NULL,
+    subject_id INTEGER      NOT NULL,
+    teacher_id VARCHAR(50)  NOT NULL,
+    weekday    SMALLINT     NOT NULL CHECK (weekday BETWEEN 1 AND 7),
+    start_time TIME         NOT NULL,
+    end_time   TIME         NOT NULL,
+    room       VARCHAR(100) NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT timetable_slots_time_check CHECK (end_time > start_time),
+    CONSTRAINT timetable_slots_class_school_fkey
+        FOREIGN KEY (class_id, school_id) REFERENCES classes (id, school_id),
+    CONSTRAINT timetable_slots_subject_school_fkey
+        FOREIGN KEY (subject_id, school_id) REFERENCES subjects (id, school_id),
+    CONSTRAINT timetable_slots_teacher_school_fkey
+        FOREIGN KEY (teacher_id, school_id) REFERENCES teachers (teacher_id, school_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timetable_slots_class ON timetable_slots (class_id, weekday) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_timetable_slots_teacher ON timetable_slots (teacher_id, weekday) WHERE deleted_at IS NULL;
+
+-- attendance taken in a lesson points at its slot; daily attendance has none
+ALTER TABLE attendances
+    ADD COLUMN IF NOT EXISTS timetable_slot_id INTEGER NULL REFERENCES timetable_slots (id);
+
+CREATE INDEX IF NOT EXISTS idx_attendances_timetable_slot ON attendances (timetable_slot_id);
+
+INSERT INTO permissions (code, description)
+VALUES ('timetable.manage', 'Manage subjects and timetable slots'),
+       ('timetable.read_own', 'View own teaching schedule')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'timetable.manage'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'timetable.read_own'
+WHERE r.default_for = 'teacher'
+ON CONFLICT DO NOTHING;
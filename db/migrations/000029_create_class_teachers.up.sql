@@ -0,0 +1,40 @@
+-- the teachers of a class and their role in it; classes.homeroom_teacher mirrors the homeroom row
+CREATE TABLE IF NOT EXISTS class_teachers
+(
+    id         SERIAL PRIMARY KEY,
+    school_id  INTEGER     NOT NULL REFERENCES schools (id),
+    class_id   INTEGER     NOT NULL,
+    teacher_id VARCHAR(50) NOT NULL,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('homeroom', 'co_homeroom', 'assistant')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES admins (id) DEFAULT NULL,
+    CONSTRAINT class_teachers_class_teacher_key UNIQUE (class_id, teacher_id),
+    CONSTRAINT class_teachers_class_school_fkey
+        FOREIGN KEY (class_id, school_id) REFERENCES classes (id, school_id) ON DELETE CASCADE,
+    CONSTRAINT class_teachers_teacher_school_fkey
+        FOREIGN KEY (teacher_id, school_id) REFERENCES teachers (teacher_id, school_id)
+);
+
+-- a class has exactly one homeroom teacher
+CREATE UNIQUE INDEX IF NOT EXISTS uq_class_teachers_homeroom
+    ON class_teachers (class_id)
+    WHERE role = 'homeroom';
+
+CREATE INDEX IF NOT EXISTS idx_class_teachers_teacher ON class_teachers (teacher_id, school_id);
+
+INSERT INTO class_teachers (school_id, class_id, teacher_id, role)
+SELECT c.school_id, c.id, c.homeroom_teacher, 'homeroom'
+FROM classes c
+ON CONFLICT DO NOTHING;
+
+-- what each class teacher role allows, per school. Roles without a row use the defaults of the API
+CREATE TABLE IF NOT EXISTS class_teacher_role_permissions
+(
+    school_id                   INTEGER     NOT NULL REFERENCES schools (id),
+    role                        VARCHAR(20) NOT NULL CHECK (role IN ('homeroom', 'co_homeroom', 'assistant')),
+    can_decide_absent_requests  BOOLEAN     NOT NULL,
+    can_comment_absent_requests BOOLEAN     NOT NULL,
+    updated_at                  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_by                  INTEGER REFERENCES admins (id) DEFAULT NULL,
+    PRIMARY KEY (school_id, role)
+);
@@ -0,0 +1,10 @@
+INSERT INTO permissions (code, description)
+VALUES ('student.import', 'Import students from CSV or XLSX files')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'student.import'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+INSERT INTO permissions (code, description)
+VALUES ('teacher.import', 'Import teachers from CSV or XLSX files')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'teacher.import'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,42 @@
+-- audit trail of actions applied to many students at once
+CREATE TABLE IF NOT EXISTS student_bulk_actions
+(
+    id             SERIAL PRIMARY KEY,
+    school_id      INTEGER     NOT NULL REFERENCES schools (id),
+    action         VARCHAR(20) NOT NULL CHECK (action IN ('deactivate', 'activate', 'move_class', 'delete', 'reset_password')),
+    class_id       INTEGER     NULL REFERENCES classes (id),
+    to_class_id    INTEGER     NULL REFERENCES classes (id),
+    effective_date DATE        NULL,
+    note           TEXT        NULL,
+    student_count  INTEGER     NOT NULL DEFAULT 0,
+    changed_count  INTEGER     NOT NULL DEFAULT 0,
+    performed_by   INTEGER     NOT NULL REFERENCES admins (id),
+    performed_at   TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_bulk_actions_school ON student_bulk_actions (school_id, performed_at DESC);
+
+-- each student a bulk action reached, with what it was before
+CREATE TABLE IF NOT EXISTS student_bulk_action_items
+(
+    id                 SERIAL PRIMARY KEY,
+    bulk_action_id     INTEGER     NOT NULL REFERENCES student_bulk_actions (id) ON DELETE CASCADE,
+    student_id         INTEGER     NOT NULL REFERENCES students (id),
+    status             VARCHAR(20) NOT NULL CHECK (status IN ('changed', 'unchanged')),
+    previous_class_id  INTEGER     NULL REFERENCES classes (id),
+    previous_is_active BOOLEAN     NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_bulk_action_items_action ON student_bulk_action_items (bulk_action_id);
+CREATE INDEX IF NOT EXISTS idx_student_bulk_action_items_student ON student_bulk_action_items (student_id);
+
+INSERT INTO permissions (code, description)
+VALUES ('student.bulk', 'Apply actions to many students at once and view their audit trail')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'student.bulk'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+-- deleted records are purged once they are old enough, so look them up by deletion date
+CREATE INDEX IF NOT EXISTS idx_teachers_deleted_at ON teachers (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_classes_deleted_at ON classes (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attendances_deleted_at ON attendances (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_absent_requests_deleted_at ON absent_requests (deleted_at) WHERE deleted_at IS NOT NULL;
+
+INSERT INTO permissions (code, description)
+VALUES ('trash.manage', 'List, restore and purge deleted teachers, students, classes, attendances and absent requests')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'trash.manage'
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
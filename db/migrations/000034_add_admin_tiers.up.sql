@@ -0,0 +1,34 @@
+-- super-admins manage admins and schools, admins run their school, auditors only look
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS tier VARCHAR(20) NOT NULL DEFAULT 'admin'
+        CHECK (tier IN ('super_admin', 'admin', 'auditor'));
+
+UPDATE admins
+SET tier = 'super_admin'
+WHERE is_super_admin;
+
+-- the flag now follows the tier, so the two can never disagree
+ALTER TABLE admins
+    DROP COLUMN is_super_admin;
+ALTER TABLE admins
+    ADD COLUMN is_super_admin BOOLEAN GENERATED ALWAYS AS (tier = 'super_admin') STORED;
+
+-- auditors hold the auditor role instead of the admin role
+ALTER TABLE roles
+    DROP CONSTRAINT IF EXISTS roles_default_for_check;
+ALTER TABLE roles
+    ADD CONSTRAINT roles_default_for_check CHECK (default_for IN ('admin', 'auditor', 'teacher', 'student', 'guardian'));
+
+INSERT INTO roles (name, description, default_for)
+VALUES ('auditor', 'Default role of every read-only auditor admin', 'auditor')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code IN ('teacher.read', 'class.read', 'student.read', 'attendance.read',
+                                          'absent_request.read', 'absent_request.escalation',
+                                          'absent_request_category.read', 'attendance_alert.read', 'report.read',
+                                          'dashboard.read', 'admin.read', 'admin.update_password')
+WHERE r.default_for = 'auditor'
+ON CONFLICT DO NOTHING;
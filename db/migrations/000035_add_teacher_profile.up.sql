@@ -0,0 +1,15 @@
+-- the dashboard language a teacher picked, one of the languages the web app is translated into
+ALTER TABLE teachers
+    ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(10) NOT NULL DEFAULT 'en'
+        CHECK (preferred_language IN ('en', 'id'));
+
+-- a new email a teacher asked for, applied once they enter the code sent to it
+CREATE TABLE IF NOT EXISTS teacher_email_changes
+(
+    teacher_id INTEGER PRIMARY KEY REFERENCES teachers (id) ON DELETE CASCADE,
+    new_email  VARCHAR(100)             NOT NULL,
+    code_hash  VARCHAR(255)             NOT NULL,
+    attempts   INTEGER                  NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
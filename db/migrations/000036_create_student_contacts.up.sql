@@ -0,0 +1,50 @@
+-- people to call about a student, the lowest priority first
+CREATE TABLE IF NOT EXISTS student_contacts
+(
+    id           SERIAL PRIMARY KEY,
+    student_id   INTEGER      NOT NULL REFERENCES students (id),
+    school_id    INTEGER      NOT NULL REFERENCES schools (id),
+    name         VARCHAR(100) NOT NULL,
+    relationship VARCHAR(50)  NOT NULL,
+    phone        VARCHAR(20)  NOT NULL,
+    alt_phone    VARCHAR(20)  NULL,
+    priority     INTEGER      NOT NULL CHECK (priority >= 1),
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by   INTEGER REFERENCES admins (id) DEFAULT NULL,
+    updated_by   INTEGER REFERENCES admins (id) DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_contacts_student ON student_contacts (student_id, priority);
+
+-- kept apart from students so it is never read along with them by accident
+CREATE TABLE IF NOT EXISTS student_medical_notes
+(
+    student_id INTEGER PRIMARY KEY REFERENCES students (id),
+    school_id  INTEGER NOT NULL REFERENCES schools (id),
+    note       TEXT    NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_by INTEGER REFERENCES admins (id) DEFAULT NULL
+);
+
+-- contacts and medical notes are confidential: admins manage them, and besides admins
+-- only the homeroom and co-homeroom teachers of the student's class may read them.
+-- Read-only auditors get neither permission
+INSERT INTO permissions (code, description)
+VALUES ('student_contact.read', 'View the emergency contacts and medical notes of students'),
+       ('student_contact.manage', 'Manage the emergency contacts and medical notes of students')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code IN ('student_contact.read', 'student_contact.manage')
+WHERE r.default_for = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'student_contact.read'
+WHERE r.default_for = 'teacher'
+ON CONFLICT DO NOTHING;
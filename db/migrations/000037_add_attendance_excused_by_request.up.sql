@@ -0,0 +1,7 @@
+-- the approved absent request that excused the attendance, so revoking the approval
+-- only restores the days that request excused
+ALTER TABLE attendances
+    ADD COLUMN IF NOT EXISTS excused_by_request_id INTEGER NULL REFERENCES absent_requests (id);
+
+CREATE INDEX IF NOT EXISTS idx_attendances_excused_by_request ON attendances (excused_by_request_id)
+    WHERE excused_by_request_id IS NOT NULL;
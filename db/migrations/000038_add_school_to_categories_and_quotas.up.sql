@@ -0,0 +1,56 @@
+-- absent request categories and absence quotas belong to one school, like the records in 000025.
+-- Until now every school shared them, so they become the first school's
+ALTER TABLE absent_request_categories
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+ALTER TABLE absence_quotas
+    ADD COLUMN IF NOT EXISTS school_id INTEGER NOT NULL DEFAULT 1 REFERENCES schools (id);
+
+-- every other school gets its own copy of the categories and quotas it used so far
+INSERT INTO absent_request_categories (school_id, code, name, description, requires_attachment, max_days,
+                                       min_notice_days, marks_excused, is_active, created_at, updated_at,
+                                       created_by, updated_by, deleted_at, deleted_by)
+SELECT s.id, arc.code, arc.name, arc.description, arc.requires_attachment, arc.max_days,
+       arc.min_notice_days, arc.marks_excused, arc.is_active, arc.created_at, arc.updated_at,
+       arc.created_by, arc.updated_by, arc.deleted_at, arc.deleted_by
+FROM absent_request_categories arc
+         CROSS JOIN schools s
+WHERE arc.school_id = 1 AND s.id <> 1;
+
+INSERT INTO absence_quotas (school_id, category_id, term_name, start_date, end_date, max_days, created_at,
+                            updated_at, created_by, updated_by, deleted_at, deleted_by)
+SELECT copy.school_id, copy.id, aq.term_name, aq.start_date, aq.end_date, aq.max_days, aq.created_at,
+       aq.updated_at, aq.created_by, aq.updated_by, aq.deleted_at, aq.deleted_by
+FROM absence_quotas aq
+         JOIN absent_request_categories original ON original.id = aq.category_id
+         JOIN absent_request_categories copy ON copy.code = original.code AND copy.school_id <> 1
+WHERE aq.school_id = 1;
+
+-- requests of the other schools point at their school's copy of the category
+UPDATE absent_requests ar
+SET category_id = copy.id
+FROM absent_request_categories original
+         JOIN absent_request_categories copy ON copy.code = original.code AND copy.school_id <> 1
+WHERE ar.category_id = original.id
+  AND original.school_id = 1
+  AND copy.school_id = ar.school_id;
+
+-- new rows must name their school
+ALTER TABLE absent_request_categories ALTER COLUMN school_id DROP DEFAULT;
+ALTER TABLE absence_quotas ALTER COLUMN school_id DROP DEFAULT;
+
+-- codes only need to be unique within a school
+ALTER TABLE absent_request_categories DROP CONSTRAINT IF EXISTS absent_request_categories_code_key;
+ALTER TABLE absent_request_categories
+    ADD CONSTRAINT absent_request_categories_school_code_key UNIQUE (school_id, code);
+
+CREATE INDEX IF NOT EXISTS idx_absence_quotas_school ON absence_quotas (school_id);
+
+-- records may only point at categories of the same school
+ALTER TABLE absent_request_categories
+    ADD CONSTRAINT absent_request_categories_id_school_key UNIQUE (id, school_id);
+ALTER TABLE absence_quotas
+    ADD CONSTRAINT absence_quotas_category_school_fkey
+        FOREIGN KEY (category_id, school_id) REFERENCES absent_request_categories (id, school_id);
+ALTER TABLE absent_requests
+    ADD CONSTRAINT absent_requests_category_school_fkey
+        FOREIGN KEY (category_id, school_id) REFERENCES absent_request_categories (id, school_id);
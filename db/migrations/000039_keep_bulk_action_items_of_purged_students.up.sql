@@ -0,0 +1,19 @@
+-- bulk action audit entries outlive the students they reached, so they keep the student's number and name
+-- and lose the link when the student is purged from the trash
+ALTER TABLE student_bulk_action_items
+    ADD COLUMN IF NOT EXISTS student_number VARCHAR(50) NULL,
+    ADD COLUMN IF NOT EXISTS first_name     VARCHAR(50) NULL,
+    ADD COLUMN IF NOT EXISTS last_name      VARCHAR(50) NULL;
+
+UPDATE student_bulk_action_items i
+SET student_number = s.student_id,
+    first_name     = s.first_name,
+    last_name      = s.last_name
+FROM students s
+WHERE s.id = i.student_id;
+
+ALTER TABLE student_bulk_action_items
+    ALTER COLUMN student_number SET NOT NULL,
+    ALTER COLUMN first_name SET NOT NULL,
+    ALTER COLUMN last_name SET NOT NULL,
+    ALTER COLUMN student_id DROP NOT NULL;
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type absenceQuotaHandler struct {
+	quotaRepo    repository.AbsenceQuotaRepository
+	categoryRepo repository.AbsentRequestCategoryRepository
+}
+
+// NewAbsenceQuotaHandler creates a new absence quota handler
+func NewAbsenceQuotaHandler(
+	quotaRepo repository.AbsenceQuotaRepository,
+	categoryRepo repository.AbsentRequestCategoryRepository,
+) AbsenceQuotaHandler {
+	return &absenceQuotaHandler{
+		quotaRepo:    quotaRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// Create godoc
+// @Summary Create absence quota
+// @Description Cap the days of a category a student may have approved within a term
+// @Tags Absence Quotas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param quota body models.AbsenceQuotaInput true "Quota data"
+// @Success 201 {object} map[string]interface{} "Absence quota created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, term or category"
+// @Failure 409 {object} map[string]interface{} "Another quota of the category overlaps the term"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absence-quotas [post]
+func (h *absenceQuotaHandler) Create(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create absence quota")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	quota, status, errBody := h.parseQuota(c, 0)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	quota.CreatedBy = &adminID
+	if err := h.quotaRepo.Create(c.Context(), quota); err != nil {
+		log.Println("error on create absence quota:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_absence_quota",
+			"error":         "Failed to create absence quota",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.absence_quota_created",
+		"message":       "Absence quota created successfully",
+		"data":          quota,
+	})
+}
+
+// GetAll godoc
+// @Summary Get absence quotas
+// @Description Retrieve the absence quotas, optionally only those of one category
+// @Tags Absence Quotas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param category_id query int false "Absent request category ID"
+// @Success 200 {object} map[string]interface{} "Absence quotas retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid category ID"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absence-quotas [get]
+func (h *absenceQuotaHandler) GetAll(c *fiber.Ctx) error {
+	categoryID, err := strconv.ParseUint(c.Query("category_id", "0"), 10, 32)
+	if err != nil {
+		log.Println("error on get absence quotas:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absent_request_category_id",
+			"error":         "Invalid absent request category ID",
+		})
+	}
+
+	quotas, err := h.quotaRepo.GetAll(c.Context(), uint(categoryID))
+	if err != nil {
+		log.Println("error on get absence quotas:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_absence_quotas",
+			"error":         "Failed to get absence quotas",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absence_quotas_retrieved",
+		"message":       "Absence quotas retrieved successfully",
+		"data":          quotas,
+	})
+}
+
+// GetByID godoc
+// @Summary Get absence quota by ID
+// @Description Retrieve a specific absence quota
+// @Tags Absence Quotas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absence quota ID"
+// @Success 200 {object} map[string]interface{} "Absence quota retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid absence quota ID"
+// @Failure 404 {object} map[string]interface{} "Absence quota not found"
+// @Router /admins/absence-quotas/quota-id/{id} [get]
+func (h *absenceQuotaHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on get absence quota by id:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absence_quota_id",
+			"error":         "Invalid absence quota ID",
+		})
+	}
+
+	quota, err := h.quotaRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on get absence quota by id:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absence_quota_not_found",
+			"error":         "Absence quota not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absence_quota_retrieved",
+		"message":       "Absence quota retrieved successfully",
+		"data":          quota,
+	})
+}
+
+// Update godoc
+// @Summary Update absence quota
+// @Description Update the category, term or allowance of an absence quota. Decisions already made are not revisited.
+// @Tags Absence Quotas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absence quota ID"
+// @Param quota body models.AbsenceQuotaInput true "Quota data"
+// @Success 200 {object} map[string]interface{} "Absence quota updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, term or category"
+// @Failure 404 {object} map[string]interface{} "Absence quota not found"
+// @Failure 409 {object} map[string]interface{} "Another quota of the category overlaps the term"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absence-quotas/quota-id/{id} [put]
+func (h *absenceQuotaHandler) Update(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update absence quota")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on update absence quota:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absence_quota_id",
+			"error":         "Invalid absence quota ID",
+		})
+	}
+
+	existing, err := h.quotaRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on update absence quota:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absence_quota_not_found",
+			"error":         "Absence quota not found",
+		})
+	}
+
+	quota, status, errBody := h.parseQuota(c, existing.ID)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	quota.ID = existing.ID
+	quota.CreatedAt = existing.CreatedAt
+	quota.CreatedBy = existing.CreatedBy
+	quota.UpdatedBy = &adminID
+	if err := h.quotaRepo.Update(c.Context(), quota); err != nil {
+		log.Println("error on update absence quota:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_absence_quota",
+			"error":         "Failed to update absence quota",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absence_quota_updated",
+		"message":       "Absence quota updated successfully",
+		"data":          quota,
+	})
+}
+
+// Delete godoc
+// @Summary Delete absence quota
+// @Description Remove an absence quota. Requests of its category and term are no longer capped.
+// @Tags Absence Quotas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absence quota ID"
+// @Success 200 {object} map[string]interface{} "Absence quota deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid absence quota ID"
+// @Failure 404 {object} map[string]interface{} "Absence quota not found"
+// @Router /admins/absence-quotas/quota-id/{id} [delete]
+func (h *absenceQuotaHandler) Delete(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete absence quota")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on delete absence quota:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absence_quota_id",
+			"error":         "Invalid absence quota ID",
+		})
+	}
+
+	if err := h.quotaRepo.UpdateDeleteInfo(c.Context(), uint(id), adminID); err != nil {
+		log.Println("error on delete absence quota:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absence_quota_not_found",
+			"error":         "Absence quota not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absence_quota_deleted",
+		"message":       "Absence quota deleted successfully",
+	})
+}
+
+// parseQuota reads the quota from the request body and checks it can be saved.
+// On failure it returns the status and body to respond with.
+func (h *absenceQuotaHandler) parseQuota(c *fiber.Ctx, quotaID uint) (*models.AbsenceQuota, int, fiber.Map) {
+	var input models.AbsenceQuotaInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on parse absence quota:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	quota, err := input.ToAbsenceQuota()
+	if err != nil {
+		log.Println("error on parse absence quota:", err)
+		switch {
+		case errors.Is(err, models.ErrAbsenceQuotaInvalidTerm):
+			return nil, fiber.StatusBadRequest, fiber.Map{
+				"translate_key": "error.absence_quota_term_required",
+				"error":         "Term name, start date and end date are required",
+			}
+		case errors.Is(err, models.ErrAbsentRequestInvalidRange):
+			return nil, fiber.StatusBadRequest, fiber.Map{
+				"translate_key": "error.invalid_date_range",
+				"error":         "End date must not be before start date",
+			}
+		default:
+			return nil, fiber.StatusBadRequest, fiber.Map{
+				"translate_key": "error.invalid_date_format",
+				"error":         "Invalid date format. Use YYYY-MM-DD format.",
+			}
+		}
+	}
+
+	if quota.MaxDays <= 0 {
+		log.Println("error on parse absence quota: max days must be positive")
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_absence_quota_max_days",
+			"error":         "Maximum days must be positive",
+		}
+	}
+
+	category, err := h.categoryRepo.GetByID(c.Context(), quota.CategoryID)
+	if err != nil || category.DeletedAt != nil {
+		log.Println("error on parse absence quota: category not found:", err)
+		return nil, fiber.StatusBadRequest, absentRequestCategoryError(models.ErrAbsentRequestCategoryInactive)
+	}
+
+	overlaps, err := h.quotaRepo.HasOverlap(c.Context(), quota.CategoryID, quota.StartDate, quota.EndDate, quotaID)
+	if err != nil {
+		log.Println("error on parse absence quota:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_absence_quota",
+			"error":         "Failed to check absence quota",
+		}
+	}
+
+	if overlaps {
+		log.Println("error on parse absence quota: overlaps another quota of the category")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.absence_quota_overlaps",
+			"error":         "Another quota of this category already covers part of the term",
+		}
+	}
+
+	return quota, fiber.StatusOK, nil
+}
+
+// absenceQuotaUsage returns the student's allowance under the quota that applies to the request,
+// or nil when its category has no quota for the term the request starts in
+func absenceQuotaUsage(
+	ctx context.Context,
+	quotaRepo repository.AbsenceQuotaRepository,
+	request *models.AbsentRequest,
+) (*models.AbsenceQuotaUsage, error) {
+	if request.CategoryID == nil {
+		return nil, nil
+	}
+
+	quota, err := quotaRepo.GetForDate(ctx, *request.CategoryID, request.StartDate)
+	if err != nil || quota == nil {
+		return nil, err
+	}
+
+	// the request itself is left out so re-deciding it does not count its own days twice
+	usedDays, pendingDays, err := quotaRepo.GetUsedDays(ctx, quota, request.StudentID, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return quota.Usage(usedDays, pendingDays), nil
+}
+
+// checkAbsenceQuota checks approving the request keeps the student within the quota of its category and term.
+// Admins may approve beyond the quota by overriding. A teacher's approval beyond it is held back and
+// the request is flagged for an admin to decide.
+// On failure it returns the status and body to respond with.
+func checkAbsenceQuota(
+	ctx context.Context,
+	quotaRepo repository.AbsenceQuotaRepository,
+	absentRequestRepo repository.AbsentRequestRepository,
+	actor policy.Actor,
+	request *models.AbsentRequest,
+	override bool,
+) (int, fiber.Map) {
+	usage, err := absenceQuotaUsage(ctx, quotaRepo, request)
+	if err != nil {
+		log.Println("error on check absence quota:", err)
+		return fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_absence_quota",
+			"error":         "Failed to check absence quota",
+		}
+	}
+
+	if usage == nil || request.TotalDays <= usage.RemainingDays {
+		return fiber.StatusOK, nil
+	}
+
+	if actor.UserType == models.UserTypeAdmin.String() {
+		if override {
+			return fiber.StatusOK, nil
+		}
+
+		log.Println("error on check absence quota:", models.ErrAbsenceQuotaExceeded)
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.absence_quota_exceeded",
+			"error":         "Approving this request would exceed the absence quota for the term; send override to approve it anyway",
+			"quota":         usage,
+		}
+	}
+
+	if err := absentRequestRepo.FlagQuotaExceeded(ctx, request.ID); err != nil {
+		log.Println("error on check absence quota:", err)
+		return fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_absence_quota",
+			"error":         "Failed to check absence quota",
+		}
+	}
+
+	log.Println("error on check absence quota:", models.ErrAbsenceQuotaExceeded)
+	return fiber.StatusConflict, fiber.Map{
+		"translate_key": "error.absence_quota_exceeded_flagged",
+		"error":         "Approving this request would exceed the absence quota for the term; it has been sent to an admin to decide",
+		"quota":         usage,
+	}
+}
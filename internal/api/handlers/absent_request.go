@@ -1,38 +1,78 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"strconv"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/config"
 	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
 	"github.com/michaelwp/student_attendance/internal/repository"
 )
 
 type absentRequestHandler struct {
 	absentRequestRepo repository.AbsentRequestRepository
 	studentRepo       repository.StudentRepository
+	attachmentRepo    repository.AbsentRequestAttachmentRepository
+	categoryRepo      repository.AbsentRequestCategoryRepository
+	commentRepo       repository.AbsentRequestCommentRepository
+	quotaRepo         repository.AbsenceQuotaRepository
+	guardianRepo      repository.GuardianRepository
+	roleRepo          repository.RoleRepository
+	policy            *policy.AbsentRequestPolicy
+	scopePolicy       *policy.ScopePolicy
+	decider           *absentRequestDecider
+	s3Client          *s3.Client
+	s3Config          *config.S3Config
 }
 
 // NewAbsentRequestHandler creates a new absent request handler
 func NewAbsentRequestHandler(
 	absentRequestRepo repository.AbsentRequestRepository,
-	studentRepo repository.StudentRepository) AbsentRequestHandler {
+	studentRepo repository.StudentRepository,
+	attachmentRepo repository.AbsentRequestAttachmentRepository,
+	categoryRepo repository.AbsentRequestCategoryRepository,
+	commentRepo repository.AbsentRequestCommentRepository,
+	quotaRepo repository.AbsenceQuotaRepository,
+	guardianRepo repository.GuardianRepository,
+	roleRepo repository.RoleRepository,
+	absentRequestPolicy *policy.AbsentRequestPolicy,
+	scopePolicy *policy.ScopePolicy,
+	s3Client *s3.Client,
+	s3Config *config.S3Config) AbsentRequestHandler {
 	return &absentRequestHandler{
 		absentRequestRepo: absentRequestRepo,
 		studentRepo:       studentRepo,
+		attachmentRepo:    attachmentRepo,
+		categoryRepo:      categoryRepo,
+		commentRepo:       commentRepo,
+		quotaRepo:         quotaRepo,
+		guardianRepo:      guardianRepo,
+		roleRepo:          roleRepo,
+		policy:            absentRequestPolicy,
+		scopePolicy:       scopePolicy,
+		decider:           newAbsentRequestDecider(absentRequestRepo, categoryRepo, attachmentRepo, quotaRepo, absentRequestPolicy),
+		s3Client:          s3Client,
+		s3Config:          s3Config,
 	}
 }
 
 // CreateAbsentRequest godoc
 // @Summary Create absent request
-// @Description Create a new absence request
+// @Description Create a new absence request covering a single day or a range of days under a category.
+// @Description Overlapping requests and requests breaking the category's length or notice rules are rejected.
+// @Description When the category has a quota for the term, the response includes the student's remaining allowance as quota.
 // @Tags Absent Requests
 // @Accept json
 // @Produce json
 // @Param request body models.AbsentRequestCreate true "Absent request data"
 // @Success 201 {object} map[string]interface{} "Absent request created successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, date range or category"
+// @Failure 409 {object} map[string]interface{} "Request overlaps an existing request"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /absent-requests [post]
 func (h *absentRequestHandler) Create(c *fiber.Ctx) error {
@@ -48,11 +88,8 @@ func (h *absentRequestHandler) Create(c *fiber.Ctx) error {
 	// Convert to AbsentRequest with proper date parsing
 	request, err := requestCreate.ToAbsentRequest()
 	if err != nil {
-		log.Println("error on create absent request: invalid date format:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.invalid_date_format",
-			"error":         "Invalid date format. Use YYYY-MM-DD format.",
-		})
+		log.Println("error on create absent request: invalid date range:", err)
+		return absentRequestDateError(c, err)
 	}
 
 	studentID := c.Locals("userID")
@@ -90,10 +127,85 @@ func (h *absentRequestHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	return h.file(c, request, student)
+}
+
+// CreateForGuardianChild godoc
+// @Summary Create absent request for a child
+// @Description File an absence request on behalf of a student linked to the logged-in guardian.
+// @Description The same category, overlap and quota rules apply as when the student files it.
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student database ID"
+// @Param request body models.AbsentRequestCreate true "Absent request data"
+// @Success 201 {object} map[string]interface{} "Absent request created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, date range or category"
+// @Failure 404 {object} map[string]interface{} "Student not found among the guardian's children"
+// @Failure 409 {object} map[string]interface{} "Request overlaps an existing request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /guardian/children/record-id/{id}/absent-requests [post]
+func (h *absentRequestHandler) CreateForGuardianChild(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "create absent request for child")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	student, status, errBody := linkedChild(c, h.guardianRepo, h.studentRepo, actor.UserID, "create absent request for child")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var requestCreate models.AbsentRequestCreate
+	if err := c.BodyParser(&requestCreate); err != nil {
+		log.Println("error on create absent request for child:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	request, err := requestCreate.ToAbsentRequest()
+	if err != nil {
+		log.Println("error on create absent request for child: invalid date range:", err)
+		return absentRequestDateError(c, err)
+	}
+
+	request.SubmittedByGuardian = &actor.UserID
+
+	return h.file(c, request, student)
+}
+
+// file checks and saves a new request for the student and responds with it
+func (h *absentRequestHandler) file(c *fiber.Ctx, request *models.AbsentRequest, student *models.Student) error {
 	request.StudentID = student.StudentID
 	request.ClassID = student.ClassesID
 	request.Status = models.AbsentRequestStatusPending
 
+	category, status, errBody := checkRequestCategory(c.Context(), h.categoryRepo, request)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+	request.Category = category
+
+	overlaps, err := h.absentRequestRepo.HasOverlap(c.Context(), request.StudentID, request.StartDate, request.EndDate, 0)
+	if err != nil {
+		log.Println("error on create absent request:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_absent_request",
+			"error":         "Failed to create absent request",
+		})
+	}
+
+	if overlaps {
+		log.Println("error on create absent request: overlaps an existing request")
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"translate_key": "error.absent_request_overlaps",
+			"error":         "An absent request already covers some of these dates",
+		})
+	}
+
 	if err := h.absentRequestRepo.Create(c.Context(), request); err != nil {
 		log.Println("error on create absent request:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -102,16 +214,24 @@ func (h *absentRequestHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	// the request is already saved, so a failed lookup only leaves the allowance out of the response
+	quota, err := absenceQuotaUsage(c.Context(), h.quotaRepo, request)
+	if err != nil {
+		log.Println("error on get absence quota usage:", err)
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"translate_key": "success.absent_request_created",
 		"message":       "Absent request created successfully",
 		"data":          request,
+		"quota":         quota,
 	})
 }
 
 // GetAbsentRequestByID godoc
 // @Summary Get absent request by ID
-// @Description Retrieve a specific absent request by ID
+// @Description Retrieve a specific absent request by ID, including presigned links to its attachments, its comment thread
+// @Description and the earlier versions the student amended
 // @Tags Absent Requests
 // @Accept json
 // @Produce json
@@ -131,12 +251,34 @@ func (h *absentRequestHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	request, err := h.absentRequestRepo.GetByID(c.Context(), uint(id))
+	request, status, errBody := h.scopedRequest(c, uint(id), "get absent request by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := loadAttachments(c.Context(), h.attachmentRepo, h.s3Client, h.s3Config, request); err != nil {
+		log.Println("error on get absent request attachments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_attachments",
+			"error":         "Failed to get attachments",
+		})
+	}
+
+	request.Comments, err = h.commentRepo.GetByAbsentRequest(c.Context(), request.ID)
 	if err != nil {
-		log.Println("error on get absent request by id:", err)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"translate_key": "error.absent_request_not_found",
-			"error":         "Absent request not found",
+		log.Println("error on get absent request comments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_comments",
+			"error":         "Failed to get comments",
+		})
+	}
+
+	request.Versions, err = h.absentRequestRepo.GetVersions(c.Context(), request.ID)
+	if err != nil {
+		log.Println("error on get absent request versions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_absent_request_versions",
+			"error":         "Failed to get absent request versions",
 		})
 	}
 
@@ -158,6 +300,7 @@ func (h *absentRequestHandler) GetByID(c *fiber.Ctx) error {
 // @Param offset query int false "Number of requests to skip" default(0)
 // @Success 200 {object} map[string]interface{} "Absent requests retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Student ID is required"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /absent-requests/student-id/{studentId} [get]
 func (h *absentRequestHandler) GetByStudent(c *fiber.Ctx) error {
@@ -177,6 +320,19 @@ func (h *absentRequestHandler) GetByStudent(c *fiber.Ctx) error {
 		limit = 100
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get absent requests by student")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if !studentInScope(c.Context(), h.studentRepo, scope, studentID) {
+		log.Println("error on get absent requests by student:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student_not_found",
+			"error":         "Student not found",
+		})
+	}
+
 	requests, err := h.absentRequestRepo.GetByStudent(c.Context(), studentID, limit, offset)
 	if err != nil {
 		log.Println("error on get absent requests by student:", err)
@@ -207,6 +363,7 @@ func (h *absentRequestHandler) GetByStudent(c *fiber.Ctx) error {
 // @Param offset query int false "Number of requests to skip" default(0)
 // @Success 200 {object} map[string]interface{} "Absent requests retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid class ID"
+// @Failure 404 {object} map[string]interface{} "Class not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /absent-requests/class-id/{classId} [get]
 func (h *absentRequestHandler) GetByClass(c *fiber.Ctx) error {
@@ -227,6 +384,19 @@ func (h *absentRequestHandler) GetByClass(c *fiber.Ctx) error {
 		limit = 100
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get absent requests by class")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if !scope.AllowsClass(uint(classID)) {
+		log.Println("error on get absent requests by class:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.class_not_found",
+			"error":         "Class not found",
+		})
+	}
+
 	requests, err := h.absentRequestRepo.GetByClass(c.Context(), uint(classID), limit, offset)
 	if err != nil {
 		log.Println("error on get absent requests by class:", err)
@@ -246,9 +416,63 @@ func (h *absentRequestHandler) GetByClass(c *fiber.Ctx) error {
 	})
 }
 
+// GetAbsentRequestsByDate godoc
+// @Summary Get absent requests by date
+// @Description Retrieve the absent requests the caller may see whose date range includes the given day
+// @Tags Absent Requests
+// @Accept json
+// @Produce json
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Param limit query int false "Number of requests to return (max 100)" default(10)
+// @Param offset query int false "Number of requests to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Absent requests retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid date format"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /absent-requests/date/{date} [get]
+func (h *absentRequestHandler) GetByDate(c *fiber.Ctx) error {
+	date, err := time.Parse("2006-01-02", c.Params("date"))
+	if err != nil {
+		log.Println("error on get absent requests by date:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_date_format",
+			"error":         "Invalid date format. Use YYYY-MM-DD format.",
+		})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get absent requests by date")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	requests, err := h.absentRequestRepo.GetByDate(c.Context(), scope, date, limit, offset)
+	if err != nil {
+		log.Println("error on get absent requests by date:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_absent_requests",
+			"error":         "Failed to get absent requests",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absent_requests_retrieved",
+		"message":       "Absent requests retrieved successfully",
+		"data":          requests,
+		"count":         len(requests),
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
 // GetPendingAbsentRequests godoc
 // @Summary Get pending absent requests
-// @Description Retrieve all pending absent requests
+// @Description Retrieve the pending absent requests the caller may see
 // @Tags Absent Requests
 // @Accept json
 // @Produce json
@@ -265,7 +489,12 @@ func (h *absentRequestHandler) GetPending(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	requests, err := h.absentRequestRepo.GetPending(c.Context(), limit, offset)
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get pending absent requests")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	requests, err := h.absentRequestRepo.GetPending(c.Context(), scope, limit, offset)
 	if err != nil {
 		log.Println("error on get pending absent requests:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -284,20 +513,79 @@ func (h *absentRequestHandler) GetPending(c *fiber.Ctx) error {
 	})
 }
 
+// GetEscalatedAbsentRequests godoc
+// @Summary Get escalated absent requests
+// @Description Retrieve the admin queue of absent requests that stayed pending past the escalation SLA, or whose approval
+// @Description was held back by the absence quota, and are still undecided
+// @Tags Absent Requests
+// @Accept json
+// @Produce json
+// @Param limit query int false "Number of requests to return (max 100)" default(10)
+// @Param offset query int false "Number of requests to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Escalated absent requests retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absent-requests/escalated [get]
+func (h *absentRequestHandler) GetEscalated(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	requests, err := h.absentRequestRepo.GetEscalated(c.Context(), limit, offset)
+	if err != nil {
+		log.Println("error on get escalated absent requests:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_escalated_absent_requests",
+			"error":         "Failed to get escalated absent requests",
+		})
+	}
+
+	total, err := h.absentRequestRepo.GetEscalatedCount(c.Context())
+	if err != nil {
+		log.Println("error on get escalated absent requests count:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_escalated_absent_requests",
+			"error":         "Failed to get escalated absent requests",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.escalated_absent_requests_retrieved",
+		"message":       "Escalated absent requests retrieved successfully",
+		"data":          requests,
+		"count":         len(requests),
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
 // UpdateAbsentRequestStatus godoc
 // @Summary Update absent request status
-// @Description Update the status of an absent request (approve/reject)
+// @Description Approve or reject an absent request. Teachers may only decide pending requests of their classes, when their role in the class allows it
+// @Description and students cannot change the status at all. Admins may change a decision already made, or approve beyond
+// @Description the absence quota, by sending override, which needs the absent_request.override permission.
 // @Tags Absent Requests
 // @Accept json
 // @Produce json
 // @Param id path int true "Absent request ID"
-// @Param status body object true "Status update with status field"
+// @Param status body object true "Status update with status (approved or rejected), an optional reviewer note and, for admins, override"
 // @Success 200 {object} map[string]interface{} "Absent request status updated successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request or status value"
+// @Failure 403 {object} map[string]interface{} "Not allowed to decide this request or to override"
 // @Failure 404 {object} map[string]interface{} "Absent request not found"
+// @Failure 409 {object} map[string]interface{} "Request already decided, or changed while it was being decided"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /absent-requests/{id}/status [patch]
+// @Router /absent-requests/absent-request-id/{id}/status [patch]
+// @Router /admins/absent-requests/absent-request-id/{id}/status [patch]
 func (h *absentRequestHandler) UpdateStatus(c *fiber.Ctx) error {
+	actor, errStatus, errBody := currentActor(c, "update absent request status")
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
+	}
+
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -309,7 +597,9 @@ func (h *absentRequestHandler) UpdateStatus(c *fiber.Ctx) error {
 	}
 
 	var statusUpdate struct {
-		Status models.AbsentRequestStatus `json:"status"`
+		Status   models.AbsentRequestStatus `json:"status"`
+		Note     string                     `json:"note"`
+		Override bool                       `json:"override"`
 	}
 
 	if err := c.BodyParser(&statusUpdate); err != nil {
@@ -322,8 +612,7 @@ func (h *absentRequestHandler) UpdateStatus(c *fiber.Ctx) error {
 
 	// Validate status
 	if statusUpdate.Status != models.AbsentRequestStatusApproved &&
-		statusUpdate.Status != models.AbsentRequestStatusRejected &&
-		statusUpdate.Status != models.AbsentRequestStatusPending {
+		statusUpdate.Status != models.AbsentRequestStatusRejected {
 		log.Println("error on update absent request status: invalid status value")
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"translate_key": "error.invalid_status_value",
@@ -331,24 +620,33 @@ func (h *absentRequestHandler) UpdateStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get existing request
-	request, err := h.absentRequestRepo.GetByID(c.Context(), uint(id))
-	if err != nil {
-		log.Println("error on update absent request status:", err)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"translate_key": "error.absent_request_not_found",
-			"error":         "Absent request not found",
-		})
+	// the generic route only needs absent_request.decide, so overriding is checked here
+	if statusUpdate.Override {
+		allowed, err := h.roleRepo.HasPermission(c.Context(), actor.UserType, actor.UserID, models.PermissionAbsentRequestOverride)
+		if err != nil {
+			log.Println("error on update absent request status: failed to check permission:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_check_permission",
+				"error":         "Failed to check permission",
+			})
+		}
+
+		if !allowed {
+			log.Println("error on update absent request status: override not permitted")
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"translate_key": "error.insufficient_permissions",
+				"error":         "Insufficient permissions for this operation",
+			})
+		}
 	}
 
-	// Update status
-	request.Status = statusUpdate.Status
-	if err := h.absentRequestRepo.Update(c.Context(), request); err != nil {
-		log.Println("error on update absent request status:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_update_absent_request_status",
-			"error":         "Failed to update absent request status",
-		})
+	if _, errStatus, errBody := h.scopedRequest(c, uint(id), "update absent request status"); errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
+	}
+
+	request, errStatus, errBody := h.decider.decide(c.Context(), actor, uint(id), statusUpdate.Status, statusUpdate.Note, statusUpdate.Override)
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
 	}
 
 	return c.JSON(fiber.Map{
@@ -358,57 +656,76 @@ func (h *absentRequestHandler) UpdateStatus(c *fiber.Ctx) error {
 	})
 }
 
-// DeleteAbsentRequest godoc
-// @Summary Delete absent request
-// @Description Delete an absent request
+// WithdrawAbsentRequest godoc
+// @Summary Withdraw absent request
+// @Description Withdraw a pending absent request of the current student. The request is kept with the withdrawn status
+// @Description so reviewers can still see it, but it no longer blocks its dates and can no longer be decided.
 // @Tags Absent Requests
 // @Accept json
 // @Produce json
 // @Param id path int true "Absent request ID"
-// @Success 200 {object} map[string]interface{} "Absent request deleted successfully"
+// @Success 200 {object} map[string]interface{} "Absent request withdrawn successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid absent request ID"
+// @Failure 404 {object} map[string]interface{} "Absent request not found"
+// @Failure 409 {object} map[string]interface{} "Request already decided or withdrawn"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /absent-requests/{id} [delete]
-func (h *absentRequestHandler) Delete(c *fiber.Ctx) error {
+// @Router /absent-requests/absent-request-id/{id}/withdraw [post]
+func (h *absentRequestHandler) Withdraw(c *fiber.Ctx) error {
+	actor, errStatus, errBody := currentActor(c, "withdraw absent request")
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
+	}
+
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		log.Println("error on delete absent request:", err)
+		log.Println("error on withdraw absent request:", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"translate_key": "error.invalid_absent_request_id",
 			"error":         "Invalid absent request ID",
 		})
 	}
 
-	studentID := c.Locals("userID")
-	if studentID == nil {
-		log.Println("error on delete absent requests: invalid student id")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.invalid_student_id",
-			"error":         "Invalid student ID",
+	request, err := h.absentRequestRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on withdraw absent request:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absent_request_not_found",
+			"error":         "Absent request not found",
 		})
 	}
 
-	studentIDUint, err := strconv.ParseUint(studentID.(string), 10, 64)
-	if err != nil {
-		log.Println("error on delete absent request:", err)
+	if err := h.policy.CanAmend(c.Context(), actor, request); err != nil {
+		log.Println("error on withdraw absent request:", err)
+		errStatus, errBody := absentRequestPolicyError(err)
+		return c.Status(errStatus).JSON(errBody)
+	}
+
+	if err := h.absentRequestRepo.Withdraw(c.Context(), request.ID, request.StudentID); err != nil {
+		log.Println("error on withdraw absent request:", err)
+		if errors.Is(err, models.ErrAbsentRequestNotPending) {
+			errStatus, errBody := absentRequestPolicyError(err)
+			return c.Status(errStatus).JSON(errBody)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_delete_absent_request",
-			"error":         "Failed to delete absent request",
+			"translate_key": "error.failed_to_withdraw_absent_request",
+			"error":         "Failed to withdraw absent request",
 		})
 	}
 
-	if err := h.absentRequestRepo.UpdateDeleteInfo(c.Context(), uint(id), uint(studentIDUint), uint(studentIDUint)); err != nil {
-		log.Println("error on delete absent request:", err)
+	request, err = h.absentRequestRepo.GetByID(c.Context(), request.ID)
+	if err != nil {
+		log.Println("error on withdraw absent request:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_delete_absent_request",
-			"error":         "Failed to delete absent request",
+			"translate_key": "error.failed_to_get_absent_request",
+			"error":         "Failed to get absent request",
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"translate_key": "success.absent_request_deleted",
-		"message":       "Absent request deleted successfully",
+		"translate_key": "success.absent_request_withdrawn",
+		"message":       "Absent request withdrawn successfully",
+		"data":          request,
 	})
 }
 
@@ -443,9 +760,9 @@ func (h *absentRequestHandler) GetByCurrentStudent(c *fiber.Ctx) error {
 		})
 	}
 
-	student, err := h.absentRequestRepo.GetByID(c.Context(), uint(userIDUint))
+	student, err := h.studentRepo.GetByID(c.Context(), uint(userIDUint))
 	if err != nil {
-		log.Println("error on get absent requests: failed to get student")
+		log.Println("error on get absent requests: failed to get student:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"translate_key": "error.failed_to_get_absent_requests",
 			"error":         "Failed to get absent requests",
@@ -459,7 +776,7 @@ func (h *absentRequestHandler) GetByCurrentStudent(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	students, err := h.absentRequestRepo.GetByStudent(c.Context(), student.StudentID, limit, offset)
+	requests, err := h.absentRequestRepo.GetByStudent(c.Context(), student.StudentID, limit, offset)
 	if err != nil {
 		log.Println("error on get absent requests:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -468,7 +785,7 @@ func (h *absentRequestHandler) GetByCurrentStudent(c *fiber.Ctx) error {
 		})
 	}
 
-	totalAbsentRequests, err := h.absentRequestRepo.GetTotalAbsentRequests(c.Context())
+	totalAbsentRequests, err := h.absentRequestRepo.GetCountByStudent(c.Context(), student.StudentID)
 	if err != nil {
 		log.Println("error on get total absent requests:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -480,7 +797,7 @@ func (h *absentRequestHandler) GetByCurrentStudent(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"translate_key": "success.absent_requests_retrieved",
 		"message":       "Absent requests retrieved successfully",
-		"data":          students,
+		"data":          requests,
 		"total":         totalAbsentRequests,
 		"limit":         limit,
 		"offset":        offset,
@@ -489,7 +806,8 @@ func (h *absentRequestHandler) GetByCurrentStudent(c *fiber.Ctx) error {
 
 // UpdateByCurrentStudent godoc
 // @Summary Update absent request by current student
-// @Description Update an absent request's date and reason if it belongs to the current student and is pending
+// @Description Update an absent request's date range, category and reason if it belongs to the current student and is pending.
+// @Description The request as it stood before is kept as a version, so reviewers can see what changed.
 // @Tags Absent Requests
 // @Accept json
 // @Produce json
@@ -499,16 +817,13 @@ func (h *absentRequestHandler) GetByCurrentStudent(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{} "Invalid request or ID"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 404 {object} map[string]interface{} "Absent request not found"
+// @Failure 409 {object} map[string]interface{} "Request overlaps an existing request, or is already decided or withdrawn"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /absent-requests/absent-request-id/{id} [put]
 func (h *absentRequestHandler) UpdateByCurrentStudent(c *fiber.Ctx) error {
-	studentIDLocal := c.Locals("userID")
-	if studentIDLocal == nil {
-		log.Println("error on update absent request: invalid student id")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"translate_key": "error.invalid_student_id",
-			"error":         "Invalid student ID",
-		})
+	actor, errStatus, errBody := currentActor(c, "update absent request")
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
 	}
 
 	idParam := c.Params("id")
@@ -521,17 +836,6 @@ func (h *absentRequestHandler) UpdateByCurrentStudent(c *fiber.Ctx) error {
 		})
 	}
 
-	// Load current student to get StudentID string
-	studentRecordID, _ := strconv.ParseUint(studentIDLocal.(string), 10, 32)
-	student, err := h.studentRepo.GetByID(c.Context(), uint(studentRecordID))
-	if err != nil || student == nil {
-		log.Println("error on update absent request: failed to get student")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"translate_key": "error.student_not_found",
-			"error":         "Student not found",
-		})
-	}
-
 	existing, err := h.absentRequestRepo.GetByID(c.Context(), uint(id))
 	if err != nil || existing == nil {
 		log.Println("error on update absent request: not found")
@@ -541,18 +845,11 @@ func (h *absentRequestHandler) UpdateByCurrentStudent(c *fiber.Ctx) error {
 		})
 	}
 
-	// Ownership and status checks
-	if existing.StudentID != student.StudentID {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"translate_key": "error.unauthorized",
-			"error":         "You are not allowed to update this request",
-		})
-	}
-	if existing.Status != models.AbsentRequestStatusPending {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.absent_request_not_pending",
-			"error":         "Only pending requests can be updated",
-		})
+	// only the student who filed the request may change it, and only until it is decided
+	if err := h.policy.CanAmend(c.Context(), actor, existing); err != nil {
+		log.Println("error on update absent request:", err)
+		errStatus, errBody := absentRequestPolicyError(err)
+		return c.Status(errStatus).JSON(errBody)
 	}
 
 	var updateBody models.AbsentRequestCreate
@@ -565,18 +862,53 @@ func (h *absentRequestHandler) UpdateByCurrentStudent(c *fiber.Ctx) error {
 	}
 	converted, err := updateBody.ToAbsentRequest()
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.invalid_date_format",
-			"error":         "Invalid date format. Use YYYY-MM-DD format.",
+		log.Println("error on update absent request: invalid date range:", err)
+		return absentRequestDateError(c, err)
+	}
+
+	// keep the current category when the body does not name one
+	if converted.CategoryID == nil {
+		converted.CategoryID = existing.CategoryID
+	}
+
+	category, status, errBody := checkRequestCategory(c.Context(), h.categoryRepo, converted)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	overlaps, err := h.absentRequestRepo.HasOverlap(c.Context(), existing.StudentID, converted.StartDate, converted.EndDate, existing.ID)
+	if err != nil {
+		log.Println("error on update absent request:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_absent_request",
+			"error":         "Failed to update absent request",
+		})
+	}
+
+	if overlaps {
+		log.Println("error on update absent request: overlaps an existing request")
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"translate_key": "error.absent_request_overlaps",
+			"error":         "An absent request already covers some of these dates",
 		})
 	}
 
 	// Apply updates
 	existing.RequestDate = converted.RequestDate
+	existing.StartDate = converted.StartDate
+	existing.EndDate = converted.EndDate
+	existing.ExcludeNonSchoolDays = converted.ExcludeNonSchoolDays
+	existing.TotalDays = converted.TotalDays
 	existing.Reason = converted.Reason
+	existing.CategoryID = converted.CategoryID
+	existing.Category = category
 
-	if err := h.absentRequestRepo.Update(c.Context(), existing); err != nil {
+	if err := h.absentRequestRepo.Amend(c.Context(), existing, actor.UserID); err != nil {
 		log.Println("error on update absent request: repo update failed", err)
+		if errors.Is(err, models.ErrAbsentRequestNotPending) {
+			errStatus, errBody := absentRequestPolicyError(err)
+			return c.Status(errStatus).JSON(errBody)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"translate_key": "error.failed_to_update_absent_request",
 			"error":         "Failed to update absent request",
@@ -589,3 +921,48 @@ func (h *absentRequestHandler) UpdateByCurrentStudent(c *fiber.Ctx) error {
 		"data":          existing,
 	})
 }
+
+// absentRequestDateError responds to a request date range that cannot be accepted
+func absentRequestDateError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, models.ErrAbsentRequestInvalidRange):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_date_range",
+			"error":         "End date must not be before start date",
+		})
+	case errors.Is(err, models.ErrAbsentRequestNoSchoolDays):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.no_school_days_in_range",
+			"error":         "The selected dates contain no school days",
+		})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_date_format",
+			"error":         "Invalid date format. Use YYYY-MM-DD format.",
+		})
+	}
+}
+
+// scopedRequest loads the absent request, answering not found
+// when it is outside the caller's record scope
+func (h *absentRequestHandler) scopedRequest(c *fiber.Ctx, id uint, action string) (*models.AbsentRequest, int, fiber.Map) {
+	scope, status, errBody := currentScope(c, h.scopePolicy, action)
+	if errBody != nil {
+		return nil, status, errBody
+	}
+
+	request, err := h.absentRequestRepo.GetByID(c.Context(), id)
+	if err == nil && !scope.AllowsStudent(request.StudentID, request.ClassID) {
+		err = policy.ErrOutOfScope
+	}
+
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.absent_request_not_found",
+			"error":         "Absent request not found",
+		}
+	}
+
+	return request, fiber.StatusOK, nil
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/config"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+const maxAttachmentsPerRequest = 5
+
+// allowedAttachmentTypes maps the accepted content types of supporting documents to their file extension
+var allowedAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+// maxAttachmentSize returns the largest accepted attachment in bytes, set in MB by ABSENT_REQUEST_ATTACHMENT_MAX_SIZE_MB
+func maxAttachmentSize() int64 {
+	sizeMB, err := strconv.ParseInt(os.Getenv("ABSENT_REQUEST_ATTACHMENT_MAX_SIZE_MB"), 10, 64)
+	if err != nil || sizeMB <= 0 {
+		sizeMB = 5
+	}
+	return sizeMB * 1024 * 1024
+}
+
+// UploadAttachments godoc
+// @Summary Upload absent request attachments
+// @Description Attach one or more supporting documents (PDF, JPEG, PNG or WebP) to a pending absent request of the current student
+// @Tags Absent Requests
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absent request ID"
+// @Param files formData file true "Supporting documents"
+// @Success 201 {object} map[string]interface{} "Attachments uploaded successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid file"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Absent request not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /absent-requests/absent-request-id/{id}/attachments [post]
+func (h *absentRequestHandler) UploadAttachments(c *fiber.Ctx) error {
+	student, request, status, errBody := h.getOwnPendingRequest(c, "upload absent request attachments")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		log.Println("error on upload absent request attachments: no files provided:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.no_file_uploaded",
+			"error":         "No file uploaded",
+		})
+	}
+	files := form.File["files"]
+
+	count, err := h.attachmentRepo.GetCountByAbsentRequest(c.Context(), request.ID)
+	if err != nil {
+		log.Println("error on upload absent request attachments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_upload_file",
+			"error":         "Failed to upload attachments",
+		})
+	}
+
+	if count+len(files) > maxAttachmentsPerRequest {
+		log.Println("error on upload absent request attachments: too many attachments")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.too_many_attachments",
+			"error":         fmt.Sprintf("An absent request can have at most %d attachments", maxAttachmentsPerRequest),
+		})
+	}
+
+	// read and validate every file before uploading any of them
+	maxSize := maxAttachmentSize()
+	buffers := make([][]byte, len(files))
+	contentTypes := make([]string, len(files))
+	for i, file := range files {
+		if file.Size > maxSize {
+			log.Println("error on upload absent request attachments: file too large:", file.Filename)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.file_too_large",
+				"error":         fmt.Sprintf("%s exceeds the maximum size of %d MB", file.Filename, maxSize/1024/1024),
+			})
+		}
+
+		fileContent, err := file.Open()
+		if err != nil {
+			log.Println("error on open file content:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_open_file",
+				"error":         "Failed to open file",
+			})
+		}
+
+		buffer, err := io.ReadAll(fileContent)
+		_ = fileContent.Close()
+		if err != nil {
+			log.Println("error on get buffer from file content:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_read_file",
+				"error":         "Failed to read file",
+			})
+		}
+
+		// trust the file content rather than the declared content type
+		contentType := http.DetectContentType(buffer)
+		if _, ok := allowedAttachmentTypes[contentType]; !ok {
+			log.Println("error on upload absent request attachments: unsupported content type:", contentType)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.unsupported_file_type",
+				"error":         fmt.Sprintf("%s must be a PDF, JPEG, PNG or WebP file", file.Filename),
+			})
+		}
+
+		buffers[i] = buffer
+		contentTypes[i] = contentType
+	}
+
+	attachments := make([]*models.AbsentRequestAttachment, 0, len(files))
+	for i, file := range files {
+		filename := fmt.Sprintf("absent_request_%d_%d_%d%s", request.ID, time.Now().UnixNano(), i, allowedAttachmentTypes[contentTypes[i]])
+		key := fmt.Sprintf("attachments/absent-requests/%d/%s", request.ID, filename)
+
+		if err := h.s3Config.UploadFile(h.s3Client, key, buffers[i]); err != nil {
+			log.Println("error on upload file to S3:", err)
+			h.deleteAttachmentFiles(attachments, "upload absent request attachments")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_upload_file",
+				"error":         "Failed to upload file to S3",
+			})
+		}
+
+		attachments = append(attachments, &models.AbsentRequestAttachment{
+			AbsentRequestID: request.ID,
+			FileName:        file.Filename,
+			FilePath:        key,
+			ContentType:     contentTypes[i],
+			FileSize:        int64(len(buffers[i])),
+			UploadedBy:      student.ID,
+		})
+	}
+
+	// either every file is attached or none is, and then none of them is kept in storage
+	if err := h.attachmentRepo.CreateAll(c.Context(), request.ID, attachments, maxAttachmentsPerRequest); err != nil {
+		log.Println("error on create absent request attachments:", err)
+		h.deleteAttachmentFiles(attachments, "upload absent request attachments")
+
+		if errors.Is(err, models.ErrTooManyAttachments) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.too_many_attachments",
+				"error":         fmt.Sprintf("An absent request can have at most %d attachments", maxAttachmentsPerRequest),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_upload_file",
+			"error":         "Failed to save attachments",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.attachments_uploaded",
+		"message":       "Attachments uploaded successfully",
+		"data":          attachments,
+	})
+}
+
+// DeleteAttachment godoc
+// @Summary Delete absent request attachment
+// @Description Remove a supporting document from a pending absent request of the current student. The file is deleted from storage too.
+// @Tags Absent Requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absent request ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {object} map[string]interface{} "Attachment deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid attachment ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Attachment not found"
+// @Router /absent-requests/absent-request-id/{id}/attachments/{attachmentId} [delete]
+func (h *absentRequestHandler) DeleteAttachment(c *fiber.Ctx) error {
+	_, request, status, errBody := h.getOwnPendingRequest(c, "delete absent request attachment")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	attachmentID, err := strconv.ParseUint(c.Params("attachmentId"), 10, 32)
+	if err != nil {
+		log.Println("error on delete absent request attachment:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_attachment_id",
+			"error":         "Invalid attachment ID",
+		})
+	}
+
+	attachment, err := h.attachmentRepo.GetByID(c.Context(), uint(attachmentID))
+	if err != nil || attachment.AbsentRequestID != request.ID {
+		log.Println("error on delete absent request attachment: attachment not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.attachment_not_found",
+			"error":         "Attachment not found",
+		})
+	}
+
+	if err := h.attachmentRepo.Delete(c.Context(), attachment.ID); err != nil {
+		log.Println("error on delete absent request attachment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_delete_attachment",
+			"error":         "Failed to delete attachment",
+		})
+	}
+
+	// the file may be a medical document, so it does not outlive its attachment. The attachment
+	// is already gone for the student, so a failure is only logged with the key to clean up.
+	if err := h.s3Config.DeleteFile(h.s3Client, attachment.FilePath); err != nil {
+		log.Printf("error on delete absent request attachment: failed to delete file %s: %v\n", attachment.FilePath, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.attachment_deleted",
+		"message":       "Attachment deleted successfully",
+	})
+}
+
+// deleteAttachmentFiles removes the files of attachments that were not saved from storage.
+// A failure is only logged with the key to clean up.
+func (h *absentRequestHandler) deleteAttachmentFiles(attachments []*models.AbsentRequestAttachment, action string) {
+	for _, attachment := range attachments {
+		if err := h.s3Config.DeleteFile(h.s3Client, attachment.FilePath); err != nil {
+			log.Printf("error on %s: failed to delete file %s: %v\n", action, attachment.FilePath, err)
+		}
+	}
+}
+
+// getOwnPendingRequest loads the absent request in the path and checks it is a pending request of the current student.
+// On failure it returns the status and body to respond with.
+func (h *absentRequestHandler) getOwnPendingRequest(c *fiber.Ctx, action string) (*models.Student, *models.AbsentRequest, int, fiber.Map) {
+	userID := c.Locals("userID")
+	if userID == nil {
+		log.Printf("error on %s: invalid student id\n", action)
+		return nil, nil, fiber.StatusUnauthorized, fiber.Map{
+			"translate_key": "error.invalid_student_id",
+			"error":         "Invalid student ID",
+		}
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: invalid id param\n", action)
+		return nil, nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_absent_request_id",
+			"error":         "Invalid absent request ID",
+		}
+	}
+
+	// a request of another student answers the same as a missing one, so its existence is not revealed
+	studentRecordID, _ := strconv.ParseUint(userID.(string), 10, 32)
+	student, err := h.studentRepo.GetByID(c.Context(), uint(studentRecordID))
+	var request *models.AbsentRequest
+	if err == nil {
+		request, err = h.absentRequestRepo.GetByID(c.Context(), uint(id))
+	}
+	if err == nil && request.StudentID != student.StudentID {
+		err = policy.ErrOutOfScope
+	}
+
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.absent_request_not_found",
+			"error":         "Absent request not found",
+		}
+	}
+
+	if request.Status != models.AbsentRequestStatusPending {
+		return nil, nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.absent_request_not_pending",
+			"error":         "Only pending requests can be updated",
+		}
+	}
+
+	return student, request, fiber.StatusOK, nil
+}
+
+// loadAttachments fills in the attachments of each request with presigned download links
+func loadAttachments(
+	ctx context.Context,
+	attachmentRepo repository.AbsentRequestAttachmentRepository,
+	s3Client *s3.Client,
+	s3Config *config.S3Config,
+	requests ...*models.AbsentRequest,
+) error {
+	ids := make([]uint, 0, len(requests))
+	byID := make(map[uint]*models.AbsentRequest, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.ID)
+		byID[request.ID] = request
+	}
+
+	attachments, err := attachmentRepo.GetByAbsentRequestIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		signedURL, err := s3Config.GetSignedURL(s3Client, attachment.FilePath, time.Hour)
+		if err != nil {
+			return fmt.Errorf("failed to generate signed URL: %w", err)
+		}
+		attachment.URL = signedURL
+
+		request := byID[attachment.AbsentRequestID]
+		request.Attachments = append(request.Attachments, attachment)
+	}
+
+	return nil
+}
@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type absentRequestCategoryHandler struct {
+	categoryRepo      repository.AbsentRequestCategoryRepository
+	absentRequestRepo repository.AbsentRequestRepository
+}
+
+// NewAbsentRequestCategoryHandler creates a new absent request category handler
+func NewAbsentRequestCategoryHandler(
+	categoryRepo repository.AbsentRequestCategoryRepository,
+	absentRequestRepo repository.AbsentRequestRepository,
+) AbsentRequestCategoryHandler {
+	return &absentRequestCategoryHandler{
+		categoryRepo:      categoryRepo,
+		absentRequestRepo: absentRequestRepo,
+	}
+}
+
+// Create godoc
+// @Summary Create absent request category
+// @Description Create a category for absent requests together with its rules
+// @Tags Absent Request Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param category body models.AbsentRequestCategory true "Category data"
+// @Success 201 {object} map[string]interface{} "Absent request category created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 409 {object} map[string]interface{} "Category code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absent-request-categories [post]
+func (h *absentRequestCategoryHandler) Create(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create absent request category")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	// new categories are active and excuse the absence unless told otherwise
+	category := models.AbsentRequestCategory{IsActive: true, MarksExcused: true}
+	if err := c.BodyParser(&category); err != nil {
+		log.Println("error on create absent request category:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	category.ID = 0
+	category.CreatedBy = &adminID
+	if status, errBody := h.validateCategory(c.Context(), &category); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.categoryRepo.Create(c.Context(), &category); err != nil {
+		log.Println("error on create absent request category:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_absent_request_category",
+			"error":         "Failed to create absent request category",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.absent_request_category_created",
+		"message":       "Absent request category created successfully",
+		"data":          category,
+	})
+}
+
+// GetAll godoc
+// @Summary Get absent request categories
+// @Description Retrieve every absent request category, including inactive ones
+// @Tags Absent Request Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Absent request categories retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absent-request-categories [get]
+func (h *absentRequestCategoryHandler) GetAll(c *fiber.Ctx) error {
+	return h.getCategories(c, false)
+}
+
+// GetActive godoc
+// @Summary Get active absent request categories
+// @Description Retrieve the categories a student can file an absent request under, with their rules
+// @Tags Absent Requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Absent request categories retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /absent-requests/categories [get]
+func (h *absentRequestCategoryHandler) GetActive(c *fiber.Ctx) error {
+	return h.getCategories(c, true)
+}
+
+func (h *absentRequestCategoryHandler) getCategories(c *fiber.Ctx, activeOnly bool) error {
+	categories, err := h.categoryRepo.GetAll(c.Context(), activeOnly)
+	if err != nil {
+		log.Println("error on get absent request categories:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_absent_request_categories",
+			"error":         "Failed to get absent request categories",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absent_request_categories_retrieved",
+		"message":       "Absent request categories retrieved successfully",
+		"data":          categories,
+	})
+}
+
+// GetByID godoc
+// @Summary Get absent request category by ID
+// @Description Retrieve a specific absent request category
+// @Tags Absent Request Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absent request category ID"
+// @Success 200 {object} map[string]interface{} "Absent request category retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid absent request category ID"
+// @Failure 404 {object} map[string]interface{} "Absent request category not found"
+// @Router /admins/absent-request-categories/category-id/{id} [get]
+func (h *absentRequestCategoryHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on get absent request category by id:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absent_request_category_id",
+			"error":         "Invalid absent request category ID",
+		})
+	}
+
+	category, err := h.categoryRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on get absent request category by id:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absent_request_category_not_found",
+			"error":         "Absent request category not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absent_request_category_retrieved",
+		"message":       "Absent request category retrieved successfully",
+		"data":          category,
+	})
+}
+
+// Update godoc
+// @Summary Update absent request category
+// @Description Update an absent request category and its rules. Fields left out of the body keep their value.
+// @Tags Absent Request Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absent request category ID"
+// @Param category body models.AbsentRequestCategory true "Category data"
+// @Success 200 {object} map[string]interface{} "Absent request category updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 404 {object} map[string]interface{} "Absent request category not found"
+// @Failure 409 {object} map[string]interface{} "Category code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/absent-request-categories/category-id/{id} [put]
+func (h *absentRequestCategoryHandler) Update(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update absent request category")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on update absent request category:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absent_request_category_id",
+			"error":         "Invalid absent request category ID",
+		})
+	}
+
+	category, err := h.categoryRepo.GetByID(c.Context(), uint(id))
+	if err != nil || category.DeletedAt != nil {
+		log.Println("error on update absent request category: category not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absent_request_category_not_found",
+			"error":         "Absent request category not found",
+		})
+	}
+
+	if err := c.BodyParser(category); err != nil {
+		log.Println("error on update absent request category:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	category.ID = uint(id)
+	category.UpdatedBy = &adminID
+	if status, errBody := h.validateCategory(c.Context(), category); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.categoryRepo.Update(c.Context(), category); err != nil {
+		log.Println("error on update absent request category:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_absent_request_category",
+			"error":         "Failed to update absent request category",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absent_request_category_updated",
+		"message":       "Absent request category updated successfully",
+		"data":          category,
+	})
+}
+
+// Delete godoc
+// @Summary Delete absent request category
+// @Description Retire an absent request category. Requests already filed under it keep the category.
+// @Tags Absent Request Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absent request category ID"
+// @Success 200 {object} map[string]interface{} "Absent request category deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid absent request category ID"
+// @Failure 404 {object} map[string]interface{} "Absent request category not found"
+// @Router /admins/absent-request-categories/category-id/{id} [delete]
+func (h *absentRequestCategoryHandler) Delete(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete absent request category")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on delete absent request category:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absent_request_category_id",
+			"error":         "Invalid absent request category ID",
+		})
+	}
+
+	if err := h.categoryRepo.UpdateDeleteInfo(c.Context(), uint(id), adminID); err != nil {
+		log.Println("error on delete absent request category:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.absent_request_category_not_found",
+			"error":         "Absent request category not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absent_request_category_deleted",
+		"message":       "Absent request category deleted successfully",
+	})
+}
+
+// GetReport godoc
+// @Summary Absences by category report
+// @Description Break down the absent requests overlapping a date range by category and status
+// @Tags Absent Request Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Absences by category retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/reports/absences-by-category [get]
+func (h *absentRequestCategoryHandler) GetReport(c *fiber.Ctx) error {
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		log.Println("error on get absences by category: invalid start date:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_start_date",
+			"error":         "Invalid start date format. Use YYYY-MM-DD",
+		})
+	}
+
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		log.Println("error on get absences by category: invalid end date:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_end_date",
+			"error":         "Invalid end date format. Use YYYY-MM-DD",
+		})
+	}
+
+	if endDate.Before(startDate) {
+		log.Println("error on get absences by category: end date before start date")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_date_range",
+			"error":         "End date must not be before start date",
+		})
+	}
+
+	report, err := h.absentRequestRepo.GetCategoryReport(c.Context(), startDate, endDate)
+	if err != nil {
+		log.Println("error on get absences by category:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_absences_by_category",
+			"error":         "Failed to get absences by category",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absences_by_category_retrieved",
+		"message":       "Absences by category retrieved successfully",
+		"data":          report,
+	})
+}
+
+// validateCategory normalises the category code and checks the rules are usable.
+// On failure it returns the status and body to respond with.
+func (h *absentRequestCategoryHandler) validateCategory(ctx context.Context, category *models.AbsentRequestCategory) (int, fiber.Map) {
+	category.Code = strings.ToLower(strings.TrimSpace(category.Code))
+	category.Name = strings.TrimSpace(category.Name)
+
+	if category.Code == "" || category.Name == "" {
+		log.Println("error on validate absent request category: code and name are required")
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.category_code_and_name_required",
+			"error":         "Code and name are required",
+		}
+	}
+
+	if (category.MaxDays != nil && *category.MaxDays <= 0) || category.MinNoticeDays < 0 {
+		log.Println("error on validate absent request category: invalid day limits")
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_category_rules",
+			"error":         "Maximum days must be positive and minimum notice must not be negative",
+		}
+	}
+
+	exist, err := h.categoryRepo.IsCodeExist(ctx, category.Code, category.ID)
+	if err != nil {
+		log.Println("error on validate absent request category:", err)
+		return fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_category_code",
+			"error":         "Failed to check category code",
+		}
+	}
+
+	if exist {
+		log.Println("error on validate absent request category: code already exists")
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.category_code_already_exists",
+			"error":         "Category code already exists",
+		}
+	}
+
+	return fiber.StatusOK, nil
+}
+
+// currentAdminID reads the ID of the admin making the request.
+// On failure it returns the status and body to respond with.
+func currentAdminID(c *fiber.Ctx, action string) (uint, int, fiber.Map) {
+	adminID := c.Locals("userID")
+	if adminID == nil {
+		log.Printf("error on %s: invalid admin id\n", action)
+		return 0, fiber.StatusUnauthorized, fiber.Map{
+			"translate_key": "error.unauthorized",
+			"error":         "Unauthorized access",
+		}
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminID.(string), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: invalid admin id format: %v\n", action, err)
+		return 0, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_admin_id",
+			"error":         "Invalid admin ID format",
+		}
+	}
+
+	return uint(adminIDUint), fiber.StatusOK, nil
+}
+
+// checkRequestCategory loads the category of a request being filed or edited and checks the request follows its rules.
+// On failure it returns the status and body to respond with.
+func checkRequestCategory(
+	ctx context.Context,
+	categoryRepo repository.AbsentRequestCategoryRepository,
+	request *models.AbsentRequest,
+) (*models.AbsentRequestCategory, int, fiber.Map) {
+	if request.CategoryID == nil {
+		return nil, fiber.StatusBadRequest, absentRequestCategoryError(models.ErrAbsentRequestCategoryRequired)
+	}
+
+	category, err := categoryRepo.GetByID(ctx, *request.CategoryID)
+	if err != nil {
+		log.Println("error on check absent request category:", err)
+		return nil, fiber.StatusBadRequest, absentRequestCategoryError(models.ErrAbsentRequestCategoryInactive)
+	}
+
+	if err := category.ValidateRequest(request, time.Now()); err != nil {
+		log.Println("error on check absent request category:", err)
+		return nil, fiber.StatusBadRequest, absentRequestCategoryError(err)
+	}
+
+	return category, fiber.StatusOK, nil
+}
+
+// approvalExcusedDays checks a request may be approved under its category rules and returns
+// the days that should be recorded as excused once it is.
+// On failure it returns the status and body to respond with.
+func approvalExcusedDays(
+	ctx context.Context,
+	categoryRepo repository.AbsentRequestCategoryRepository,
+	attachmentRepo repository.AbsentRequestAttachmentRepository,
+	request *models.AbsentRequest,
+) ([]time.Time, int, fiber.Map) {
+	// requests filed before categories existed carry no rules
+	if request.CategoryID == nil {
+		return nil, fiber.StatusOK, nil
+	}
+
+	category, err := categoryRepo.GetByID(ctx, *request.CategoryID)
+	if err != nil {
+		log.Println("error on check absent request approval:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_approve_request",
+			"error":         "Failed to approve request",
+		}
+	}
+
+	if category.RequiresAttachment {
+		count, err := attachmentRepo.GetCountByAbsentRequest(ctx, request.ID)
+		if err != nil {
+			log.Println("error on check absent request approval:", err)
+			return nil, fiber.StatusInternalServerError, fiber.Map{
+				"translate_key": "error.failed_to_approve_request",
+				"error":         "Failed to approve request",
+			}
+		}
+
+		if count == 0 {
+			log.Println("error on check absent request approval: supporting document missing")
+			return nil, fiber.StatusBadRequest, absentRequestCategoryError(models.ErrAbsentRequestAttachmentNeeded)
+		}
+	}
+
+	if !category.MarksExcused {
+		return nil, fiber.StatusOK, nil
+	}
+
+	return request.Days(), fiber.StatusOK, nil
+}
+
+// absentRequestCategoryError builds the response body for a request that breaks its category rules
+func absentRequestCategoryError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, models.ErrAbsentRequestCategoryRequired):
+		return fiber.Map{
+			"translate_key": "error.absent_request_category_required",
+			"error":         "Category is required",
+		}
+	case errors.Is(err, models.ErrAbsentRequestTooManyDays):
+		return fiber.Map{
+			"translate_key": "error.absent_request_too_many_days",
+			"error":         "The request is longer than its category allows",
+		}
+	case errors.Is(err, models.ErrAbsentRequestNoticeTooShort):
+		return fiber.Map{
+			"translate_key": "error.absent_request_notice_too_short",
+			"error":         "The request does not give enough notice for its category",
+		}
+	case errors.Is(err, models.ErrAbsentRequestAttachmentNeeded):
+		return fiber.Map{
+			"translate_key": "error.absent_request_attachment_required",
+			"error":         "A supporting document is required before this request can be approved",
+		}
+	default:
+		return fiber.Map{
+			"translate_key": "error.absent_request_category_not_available",
+			"error":         "Category is not available",
+		}
+	}
+}
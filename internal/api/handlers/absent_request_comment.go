@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+const maxCommentLength = 1000
+
+// AddComment godoc
+// @Summary Comment on absent request
+// @Description Add a message to the comment thread of a pending absent request. The student who filed it and the teachers of their class whose role allows it can take part.
+// @Tags Absent Requests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Absent request ID"
+// @Param comment body object true "Comment with body field"
+// @Success 201 {object} map[string]interface{} "Comment added successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid comment"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not allowed to comment on this request"
+// @Failure 404 {object} map[string]interface{} "Absent request not found"
+// @Failure 409 {object} map[string]interface{} "Request already decided"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /absent-requests/absent-request-id/{id}/comments [post]
+func (h *absentRequestHandler) AddComment(c *fiber.Ctx) error {
+	actor, errStatus, errBody := currentActor(c, "add absent request comment")
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on add absent request comment:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_absent_request_id",
+			"error":         "Invalid absent request ID",
+		})
+	}
+
+	var body struct {
+		Body string `json:"body"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		log.Println("error on add absent request comment:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	body.Body = strings.TrimSpace(body.Body)
+	if body.Body == "" || utf8.RuneCountInString(body.Body) > maxCommentLength {
+		log.Println("error on add absent request comment: invalid comment length")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_comment",
+			"error":         "Comment must be between 1 and 1000 characters",
+		})
+	}
+
+	request, errStatus, errBody := h.scopedRequest(c, uint(id), "add absent request comment")
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
+	}
+
+	if err := h.policy.CanComment(c.Context(), actor, request); err != nil {
+		log.Println("error on add absent request comment:", err)
+		errStatus, errBody := absentRequestPolicyError(err)
+		return c.Status(errStatus).JSON(errBody)
+	}
+
+	comment := &models.AbsentRequestComment{
+		AbsentRequestID: request.ID,
+		AuthorType:      actor.UserType,
+		AuthorID:        actor.UserID,
+		Body:            body.Body,
+	}
+
+	if err := h.commentRepo.Create(c.Context(), comment); err != nil {
+		log.Println("error on add absent request comment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_add_comment",
+			"error":         "Failed to add comment",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.comment_added",
+		"message":       "Comment added successfully",
+		"data":          comment,
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// absentRequestDecider approves and rejects absent requests once the policy allows the actor to
+type absentRequestDecider struct {
+	absentRequestRepo repository.AbsentRequestRepository
+	categoryRepo      repository.AbsentRequestCategoryRepository
+	attachmentRepo    repository.AbsentRequestAttachmentRepository
+	quotaRepo         repository.AbsenceQuotaRepository
+	policy            *policy.AbsentRequestPolicy
+}
+
+func newAbsentRequestDecider(
+	absentRequestRepo repository.AbsentRequestRepository,
+	categoryRepo repository.AbsentRequestCategoryRepository,
+	attachmentRepo repository.AbsentRequestAttachmentRepository,
+	quotaRepo repository.AbsenceQuotaRepository,
+	absentRequestPolicy *policy.AbsentRequestPolicy,
+) *absentRequestDecider {
+	return &absentRequestDecider{
+		absentRequestRepo: absentRequestRepo,
+		categoryRepo:      categoryRepo,
+		attachmentRepo:    attachmentRepo,
+		quotaRepo:         quotaRepo,
+		policy:            absentRequestPolicy,
+	}
+}
+
+// decide approves or rejects the request for the actor and returns the request as it now stands.
+// The reviewer note, if any, is shown to the student alongside the decision. Override lets an admin
+// change a decision already made or approve beyond the absence quota.
+// On failure it returns the status and body to respond with.
+func (d *absentRequestDecider) decide(
+	ctx context.Context,
+	actor policy.Actor,
+	requestID uint,
+	status models.AbsentRequestStatus,
+	note string,
+	override bool,
+) (*models.AbsentRequest, int, fiber.Map) {
+	request, err := d.absentRequestRepo.GetByID(ctx, requestID)
+	if err != nil {
+		log.Println("error on decide absent request:", err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.absent_request_not_found",
+			"error":         "Absent request not found",
+		}
+	}
+
+	if err := d.policy.CanDecide(ctx, actor, request, override); err != nil {
+		log.Println("error on decide absent request:", err)
+		errStatus, errBody := absentRequestPolicyError(err)
+		return nil, errStatus, errBody
+	}
+
+	var reviewerNote *string
+	if note = strings.TrimSpace(note); note != "" {
+		reviewerNote = &note
+	}
+
+	if status == models.AbsentRequestStatusApproved {
+		days, errStatus, errBody := approvalExcusedDays(ctx, d.categoryRepo, d.attachmentRepo, request)
+		if errBody != nil {
+			return nil, errStatus, errBody
+		}
+
+		if errStatus, errBody := checkAbsenceQuota(ctx, d.quotaRepo, d.absentRequestRepo, actor, request, override); errBody != nil {
+			return nil, errStatus, errBody
+		}
+
+		if actor.UserType == models.UserTypeAdmin.String() {
+			err = d.absentRequestRepo.DecideByAdmin(ctx, request.ID, request.Status, status, actor.UserID, reviewerNote, days)
+		} else {
+			err = d.absentRequestRepo.Approve(ctx, request.ID, actor.UserID, reviewerNote, days)
+		}
+	} else if actor.UserType == models.UserTypeAdmin.String() {
+		err = d.absentRequestRepo.DecideByAdmin(ctx, request.ID, request.Status, status, actor.UserID, reviewerNote, nil)
+	} else {
+		err = d.absentRequestRepo.Reject(ctx, request.ID, actor.UserID, reviewerNote)
+	}
+
+	if err != nil {
+		log.Println("error on decide absent request:", err)
+		if errors.Is(err, models.ErrAbsentRequestChanged) || errors.Is(err, models.ErrAbsentRequestNotPending) {
+			errStatus, errBody := absentRequestPolicyError(err)
+			return nil, errStatus, errBody
+		}
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_update_absent_request_status",
+			"error":         "Failed to update absent request status",
+		}
+	}
+
+	request, err = d.absentRequestRepo.GetByID(ctx, request.ID)
+	if err != nil {
+		log.Println("error on decide absent request:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_get_absent_request",
+			"error":         "Failed to get absent request",
+		}
+	}
+
+	return request, fiber.StatusOK, nil
+}
+
+// currentActor reads the authenticated user from the request context.
+// On failure it returns the status and body to respond with.
+func currentActor(c *fiber.Ctx, action string) (policy.Actor, int, fiber.Map) {
+	userID, _ := c.Locals("userID").(string)
+	userType, _ := c.Locals("userType").(string)
+	if userID == "" || userType == "" {
+		log.Printf("error on %s: invalid user id\n", action)
+		return policy.Actor{}, fiber.StatusUnauthorized, fiber.Map{
+			"translate_key": "error.invalid_user_id",
+			"error":         "Invalid user ID",
+		}
+	}
+
+	userIDUint, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		log.Printf("error on %s: invalid user id format: %v\n", action, err)
+		return policy.Actor{}, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_user_id_format",
+			"error":         "Invalid user ID format",
+		}
+	}
+
+	return policy.Actor{UserID: uint(userIDUint), UserType: userType}, fiber.StatusOK, nil
+}
+
+// absentRequestPolicyError maps a policy refusal to the status and body to respond with
+func absentRequestPolicyError(err error) (int, fiber.Map) {
+	switch {
+	case errors.Is(err, policy.ErrStudentCannotDecide):
+		return fiber.StatusForbidden, fiber.Map{
+			"translate_key": "error.student_cannot_decide_absent_request",
+			"error":         "Students cannot change the status of absent requests",
+		}
+	case errors.Is(err, policy.ErrNotClassTeacher):
+		return fiber.StatusForbidden, fiber.Map{
+			"translate_key": "error.not_class_teacher",
+			"error":         "Only teachers of the class can act on this request",
+		}
+	case errors.Is(err, policy.ErrClassRoleNotAllowed):
+		return fiber.StatusForbidden, fiber.Map{
+			"translate_key": "error.class_role_not_allowed",
+			"error":         "Your role in the class does not allow this",
+		}
+	case errors.Is(err, policy.ErrAlreadyDecided), errors.Is(err, models.ErrAbsentRequestNotPending):
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.absent_request_already_decided",
+			"error":         "This request has already been decided",
+		}
+	case errors.Is(err, models.ErrAbsentRequestChanged):
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.absent_request_changed",
+			"error":         "This request changed while it was being decided. Reload it and try again.",
+		}
+	case errors.Is(err, policy.ErrRequestWithdrawn):
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.absent_request_withdrawn",
+			"error":         "This request has been withdrawn",
+		}
+	case errors.Is(err, policy.ErrNotRequestOwner):
+		// answered like a missing request, so the request of another student is not revealed
+		return fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.absent_request_not_found",
+			"error":         "Absent request not found",
+		}
+	case errors.Is(err, policy.ErrUnknownActor):
+		return fiber.StatusForbidden, fiber.Map{
+			"translate_key": "error.insufficient_permissions",
+			"error":         "Insufficient permissions for this operation",
+		}
+	default:
+		return fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_update_absent_request_status",
+			"error":         "Failed to update absent request status",
+		}
+	}
+}
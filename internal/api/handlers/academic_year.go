@@ -0,0 +1,764 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type academicYearHandler struct {
+	academicYearRepo repository.AcademicYearRepository
+	classRepo        repository.ClassRepository
+	enrollmentRepo   repository.EnrollmentRepository
+}
+
+// NewAcademicYearHandler creates a new academic year handler
+func NewAcademicYearHandler(
+	academicYearRepo repository.AcademicYearRepository,
+	classRepo repository.ClassRepository,
+	enrollmentRepo repository.EnrollmentRepository,
+) AcademicYearHandler {
+	return &academicYearHandler{
+		academicYearRepo: academicYearRepo,
+		classRepo:        classRepo,
+		enrollmentRepo:   enrollmentRepo,
+	}
+}
+
+// Create godoc
+// @Summary Create academic year
+// @Description Add an academic year to the current school. A new current year replaces the previous one.
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param academic_year body models.AcademicYearInput true "Academic year data"
+// @Success 201 {object} map[string]interface{} "Academic year created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or period"
+// @Failure 409 {object} map[string]interface{} "Academic year name already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years [post]
+func (h *academicYearHandler) Create(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create academic year")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	year, status, errBody := h.parseAcademicYear(c, 0)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	year.CreatedBy = &adminID
+	if err := h.academicYearRepo.Create(c.Context(), year); err != nil {
+		log.Println("error on create academic year:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_academic_year",
+			"error":         "Failed to create academic year",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.academic_year_created",
+		"message":       "Academic year created successfully",
+		"data":          year,
+	})
+}
+
+// GetAll godoc
+// @Summary Get academic years
+// @Description Retrieve the academic years of the current school, the most recent first
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Academic years retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years [get]
+func (h *academicYearHandler) GetAll(c *fiber.Ctx) error {
+	years, err := h.academicYearRepo.GetAll(c.Context())
+	if err != nil {
+		log.Println("error on get academic years:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_academic_years",
+			"error":         "Failed to get academic years",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.academic_years_retrieved",
+		"message":       "Academic years retrieved successfully",
+		"data":          years,
+	})
+}
+
+// GetByID godoc
+// @Summary Get academic year by ID
+// @Description Retrieve a specific academic year with its terms
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Academic year ID"
+// @Success 200 {object} map[string]interface{} "Academic year retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid academic year ID"
+// @Failure 404 {object} map[string]interface{} "Academic year not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years/academic-year-id/{id} [get]
+func (h *academicYearHandler) GetByID(c *fiber.Ctx) error {
+	year, status, errBody := h.findAcademicYear(c, "get academic year by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	terms, err := h.academicYearRepo.GetTerms(c.Context(), year.ID)
+	if err != nil {
+		log.Println("error on get academic year by id:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_terms",
+			"error":         "Failed to get terms",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.academic_year_retrieved",
+		"message":       "Academic year retrieved successfully",
+		"data":          year,
+		"terms":         terms,
+	})
+}
+
+// Update godoc
+// @Summary Update academic year
+// @Description Update the name, period or current flag of an academic year. Its terms must still fall within the period.
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Academic year ID"
+// @Param academic_year body models.AcademicYearInput true "Academic year data"
+// @Success 200 {object} map[string]interface{} "Academic year updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or period"
+// @Failure 404 {object} map[string]interface{} "Academic year not found"
+// @Failure 409 {object} map[string]interface{} "Name already exists or terms fall outside the period"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years/academic-year-id/{id} [put]
+func (h *academicYearHandler) Update(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update academic year")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	existing, status, errBody := h.findAcademicYear(c, "update academic year")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	year, status, errBody := h.parseAcademicYear(c, existing.ID)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	terms, err := h.academicYearRepo.GetTerms(c.Context(), existing.ID)
+	if err != nil {
+		log.Println("error on update academic year:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_terms",
+			"error":         "Failed to get terms",
+		})
+	}
+
+	for _, term := range terms {
+		if term.StartDate.Before(year.StartDate) || term.EndDate.After(year.EndDate) {
+			log.Println("error on update academic year: term falls outside the period:", term.ID)
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"translate_key": "error.academic_year_terms_outside_period",
+				"error":         "Some terms of the academic year fall outside the new period",
+			})
+		}
+	}
+
+	year.ID = existing.ID
+	year.SchoolID = existing.SchoolID
+	year.CreatedAt = existing.CreatedAt
+	year.CreatedBy = existing.CreatedBy
+	year.UpdatedBy = &adminID
+	if err := h.academicYearRepo.Update(c.Context(), year); err != nil {
+		log.Println("error on update academic year:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_academic_year",
+			"error":         "Failed to update academic year",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.academic_year_updated",
+		"message":       "Academic year updated successfully",
+		"data":          year,
+	})
+}
+
+// Delete godoc
+// @Summary Delete academic year
+// @Description Remove an academic year that no class belongs to
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Academic year ID"
+// @Success 200 {object} map[string]interface{} "Academic year deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid academic year ID"
+// @Failure 404 {object} map[string]interface{} "Academic year not found"
+// @Failure 409 {object} map[string]interface{} "Classes still belong to the academic year"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years/academic-year-id/{id} [delete]
+func (h *academicYearHandler) Delete(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete academic year")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	year, status, errBody := h.findAcademicYear(c, "delete academic year")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	classes, err := h.classRepo.GetByAcademicYear(c.Context(), year.ID)
+	if err != nil {
+		log.Println("error on delete academic year:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_classes",
+			"error":         "Failed to get classes",
+		})
+	}
+
+	if len(classes) > 0 {
+		log.Println("error on delete academic year: classes still belong to it")
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"translate_key": "error.academic_year_has_classes",
+			"error":         "Classes still belong to this academic year",
+		})
+	}
+
+	if err := h.academicYearRepo.UpdateDeleteInfo(c.Context(), year.ID, adminID); err != nil {
+		log.Println("error on delete academic year:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.academic_year_not_found",
+			"error":         "Academic year not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.academic_year_deleted",
+		"message":       "Academic year deleted successfully",
+	})
+}
+
+// GetClasses godoc
+// @Summary Get classes of an academic year
+// @Description Retrieve the classes that belong to an academic year
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Academic year ID"
+// @Success 200 {object} map[string]interface{} "Classes retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid academic year ID"
+// @Failure 404 {object} map[string]interface{} "Academic year not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years/academic-year-id/{id}/classes [get]
+func (h *academicYearHandler) GetClasses(c *fiber.Ctx) error {
+	year, status, errBody := h.findAcademicYear(c, "get academic year classes")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	classes, err := h.classRepo.GetByAcademicYear(c.Context(), year.ID)
+	if err != nil {
+		log.Println("error on get academic year classes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_classes",
+			"error":         "Failed to get classes",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.classes_retrieved",
+		"message":       "Classes retrieved successfully",
+		"data":          classes,
+	})
+}
+
+// CreateTerm godoc
+// @Summary Create term
+// @Description Add a term to an academic year. It must fall within the year and not overlap its other terms.
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Academic year ID"
+// @Param term body models.TermInput true "Term data"
+// @Success 201 {object} map[string]interface{} "Term created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or period"
+// @Failure 404 {object} map[string]interface{} "Academic year not found"
+// @Failure 409 {object} map[string]interface{} "Term overlaps another term"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years/academic-year-id/{id}/terms [post]
+func (h *academicYearHandler) CreateTerm(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create term")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	year, status, errBody := h.findAcademicYear(c, "create term")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	term, status, errBody := h.parseTerm(c, year, 0)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	term.AcademicYearID = year.ID
+	term.CreatedBy = &adminID
+	if err := h.academicYearRepo.CreateTerm(c.Context(), term); err != nil {
+		log.Println("error on create term:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_term",
+			"error":         "Failed to create term",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.term_created",
+		"message":       "Term created successfully",
+		"data":          term,
+	})
+}
+
+// GetTerms godoc
+// @Summary Get terms of an academic year
+// @Description Retrieve the terms of an academic year in date order
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Academic year ID"
+// @Success 200 {object} map[string]interface{} "Terms retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid academic year ID"
+// @Failure 404 {object} map[string]interface{} "Academic year not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/academic-years/academic-year-id/{id}/terms [get]
+func (h *academicYearHandler) GetTerms(c *fiber.Ctx) error {
+	year, status, errBody := h.findAcademicYear(c, "get terms")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	terms, err := h.academicYearRepo.GetTerms(c.Context(), year.ID)
+	if err != nil {
+		log.Println("error on get terms:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_terms",
+			"error":         "Failed to get terms",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.terms_retrieved",
+		"message":       "Terms retrieved successfully",
+		"data":          terms,
+	})
+}
+
+// UpdateTerm godoc
+// @Summary Update term
+// @Description Update the name or period of a term. It must still fall within its year and not overlap its other terms.
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Term ID"
+// @Param term body models.TermInput true "Term data"
+// @Success 200 {object} map[string]interface{} "Term updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or period"
+// @Failure 404 {object} map[string]interface{} "Term not found"
+// @Failure 409 {object} map[string]interface{} "Term overlaps another term"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/terms/term-id/{id} [put]
+func (h *academicYearHandler) UpdateTerm(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update term")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on update term:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_term_id",
+			"error":         "Invalid term ID",
+		})
+	}
+
+	existing, err := h.academicYearRepo.GetTermByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on update term:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.term_not_found",
+			"error":         "Term not found",
+		})
+	}
+
+	year, err := h.academicYearRepo.GetByID(c.Context(), existing.AcademicYearID)
+	if err != nil {
+		log.Println("error on update term:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.academic_year_not_found",
+			"error":         "Academic year not found",
+		})
+	}
+
+	term, status, errBody := h.parseTerm(c, year, existing.ID)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	term.ID = existing.ID
+	term.AcademicYearID = existing.AcademicYearID
+	term.CreatedAt = existing.CreatedAt
+	term.CreatedBy = existing.CreatedBy
+	term.UpdatedBy = &adminID
+	if err := h.academicYearRepo.UpdateTerm(c.Context(), term); err != nil {
+		log.Println("error on update term:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_term",
+			"error":         "Failed to update term",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.term_updated",
+		"message":       "Term updated successfully",
+		"data":          term,
+	})
+}
+
+// DeleteTerm godoc
+// @Summary Delete term
+// @Description Remove a term from its academic year
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Term ID"
+// @Success 200 {object} map[string]interface{} "Term deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid term ID"
+// @Failure 404 {object} map[string]interface{} "Term not found"
+// @Router /admins/terms/term-id/{id} [delete]
+func (h *academicYearHandler) DeleteTerm(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete term")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on delete term:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_term_id",
+			"error":         "Invalid term ID",
+		})
+	}
+
+	if err := h.academicYearRepo.DeleteTerm(c.Context(), uint(id), adminID); err != nil {
+		log.Println("error on delete term:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.term_not_found",
+			"error":         "Term not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.term_deleted",
+		"message":       "Term deleted successfully",
+	})
+}
+
+// Promote godoc
+// @Summary Promote students to the next academic year
+// @Description Move the active students of each class of one academic year to the class mapped as its successor
+// @Description in the next year. Students of a class without a successor graduate. Past attendance stays
+// @Description recorded under the old class. The date, the first day in the new class, defaults to the start
+// @Description of the next year. Send dry_run to see how many students would move without moving them.
+// @Description Students who joined their class after the date are listed as enrolled_after_date, and then nobody is moved.
+// @Tags Academic Years
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param promotion body models.PromotionInput true "Class mappings"
+// @Success 200 {object} map[string]interface{} "Students promoted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid academic years or class mappings"
+// @Failure 422 {object} map[string]interface{} "Some students joined their class after the promotion date"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/promotions [post]
+func (h *academicYearHandler) Promote(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "promote students")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var input models.PromotionInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on promote students:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	promotion, err := input.ToPromotion()
+	if err != nil {
+		log.Println("error on promote students:", err)
+		switch {
+		case errors.Is(err, models.ErrPromotionNoClasses):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.promotion_classes_required",
+				"error":         "At least one class must be mapped",
+			})
+		case errors.Is(err, models.ErrPromotionDuplicateClass):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.promotion_duplicate_class",
+				"error":         "A class may only be mapped once",
+			})
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_date_format",
+				"error":         "Invalid date format. Use YYYY-MM-DD format.",
+			})
+		}
+	}
+
+	if promotion.FromAcademicYearID == promotion.ToAcademicYearID {
+		log.Println("error on promote students: academic years are the same")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.promotion_same_academic_year",
+			"error":         "Students must be promoted to another academic year",
+		})
+	}
+
+	if _, err := h.academicYearRepo.GetByID(c.Context(), promotion.FromAcademicYearID); err != nil {
+		log.Println("error on promote students:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.academic_year_not_found",
+			"error":         "Academic year to promote from not found",
+		})
+	}
+
+	toYear, err := h.academicYearRepo.GetByID(c.Context(), promotion.ToAcademicYearID)
+	if err != nil {
+		log.Println("error on promote students:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.academic_year_not_found",
+			"error":         "Academic year to promote to not found",
+		})
+	}
+
+	if promotion.Date.IsZero() {
+		promotion.Date = toYear.StartDate
+	}
+
+	for _, mapping := range promotion.Classes {
+		if !h.classInAcademicYear(c, mapping.FromClassID, promotion.FromAcademicYearID) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.promotion_invalid_class",
+				"error":         fmt.Sprintf("Class %d does not belong to the academic year to promote from", mapping.FromClassID),
+			})
+		}
+
+		if mapping.ToClassID != nil && !h.classInAcademicYear(c, *mapping.ToClassID, promotion.ToAcademicYearID) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.promotion_invalid_class",
+				"error":         fmt.Sprintf("Class %d does not belong to the academic year to promote to", *mapping.ToClassID),
+			})
+		}
+	}
+
+	result, err := h.enrollmentRepo.Promote(c.Context(), promotion, adminID)
+	if errors.Is(err, models.ErrPromotionBeforeEnrollment) {
+		log.Println("error on promote students:", err)
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"translate_key": "error.promotion_before_enrollment",
+			"error":         "The promotion date is before some students joined their current class",
+			"data":          result,
+		})
+	}
+
+	if err != nil {
+		log.Println("error on promote students:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_promote_students",
+			"error":         "Failed to promote students",
+		})
+	}
+
+	if result.DryRun {
+		return c.JSON(fiber.Map{
+			"translate_key": "success.promotion_previewed",
+			"message":       "Promotion previewed successfully; no students were moved",
+			"data":          result,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.students_promoted",
+		"message":       "Students promoted successfully",
+		"data":          result,
+	})
+}
+
+// classInAcademicYear reports whether the class exists and belongs to the academic year
+func (h *academicYearHandler) classInAcademicYear(c *fiber.Ctx, classID, academicYearID uint) bool {
+	class, err := h.classRepo.GetByID(c.Context(), classID)
+	if err != nil {
+		log.Println("error on promote students:", err)
+		return false
+	}
+
+	return class.AcademicYearID != nil && *class.AcademicYearID == academicYearID
+}
+
+// findAcademicYear returns the academic year named by the id path parameter.
+// On failure it returns the status and body to respond with.
+func (h *academicYearHandler) findAcademicYear(c *fiber.Ctx, action string) (*models.AcademicYear, int, fiber.Map) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_academic_year_id",
+			"error":         "Invalid academic year ID",
+		}
+	}
+
+	year, err := h.academicYearRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.academic_year_not_found",
+			"error":         "Academic year not found",
+		}
+	}
+
+	return year, fiber.StatusOK, nil
+}
+
+// parseAcademicYear reads the academic year from the request body and checks it can be saved.
+// On failure it returns the status and body to respond with.
+func (h *academicYearHandler) parseAcademicYear(c *fiber.Ctx, academicYearID uint) (*models.AcademicYear, int, fiber.Map) {
+	var input models.AcademicYearInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on parse academic year:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	year, err := input.ToAcademicYear()
+	if err != nil {
+		log.Println("error on parse academic year:", err)
+		return nil, fiber.StatusBadRequest, periodError(err)
+	}
+
+	exists, err := h.academicYearRepo.IsNameExist(c.Context(), year.Name, academicYearID)
+	if err != nil {
+		log.Println("error on parse academic year:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_academic_year",
+			"error":         "Failed to check academic year",
+		}
+	}
+
+	if exists {
+		log.Println("error on parse academic year: name already exists")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.academic_year_name_exists",
+			"error":         "An academic year with this name already exists",
+		}
+	}
+
+	return year, fiber.StatusOK, nil
+}
+
+// parseTerm reads the term from the request body and checks it fits in the academic year.
+// On failure it returns the status and body to respond with.
+func (h *academicYearHandler) parseTerm(c *fiber.Ctx, year *models.AcademicYear, termID uint) (*models.Term, int, fiber.Map) {
+	var input models.TermInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on parse term:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	term, err := input.ToTerm()
+	if err != nil {
+		log.Println("error on parse term:", err)
+		return nil, fiber.StatusBadRequest, periodError(err)
+	}
+
+	if term.StartDate.Before(year.StartDate) || term.EndDate.After(year.EndDate) {
+		log.Println("error on parse term: outside the academic year")
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.term_outside_academic_year",
+			"error":         "The term must fall within its academic year",
+		}
+	}
+
+	overlaps, err := h.academicYearRepo.HasTermOverlap(c.Context(), year.ID, term.StartDate, term.EndDate, termID)
+	if err != nil {
+		log.Println("error on parse term:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_term",
+			"error":         "Failed to check term",
+		}
+	}
+
+	if overlaps {
+		log.Println("error on parse term: overlaps another term")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.term_overlaps",
+			"error":         "Another term of this academic year already covers part of the period",
+		}
+	}
+
+	return term, fiber.StatusOK, nil
+}
+
+// periodError returns the response body for an academic year or term period that could not be parsed
+func periodError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, models.ErrAcademicYearInvalidPeriod):
+		return fiber.Map{
+			"translate_key": "error.period_required",
+			"error":         "Name, start date and end date are required",
+		}
+	case errors.Is(err, models.ErrAbsentRequestInvalidRange):
+		return fiber.Map{
+			"translate_key": "error.invalid_date_range",
+			"error":         "End date must be after start date",
+		}
+	default:
+		return fiber.Map{
+			"translate_key": "error.invalid_date_format",
+			"error":         "Invalid date format. Use YYYY-MM-DD format.",
+		}
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"strconv"
@@ -25,13 +26,15 @@ func NewAdminHandler(adminRepo repository.AdminRepository) AdminHandler {
 
 // CreateAdmin godoc
 // @Summary Create a new admin
-// @Description Create a new admin in the system
+// @Description Create a new admin in the system. Only super-admins manage admins. The tier is super_admin, admin
+// @Description or auditor (read-only) and defaults to admin.
 // @Tags Admins
 // @Accept json
 // @Produce json
 // @Param admin body models.Admin true "Admin data"
 // @Success 201 {object} map[string]interface{} "Admin created successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage admins"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /admins [post]
 func (h *adminHandler) Create(c *fiber.Ctx) error {
@@ -44,6 +47,18 @@ func (h *adminHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	// new admins join the school of the admin creating them and run it unless given another tier
+	if admin.Tier == "" {
+		admin.Tier = models.AdminTierAdmin
+	}
+	if !models.IsValidAdminTier(admin.Tier) {
+		log.Println("error on create admin: invalid tier:", admin.Tier)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_admin_tier",
+			"error":         "Tier must be super_admin, admin or auditor",
+		})
+	}
+
 	if admin.Email == "" || admin.Password == "" {
 		log.Println("error on create admin: email and password are required")
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -233,7 +248,8 @@ func (h *adminHandler) GetAll(c *fiber.Ctx) error {
 
 // UpdateAdmin godoc
 // @Summary Update admin
-// @Description Update an admin's information
+// @Description Update an admin's email, active status and tier. Only super-admins manage admins, and the last active
+// @Description super-admin cannot be deactivated or given another tier. An empty tier keeps the current one.
 // @Tags Admins
 // @Accept json
 // @Produce json
@@ -241,6 +257,8 @@ func (h *adminHandler) GetAll(c *fiber.Ctx) error {
 // @Param admin body models.Admin true "Admin data"
 // @Success 200 {object} map[string]interface{} "Admin updated successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage admins"
+// @Failure 409 {object} map[string]interface{} "The admin is the last active super-admin"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /admins/{id} [put]
 func (h *adminHandler) Update(c *fiber.Ctx) error {
@@ -263,9 +281,20 @@ func (h *adminHandler) Update(c *fiber.Ctx) error {
 		})
 	}
 
+	if admin.Tier != "" && !models.IsValidAdminTier(admin.Tier) {
+		log.Println("error on update admin: invalid tier:", admin.Tier)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_admin_tier",
+			"error":         "Tier must be super_admin, admin or auditor",
+		})
+	}
+
 	admin.ID = uint(id)
 	if err := h.adminRepo.Update(c.Context(), &admin); err != nil {
 		log.Println("error on update admin: failed to update admin:", err)
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			return c.Status(fiber.StatusConflict).JSON(lastSuperAdminError())
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"translate_key": "error.failed_to_update_admin",
 			"error":         "Failed to update admin",
@@ -281,13 +310,16 @@ func (h *adminHandler) Update(c *fiber.Ctx) error {
 
 // DeleteAdmin godoc
 // @Summary Delete admin
-// @Description Delete an admin from the system
+// @Description Delete an admin from the system. Only super-admins manage admins, and the last active super-admin
+// @Description cannot be deleted.
 // @Tags Admins
 // @Accept json
 // @Produce json
 // @Param id path int true "Admin database ID"
 // @Success 200 {object} map[string]interface{} "Admin deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid admin ID"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage admins"
+// @Failure 409 {object} map[string]interface{} "The admin is the last active super-admin"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /admins/{id} [delete]
 func (h *adminHandler) Delete(c *fiber.Ctx) error {
@@ -303,6 +335,9 @@ func (h *adminHandler) Delete(c *fiber.Ctx) error {
 
 	if err := h.adminRepo.Delete(c.Context(), uint(id)); err != nil {
 		log.Println("error on delete admin: failed to delete admin:", err)
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			return c.Status(fiber.StatusConflict).JSON(lastSuperAdminError())
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"translate_key": "error.failed_to_delete_admin",
 			"error":         "Failed to delete admin",
@@ -418,7 +453,8 @@ func (h *adminHandler) UpdatePassword(c *fiber.Ctx) error {
 
 // SetAdminActiveStatus godoc
 // @Summary Set admin active status
-// @Description Set an admin's active status (activate/deactivate)
+// @Description Set an admin's active status (activate/deactivate). Only super-admins manage admins, and the last
+// @Description active super-admin cannot be deactivated.
 // @Tags Admins
 // @Accept json
 // @Produce json
@@ -426,6 +462,8 @@ func (h *adminHandler) UpdatePassword(c *fiber.Ctx) error {
 // @Param request body map[string]bool true "Active status request"
 // @Success 200 {object} map[string]interface{} "Admin status updated successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage admins"
+// @Failure 409 {object} map[string]interface{} "The admin is the last active super-admin"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /admins/{id}/status [put]
 func (h *adminHandler) SetActiveStatus(c *fiber.Ctx) error {
@@ -453,6 +491,9 @@ func (h *adminHandler) SetActiveStatus(c *fiber.Ctx) error {
 
 	if err := h.adminRepo.SetActiveStatus(c.Context(), uint(id), request.IsActive); err != nil {
 		log.Println("error on set admin active status: failed to update admin status:", err)
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			return c.Status(fiber.StatusConflict).JSON(lastSuperAdminError())
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"translate_key": "error.failed_to_update_status",
 			"error":         "Failed to update admin status",
@@ -505,6 +546,7 @@ func (h *adminHandler) GetStat(c *fiber.Ctx) error {
 // @Param email query string true "Admin email"
 // @Success 200 {object} map[string]interface{} "Password reset successfully"
 // @Failure 400 {object} map[string]interface{} "Email is required"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage admins"
 // @Failure 404 {object} map[string]interface{} "Admin not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /admins/{id}/reset-password [put]
@@ -561,6 +603,14 @@ func (h *adminHandler) ResetPassword(c *fiber.Ctx) error {
 	})
 }
 
+// lastSuperAdminError is the body to respond with when a change would leave no active super-admin
+func lastSuperAdminError() fiber.Map {
+	return fiber.Map{
+		"translate_key": "error.last_super_admin",
+		"error":         "The last active super-admin cannot be deactivated, demoted or deleted",
+	}
+}
+
 func (h *adminHandler) updateCurrentPassword(ctx context.Context, email string, password string) error {
 	round, _ := strconv.Atoi(os.Getenv("SALT"))
 	hashPassword, err := pkg.HashPassword(password, round)
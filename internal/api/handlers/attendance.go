@@ -7,6 +7,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
 	"github.com/michaelwp/student_attendance/internal/repository"
 	"github.com/michaelwp/student_attendance/pkg"
 	"golang.org/x/crypto/bcrypt"
@@ -15,25 +16,35 @@ import (
 type attendanceHandler struct {
 	attendanceRepo repository.AttendanceRepository
 	studentRepo    repository.StudentRepository
+	timetableRepo  repository.TimetableRepository
+	scopePolicy    *policy.ScopePolicy
 }
 
 // NewAttendanceHandler creates a new attendance handler
-func NewAttendanceHandler(attendanceRepo repository.AttendanceRepository, studentRepo repository.StudentRepository) AttendanceHandler {
+func NewAttendanceHandler(
+	attendanceRepo repository.AttendanceRepository,
+	studentRepo repository.StudentRepository,
+	timetableRepo repository.TimetableRepository,
+	scopePolicy *policy.ScopePolicy,
+) AttendanceHandler {
 	return &attendanceHandler{
 		attendanceRepo: attendanceRepo,
 		studentRepo:    studentRepo,
+		timetableRepo:  timetableRepo,
+		scopePolicy:    scopePolicy,
 	}
 }
 
 // CreateAttendance godoc
 // @Summary Create attendance record
-// @Description Create a new attendance record for a student
+// @Description Create a new attendance record for a student. Set timetable_slot_id to record attendance for a lesson of the class on that weekday.
 // @Tags Attendances
 // @Accept json
 // @Produce json
 // @Param attendance body models.Attendance true "Attendance data"
 // @Success 201 {object} map[string]interface{} "Attendance record created successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or timetable slot"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /attendances [post]
 func (h *attendanceHandler) Create(c *fiber.Ctx) error {
@@ -46,6 +57,25 @@ func (h *attendanceHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "create attendance")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	// the student must be in scope both in their current class and in the class recorded on the attendance
+	if !studentInScope(c.Context(), h.studentRepo, scope, attendance.StudentID) ||
+		!scope.AllowsStudent(attendance.StudentID, attendance.ClassID) {
+		log.Println("Error creating attendance:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student_not_found",
+			"error":         "Student not found",
+		})
+	}
+
+	if status, errBody := h.checkTimetableSlot(c, &attendance, "create attendance"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	if err := h.attendanceRepo.Create(c.Context(), &attendance); err != nil {
 		log.Println("Error creating attendance:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -83,13 +113,9 @@ func (h *attendanceHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	attendance, err := h.attendanceRepo.GetByID(c.Context(), uint(id))
-	if err != nil {
-		log.Println("Error getting attendance:", err)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"translate_key": "error.attendance_not_found",
-			"error":         "Attendance not found",
-		})
+	attendance, status, errBody := h.scopedAttendance(c, uint(id), "get attendance")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
 	}
 
 	return c.JSON(fiber.Map{
@@ -110,6 +136,7 @@ func (h *attendanceHandler) GetByID(c *fiber.Ctx) error {
 // @Param offset query int false "Number of records to skip" default(0)
 // @Success 200 {object} map[string]interface{} "Attendance records retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Student ID is required"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /attendances/student-id/{studentId} [get]
 func (h *attendanceHandler) GetByStudent(c *fiber.Ctx) error {
@@ -129,6 +156,19 @@ func (h *attendanceHandler) GetByStudent(c *fiber.Ctx) error {
 		limit = 100
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get attendances by student")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if !studentInScope(c.Context(), h.studentRepo, scope, studentID) {
+		log.Println("Error getting attendances:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student_not_found",
+			"error":         "Student not found",
+		})
+	}
+
 	attendances, err := h.attendanceRepo.GetByStudent(c.Context(), studentID, limit, offset)
 	if err != nil {
 		log.Println("Error getting attendances:", err)
@@ -159,6 +199,7 @@ func (h *attendanceHandler) GetByStudent(c *fiber.Ctx) error {
 // @Param offset query int false "Number of records to skip" default(0)
 // @Success 200 {object} map[string]interface{} "Attendance records retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid class ID"
+// @Failure 404 {object} map[string]interface{} "Class not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /attendances/class-id/{classId} [get]
 func (h *attendanceHandler) GetByClass(c *fiber.Ctx) error {
@@ -179,6 +220,19 @@ func (h *attendanceHandler) GetByClass(c *fiber.Ctx) error {
 		limit = 100
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get attendances by class")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if !scope.AllowsClass(uint(classID)) {
+		log.Println("Error getting attendances:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.class_not_found",
+			"error":         "Class not found",
+		})
+	}
+
 	attendances, err := h.attendanceRepo.GetByClass(c.Context(), uint(classID), limit, offset)
 	if err != nil {
 		log.Println("Error getting attendances:", err)
@@ -200,7 +254,7 @@ func (h *attendanceHandler) GetByClass(c *fiber.Ctx) error {
 
 // GetAttendancesByDateRange godoc
 // @Summary Get attendance by date range
-// @Description Retrieve attendance records within a specific date range
+// @Description Retrieve attendance records within a specific date range, limited to the records the caller may see
 // @Tags Attendances
 // @Accept json
 // @Produce json
@@ -249,7 +303,12 @@ func (h *attendanceHandler) GetByDateRange(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	attendances, err := h.attendanceRepo.GetByDateRange(c.Context(), startDate, endDate, limit, offset)
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get attendances by date range")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	attendances, err := h.attendanceRepo.GetByDateRange(c.Context(), scope, startDate, endDate, limit, offset)
 	if err != nil {
 		log.Println("Error getting attendances:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -279,7 +338,8 @@ func (h *attendanceHandler) GetByDateRange(c *fiber.Ctx) error {
 // @Param id path int true "Attendance ID"
 // @Param attendance body models.Attendance true "Attendance data"
 // @Success 200 {object} map[string]interface{} "Attendance record updated successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 400 {object} map[string]interface{} "Invalid request or timetable slot"
+// @Failure 404 {object} map[string]interface{} "Attendance record or student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /attendances/{id} [put]
 func (h *attendanceHandler) Update(c *fiber.Ctx) error {
@@ -323,6 +383,29 @@ func (h *attendanceHandler) Update(c *fiber.Ctx) error {
 	adminIDUint := uint(adminIDUint64)
 	attendance.UpdatedBy = &adminIDUint
 
+	if _, status, errBody := h.scopedAttendance(c, uint(id), "update attendance"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	scope, status, errBody := currentScope(c, h.scopePolicy, "update attendance")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	// the record may not be moved to a student or class outside the caller's scope, as when creating one
+	if !studentInScope(c.Context(), h.studentRepo, scope, attendance.StudentID) ||
+		!scope.AllowsStudent(attendance.StudentID, attendance.ClassID) {
+		log.Println("Error updating attendance:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student_not_found",
+			"error":         "Student not found",
+		})
+	}
+
+	if status, errBody := h.checkTimetableSlot(c, &attendance, "update attendance"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	attendance.ID = uint(id)
 	if err := h.attendanceRepo.Update(c.Context(), &attendance); err != nil {
 		log.Println("Error updating attendance:", err)
@@ -348,6 +431,7 @@ func (h *attendanceHandler) Update(c *fiber.Ctx) error {
 // @Param id path int true "Attendance ID"
 // @Success 200 {object} map[string]interface{} "Attendance record deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid attendance ID"
+// @Failure 404 {object} map[string]interface{} "Attendance record not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /attendances/{id} [delete]
 func (h *attendanceHandler) Delete(c *fiber.Ctx) error {
@@ -379,6 +463,10 @@ func (h *attendanceHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, status, errBody := h.scopedAttendance(c, uint(id), "delete attendance"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	if err := h.attendanceRepo.UpdateDeleteInfo(c.Context(), uint(id), uint(adminIDUint)); err != nil {
 		log.Println("Error deleting attendance:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -523,7 +611,7 @@ func (h *attendanceHandler) MarkAttendance(c *fiber.Ctx) error {
 
 // GetAll godoc
 // @Summary Get all attendance records
-// @Description Retrieve all attendance records with pagination
+// @Description Retrieve the attendance records the caller may see, with pagination
 // @Tags Attendances
 // @Accept json
 // @Produce json
@@ -540,7 +628,12 @@ func (h *attendanceHandler) GetAll(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	attendances, err := h.attendanceRepo.GetAll(c.Context(), limit, offset)
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get all attendances")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	attendances, err := h.attendanceRepo.GetAll(c.Context(), scope, limit, offset)
 	if err != nil {
 		log.Println("Error getting attendances:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -549,7 +642,7 @@ func (h *attendanceHandler) GetAll(c *fiber.Ctx) error {
 		})
 	}
 
-	total, err := h.attendanceRepo.GetCount(c.Context())
+	total, err := h.attendanceRepo.GetCount(c.Context(), scope)
 	if err != nil {
 		log.Println("Error getting attendance count:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -567,3 +660,55 @@ func (h *attendanceHandler) GetAll(c *fiber.Ctx) error {
 		"offset":        offset,
 	})
 }
+
+// scopedAttendance loads the attendance record, answering not found
+// when it is outside the caller's record scope
+func (h *attendanceHandler) scopedAttendance(c *fiber.Ctx, id uint, action string) (*models.Attendance, int, fiber.Map) {
+	scope, status, errBody := currentScope(c, h.scopePolicy, action)
+	if errBody != nil {
+		return nil, status, errBody
+	}
+
+	attendance, err := h.attendanceRepo.GetByID(c.Context(), id)
+	if err == nil && !scope.AllowsStudent(attendance.StudentID, attendance.ClassID) {
+		err = policy.ErrOutOfScope
+	}
+
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.attendance_not_found",
+			"error":         "Attendance not found",
+		}
+	}
+
+	return attendance, fiber.StatusOK, nil
+}
+
+// checkTimetableSlot checks that lesson attendance points at a lesson of its class
+// held on the weekday of its date. Daily attendance has no slot and always passes.
+func (h *attendanceHandler) checkTimetableSlot(c *fiber.Ctx, attendance *models.Attendance, action string) (int, fiber.Map) {
+	if attendance.TimetableSlotID == nil {
+		return fiber.StatusOK, nil
+	}
+
+	slot, err := h.timetableRepo.GetByID(c.Context(), *attendance.TimetableSlotID)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.timetable_slot_not_found",
+			"error":         "Timetable slot not found",
+		}
+	}
+
+	if slot.ClassID != attendance.ClassID || slot.Weekday != models.ISOWeekday(attendance.Date) {
+		log.Printf("error on %s: timetable slot %d is not a lesson of class %d on %s\n",
+			action, slot.ID, attendance.ClassID, attendance.Date.Format("2006-01-02"))
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.timetable_slot_mismatch",
+			"error":         "The timetable slot is not a lesson of this class on this date",
+		}
+	}
+
+	return fiber.StatusOK, nil
+}
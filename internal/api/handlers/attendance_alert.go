@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type attendanceAlertHandler struct {
+	alertRepo repository.AttendanceAlertRepository
+}
+
+// NewAttendanceAlertHandler creates a new attendance alert handler
+func NewAttendanceAlertHandler(alertRepo repository.AttendanceAlertRepository) AttendanceAlertHandler {
+	return &attendanceAlertHandler{
+		alertRepo: alertRepo,
+	}
+}
+
+// GetAll godoc
+// @Summary Get attendance alerts
+// @Description Retrieve attendance anomaly alerts for the admin dashboard, optionally filtered by status
+// @Tags Attendance Alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Alert status (open, acknowledged, resolved)"
+// @Param limit query int false "Number of alerts to return (max 100)" default(10)
+// @Param offset query int false "Number of alerts to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Attendance alerts retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid status value"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/attendance-alerts [get]
+func (h *attendanceAlertHandler) GetAll(c *fiber.Ctx) error {
+	status := models.AttendanceAlertStatus(c.Query("status", ""))
+	if status != "" &&
+		status != models.AttendanceAlertStatusOpen &&
+		status != models.AttendanceAlertStatusAcknowledged &&
+		status != models.AttendanceAlertStatusResolved {
+		log.Println("error on get attendance alerts: invalid status value")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_status_value",
+			"error":         "Invalid status value",
+		})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	alerts, err := h.alertRepo.GetAll(c.Context(), status, limit, offset)
+	if err != nil {
+		log.Println("error on get attendance alerts:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_attendance_alerts",
+			"error":         "Failed to get attendance alerts",
+		})
+	}
+
+	total, err := h.alertRepo.GetCount(c.Context(), status)
+	if err != nil {
+		log.Println("error on get attendance alert count:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_attendance_alerts",
+			"error":         "Failed to get attendance alert count",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.attendance_alerts_retrieved",
+		"message":       "Attendance alerts retrieved successfully",
+		"data":          alerts,
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// GetByID godoc
+// @Summary Get attendance alert by ID
+// @Description Retrieve a specific attendance anomaly alert
+// @Tags Attendance Alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance alert ID"
+// @Success 200 {object} map[string]interface{} "Attendance alert retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid attendance alert ID"
+// @Failure 404 {object} map[string]interface{} "Attendance alert not found"
+// @Router /admins/attendance-alerts/alert-id/{id} [get]
+func (h *attendanceAlertHandler) GetByID(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		log.Println("error on get attendance alert by id:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_attendance_alert_id",
+			"error":         "Invalid attendance alert ID",
+		})
+	}
+
+	alert, err := h.alertRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on get attendance alert by id:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.attendance_alert_not_found",
+			"error":         "Attendance alert not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.attendance_alert_retrieved",
+		"message":       "Attendance alert retrieved successfully",
+		"data":          alert,
+	})
+}
+
+// Acknowledge godoc
+// @Summary Acknowledge attendance alert
+// @Description Mark an open attendance anomaly alert as acknowledged by the current admin
+// @Tags Attendance Alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance alert ID"
+// @Success 200 {object} map[string]interface{} "Attendance alert acknowledged successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid attendance alert ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/attendance-alerts/alert-id/{id}/acknowledge [put]
+func (h *attendanceAlertHandler) Acknowledge(c *fiber.Ctx) error {
+	adminID, id, status, errBody := h.parseAlertAction(c, "acknowledge")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.alertRepo.Acknowledge(c.Context(), id, adminID); err != nil {
+		log.Println("error on acknowledge attendance alert:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_acknowledge_attendance_alert",
+			"error":         "Failed to acknowledge attendance alert",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.attendance_alert_acknowledged",
+		"message":       "Attendance alert acknowledged successfully",
+	})
+}
+
+// Resolve godoc
+// @Summary Resolve attendance alert
+// @Description Mark an attendance anomaly alert as resolved by the current admin
+// @Tags Attendance Alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance alert ID"
+// @Success 200 {object} map[string]interface{} "Attendance alert resolved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid attendance alert ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/attendance-alerts/alert-id/{id}/resolve [put]
+func (h *attendanceAlertHandler) Resolve(c *fiber.Ctx) error {
+	adminID, id, status, errBody := h.parseAlertAction(c, "resolve")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.alertRepo.Resolve(c.Context(), id, adminID); err != nil {
+		log.Println("error on resolve attendance alert:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_resolve_attendance_alert",
+			"error":         "Failed to resolve attendance alert",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.attendance_alert_resolved",
+		"message":       "Attendance alert resolved successfully",
+	})
+}
+
+// parseAlertAction extracts the current admin ID and the alert ID for acknowledge and resolve actions.
+// On failure it returns the status and body to respond with.
+func (h *attendanceAlertHandler) parseAlertAction(c *fiber.Ctx, action string) (uint, uint, int, fiber.Map) {
+	adminID := c.Locals("userID")
+	if adminID == nil {
+		log.Printf("error on %s attendance alert: invalid admin id\n", action)
+		return 0, 0, fiber.StatusUnauthorized, fiber.Map{
+			"translate_key": "error.unauthorized",
+			"error":         "Unauthorized access",
+		}
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminID.(string), 10, 32)
+	if err != nil {
+		log.Printf("error on %s attendance alert: invalid admin id format: %v\n", action, err)
+		return 0, 0, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_admin_id",
+			"error":         "Invalid admin ID format",
+		}
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s attendance alert: invalid alert id: %v\n", action, err)
+		return 0, 0, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_attendance_alert_id",
+			"error":         "Invalid attendance alert ID",
+		}
+	}
+
+	return uint(adminIDUint), uint(id), fiber.StatusOK, nil
+}
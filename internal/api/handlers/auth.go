@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/michaelwp/student_attendance/internal/models"
@@ -16,31 +17,39 @@ import (
 )
 
 type authHandler struct {
-	adminRepo   repository.AdminRepository
-	teacherRepo repository.TeacherRepository
-	studentRepo repository.StudentRepository
-	redisClient *redis.Client
+	adminRepo    repository.AdminRepository
+	teacherRepo  repository.TeacherRepository
+	studentRepo  repository.StudentRepository
+	guardianRepo repository.GuardianRepository
+	schoolRepo   repository.SchoolRepository
+	redisClient  *redis.Client
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(adminRepo repository.AdminRepository, teacherRepo repository.TeacherRepository, studentRepo repository.StudentRepository, redisClient *redis.Client) AuthHandler {
+func NewAuthHandler(adminRepo repository.AdminRepository, teacherRepo repository.TeacherRepository, studentRepo repository.StudentRepository, guardianRepo repository.GuardianRepository, schoolRepo repository.SchoolRepository, redisClient *redis.Client) AuthHandler {
 	return &authHandler{
-		adminRepo:   adminRepo,
-		teacherRepo: teacherRepo,
-		studentRepo: studentRepo,
-		redisClient: redisClient,
+		adminRepo:    adminRepo,
+		teacherRepo:  teacherRepo,
+		studentRepo:  studentRepo,
+		guardianRepo: guardianRepo,
+		schoolRepo:   schoolRepo,
+		redisClient:  redisClient,
 	}
 }
 
 type LoginRequest struct {
-	UserType string `json:"user_type" validate:"required,oneof=admin teacher student"`
+	UserType string `json:"user_type" validate:"required,oneof=admin teacher student guardian"`
 	UserID   string `json:"user_id" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
 
+type SwitchSchoolRequest struct {
+	SchoolID uint `json:"school_id" validate:"required"`
+}
+
 // Login godoc
 // @Summary User login
-// @Description Authenticate user and return JWT token. For admin use email as user_id, for teacher/student use their respective IDs
+// @Description Authenticate user and return JWT token. For admin and guardian use email as user_id, for teacher/student use their respective IDs
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -67,25 +76,30 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Validate user type
-	if loginReq.UserType != "admin" && loginReq.UserType != "teacher" && loginReq.UserType != "student" {
+	if loginReq.UserType != "admin" && loginReq.UserType != "teacher" && loginReq.UserType != "student" && loginReq.UserType != "guardian" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"translate_key": "error.invalid_user_type",
-			"error":         "User type must be admin, teacher, or student",
+			"error":         "User type must be admin, teacher, student, or guardian",
 		})
 	}
 
-	var userID uint
+	var userID, schoolID uint
 	var storedPassword string
 	var err error
 
+	// the user's school is only known once they are found, so the lookup reaches every school
+	ctx := models.WithAllSchools(c.Context())
+
 	// Authenticate based on a user type
 	switch loginReq.UserType {
 	case models.UserTypeAdmin.String():
-		userID, storedPassword, err = h.authenticateAdmin(c.Context(), loginReq.UserID)
+		userID, schoolID, storedPassword, err = h.authenticateAdmin(ctx, loginReq.UserID)
 	case models.UserTypeTeacher.String(), "teacher":
-		userID, storedPassword, err = h.authenticateTeacher(c.Context(), loginReq.UserID)
+		userID, schoolID, storedPassword, err = h.authenticateTeacher(ctx, loginReq.UserID)
 	case models.UserTypeStudent.String():
-		userID, storedPassword, err = h.authenticateStudent(c.Context(), loginReq.UserID)
+		userID, schoolID, storedPassword, err = h.authenticateStudent(ctx, loginReq.UserID)
+	case models.UserTypeGuardian.String():
+		userID, schoolID, storedPassword, err = h.authenticateGuardian(ctx, loginReq.UserID)
 	}
 
 	if err != nil {
@@ -111,7 +125,7 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 
 	// Generate and cache JWT token
 	strUserID := strconv.FormatUint(uint64(userID), 10) // Safe conversion from uint to string
-	token, err := h.generateAndCacheToken(c.Context(), strUserID, loginReq.UserType)
+	token, err := h.generateAndCacheToken(c.Context(), strUserID, loginReq.UserType, schoolID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"translate_key": "error.token_generation_failed",
@@ -119,9 +133,14 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update last login for admin
+	// Update last login for admin and guardian
 	if loginReq.UserType == "admin" {
-		h.updateAdminLastLogin(c.Context(), loginReq.UserID)
+		h.updateAdminLastLogin(ctx, loginReq.UserID)
+	}
+	if loginReq.UserType == "guardian" {
+		if err := h.guardianRepo.UpdateLastLogin(ctx, userID, time.Now()); err != nil {
+			log.Println("error updating last login for guardian:", err)
+		}
 	}
 
 	c.Cookie(&fiber.Cookie{
@@ -139,96 +158,117 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 		"token":         token,
 		"user_type":     loginReq.UserType,
 		"user_id":       loginReq.UserID,
+		"school_id":     schoolID,
 		"expires_at":    time.Now().Add(time.Hour).Unix(),
 	})
 }
 
-// authenticateAdmin validates admin credentials and returns userID and password if successful
-func (h *authHandler) authenticateAdmin(ctx context.Context, userID string) (uint, string, error) {
+// authenticateAdmin validates admin credentials and returns userID, schoolID and password if successful
+func (h *authHandler) authenticateAdmin(ctx context.Context, userID string) (uint, uint, string, error) {
 	userExists, err := h.adminRepo.IsAdminExist(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 	if !userExists {
-		return 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
 	}
 
 	// Check if admin is active
 	admin, err := h.adminRepo.GetByEmail(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 
 	if !admin.IsActive {
-		return 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
 	}
 
 	storedPassword, err := h.adminRepo.GetPasswordByEmail(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 
-	return admin.ID, storedPassword, nil
+	return admin.ID, admin.SchoolID, storedPassword, nil
 }
 
-// authenticateTeacher validates teacher credentials and returns userID and password if successful
-func (h *authHandler) authenticateTeacher(ctx context.Context, userID string) (uint, string, error) {
+// authenticateTeacher validates teacher credentials and returns userID, schoolID and password if successful
+func (h *authHandler) authenticateTeacher(ctx context.Context, userID string) (uint, uint, string, error) {
 	userExists, err := h.teacherRepo.IsTeacherExist(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 	if !userExists {
-		return 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
 	}
 
 	// Check if the teacher is active
 	teacher, err := h.teacherRepo.GetByTeacherID(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 
 	if !teacher.IsActive {
-		return 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
 	}
 
 	storedPassword, err := h.teacherRepo.GetPasswordByTeacherID(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 
-	return teacher.ID, storedPassword, nil
+	return teacher.ID, teacher.SchoolID, storedPassword, nil
 }
 
-// authenticateStudent validates student credentials and returns userID and password if successful
-func (h *authHandler) authenticateStudent(ctx context.Context, userID string) (uint, string, error) {
+// authenticateStudent validates student credentials and returns userID, schoolID and password if successful
+func (h *authHandler) authenticateStudent(ctx context.Context, userID string) (uint, uint, string, error) {
 	userExists, err := h.studentRepo.IsStudentExist(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 	if !userExists {
-		return 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
 	}
 
 	// Check if the student is active
 	student, err := h.studentRepo.GetByStudentID(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 
 	if !student.IsActive {
-		return 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
 	}
 
 	storedPassword, err := h.studentRepo.GetPasswordByStudentID(ctx, userID)
 	if err != nil {
-		return 0, "", err
+		return 0, 0, "", err
 	}
 
-	return student.ID, storedPassword, nil
+	return student.ID, student.SchoolID, storedPassword, nil
+}
+
+// authenticateGuardian validates guardian credentials and returns userID, schoolID and password if successful
+func (h *authHandler) authenticateGuardian(ctx context.Context, email string) (uint, uint, string, error) {
+	guardian, err := h.guardianRepo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
+	}
+
+	if !guardian.IsActive {
+		return 0, 0, "", fiber.NewError(fiber.StatusUnauthorized, "Account is deactivated")
+	}
+
+	storedPassword, err := h.guardianRepo.GetPasswordByEmail(ctx, guardian.Email)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	// guardians belong to the foundation, their links decide which students they reach
+	return guardian.ID, 0, storedPassword, nil
 }
 
 // generateAndCacheToken generates a JWT token and caches it in Redis
-func (h *authHandler) generateAndCacheToken(ctx context.Context, userID, userType string) (string, error) {
+func (h *authHandler) generateAndCacheToken(ctx context.Context, userID, userType string, schoolID uint) (string, error) {
 	// Get JWT secret from the environment
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -242,7 +282,7 @@ func (h *authHandler) generateAndCacheToken(ctx context.Context, userID, userTyp
 	}
 
 	// Generate token
-	token, err := pkg.GenerateToken(userID, userType, jwtConfig)
+	token, err := pkg.GenerateToken(userID, userType, schoolID, jwtConfig)
 	if err != nil {
 		return "", err
 	}
@@ -318,3 +358,88 @@ func (h *authHandler) Logout(c *fiber.Ctx) error {
 		"message":       "Logout successful",
 	})
 }
+
+// SwitchSchool godoc
+// @Summary Switch school
+// @Description Issue a new token for the super-admin that works in another school. The previous token stops working.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SwitchSchoolRequest true "School to switch to"
+// @Success 200 {object} map[string]interface{} "School switched"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Super-admin required"
+// @Failure 404 {object} map[string]interface{} "School not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/switch-school [post]
+func (h *authHandler) SwitchSchool(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	userType, _ := c.Locals("userType").(string)
+	if userID == "" || userType == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"translate_key": "error.authentication_required",
+			"error":         "Authentication required",
+		})
+	}
+
+	var req SwitchSchoolRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	if req.SchoolID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.school_id_required",
+			"error":         "School ID is required",
+		})
+	}
+
+	school, err := h.schoolRepo.GetByID(c.Context(), req.SchoolID)
+	if err != nil {
+		log.Println("error on get school:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.school_not_found",
+			"error":         "School not found",
+		})
+	}
+
+	if !school.IsActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.school_inactive",
+			"error":         "School is not active",
+		})
+	}
+
+	// the new token replaces the cached one, so the token for the previous school stops working
+	token, err := h.generateAndCacheToken(c.Context(), userID, userType, school.ID)
+	if err != nil {
+		log.Println("error on switch school token:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.token_generation_failed",
+			"error":         "Failed to generate or cache token",
+		})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "token",
+		Value:    token,
+		Expires:  time.Now().Add(time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+	})
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.school_switched",
+		"message":       "School switched successfully",
+		"token":         token,
+		"user_type":     userType,
+		"school_id":     school.ID,
+		"expires_at":    time.Now().Add(time.Hour).Unix(),
+	})
+}
@@ -10,13 +10,24 @@ import (
 )
 
 type classHandler struct {
-	classRepo repository.ClassRepository
+	classRepo        repository.ClassRepository
+	academicYearRepo repository.AcademicYearRepository
+	classTeacherRepo repository.ClassTeacherRepository
+	teacherRepo      repository.TeacherRepository
 }
 
 // NewClassHandler creates a new class handler
-func NewClassHandler(classRepo repository.ClassRepository) ClassHandler {
+func NewClassHandler(
+	classRepo repository.ClassRepository,
+	academicYearRepo repository.AcademicYearRepository,
+	classTeacherRepo repository.ClassTeacherRepository,
+	teacherRepo repository.TeacherRepository,
+) ClassHandler {
 	return &classHandler{
-		classRepo: classRepo,
+		classRepo:        classRepo,
+		academicYearRepo: academicYearRepo,
+		classTeacherRepo: classTeacherRepo,
+		teacherRepo:      teacherRepo,
 	}
 }
 
@@ -28,7 +39,7 @@ func NewClassHandler(classRepo repository.ClassRepository) ClassHandler {
 // @Produce json
 // @Param class body models.Class true "Class data"
 // @Success 201 {object} map[string]interface{} "Class created successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or academic year"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /classes [post]
 func (h *classHandler) Create(c *fiber.Ctx) error {
@@ -39,6 +50,10 @@ func (h *classHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	if errBody := h.checkAcademicYear(c, &class); errBody != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody)
+	}
+
 	if err := h.classRepo.Create(c.Context(), &class); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create class",
@@ -158,7 +173,7 @@ func (h *classHandler) GetByTeacher(c *fiber.Ctx) error {
 // @Param id path int true "Class ID"
 // @Param class body models.Class true "Class data"
 // @Success 200 {object} map[string]interface{} "Class updated successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 400 {object} map[string]interface{} "Invalid request or academic year"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /classes/{id} [put]
 func (h *classHandler) Update(c *fiber.Ctx) error {
@@ -177,6 +192,10 @@ func (h *classHandler) Update(c *fiber.Ctx) error {
 		})
 	}
 
+	if errBody := h.checkAcademicYear(c, &class); errBody != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody)
+	}
+
 	class.ID = uint(id)
 	if err := h.classRepo.Update(c.Context(), &class); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -243,3 +262,21 @@ func (h *classHandler) Delete(c *fiber.Ctx) error {
 		"message":       "Class deleted successfully",
 	})
 }
+
+// checkAcademicYear checks the academic year the class belongs to, if any, exists in the current school.
+// On failure it returns the body to respond with.
+func (h *classHandler) checkAcademicYear(c *fiber.Ctx, class *models.Class) fiber.Map {
+	if class.AcademicYearID == nil {
+		return nil
+	}
+
+	if _, err := h.academicYearRepo.GetByID(c.Context(), *class.AcademicYearID); err != nil {
+		log.Println("error on check class academic year:", err)
+		return fiber.Map{
+			"translate_key": "error.academic_year_not_found",
+			"error":         "Academic year not found",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// GetTeachers godoc
+// @Summary Get class teachers
+// @Description Retrieve the teachers of a class with their role in it, the homeroom teacher first
+// @Tags Classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Class ID"
+// @Success 200 {object} map[string]interface{} "Class teachers retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid class ID"
+// @Failure 404 {object} map[string]interface{} "Class not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /classes/{id}/teachers [get]
+func (h *classHandler) GetTeachers(c *fiber.Ctx) error {
+	class, status, errBody := h.findClass(c, "get class teachers")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	teachers, err := h.classTeacherRepo.GetByClass(c.Context(), class.ID)
+	if err != nil {
+		log.Println("error on get class teachers:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_class_teachers",
+			"error":         "Failed to get class teachers",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.class_teachers_retrieved",
+		"message":       "Class teachers retrieved successfully",
+		"data":          teachers,
+	})
+}
+
+// AssignTeacher godoc
+// @Summary Assign class teacher
+// @Description Give a teacher a role in a class (homeroom, co_homeroom or assistant), changing the role they already hold there.
+// @Description A new homeroom teacher replaces the previous one, who leaves the class.
+// @Tags Classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Class ID"
+// @Param class_teacher body models.ClassTeacher true "Teacher ID and role"
+// @Success 200 {object} map[string]interface{} "Class teacher assigned successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, role or teacher"
+// @Failure 404 {object} map[string]interface{} "Class not found"
+// @Failure 409 {object} map[string]interface{} "The homeroom teacher cannot change role"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /classes/{id}/teachers [put]
+func (h *classHandler) AssignTeacher(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "assign class teacher")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	class, status, errBody := h.findClass(c, "assign class teacher")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var classTeacher models.ClassTeacher
+	if err := c.BodyParser(&classTeacher); err != nil {
+		log.Println("error on assign class teacher:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	if !models.IsValidClassTeacherRole(classTeacher.Role) {
+		log.Println("error on assign class teacher:", models.ErrInvalidClassTeacherRole)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_class_teacher_role",
+			"error":         "Role must be homeroom, co_homeroom or assistant",
+		})
+	}
+
+	teacher, err := h.teacherRepo.GetByTeacherID(c.Context(), strings.TrimSpace(classTeacher.TeacherID))
+	if err != nil {
+		log.Println("error on assign class teacher:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.teacher_not_found",
+			"error":         "Teacher not found",
+		})
+	}
+
+	classTeacher.ClassID = class.ID
+	classTeacher.TeacherID = teacher.TeacherID
+	classTeacher.FirstName = teacher.FirstName
+	classTeacher.LastName = teacher.LastName
+	classTeacher.CreatedBy = &adminID
+	if err := h.classTeacherRepo.Assign(c.Context(), &classTeacher); err != nil {
+		log.Println("error on assign class teacher:", err)
+		if errors.Is(err, models.ErrHomeroomTeacherRequired) {
+			return c.Status(fiber.StatusConflict).JSON(homeroomTeacherRequiredError())
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_assign_class_teacher",
+			"error":         "Failed to assign class teacher",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.class_teacher_assigned",
+		"message":       "Class teacher assigned successfully",
+		"data":          classTeacher,
+	})
+}
+
+// RemoveTeacher godoc
+// @Summary Remove class teacher
+// @Description Take a co-homeroom teacher or an assistant off a class. The homeroom teacher can only be replaced.
+// @Tags Classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Class ID"
+// @Param teacherId path string true "Teacher ID"
+// @Success 200 {object} map[string]interface{} "Class teacher removed successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid class ID"
+// @Failure 404 {object} map[string]interface{} "Class or class teacher not found"
+// @Failure 409 {object} map[string]interface{} "The homeroom teacher cannot be removed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /classes/{id}/teachers/teacher-id/{teacherId} [delete]
+func (h *classHandler) RemoveTeacher(c *fiber.Ctx) error {
+	class, status, errBody := h.findClass(c, "remove class teacher")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.classTeacherRepo.Remove(c.Context(), class.ID, c.Params("teacherId")); err != nil {
+		log.Println("error on remove class teacher:", err)
+		if errors.Is(err, models.ErrHomeroomTeacherRequired) {
+			return c.Status(fiber.StatusConflict).JSON(homeroomTeacherRequiredError())
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.class_teacher_not_found",
+			"error":         "Class teacher not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.class_teacher_removed",
+		"message":       "Class teacher removed successfully",
+	})
+}
+
+// GetTeacherRoles godoc
+// @Summary Get class teacher role permissions
+// @Description Retrieve what homeroom, co-homeroom and assistant teachers may do in their classes in the current school
+// @Tags Classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Class teacher roles retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/class-teacher-roles [get]
+func (h *classHandler) GetTeacherRoles(c *fiber.Ctx) error {
+	permissions, err := h.classTeacherRepo.GetRolePermissions(c.Context())
+	if err != nil {
+		log.Println("error on get class teacher roles:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_class_teacher_roles",
+			"error":         "Failed to get class teacher roles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.class_teacher_roles_retrieved",
+		"message":       "Class teacher roles retrieved successfully",
+		"data":          permissions,
+	})
+}
+
+// UpdateTeacherRole godoc
+// @Summary Update class teacher role permissions
+// @Description Set what teachers of a class role may do in their classes in the current school, for example whether assistants may decide absent requests
+// @Tags Classes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Class teacher role (homeroom, co_homeroom or assistant)"
+// @Param permission body models.ClassTeacherRolePermission true "Role permissions"
+// @Success 200 {object} map[string]interface{} "Class teacher role updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid role or request body"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/class-teacher-roles/{role} [put]
+func (h *classHandler) UpdateTeacherRole(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update class teacher role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role := c.Params("role")
+	if !models.IsValidClassTeacherRole(role) {
+		log.Println("error on update class teacher role:", models.ErrInvalidClassTeacherRole)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_class_teacher_role",
+			"error":         "Role must be homeroom, co_homeroom or assistant",
+		})
+	}
+
+	var permission models.ClassTeacherRolePermission
+	if err := c.BodyParser(&permission); err != nil {
+		log.Println("error on update class teacher role:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	permission.Role = role
+	permission.UpdatedBy = &adminID
+	if err := h.classTeacherRepo.UpdateRolePermission(c.Context(), &permission); err != nil {
+		log.Println("error on update class teacher role:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_class_teacher_role",
+			"error":         "Failed to update class teacher role",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.class_teacher_role_updated",
+		"message":       "Class teacher role updated successfully",
+		"data":          permission,
+	})
+}
+
+func (h *classHandler) findClass(c *fiber.Ctx, action string) (*models.Class, int, fiber.Map) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_class_id",
+			"error":         "Invalid class ID",
+		}
+	}
+
+	class, err := h.classRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.class_not_found",
+			"error":         "Class not found",
+		}
+	}
+
+	return class, fiber.StatusOK, nil
+}
+
+// homeroomTeacherRequiredError is the body to respond with when a change would leave a class without its homeroom teacher
+func homeroomTeacherRequiredError() fiber.Map {
+	return fiber.Map{
+		"translate_key": "error.homeroom_teacher_required",
+		"error":         "A class must keep its homeroom teacher. Make another teacher homeroom first.",
+	}
+}
@@ -0,0 +1,830 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+	"github.com/michaelwp/student_attendance/pkg"
+)
+
+type guardianHandler struct {
+	guardianRepo      repository.GuardianRepository
+	studentRepo       repository.StudentRepository
+	attendanceRepo    repository.AttendanceRepository
+	absentRequestRepo repository.AbsentRequestRepository
+}
+
+// NewGuardianHandler creates a new guardian handler
+func NewGuardianHandler(
+	guardianRepo repository.GuardianRepository,
+	studentRepo repository.StudentRepository,
+	attendanceRepo repository.AttendanceRepository,
+	absentRequestRepo repository.AbsentRequestRepository,
+) GuardianHandler {
+	return &guardianHandler{
+		guardianRepo:      guardianRepo,
+		studentRepo:       studentRepo,
+		attendanceRepo:    attendanceRepo,
+		absentRequestRepo: absentRequestRepo,
+	}
+}
+
+// Create godoc
+// @Summary Create guardian
+// @Description Create a guardian account. When no password is given one is generated and returned as newPassword.
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param guardian body models.GuardianInput true "Guardian data"
+// @Success 201 {object} map[string]interface{} "Guardian created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/guardians [post]
+func (h *guardianHandler) Create(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create guardian")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	input, status, errBody := h.parseGuardian(c, 0)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	password := input.Password
+	var generatedPassword string
+	if password == "" {
+		var err error
+		generatedPassword, err = pkg.GeneratePassword(12)
+		if err != nil {
+			log.Println("error on create guardian: failed to generate password:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_generate_password",
+				"error":         "Failed to generate password",
+			})
+		}
+		password = generatedPassword
+	}
+
+	round, _ := strconv.Atoi(os.Getenv("SALT"))
+	hashPassword, err := pkg.HashPassword(password, round)
+	if err != nil {
+		log.Println("error on create guardian: failed to hash password:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_hash_password",
+			"error":         "Failed to hash password",
+		})
+	}
+
+	guardian := &models.Guardian{
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Email:     input.Email,
+		Phone:     input.Phone,
+		Password:  hashPassword,
+		IsActive:  input.IsActive == nil || *input.IsActive,
+		CreatedBy: &adminID,
+	}
+
+	if err := h.guardianRepo.Create(c.Context(), guardian); err != nil {
+		log.Println("error on create guardian:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_guardian",
+			"error":         "Failed to create guardian",
+		})
+	}
+
+	response := fiber.Map{
+		"translate_key": "success.guardian_created",
+		"message":       "Guardian created successfully",
+		"data":          guardian,
+	}
+	if generatedPassword != "" {
+		response["newPassword"] = generatedPassword
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// GetAll godoc
+// @Summary Get all guardians
+// @Description Retrieve a paginated list of guardians
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of guardians to return (max 100)" default(10)
+// @Param offset query int false "Number of guardians to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Guardians retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/guardians [get]
+func (h *guardianHandler) GetAll(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	guardians, err := h.guardianRepo.GetAll(c.Context(), limit, offset)
+	if err != nil {
+		log.Println("error on get all guardians:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_guardians",
+			"error":         "Failed to get guardians",
+		})
+	}
+
+	total, err := h.guardianRepo.GetTotalGuardians(c.Context())
+	if err != nil {
+		log.Println("error on get total guardians:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_guardians",
+			"error":         "Failed to get guardians",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardians_retrieved",
+		"message":       "Guardians retrieved successfully",
+		"data":          guardians,
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// GetByID godoc
+// @Summary Get guardian by ID
+// @Description Retrieve a guardian together with the students linked to them
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Guardian ID"
+// @Success 200 {object} map[string]interface{} "Guardian retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid guardian ID"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/guardians/guardian-id/{id} [get]
+func (h *guardianHandler) GetByID(c *fiber.Ctx) error {
+	guardian, status, errBody := h.guardianFromParam(c, "get guardian by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	children, err := h.guardianRepo.GetChildren(c.Context(), guardian.ID)
+	if err != nil {
+		log.Println("error on get guardian by id:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_guardian_children",
+			"error":         "Failed to get guardian children",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardian_retrieved",
+		"message":       "Guardian retrieved successfully",
+		"data":          guardian,
+		"children":      children,
+	})
+}
+
+// Update godoc
+// @Summary Update guardian
+// @Description Update a guardian's name, contact details and status. The password is left unchanged.
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Guardian ID"
+// @Param guardian body models.GuardianInput true "Guardian data"
+// @Success 200 {object} map[string]interface{} "Guardian updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/guardians/guardian-id/{id} [put]
+func (h *guardianHandler) Update(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update guardian")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	guardian, status, errBody := h.guardianFromParam(c, "update guardian")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	input, status, errBody := h.parseGuardian(c, guardian.ID)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	guardian.FirstName = input.FirstName
+	guardian.LastName = input.LastName
+	guardian.Email = input.Email
+	guardian.Phone = input.Phone
+	if input.IsActive != nil {
+		guardian.IsActive = *input.IsActive
+	}
+	guardian.UpdatedBy = &adminID
+
+	if err := h.guardianRepo.Update(c.Context(), guardian); err != nil {
+		log.Println("error on update guardian:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_guardian",
+			"error":         "Failed to update guardian",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardian_updated",
+		"message":       "Guardian updated successfully",
+		"data":          guardian,
+	})
+}
+
+// Delete godoc
+// @Summary Delete guardian
+// @Description Remove a guardian account. Requests they filed keep a reference to them.
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Guardian ID"
+// @Success 200 {object} map[string]interface{} "Guardian deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid guardian ID"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Router /admins/guardians/guardian-id/{id} [delete]
+func (h *guardianHandler) Delete(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete guardian")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on delete guardian:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_guardian_id",
+			"error":         "Invalid guardian ID",
+		})
+	}
+
+	if err := h.guardianRepo.UpdateDeleteInfo(c.Context(), uint(id), adminID); err != nil {
+		log.Println("error on delete guardian:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.guardian_not_found",
+			"error":         "Guardian not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardian_deleted",
+		"message":       "Guardian deleted successfully",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset guardian password
+// @Description Generate a new password for a guardian and return it as newPassword
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Guardian ID"
+// @Success 200 {object} map[string]interface{} "Password reset successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid guardian ID"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/guardians/guardian-id/{id}/reset-password [put]
+func (h *guardianHandler) ResetPassword(c *fiber.Ctx) error {
+	guardian, status, errBody := h.guardianFromParam(c, "reset guardian password")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	password, err := pkg.GeneratePassword(12)
+	if err != nil {
+		log.Println("error on reset guardian password: failed to generate password:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_generate_password",
+			"error":         "Failed to generate password",
+		})
+	}
+
+	if err := h.updatePassword(c.Context(), guardian.ID, password); err != nil {
+		log.Println("error on reset guardian password:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.password.update.failed",
+			"error":         "Failed to update password",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.password.reset",
+		"message":       "Password reset successfully",
+		"newPassword":   password,
+	})
+}
+
+// LinkStudent godoc
+// @Summary Link guardian to student
+// @Description Allow a guardian to follow and report absences for a student. Linking again updates the relationship.
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Guardian ID"
+// @Param link body object true "Student database ID and relationship, e.g. {\"student_id\": 1, \"relationship\": \"mother\"}"
+// @Success 200 {object} map[string]interface{} "Guardian linked to student successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or student"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/guardians/guardian-id/{id}/students [post]
+func (h *guardianHandler) LinkStudent(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "link guardian to student")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	guardian, status, errBody := h.guardianFromParam(c, "link guardian to student")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var request struct {
+		StudentID    uint    `json:"student_id"`
+		Relationship *string `json:"relationship"`
+	}
+
+	if err := c.BodyParser(&request); err != nil || request.StudentID == 0 {
+		log.Println("error on link guardian to student: invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	if _, err := h.studentRepo.GetByID(c.Context(), request.StudentID); err != nil {
+		log.Println("error on link guardian to student:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.student_not_found",
+			"error":         "Student not found",
+		})
+	}
+
+	if request.Relationship != nil {
+		relationship := strings.TrimSpace(*request.Relationship)
+		request.Relationship = &relationship
+	}
+
+	link := &models.GuardianStudent{
+		GuardianID:   guardian.ID,
+		StudentID:    request.StudentID,
+		Relationship: request.Relationship,
+		CreatedBy:    &adminID,
+	}
+
+	if err := h.guardianRepo.LinkStudent(c.Context(), link); err != nil {
+		log.Println("error on link guardian to student:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_link_guardian_student",
+			"error":         "Failed to link guardian to student",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardian_student_linked",
+		"message":       "Guardian linked to student successfully",
+		"data":          link,
+	})
+}
+
+// UnlinkStudent godoc
+// @Summary Unlink guardian from student
+// @Description Stop a guardian from following a student. Requests they already filed are kept.
+// @Tags Guardians
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Guardian ID"
+// @Param studentId path int true "Student database ID"
+// @Success 200 {object} map[string]interface{} "Guardian unlinked from student successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid guardian or student ID"
+// @Failure 404 {object} map[string]interface{} "Link not found"
+// @Router /admins/guardians/guardian-id/{id}/students/{studentId} [delete]
+func (h *guardianHandler) UnlinkStudent(c *fiber.Ctx) error {
+	guardianID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on unlink guardian from student:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_guardian_id",
+			"error":         "Invalid guardian ID",
+		})
+	}
+
+	studentID, err := strconv.ParseUint(c.Params("studentId"), 10, 32)
+	if err != nil {
+		log.Println("error on unlink guardian from student:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_student_id",
+			"error":         "Invalid student ID",
+		})
+	}
+
+	if err := h.guardianRepo.UnlinkStudent(c.Context(), uint(guardianID), uint(studentID)); err != nil {
+		log.Println("error on unlink guardian from student:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.guardian_student_link_not_found",
+			"error":         "Guardian is not linked to this student",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardian_student_unlinked",
+		"message":       "Guardian unlinked from student successfully",
+	})
+}
+
+// GetProfile godoc
+// @Summary Get current guardian profile
+// @Description Retrieve the profile of the logged-in guardian
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Profile retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Invalid user ID"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Router /guardian/profile [get]
+func (h *guardianHandler) GetProfile(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "get guardian profile")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	// guardians belong to the foundation, so their own account is outside any one school
+	guardian, err := h.guardianRepo.GetByID(models.WithAllSchools(c.Context()), actor.UserID)
+	if err != nil {
+		log.Println("error on get guardian profile:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.guardian_not_found",
+			"error":         "Guardian not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.profile_retrieved",
+		"message":       "Profile retrieved successfully",
+		"data":          guardian,
+	})
+}
+
+// UpdateCurrentPassword godoc
+// @Summary Update current guardian password
+// @Description Change the password of the logged-in guardian
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param password body object true "Old and new password, e.g. {\"old_password\": \"...\", \"new_password\": \"...\"}"
+// @Success 200 {object} map[string]interface{} "Password updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or old password"
+// @Failure 404 {object} map[string]interface{} "Guardian not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /guardian/password [put]
+func (h *guardianHandler) UpdateCurrentPassword(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "update guardian password")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var request struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
+		log.Println("error on update guardian password: failed to parse request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	if request.NewPassword == "" || request.OldPassword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.password.required",
+			"error":         "Password is required",
+		})
+	}
+
+	// guardians belong to the foundation, so their own account is outside any one school
+	ctx := models.WithAllSchools(c.Context())
+	guardian, err := h.guardianRepo.GetByID(ctx, actor.UserID)
+	if err != nil {
+		log.Println("error on update guardian password:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.guardian_not_found",
+			"error":         "Guardian not found",
+		})
+	}
+
+	storedPassword, err := h.guardianRepo.GetPasswordByEmail(ctx, guardian.Email)
+	if err != nil {
+		log.Println("error on update guardian password: failed to retrieve stored password:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.password.retrieval.failed",
+			"error":         "Failed to retrieve stored password",
+		})
+	}
+
+	if err := pkg.ComparePasswords(storedPassword, request.OldPassword); err != nil {
+		log.Println("error on update guardian password: failed to compare passwords:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid.old.password",
+			"error":         "Invalid old password",
+		})
+	}
+
+	if err := h.updatePassword(ctx, guardian.ID, request.NewPassword); err != nil {
+		log.Println("error on update guardian password:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.password.update.failed",
+			"error":         "Failed to update password",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.password.updated",
+		"message":       "Password updated successfully",
+	})
+}
+
+// GetChildren godoc
+// @Summary Get current guardian's children
+// @Description Retrieve the students linked to the logged-in guardian
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Children retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Invalid user ID"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /guardian/children [get]
+func (h *guardianHandler) GetChildren(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "get guardian children")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	children, err := h.guardianRepo.GetChildren(c.Context(), actor.UserID)
+	if err != nil {
+		log.Println("error on get guardian children:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_guardian_children",
+			"error":         "Failed to get guardian children",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.guardian_children_retrieved",
+		"message":       "Children retrieved successfully",
+		"data":          children,
+	})
+}
+
+// GetChildAttendances godoc
+// @Summary Get a child's attendance
+// @Description Retrieve the attendance records of a student linked to the logged-in guardian
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student database ID"
+// @Param limit query int false "Number of records to return (max 100)" default(10)
+// @Param offset query int false "Number of records to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Attendances retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID"
+// @Failure 404 {object} map[string]interface{} "Student not found among the guardian's children"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /guardian/children/record-id/{id}/attendances [get]
+func (h *guardianHandler) GetChildAttendances(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "get child attendances")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	student, status, errBody := linkedChild(c, h.guardianRepo, h.studentRepo, actor.UserID, "get child attendances")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	attendances, err := h.attendanceRepo.GetByStudent(c.Context(), student.StudentID, limit, offset)
+	if err != nil {
+		log.Println("error on get child attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_attendances",
+			"error":         "Failed to get attendances",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.attendances_retrieved",
+		"message":       "Attendances retrieved successfully",
+		"data":          attendances,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// GetChildAbsentRequests godoc
+// @Summary Get a child's absent requests
+// @Description Retrieve the absent requests of a student linked to the logged-in guardian
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student database ID"
+// @Param limit query int false "Number of requests to return (max 100)" default(10)
+// @Param offset query int false "Number of requests to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Absent requests retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID"
+// @Failure 404 {object} map[string]interface{} "Student not found among the guardian's children"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /guardian/children/record-id/{id}/absent-requests [get]
+func (h *guardianHandler) GetChildAbsentRequests(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "get child absent requests")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	student, status, errBody := linkedChild(c, h.guardianRepo, h.studentRepo, actor.UserID, "get child absent requests")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	requests, err := h.absentRequestRepo.GetByStudent(c.Context(), student.StudentID, limit, offset)
+	if err != nil {
+		log.Println("error on get child absent requests:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_absent_requests",
+			"error":         "Failed to get absent requests",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.absent_requests_retrieved",
+		"message":       "Absent requests retrieved successfully",
+		"data":          requests,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// guardianFromParam loads the guardian named by the id path parameter.
+// On failure it returns the status and body to respond with.
+func (h *guardianHandler) guardianFromParam(c *fiber.Ctx, action string) (*models.Guardian, int, fiber.Map) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_guardian_id",
+			"error":         "Invalid guardian ID",
+		}
+	}
+
+	guardian, err := h.guardianRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.guardian_not_found",
+			"error":         "Guardian not found",
+		}
+	}
+
+	return guardian, fiber.StatusOK, nil
+}
+
+// parseGuardian reads the guardian from the request body and checks the email is free.
+// On failure it returns the status and body to respond with.
+func (h *guardianHandler) parseGuardian(c *fiber.Ctx, guardianID uint) (*models.GuardianInput, int, fiber.Map) {
+	var input models.GuardianInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on parse guardian:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	input.FirstName = strings.TrimSpace(input.FirstName)
+	input.LastName = strings.TrimSpace(input.LastName)
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	if input.FirstName == "" || input.LastName == "" || input.Email == "" {
+		log.Println("error on parse guardian: required fields missing")
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.guardian_fields_required",
+			"error":         "First name, last name and email are required",
+		}
+	}
+
+	exists, err := h.guardianRepo.IsEmailExist(c.Context(), input.Email, guardianID)
+	if err != nil {
+		log.Println("error on parse guardian:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_guardian_email",
+			"error":         "Failed to check guardian email",
+		}
+	}
+
+	if exists {
+		log.Println("error on parse guardian: email already in use")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.guardian_email_exists",
+			"error":         "Another guardian already uses this email",
+		}
+	}
+
+	return &input, fiber.StatusOK, nil
+}
+
+func (h *guardianHandler) updatePassword(ctx context.Context, id uint, password string) error {
+	round, _ := strconv.Atoi(os.Getenv("SALT"))
+	hashPassword, err := pkg.HashPassword(password, round)
+	if err != nil {
+		return err
+	}
+
+	return h.guardianRepo.UpdatePassword(ctx, id, hashPassword)
+}
+
+// linkedChild loads the student named by the id path parameter when they are linked to the guardian,
+// and limits the rest of the request to the student's school. Students outside the guardian's children
+// are reported as not found.
+// On failure it returns the status and body to respond with.
+func linkedChild(
+	c *fiber.Ctx,
+	guardianRepo repository.GuardianRepository,
+	studentRepo repository.StudentRepository,
+	guardianID uint,
+	action string,
+) (*models.Student, int, fiber.Map) {
+	studentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: invalid student id: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_student_id",
+			"error":         "Invalid student ID",
+		}
+	}
+
+	schoolID, linked, err := guardianRepo.GetChildSchool(c.Context(), guardianID, uint(studentID))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_get_student",
+			"error":         "Failed to get student",
+		}
+	}
+
+	var student *models.Student
+	if linked {
+		c.Locals(models.SchoolContextKey, schoolID)
+		student, err = studentRepo.GetByID(c.Context(), uint(studentID))
+	}
+
+	if !linked || err != nil {
+		log.Printf("error on %s: student %d is not a child of guardian %d: %v\n", action, studentID, guardianID, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.student_not_found",
+			"error":         "Student not found",
+		}
+	}
+
+	return student, fiber.StatusOK, nil
+}
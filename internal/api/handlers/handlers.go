@@ -3,6 +3,8 @@ package handlers
 import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/michaelwp/student_attendance/internal/config"
+	"github.com/michaelwp/student_attendance/internal/notify"
+	"github.com/michaelwp/student_attendance/internal/policy"
 	"github.com/michaelwp/student_attendance/internal/repository"
 	"github.com/redis/go-redis/v9"
 )
@@ -12,17 +14,37 @@ type HandlerDependencies struct {
 	S3Client     *s3.Client
 	S3Config     *config.S3Config
 	RedisClient  *redis.Client
+	Notifier     notify.Notifier
 }
 
 // NewHandlers creates a new instance of all handlers
 func NewHandlers(dep *HandlerDependencies) *Handlers {
+	absentRequestPolicy := policy.NewAbsentRequestPolicy(dep.Repositories.Teacher, dep.Repositories.ClassTeacher, dep.Repositories.Student)
+	scopePolicy := policy.NewScopePolicy(
+		dep.Repositories.Teacher,
+		dep.Repositories.Class,
+		dep.Repositories.Student,
+		dep.Repositories.ClassTeacher,
+		dep.Repositories.Admin,
+	)
+
 	return &Handlers{
-		Teacher:       NewTeacherHandler(dep.Repositories.Teacher, dep.S3Client, dep.S3Config, dep.Repositories.Class, dep.Repositories.AbsentRequest),
-		Class:         NewClassHandler(dep.Repositories.Class),
-		Student:       NewStudentHandler(dep.Repositories.Student, dep.S3Client, dep.S3Config, dep.Repositories.Attendance),
-		Attendance:    NewAttendanceHandler(dep.Repositories.Attendance, dep.Repositories.Student),
-		AbsentRequest: NewAbsentRequestHandler(dep.Repositories.AbsentRequest, dep.Repositories.Student),
-		Admin:         NewAdminHandler(dep.Repositories.Admin),
-		Auth:          NewAuthHandler(dep.Repositories.Admin, dep.Repositories.Teacher, dep.Repositories.Student, dep.RedisClient),
+		Teacher:         NewTeacherHandler(dep.Repositories.Teacher, dep.S3Client, dep.S3Config, dep.Repositories.Class, dep.Repositories.AbsentRequest, dep.Repositories.Attachment, dep.Repositories.Category, dep.Repositories.Quota, absentRequestPolicy, dep.Notifier),
+		Class:           NewClassHandler(dep.Repositories.Class, dep.Repositories.AcademicYear, dep.Repositories.ClassTeacher, dep.Repositories.Teacher),
+		Student:         NewStudentHandler(dep.Repositories.Student, dep.S3Client, dep.S3Config, dep.Repositories.Attendance, dep.Repositories.Enrollment, dep.Repositories.Class, scopePolicy, dep.Repositories.StudentContact, dep.Repositories.Role),
+		Attendance:      NewAttendanceHandler(dep.Repositories.Attendance, dep.Repositories.Student, dep.Repositories.Timetable, scopePolicy),
+		AbsentRequest:   NewAbsentRequestHandler(dep.Repositories.AbsentRequest, dep.Repositories.Student, dep.Repositories.Attachment, dep.Repositories.Category, dep.Repositories.Comment, dep.Repositories.Quota, dep.Repositories.Guardian, dep.Repositories.Role, absentRequestPolicy, scopePolicy, dep.S3Client, dep.S3Config),
+		Admin:           NewAdminHandler(dep.Repositories.Admin),
+		Category:        NewAbsentRequestCategoryHandler(dep.Repositories.Category, dep.Repositories.AbsentRequest),
+		Quota:           NewAbsenceQuotaHandler(dep.Repositories.Quota, dep.Repositories.Category),
+		Guardian:        NewGuardianHandler(dep.Repositories.Guardian, dep.Repositories.Student, dep.Repositories.Attendance, dep.Repositories.AbsentRequest),
+		Role:            NewRoleHandler(dep.Repositories.Role),
+		AttendanceAlert: NewAttendanceAlertHandler(dep.Repositories.AttendanceAlert),
+		OneRoster:       NewOneRosterHandler(dep.Repositories.OneRoster),
+		School:          NewSchoolHandler(dep.Repositories.School),
+		AcademicYear:    NewAcademicYearHandler(dep.Repositories.AcademicYear, dep.Repositories.Class, dep.Repositories.Enrollment),
+		Timetable:       NewTimetableHandler(dep.Repositories.Subject, dep.Repositories.Timetable, dep.Repositories.Class, dep.Repositories.Teacher),
+		Trash:           NewTrashHandler(dep.Repositories.Trash, dep.S3Client, dep.S3Config),
+		Auth:            NewAuthHandler(dep.Repositories.Admin, dep.Repositories.Teacher, dep.Repositories.Student, dep.Repositories.Guardian, dep.Repositories.School, dep.RedisClient),
 	}
 }
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/spreadsheet"
+	"github.com/michaelwp/student_attendance/pkg"
+)
+
+const (
+	// maxImportRows bounds how many accounts one import file may create
+	maxImportRows = 5000
+	// importPasswordLength is the length of the initial passwords generated for imported accounts
+	importPasswordLength = 12
+)
+
+// readImportSheet reads the spreadsheet uploaded in the file field and checks it has the required columns.
+// On failure it returns the status and body to respond with.
+func readImportSheet(c *fiber.Ctx, action string, required []string) (*spreadsheet.Sheet, int, fiber.Map) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.no_file_uploaded",
+			"error":         "No file uploaded",
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_open_file",
+			"error":         "Failed to open file",
+		}
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_read_file",
+			"error":         "Failed to read file",
+		}
+	}
+
+	sheet, err := spreadsheet.Read(fileHeader.Filename, data)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		if errors.Is(err, spreadsheet.ErrUnsupportedFormat) {
+			return nil, fiber.StatusBadRequest, fiber.Map{
+				"translate_key": "error.unsupported_import_file",
+				"error":         "File must be a .csv or .xlsx spreadsheet",
+			}
+		}
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_import_file",
+			"error":         "The file could not be read as a spreadsheet",
+		}
+	}
+
+	var missing []string
+	for _, column := range required {
+		if !sheet.Has(column) {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		log.Printf("error on %s: missing columns %v\n", action, missing)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.import_missing_columns",
+			"error":         fmt.Sprintf("The file is missing the columns: %s", strings.Join(missing, ", ")),
+		}
+	}
+
+	return sheet, fiber.StatusOK, nil
+}
+
+// importFailedError returns the status and body to respond with when checking or importing the rows fails
+func importFailedError(err error, translateKey, message string) (int, fiber.Map) {
+	if errors.Is(err, models.ErrSchoolRequired) {
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.school_required",
+			"error":         "Select a school to import into",
+		}
+	}
+
+	return fiber.StatusInternalServerError, fiber.Map{
+		"translate_key": translateKey,
+		"error":         message,
+	}
+}
+
+// isImportDryRun reports whether the import should only be checked, asked with dry_run in the query or the form
+func isImportDryRun(c *fiber.Ctx) bool {
+	value := c.Query("dry_run")
+	if value == "" {
+		value = c.FormValue("dry_run")
+	}
+
+	dryRun, _ := strconv.ParseBool(value)
+	return dryRun
+}
+
+// sendCredentialsSheet responds with the generated passwords as a CSV attachment, one account per line.
+// The passwords are not stored in plain text, so this is the only copy.
+func sendCredentialsSheet(c *fiber.Ctx, name string, header []string, records [][]string) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("%s_%s.csv", name, time.Now().Format("20060102"))
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return c.Status(fiber.StatusCreated).Send(buf.Bytes())
+}
+
+// importRowChecker collects the problems of the rows of an import file, including values
+// that must be unique but appear on more than one row
+type importRowChecker struct {
+	errors []models.ImportRowError
+	seen   map[string]map[string]int
+}
+
+func newImportRowChecker() *importRowChecker {
+	return &importRowChecker{
+		errors: []models.ImportRowError{},
+		seen:   make(map[string]map[string]int),
+	}
+}
+
+func (r *importRowChecker) add(row int, field, message string) {
+	r.errors = append(r.errors, models.ImportRowError{Row: row, Field: field, Error: message})
+}
+
+// required checks the value is set and fits the column it is stored in
+func (r *importRowChecker) required(row int, field, value string, maxLength int) {
+	if value == "" {
+		r.add(row, field, fmt.Sprintf("%s is required", field))
+		return
+	}
+	r.maxLength(row, field, value, maxLength)
+}
+
+func (r *importRowChecker) maxLength(row int, field, value string, maxLength int) {
+	if len([]rune(value)) > maxLength {
+		r.add(row, field, fmt.Sprintf("%s must be at most %d characters", field, maxLength))
+	}
+}
+
+func (r *importRowChecker) email(row int, field, value string, maxLength int) {
+	r.required(row, field, value, maxLength)
+	if value == "" {
+		return
+	}
+
+	if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+		r.add(row, field, fmt.Sprintf("%s is not a valid email address", field))
+	}
+}
+
+// unique reports a value already seen on an earlier row. Values are compared case-insensitively.
+func (r *importRowChecker) unique(row int, field, value string) {
+	if value == "" {
+		return
+	}
+
+	if r.seen[field] == nil {
+		r.seen[field] = make(map[string]int)
+	}
+
+	key := strings.ToLower(value)
+	if first, ok := r.seen[field][key]; ok {
+		r.add(row, field, fmt.Sprintf("%s is the same as on row %d", field, first))
+		return
+	}
+	r.seen[field][key] = row
+}
+
+// sortImportErrors orders the errors by row, keeping the order of the errors of a row
+func sortImportErrors(rowErrors []models.ImportRowError) {
+	sort.SliceStable(rowErrors, func(i, j int) bool {
+		return rowErrors[i].Row < rowErrors[j].Row
+	})
+}
+
+// generateHashedPasswords generates n passwords and their hashes. Hashing is what makes
+// imports and bulk resets slow, so it is spread over the available CPUs.
+func generateHashedPasswords(n int) ([]string, []string, error) {
+	passwords := make([]string, n)
+	for i := range passwords {
+		password, err := pkg.GeneratePassword(importPasswordLength)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+		passwords[i] = password
+	}
+
+	round, _ := strconv.Atoi(os.Getenv("SALT"))
+	hashes := make([]string, n)
+	hashErrors := make([]error, n)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				hashes[i], hashErrors[i] = pkg.HashPassword(passwords[i], round)
+			}
+		}()
+	}
+
+	for i := range passwords {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range hashErrors {
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+	}
+
+	return passwords, hashes, nil
+}
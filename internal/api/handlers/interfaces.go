@@ -15,8 +15,12 @@ type TeacherHandler interface {
 	ResetPassword(c *fiber.Ctx) error
 	UpdatePassword(c *fiber.Ctx) error
 	GetStats(c *fiber.Ctx) error
+	Import(c *fiber.Ctx) error
 	// Teacher dashboard methods
 	GetProfile(c *fiber.Ctx) error
+	UpdateProfile(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	UploadCurrentPhoto(c *fiber.Ctx) error
 	UpdateCurrentPassword(c *fiber.Ctx) error
 	GetAbsentRequests(c *fiber.Ctx) error
 	ApproveAbsentRequest(c *fiber.Ctx) error
@@ -31,6 +35,11 @@ type ClassHandler interface {
 	GetByTeacher(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
+	GetTeachers(c *fiber.Ctx) error
+	AssignTeacher(c *fiber.Ctx) error
+	RemoveTeacher(c *fiber.Ctx) error
+	GetTeacherRoles(c *fiber.Ctx) error
+	UpdateTeacherRole(c *fiber.Ctx) error
 }
 
 // StudentHandler defines the interface for student API operations
@@ -51,6 +60,15 @@ type StudentHandler interface {
 	GetProfile(c *fiber.Ctx) error
 	UpdateProfile(c *fiber.Ctx) error
 	UpdateCurrentPassword(c *fiber.Ctx) error
+	GetEnrollments(c *fiber.Ctx) error
+	Transfer(c *fiber.Ctx) error
+	Import(c *fiber.Ctx) error
+	Bulk(c *fiber.Ctx) error
+	GetBulkActions(c *fiber.Ctx) error
+	GetBulkActionByID(c *fiber.Ctx) error
+	GetContacts(c *fiber.Ctx) error
+	UpdateContacts(c *fiber.Ctx) error
+	UpdateMedicalNote(c *fiber.Ctx) error
 }
 
 // AttendanceHandler defines the interface for attendance API operations
@@ -69,14 +87,20 @@ type AttendanceHandler interface {
 // AbsentRequestHandler defines the interface for absent request API operations
 type AbsentRequestHandler interface {
 	Create(c *fiber.Ctx) error
+	CreateForGuardianChild(c *fiber.Ctx) error
 	GetByID(c *fiber.Ctx) error
 	GetByStudent(c *fiber.Ctx) error
 	GetByClass(c *fiber.Ctx) error
+	GetByDate(c *fiber.Ctx) error
 	GetPending(c *fiber.Ctx) error
+	GetEscalated(c *fiber.Ctx) error
 	UpdateStatus(c *fiber.Ctx) error
-	Delete(c *fiber.Ctx) error
+	AddComment(c *fiber.Ctx) error
+	Withdraw(c *fiber.Ctx) error
 	GetByCurrentStudent(c *fiber.Ctx) error
 	UpdateByCurrentStudent(c *fiber.Ctx) error
+	UploadAttachments(c *fiber.Ctx) error
+	DeleteAttachment(c *fiber.Ctx) error
 }
 
 // AdminHandler defines the interface for admin API operations
@@ -93,18 +117,139 @@ type AdminHandler interface {
 	ResetPassword(c *fiber.Ctx) error
 }
 
+// AbsentRequestCategoryHandler defines the interface for absent request category API operations
+type AbsentRequestCategoryHandler interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetActive(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetReport(c *fiber.Ctx) error
+}
+
+// AbsenceQuotaHandler defines the interface for absence quota API operations
+type AbsenceQuotaHandler interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+}
+
+// GuardianHandler defines the interface for guardian API operations
+type GuardianHandler interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	LinkStudent(c *fiber.Ctx) error
+	UnlinkStudent(c *fiber.Ctx) error
+	GetProfile(c *fiber.Ctx) error
+	UpdateCurrentPassword(c *fiber.Ctx) error
+	GetChildren(c *fiber.Ctx) error
+	GetChildAttendances(c *fiber.Ctx) error
+	GetChildAbsentRequests(c *fiber.Ctx) error
+}
+
+// RoleHandler defines the interface for role and permission API operations
+type RoleHandler interface {
+	GetPermissions(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetUserRoles(c *fiber.Ctx) error
+	AssignRole(c *fiber.Ctx) error
+	UnassignRole(c *fiber.Ctx) error
+	GetCurrentPermissions(c *fiber.Ctx) error
+}
+
+// AttendanceAlertHandler defines the interface for attendance alert API operations
+type AttendanceAlertHandler interface {
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Acknowledge(c *fiber.Ctx) error
+	Resolve(c *fiber.Ctx) error
+}
+
+// OneRosterHandler defines the interface for OneRoster export and import API operations
+type OneRosterHandler interface {
+	Export(c *fiber.Ctx) error
+	Import(c *fiber.Ctx) error
+}
+
+// SchoolHandler defines the interface for school API operations
+type SchoolHandler interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+}
+
+// AcademicYearHandler defines the interface for academic year, term and promotion API operations
+type AcademicYearHandler interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetClasses(c *fiber.Ctx) error
+	CreateTerm(c *fiber.Ctx) error
+	GetTerms(c *fiber.Ctx) error
+	UpdateTerm(c *fiber.Ctx) error
+	DeleteTerm(c *fiber.Ctx) error
+	Promote(c *fiber.Ctx) error
+}
+
+// TimetableHandler defines the interface for subject, timetable slot and teacher schedule API operations
+type TimetableHandler interface {
+	CreateSubject(c *fiber.Ctx) error
+	GetSubjects(c *fiber.Ctx) error
+	GetSubjectByID(c *fiber.Ctx) error
+	UpdateSubject(c *fiber.Ctx) error
+	DeleteSubject(c *fiber.Ctx) error
+	CreateSlot(c *fiber.Ctx) error
+	GetSlots(c *fiber.Ctx) error
+	GetSlotByID(c *fiber.Ctx) error
+	UpdateSlot(c *fiber.Ctx) error
+	DeleteSlot(c *fiber.Ctx) error
+	GetMySchedule(c *fiber.Ctx) error
+}
+
 type AuthHandler interface {
 	Login(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
+	SwitchSchool(c *fiber.Ctx) error
+}
+
+// TrashHandler defines the interface for the trash of soft-deleted records
+type TrashHandler interface {
+	GetDeleted(c *fiber.Ctx) error
+	Restore(c *fiber.Ctx) error
+	Purge(c *fiber.Ctx) error
 }
 
 // Handlers aggregates all handler interfaces
 type Handlers struct {
-	Teacher       TeacherHandler
-	Class         ClassHandler
-	Student       StudentHandler
-	Attendance    AttendanceHandler
-	AbsentRequest AbsentRequestHandler
-	Admin         AdminHandler
-	Auth          AuthHandler
+	Teacher         TeacherHandler
+	Class           ClassHandler
+	Student         StudentHandler
+	Attendance      AttendanceHandler
+	AbsentRequest   AbsentRequestHandler
+	Admin           AdminHandler
+	Category        AbsentRequestCategoryHandler
+	Quota           AbsenceQuotaHandler
+	Guardian        GuardianHandler
+	Role            RoleHandler
+	AttendanceAlert AttendanceAlertHandler
+	OneRoster       OneRosterHandler
+	School          SchoolHandler
+	AcademicYear    AcademicYearHandler
+	Timetable       TimetableHandler
+	Trash           TrashHandler
+	Auth            AuthHandler
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/oneroster"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type oneRosterHandler struct {
+	service *oneroster.Service
+}
+
+// NewOneRosterHandler creates a new OneRoster handler
+func NewOneRosterHandler(oneRosterRepo repository.OneRosterRepository) OneRosterHandler {
+	return &oneRosterHandler{
+		service: oneroster.NewService(oneRosterRepo),
+	}
+}
+
+// Export godoc
+// @Summary Export OneRoster bundle
+// @Description Export teachers, students, classes, enrollments and attendance as a OneRoster 1.2 CSV zip
+// @Tags OneRoster
+// @Produce application/zip
+// @Security BearerAuth
+// @Param start_date query string false "First attendance and enrollment date to include (YYYY-MM-DD)"
+// @Param end_date query string false "Last attendance and enrollment date to include (YYYY-MM-DD)"
+// @Success 200 {file} file "OneRoster CSV bundle"
+// @Failure 400 {object} map[string]interface{} "Invalid date format"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/oneroster/export [get]
+func (h *oneRosterHandler) Export(c *fiber.Ctx) error {
+	var opts oneroster.ExportOptions
+
+	if startDate := c.Query("start_date"); startDate != "" {
+		date, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			log.Println("error on export oneroster: invalid start date:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_start_date",
+				"error":         "Invalid start date format. Use YYYY-MM-DD",
+			})
+		}
+		opts.StartDate = &date
+	}
+
+	if endDate := c.Query("end_date"); endDate != "" {
+		date, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			log.Println("error on export oneroster: invalid end date:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_end_date",
+				"error":         "Invalid end date format. Use YYYY-MM-DD",
+			})
+		}
+		opts.EndDate = &date
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.Export(c.Context(), &buf, opts); err != nil {
+		log.Println("error on export oneroster:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_export_oneroster",
+			"error":         "Failed to export OneRoster bundle",
+		})
+	}
+
+	filename := fmt.Sprintf("oneroster_%s.zip", time.Now().Format("20060102"))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	return c.Send(buf.Bytes())
+}
+
+// Import godoc
+// @Summary Import OneRoster bundle
+// @Description Upsert teachers, classes and students from a OneRoster CSV zip by their sourcedId
+// @Tags OneRoster
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "OneRoster CSV zip"
+// @Success 200 {object} map[string]interface{} "OneRoster bundle imported successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid file"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/oneroster/import [post]
+func (h *oneRosterHandler) Import(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Println("error on import oneroster:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.no_file_uploaded",
+			"error":         "No file uploaded",
+		})
+	}
+
+	if fileHeader.Size > oneroster.MaxArchiveSize {
+		log.Println("error on import oneroster: file too large:", fileHeader.Size)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.file_too_large",
+			"error":         fmt.Sprintf("File must be at most %d MB", oneroster.MaxArchiveSize>>20),
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Println("error on import oneroster:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_open_file",
+			"error":         "Failed to open file",
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, oneroster.MaxArchiveSize+1))
+	if err != nil {
+		log.Println("error on import oneroster:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_read_file",
+			"error":         "Failed to read file",
+		})
+	}
+
+	result, err := h.service.Import(c.Context(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		log.Println("error on import oneroster:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.failed_to_import_oneroster",
+			"error":         err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.oneroster_imported",
+		"message":       "OneRoster bundle imported successfully",
+		"data":          result,
+	})
+}
@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type roleHandler struct {
+	roleRepo repository.RoleRepository
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleRepo repository.RoleRepository) RoleHandler {
+	return &roleHandler{roleRepo: roleRepo}
+}
+
+// GetPermissions godoc
+// @Summary Get permissions
+// @Description Retrieve every permission a role can allow
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Permissions retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/permissions [get]
+func (h *roleHandler) GetPermissions(c *fiber.Ctx) error {
+	permissions, err := h.roleRepo.GetPermissions(c.Context())
+	if err != nil {
+		log.Println("error on get permissions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_permissions",
+			"error":         "Failed to get permissions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.permissions_retrieved",
+		"message":       "Permissions retrieved successfully",
+		"data":          permissions,
+	})
+}
+
+// Create godoc
+// @Summary Create role
+// @Description Create a role allowing a set of permissions
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role body models.RoleInput true "Role data"
+// @Success 201 {object} map[string]interface{} "Role created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or unknown permission"
+// @Failure 409 {object} map[string]interface{} "Role name already in use"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage roles"
+// @Router /admins/roles [post]
+func (h *roleHandler) Create(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role, status, errBody := h.parseRole(c, nil)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role.CreatedBy = &adminID
+	if err := h.roleRepo.Create(c.Context(), role); err != nil {
+		log.Println("error on create role:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_role",
+			"error":         "Failed to create role",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.role_created",
+		"message":       "Role created successfully",
+		"data":          role,
+	})
+}
+
+// GetAll godoc
+// @Summary Get roles
+// @Description Retrieve every role and its permissions, default roles first
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Roles retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/roles [get]
+func (h *roleHandler) GetAll(c *fiber.Ctx) error {
+	roles, err := h.roleRepo.GetAll(c.Context())
+	if err != nil {
+		log.Println("error on get roles:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_roles",
+			"error":         "Failed to get roles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.roles_retrieved",
+		"message":       "Roles retrieved successfully",
+		"data":          roles,
+	})
+}
+
+// GetByID godoc
+// @Summary Get role by ID
+// @Description Retrieve a role and its permissions
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} map[string]interface{} "Role retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid role ID"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Router /admins/roles/role-id/{id} [get]
+func (h *roleHandler) GetByID(c *fiber.Ctx) error {
+	role, status, errBody := h.roleFromParam(c, "get role by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.role_retrieved",
+		"message":       "Role retrieved successfully",
+		"data":          role,
+	})
+}
+
+// Update godoc
+// @Summary Update role
+// @Description Update a role and replace its permissions. Default roles keep their name,
+// @Description and the default admin role must keep role.manage.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Param role body models.RoleInput true "Role data"
+// @Success 200 {object} map[string]interface{} "Role updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or unknown permission"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Failure 409 {object} map[string]interface{} "Role name already in use or default role locked"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage roles"
+// @Router /admins/roles/role-id/{id} [put]
+func (h *roleHandler) Update(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	existing, status, errBody := h.roleFromParam(c, "update role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role, status, errBody := h.parseRole(c, existing)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role.ID = existing.ID
+	role.DefaultFor = existing.DefaultFor
+	role.CreatedAt = existing.CreatedAt
+	role.CreatedBy = existing.CreatedBy
+	role.UpdatedBy = &adminID
+	if err := h.roleRepo.Update(c.Context(), role); err != nil {
+		log.Println("error on update role:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_role",
+			"error":         "Failed to update role",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.role_updated",
+		"message":       "Role updated successfully",
+		"data":          role,
+	})
+}
+
+// Delete godoc
+// @Summary Delete role
+// @Description Retire a role and remove it from every user it was assigned to. Default roles cannot be deleted.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} map[string]interface{} "Role deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid role ID"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Failure 409 {object} map[string]interface{} "Default role locked"
+// @Failure 403 {object} map[string]interface{} "Only super-admins may manage roles"
+// @Router /admins/roles/role-id/{id} [delete]
+func (h *roleHandler) Delete(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role, status, errBody := h.roleFromParam(c, "delete role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if role.DefaultFor != nil {
+		log.Println("error on delete role:", models.ErrDefaultRoleLocked)
+		return c.Status(fiber.StatusConflict).JSON(roleError(models.ErrDefaultRoleLocked))
+	}
+
+	if err := h.roleRepo.UpdateDeleteInfo(c.Context(), role.ID, adminID); err != nil {
+		log.Println("error on delete role:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.role_not_found",
+			"error":         "Role not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.role_deleted",
+		"message":       "Role deleted successfully",
+	})
+}
+
+// GetUserRoles godoc
+// @Summary Get a user's roles
+// @Description Retrieve the roles assigned to a user and every permission they hold, including through the default role of their type
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userType path string true "User type (admin, teacher, student or guardian)"
+// @Param userId path int true "User database ID"
+// @Success 200 {object} map[string]interface{} "User roles retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid user type or ID"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/role-assignments/user-type/{userType}/user-id/{userId} [get]
+func (h *roleHandler) GetUserRoles(c *fiber.Ctx) error {
+	userType, userID, status, errBody := h.parseUser(c, c.Params("userType"), c.Params("userId"), "get user roles")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	roles, err := h.roleRepo.GetUserRoles(c.Context(), userType, userID)
+	if err != nil {
+		log.Println("error on get user roles:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_user_roles",
+			"error":         "Failed to get user roles",
+		})
+	}
+
+	permissions, err := h.roleRepo.GetUserPermissions(c.Context(), userType, userID)
+	if err != nil {
+		log.Println("error on get user roles:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_user_roles",
+			"error":         "Failed to get user roles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.user_roles_retrieved",
+		"message":       "User roles retrieved successfully",
+		"data":          roles,
+		"permissions":   permissions,
+	})
+}
+
+// AssignRole godoc
+// @Summary Assign role to user
+// @Description Give a user a role on top of the default role of their type
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assignment body object true "User and role, e.g. {\"user_type\": \"teacher\", \"user_id\": 1, \"role_id\": 5}"
+// @Success 200 {object} map[string]interface{} "Role assigned successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, user type or role"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/role-assignments [post]
+func (h *roleHandler) AssignRole(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "assign role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var request struct {
+		UserType string `json:"user_type"`
+		UserID   uint   `json:"user_id"`
+		RoleID   uint   `json:"role_id"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
+		log.Println("error on assign role:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	userType, userID, status, errBody := h.parseUser(c, request.UserType, strconv.FormatUint(uint64(request.UserID), 10), "assign role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	role, err := h.roleRepo.GetByID(c.Context(), request.RoleID)
+	if err != nil {
+		log.Println("error on assign role:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.role_not_found",
+			"error":         "Role not found",
+		})
+	}
+
+	userRole := &models.UserRole{
+		UserType:  userType,
+		UserID:    userID,
+		RoleID:    role.ID,
+		RoleName:  role.Name,
+		CreatedBy: &adminID,
+	}
+
+	if err := h.roleRepo.AssignRole(c.Context(), userRole); err != nil {
+		log.Println("error on assign role:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_assign_role",
+			"error":         "Failed to assign role",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.role_assigned",
+		"message":       "Role assigned successfully",
+		"data":          userRole,
+	})
+}
+
+// UnassignRole godoc
+// @Summary Remove role from user
+// @Description Take an assigned role away from a user. The default role of their type cannot be removed.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userType path string true "User type (admin, teacher, student or guardian)"
+// @Param userId path int true "User database ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} map[string]interface{} "Role unassigned successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid user type, user ID or role ID"
+// @Failure 404 {object} map[string]interface{} "User or role assignment not found"
+// @Router /admins/role-assignments/user-type/{userType}/user-id/{userId}/role-id/{roleId} [delete]
+func (h *roleHandler) UnassignRole(c *fiber.Ctx) error {
+	userType, userID, status, errBody := h.parseUser(c, c.Params("userType"), c.Params("userId"), "unassign role")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	roleID, err := strconv.ParseUint(c.Params("roleId"), 10, 32)
+	if err != nil {
+		log.Println("error on unassign role:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_role_id",
+			"error":         "Invalid role ID",
+		})
+	}
+
+	if err := h.roleRepo.UnassignRole(c.Context(), userType, userID, uint(roleID)); err != nil {
+		log.Println("error on unassign role:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.role_assignment_not_found",
+			"error":         "Role assignment not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.role_unassigned",
+		"message":       "Role unassigned successfully",
+	})
+}
+
+// GetCurrentPermissions godoc
+// @Summary Get current user's permissions
+// @Description Retrieve every permission the logged-in user holds, so clients can hide actions they cannot take
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Permissions retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Invalid user ID"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/permissions [get]
+func (h *roleHandler) GetCurrentPermissions(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "get current permissions")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	permissions, err := h.roleRepo.GetUserPermissions(c.Context(), actor.UserType, actor.UserID)
+	if err != nil {
+		log.Println("error on get current permissions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_permissions",
+			"error":         "Failed to get permissions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.permissions_retrieved",
+		"message":       "Permissions retrieved successfully",
+		"data":          permissions,
+	})
+}
+
+// roleFromParam loads the role named by the id path parameter.
+// On failure it returns the status and body to respond with.
+func (h *roleHandler) roleFromParam(c *fiber.Ctx, action string) (*models.Role, int, fiber.Map) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_role_id",
+			"error":         "Invalid role ID",
+		}
+	}
+
+	role, err := h.roleRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.role_not_found",
+			"error":         "Role not found",
+		}
+	}
+
+	return role, fiber.StatusOK, nil
+}
+
+// parseRole reads the role from the request body and checks it can be saved over the existing role, if any.
+// On failure it returns the status and body to respond with.
+func (h *roleHandler) parseRole(c *fiber.Ctx, existing *models.Role) (*models.Role, int, fiber.Map) {
+	var input models.RoleInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on parse role:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	role := &models.Role{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		Permissions: input.Permissions,
+	}
+
+	if role.Name == "" {
+		log.Println("error on parse role:", models.ErrRoleNameRequired)
+		return nil, fiber.StatusBadRequest, roleError(models.ErrRoleNameRequired)
+	}
+
+	var roleID uint
+	if existing != nil {
+		roleID = existing.ID
+		if existing.DefaultFor != nil && role.Name != existing.Name {
+			log.Println("error on parse role:", models.ErrDefaultRoleLocked)
+			return nil, fiber.StatusConflict, roleError(models.ErrDefaultRoleLocked)
+		}
+
+		if existing.DefaultFor != nil && *existing.DefaultFor == models.UserTypeAdmin.String() &&
+			!slices.Contains(role.Permissions, models.PermissionRoleManage) {
+			log.Println("error on parse role:", models.ErrRoleManageLockout)
+			return nil, fiber.StatusConflict, roleError(models.ErrRoleManageLockout)
+		}
+	}
+
+	permissions, err := h.roleRepo.GetPermissions(c.Context())
+	if err != nil {
+		log.Println("error on parse role:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_get_permissions",
+			"error":         "Failed to get permissions",
+		}
+	}
+
+	for _, code := range role.Permissions {
+		if !slices.ContainsFunc(permissions, func(p *models.Permission) bool { return p.Code == code }) {
+			log.Printf("error on parse role: unknown permission %q\n", code)
+			return nil, fiber.StatusBadRequest, roleError(models.ErrUnknownPermission)
+		}
+	}
+
+	exists, err := h.roleRepo.IsNameExist(c.Context(), role.Name, roleID)
+	if err != nil {
+		log.Println("error on parse role:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_role_name",
+			"error":         "Failed to check role name",
+		}
+	}
+
+	if exists {
+		log.Println("error on parse role: name already in use")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.role_name_exists",
+			"error":         "Another role already uses this name",
+		}
+	}
+
+	return role, fiber.StatusOK, nil
+}
+
+// parseUser checks the user type and ID name an existing user.
+// On failure it returns the status and body to respond with.
+func (h *roleHandler) parseUser(c *fiber.Ctx, userTypeParam, userIDParam, action string) (string, uint, int, fiber.Map) {
+	userType, err := models.ParseUserType(userTypeParam)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return "", 0, fiber.StatusBadRequest, roleError(err)
+	}
+
+	userID, err := strconv.ParseUint(userIDParam, 10, 32)
+	if err != nil || userID == 0 {
+		log.Printf("error on %s: invalid user id: %v\n", action, err)
+		return "", 0, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_user_id_format",
+			"error":         "Invalid user ID format",
+		}
+	}
+
+	exists, err := h.roleRepo.IsUserExist(c.Context(), userType, uint(userID))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return "", 0, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_get_user",
+			"error":         "Failed to get user",
+		}
+	}
+
+	if !exists {
+		log.Printf("error on %s: %s %d not found\n", action, userType, userID)
+		return "", 0, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.user_not_found",
+			"error":         "User not found",
+		}
+	}
+
+	return userType.String(), uint(userID), fiber.StatusOK, nil
+}
+
+// roleError maps a role validation error to the body to respond with
+func roleError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, models.ErrRoleNameRequired):
+		return fiber.Map{
+			"translate_key": "error.role_name_required",
+			"error":         "Role name is required",
+		}
+	case errors.Is(err, models.ErrUnknownPermission):
+		return fiber.Map{
+			"translate_key": "error.unknown_permission",
+			"error":         "Unknown permission",
+		}
+	case errors.Is(err, models.ErrDefaultRoleLocked):
+		return fiber.Map{
+			"translate_key": "error.default_role_locked",
+			"error":         "Default roles cannot be renamed or deleted",
+		}
+	case errors.Is(err, models.ErrRoleManageLockout):
+		return fiber.Map{
+			"translate_key": "error.role_manage_lockout",
+			"error":         "The default admin role must keep the role.manage permission",
+		}
+	case errors.Is(err, models.ErrInvalidUserType):
+		return fiber.Map{
+			"translate_key": "error.invalid_user_type",
+			"error":         "User type must be admin, teacher, student, or guardian",
+		}
+	default:
+		return fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+}
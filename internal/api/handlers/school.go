@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type schoolHandler struct {
+	schoolRepo repository.SchoolRepository
+}
+
+// NewSchoolHandler creates a new school handler
+func NewSchoolHandler(schoolRepo repository.SchoolRepository) SchoolHandler {
+	return &schoolHandler{
+		schoolRepo: schoolRepo,
+	}
+}
+
+// Create godoc
+// @Summary Create school
+// @Description Add a school to the deployment. Only super-admins may manage schools.
+// @Tags Schools
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param school body models.School true "School data"
+// @Success 201 {object} map[string]interface{} "School created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 403 {object} map[string]interface{} "Super-admin required"
+// @Failure 409 {object} map[string]interface{} "School code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/schools [post]
+func (h *schoolHandler) Create(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create school")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	school := models.School{IsActive: true}
+	if err := c.BodyParser(&school); err != nil {
+		log.Println("error on create school:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	school.ID = 0
+	school.CreatedBy = &adminID
+	if status, errBody := h.validateSchool(c.Context(), &school); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.schoolRepo.Create(c.Context(), &school); err != nil {
+		log.Println("error on create school:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_school",
+			"error":         "Failed to create school",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.school_created",
+		"message":       "School created successfully",
+		"data":          school,
+	})
+}
+
+// GetAll godoc
+// @Summary Get schools
+// @Description Retrieve every school of the deployment, including inactive ones
+// @Tags Schools
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Schools retrieved successfully"
+// @Failure 403 {object} map[string]interface{} "Super-admin required"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/schools [get]
+func (h *schoolHandler) GetAll(c *fiber.Ctx) error {
+	schools, err := h.schoolRepo.GetAll(c.Context())
+	if err != nil {
+		log.Println("error on get schools:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_schools",
+			"error":         "Failed to get schools",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.schools_retrieved",
+		"message":       "Schools retrieved successfully",
+		"data":          schools,
+	})
+}
+
+// GetByID godoc
+// @Summary Get school by ID
+// @Description Retrieve a specific school
+// @Tags Schools
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "School ID"
+// @Success 200 {object} map[string]interface{} "School retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid school ID"
+// @Failure 403 {object} map[string]interface{} "Super-admin required"
+// @Failure 404 {object} map[string]interface{} "School not found"
+// @Router /admins/schools/school-id/{id} [get]
+func (h *schoolHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on get school by id:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_school_id",
+			"error":         "Invalid school ID",
+		})
+	}
+
+	school, err := h.schoolRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on get school by id:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.school_not_found",
+			"error":         "School not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.school_retrieved",
+		"message":       "School retrieved successfully",
+		"data":          school,
+	})
+}
+
+// Update godoc
+// @Summary Update school
+// @Description Update a school. Fields left out of the body keep their value; an inactive school can no longer be switched to.
+// @Tags Schools
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "School ID"
+// @Param school body models.School true "School data"
+// @Success 200 {object} map[string]interface{} "School updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 403 {object} map[string]interface{} "Super-admin required"
+// @Failure 404 {object} map[string]interface{} "School not found"
+// @Failure 409 {object} map[string]interface{} "School code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/schools/school-id/{id} [put]
+func (h *schoolHandler) Update(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update school")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on update school:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_school_id",
+			"error":         "Invalid school ID",
+		})
+	}
+
+	school, err := h.schoolRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on update school: school not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.school_not_found",
+			"error":         "School not found",
+		})
+	}
+
+	if err := c.BodyParser(school); err != nil {
+		log.Println("error on update school:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	school.ID = uint(id)
+	school.UpdatedBy = &adminID
+	if status, errBody := h.validateSchool(c.Context(), school); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.schoolRepo.Update(c.Context(), school); err != nil {
+		log.Println("error on update school:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_school",
+			"error":         "Failed to update school",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.school_updated",
+		"message":       "School updated successfully",
+		"data":          school,
+	})
+}
+
+// validateSchool normalises the school code and checks it is not taken.
+// On failure it returns the status and body to respond with.
+func (h *schoolHandler) validateSchool(ctx context.Context, school *models.School) (int, fiber.Map) {
+	school.Code = strings.ToLower(strings.TrimSpace(school.Code))
+	school.Name = strings.TrimSpace(school.Name)
+
+	if school.Code == "" || school.Name == "" {
+		log.Println("error on validate school: code and name are required")
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.school_code_and_name_required",
+			"error":         "Code and name are required",
+		}
+	}
+
+	exist, err := h.schoolRepo.IsCodeExist(ctx, school.Code, school.ID)
+	if err != nil {
+		log.Println("error on validate school:", err)
+		return fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_school_code",
+			"error":         "Failed to check school code",
+		}
+	}
+
+	if exist {
+		log.Println("error on validate school: code already exists")
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.school_code_already_exists",
+			"error":         "School code already exists",
+		}
+	}
+
+	return fiber.StatusOK, nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// currentScope returns the records the caller may see
+func currentScope(c *fiber.Ctx, scopePolicy *policy.ScopePolicy, action string) (*models.RecordScope, int, fiber.Map) {
+	actor, status, errBody := currentActor(c, action)
+	if errBody != nil {
+		return nil, status, errBody
+	}
+
+	scope, err := scopePolicy.ScopeFor(c.Context(), actor)
+	if err != nil {
+		log.Printf("error on %s: failed to get record scope: %v\n", action, err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_get_record_scope",
+			"error":         "Failed to get record scope",
+		}
+	}
+
+	return scope, fiber.StatusOK, nil
+}
+
+// studentInScope reports whether the student with the student ID exists and is in the scope
+func studentInScope(ctx context.Context, studentRepo repository.StudentRepository, scope *models.RecordScope, studentID string) bool {
+	student, err := studentRepo.GetByStudentID(ctx, studentID)
+	if err != nil {
+		log.Println("error on get student by student id:", err)
+		return false
+	}
+
+	return scope.AllowsStudent(student.StudentID, student.ClassesID)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/policy"
 	"github.com/michaelwp/student_attendance/internal/repository"
 )
 
@@ -24,6 +26,11 @@ type studentHandler struct {
 	s3Config       *config.S3Config
 	s3Client       *s3.Client
 	attendanceRepo repository.AttendanceRepository
+	enrollmentRepo repository.EnrollmentRepository
+	classRepo      repository.ClassRepository
+	scopePolicy    *policy.ScopePolicy
+	contactRepo    repository.StudentContactRepository
+	roleRepo       repository.RoleRepository
 }
 
 // NewStudentHandler creates a new student handler
@@ -32,12 +39,22 @@ func NewStudentHandler(
 	s3Client *s3.Client,
 	s3Config *config.S3Config,
 	attendanceRepo repository.AttendanceRepository,
+	enrollmentRepo repository.EnrollmentRepository,
+	classRepo repository.ClassRepository,
+	scopePolicy *policy.ScopePolicy,
+	contactRepo repository.StudentContactRepository,
+	roleRepo repository.RoleRepository,
 ) StudentHandler {
 	return &studentHandler{
 		studentRepo:    studentRepo,
 		s3Client:       s3Client,
 		s3Config:       s3Config,
 		attendanceRepo: attendanceRepo,
+		enrollmentRepo: enrollmentRepo,
+		classRepo:      classRepo,
+		scopePolicy:    scopePolicy,
+		contactRepo:    contactRepo,
+		roleRepo:       roleRepo,
 	}
 }
 
@@ -104,7 +121,7 @@ func (h *studentHandler) Create(c *fiber.Ctx) error {
 
 // GetStudentByID godoc
 // @Summary Get student by ID
-// @Description Retrieve a specific student by their database ID
+// @Description Retrieve a specific student by their database ID. Admins and the homeroom teacher of the class also get the emergency contacts and medical note.
 // @Tags Students
 // @Accept json
 // @Produce json
@@ -112,6 +129,7 @@ func (h *studentHandler) Create(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{} "Student retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid student ID"
 // @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /students/{id} [get]
 func (h *studentHandler) GetByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
@@ -124,7 +142,16 @@ func (h *studentHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	student, err := h.studentRepo.GetByID(c.Context(), uint(id))
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get student by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	student, err := h.studentRepo.GetByIDWithClassName(c.Context(), uint(id))
+	if err == nil && !scope.AllowsStudent(student.StudentID, student.ClassesID) {
+		err = policy.ErrOutOfScope
+	}
+
 	if err != nil {
 		log.Println("error on get student by id:", err)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -133,6 +160,22 @@ func (h *studentHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
+	visible, status, errBody := h.canSeeEmergencyInfo(c, student.ClassesID, "get student by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if visible {
+		student.Emergency, err = h.contactRepo.GetByStudent(c.Context(), student.ID)
+		if err != nil {
+			log.Println("error on get student by id:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_get_student_contacts",
+				"error":         "Failed to get student contacts",
+			})
+		}
+	}
+
 	student.Password = ""
 	return c.JSON(fiber.Map{
 		"translate_key": "success.student.retrieved.successfully",
@@ -162,7 +205,16 @@ func (h *studentHandler) GetByStudentID(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get student by student id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	student, err := h.studentRepo.GetByStudentID(c.Context(), studentID)
+	if err == nil && !scope.AllowsStudent(student.StudentID, student.ClassesID) {
+		err = policy.ErrOutOfScope
+	}
+
 	if err != nil {
 		log.Println("error on get student by student id:", err)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -181,7 +233,8 @@ func (h *studentHandler) GetByStudentID(c *fiber.Ctx) error {
 
 // GetAllStudents godoc
 // @Summary Get all students
-// @Description Retrieve a paginated list of all students
+// @Description Retrieve a paginated list of the students the caller may see: every student for admins,
+// @Description the students of their classes for teachers and only themselves for students
 // @Tags Students
 // @Accept json
 // @Produce json
@@ -198,7 +251,12 @@ func (h *studentHandler) GetAll(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	students, err := h.studentRepo.GetAll(c.Context(), limit, offset)
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get all students")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	students, err := h.studentRepo.GetAll(c.Context(), scope, limit, offset)
 	if err != nil {
 		log.Println("error on get all students:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -211,7 +269,7 @@ func (h *studentHandler) GetAll(c *fiber.Ctx) error {
 		student.Password = ""
 	}
 
-	totalStudents, err := h.studentRepo.GetTotalStudents(c.Context())
+	totalStudents, err := h.studentRepo.GetTotalStudents(c.Context(), scope)
 	if err != nil {
 		log.Println("error on get total students:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -232,13 +290,16 @@ func (h *studentHandler) GetAll(c *fiber.Ctx) error {
 
 // GetStudentsByClass godoc
 // @Summary Get students by class
-// @Description Retrieve all students in a specific class
+// @Description Retrieve all students in a specific class. With a date, retrieve the students who were in the class
+// @Description on that day according to their enrollments, including those who have since moved to another class.
 // @Tags Students
 // @Accept json
 // @Produce json
 // @Param classId path int true "Class ID"
+// @Param date query string false "Roster date (YYYY-MM-DD)"
 // @Success 200 {object} map[string]interface{} "Students retrieved successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid class ID"
+// @Failure 400 {object} map[string]interface{} "Invalid class ID or date"
+// @Failure 404 {object} map[string]interface{} "Class not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /students/class-id/{classId} [get]
 func (h *studentHandler) GetByClass(c *fiber.Ctx) error {
@@ -252,7 +313,34 @@ func (h *studentHandler) GetByClass(c *fiber.Ctx) error {
 		})
 	}
 
-	students, err := h.studentRepo.GetByClass(c.Context(), uint(classID))
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get students by class id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if !scope.AllowsClass(uint(classID)) {
+		log.Println("error on get students by class id:", policy.ErrOutOfScope)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.class.not.found",
+			"error":         "Class not found",
+		})
+	}
+
+	var students []*models.Student
+	if dateParam := c.Query("date"); dateParam != "" {
+		date, parseErr := time.Parse("2006-01-02", dateParam)
+		if parseErr != nil {
+			log.Println("error on get students by class id:", parseErr)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_date_format",
+				"error":         "Invalid date format. Use YYYY-MM-DD format.",
+			})
+		}
+		students, err = h.studentRepo.GetByClassOn(c.Context(), uint(classID), date)
+	} else {
+		students, err = h.studentRepo.GetByClass(c.Context(), uint(classID))
+	}
+
 	if err != nil {
 		log.Println("error on get students by class id:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -283,6 +371,7 @@ func (h *studentHandler) GetByClass(c *fiber.Ctx) error {
 // @Param student body models.Student true "Student data"
 // @Success 200 {object} map[string]interface{} "Student updated successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /students/{id} [put]
 func (h *studentHandler) Update(c *fiber.Ctx) error {
@@ -305,6 +394,10 @@ func (h *studentHandler) Update(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, status, errBody := h.scopedStudent(c, uint(id), "update student"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	student.ID = uint(id)
 	if err := h.studentRepo.Update(c.Context(), &student); err != nil {
 		log.Println("error on update student:", err)
@@ -329,6 +422,7 @@ func (h *studentHandler) Update(c *fiber.Ctx) error {
 // @Param id path int true "Student database ID"
 // @Success 200 {object} map[string]interface{} "Student deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid student ID"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /students/{id} [delete]
 func (h *studentHandler) Delete(c *fiber.Ctx) error {
@@ -360,6 +454,10 @@ func (h *studentHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, status, errBody := h.scopedStudent(c, uint(id), "delete student"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	if err := h.studentRepo.UpdateDeleteInfo(c.Context(), uint(id), uint(currentUserIDUint)); err != nil {
 		log.Println("error on update delete info student:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -384,6 +482,7 @@ func (h *studentHandler) Delete(c *fiber.Ctx) error {
 // @Param photo formData file true "Student photo"
 // @Success 200 {object} map[string]interface{} "Photo uploaded successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /students/{id}/photo [put]
 func (h *studentHandler) UploadPhoto(c *fiber.Ctx) error {
@@ -397,6 +496,10 @@ func (h *studentHandler) UploadPhoto(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, status, errBody := h.scopedStudent(c, uint(id), "upload photo"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	file, err := c.FormFile("photo")
 	if err != nil {
 		log.Println("error on upload photo:", err)
@@ -475,6 +578,10 @@ func (h *studentHandler) GetPhoto(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, status, errBody := h.scopedStudent(c, uint(id), "get photo"); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	photoPath, err := h.studentRepo.GetPhotoPath(c.Context(), uint(id))
 	if err != nil {
 		log.Println("error on get photo path:", err)
@@ -511,6 +618,7 @@ func (h *studentHandler) GetPhoto(c *fiber.Ctx) error {
 // @Param password body string true "New password"
 // @Success 200 {object} map[string]interface{} "Password reset successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /students/student-id/{studentId}/reset-password [put]
 func (h *studentHandler) ResetPassword(c *fiber.Ctx) error {
@@ -524,6 +632,11 @@ func (h *studentHandler) ResetPassword(c *fiber.Ctx) error {
 	}
 
 	// Check if a student exists
+	scope, status, errBody := currentScope(c, h.scopePolicy, "reset password")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	exist, err := h.studentRepo.IsStudentExist(c.Context(), studentID)
 	if err != nil {
 		log.Println("error on reset password: failed to check student existence:", err)
@@ -533,7 +646,7 @@ func (h *studentHandler) ResetPassword(c *fiber.Ctx) error {
 		})
 	}
 
-	if !exist {
+	if !exist || !studentInScope(c.Context(), h.studentRepo, scope, studentID) {
 		log.Println("error on reset password: student not found")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"translate_key": "error.student.not.found",
@@ -609,6 +722,11 @@ func (h *studentHandler) UpdatePassword(c *fiber.Ctx) error {
 		})
 	}
 
+	scope, status, errBody := currentScope(c, h.scopePolicy, "update password")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
 	exist, err := h.studentRepo.IsStudentExist(c.Context(), studentID)
 	if err != nil {
 		log.Println("error on update password: failed to check student existence:", err)
@@ -618,7 +736,7 @@ func (h *studentHandler) UpdatePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	if !exist {
+	if !exist || !studentInScope(c.Context(), h.studentRepo, scope, studentID) {
 		log.Println("error on update password: student not found")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"translate_key": "error.student.not.found",
@@ -985,3 +1103,186 @@ func (h *studentHandler) UpdateCurrentPassword(c *fiber.Ctx) error {
 		"message":       "Password updated successfully",
 	})
 }
+
+// scopedStudent loads the student with the record ID, answering not found
+// when the student is outside the caller's record scope
+func (h *studentHandler) scopedStudent(c *fiber.Ctx, id uint, action string) (*models.Student, int, fiber.Map) {
+	scope, status, errBody := currentScope(c, h.scopePolicy, action)
+	if errBody != nil {
+		return nil, status, errBody
+	}
+
+	student, err := h.studentRepo.GetByID(c.Context(), id)
+	if err == nil && !scope.AllowsStudent(student.StudentID, student.ClassesID) {
+		err = policy.ErrOutOfScope
+	}
+
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.student.not.found",
+			"error":         "Student not found",
+		}
+	}
+
+	return student, fiber.StatusOK, nil
+}
+
+// GetEnrollments godoc
+// @Summary Get student enrollments
+// @Description Retrieve the classes a student has been in and when, the most recent first
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student ID"
+// @Success 200 {object} map[string]interface{} "Enrollments retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/record-id/{id}/enrollments [get]
+func (h *studentHandler) GetEnrollments(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on get student enrollments:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid.student.id",
+			"error":         "Invalid student ID",
+		})
+	}
+
+	scope, status, errBody := currentScope(c, h.scopePolicy, "get student enrollments")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	student, err := h.studentRepo.GetByID(c.Context(), uint(id))
+	if err == nil && !scope.AllowsStudent(student.StudentID, student.ClassesID) {
+		err = policy.ErrOutOfScope
+	}
+
+	if err != nil {
+		log.Println("error on get student enrollments:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student.not.found",
+			"error":         "Student not found",
+		})
+	}
+
+	enrollments, err := h.enrollmentRepo.GetByStudent(c.Context(), student.ID)
+	if err != nil {
+		log.Println("error on get student enrollments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_enrollments",
+			"error":         "Failed to get enrollments",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.enrollments_retrieved",
+		"message":       "Enrollments retrieved successfully",
+		"data":          enrollments,
+	})
+}
+
+// Transfer godoc
+// @Summary Transfer student to another class
+// @Description Close the student's current enrollment and enroll them in another class from the effective date,
+// @Description which defaults to today and may not be in the future. Attendance already recorded keeps its class.
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student ID"
+// @Param transfer body models.TransferInput true "Transfer data"
+// @Success 200 {object} map[string]interface{} "Student transferred successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, class or effective date"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 409 {object} map[string]interface{} "Student is already in the class"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/record-id/{id}/transfer [post]
+func (h *studentHandler) Transfer(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "transfer student")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on transfer student:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid.student.id",
+			"error":         "Invalid student ID",
+		})
+	}
+
+	student, err := h.studentRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on transfer student:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student.not.found",
+			"error":         "Student not found",
+		})
+	}
+
+	var input models.TransferInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on transfer student:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	transfer, err := input.ToTransfer(student.ID, adminID, today)
+	if err != nil {
+		log.Println("error on transfer student:", err)
+		if errors.Is(err, models.ErrTransferFutureDate) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.transfer_future_date",
+				"error":         "The effective date may not be in the future",
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_date_format",
+			"error":         "Invalid date format. Use YYYY-MM-DD format.",
+		})
+	}
+
+	if _, err := h.classRepo.GetByID(c.Context(), transfer.ClassID); err != nil {
+		log.Println("error on transfer student:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.class.not.found",
+			"error":         "Class not found",
+		})
+	}
+
+	enrollment, err := h.enrollmentRepo.Transfer(c.Context(), transfer)
+	if err != nil {
+		log.Println("error on transfer student:", err)
+		switch {
+		case errors.Is(err, models.ErrTransferSameClass):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"translate_key": "error.transfer_same_class",
+				"error":         "The student is already in this class",
+			})
+		case errors.Is(err, models.ErrTransferBeforeEnrollment):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.transfer_before_enrollment",
+				"error":         "The effective date is before the student joined their current class",
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_transfer_student",
+				"error":         "Failed to transfer student",
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_transferred",
+		"message":       "Student transferred successfully",
+		"data":          enrollment,
+	})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// Bulk godoc
+// @Summary Apply an action to many students
+// @Description Deactivate, activate, move to another class, delete or reset the passwords of the students listed in student_ids,
+// @Description or of every student of class_id. Every student gets their own result: changed, unchanged when already in the
+// @Description requested state, or failed. The action runs in one transaction: if any student fails nothing changes.
+// @Description A class move needs to_class_id and takes effect on effective_date, which defaults to today.
+// @Description Applied actions are recorded in the audit trail with the state of each student before.
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bulk body models.StudentBulkInput true "Action and students"
+// @Success 200 {object} map[string]interface{} "Student bulk action applied successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid action, students, class or effective date"
+// @Failure 404 {object} map[string]interface{} "No students matched"
+// @Failure 422 {object} map[string]interface{} "Some students failed, nothing changed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/bulk [post]
+func (h *studentHandler) Bulk(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "apply student bulk action")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var input models.StudentBulkInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Println("error on apply student bulk action:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	action, err := input.ToStudentBulkAction(adminID, today)
+	if err != nil {
+		log.Println("error on apply student bulk action:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(studentBulkInputError(err))
+	}
+
+	for _, classID := range []*uint{action.ClassID, action.ToClassID} {
+		if classID == nil {
+			continue
+		}
+
+		if _, err := h.classRepo.GetByID(c.Context(), *classID); err != nil {
+			log.Println("error on apply student bulk action:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.class.not.found",
+				"error":         "Class not found",
+			})
+		}
+	}
+
+	if err := h.studentRepo.ApplyBulkAction(c.Context(), action, generateHashedPasswords); err != nil {
+		log.Println("error on apply student bulk action:", err)
+		switch {
+		case errors.Is(err, models.ErrStudentBulkFailed):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"translate_key": "error.student_bulk_action_failed",
+				"error":         "Some students could not be changed. Nothing was changed.",
+				"data":          action,
+			})
+		case errors.Is(err, models.ErrStudentBulkNoStudents):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"translate_key": "error.student_bulk_no_students",
+				"error":         "No students matched",
+			})
+		case errors.Is(err, models.ErrSchoolRequired):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.school_required",
+				"error":         "Select a school to change its students",
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_apply_student_bulk_action",
+				"error":         "Failed to apply student bulk action",
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_bulk_action_applied",
+		"message":       "Student bulk action applied successfully",
+		"data":          action,
+	})
+}
+
+// GetBulkActions godoc
+// @Summary Get student bulk actions
+// @Description Retrieve the audit trail of bulk actions applied to the students of the current school, the most recent first
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of records to return" default(10)
+// @Param offset query int false "Number of records to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Student bulk actions retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/bulk [get]
+func (h *studentHandler) GetBulkActions(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	actions, err := h.studentRepo.GetBulkActions(c.Context(), limit, offset)
+	if err != nil {
+		log.Println("error on get student bulk actions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_student_bulk_actions",
+			"error":         "Failed to get student bulk actions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_bulk_actions_retrieved",
+		"message":       "Student bulk actions retrieved successfully",
+		"data":          actions,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// GetBulkActionByID godoc
+// @Summary Get student bulk action
+// @Description Retrieve a bulk action from the audit trail with each student it reached and their state before
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bulk action ID"
+// @Success 200 {object} map[string]interface{} "Student bulk action retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid bulk action ID"
+// @Failure 404 {object} map[string]interface{} "Bulk action not found"
+// @Router /students/bulk/bulk-id/{id} [get]
+func (h *studentHandler) GetBulkActionByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on get student bulk action:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_student_bulk_action_id",
+			"error":         "Invalid bulk action ID",
+		})
+	}
+
+	action, err := h.studentRepo.GetBulkActionByID(c.Context(), uint(id))
+	if err != nil {
+		log.Println("error on get student bulk action:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.student_bulk_action_not_found",
+			"error":         "Bulk action not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_bulk_action_retrieved",
+		"message":       "Student bulk action retrieved successfully",
+		"data":          action,
+	})
+}
+
+func studentBulkInputError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, models.ErrInvalidStudentBulkAction):
+		return fiber.Map{
+			"translate_key": "error.invalid_student_bulk_action",
+			"error":         "Action must be deactivate, activate, move_class, delete or reset_password",
+		}
+	case errors.Is(err, models.ErrStudentBulkTargetRequired):
+		return fiber.Map{
+			"translate_key": "error.student_bulk_target_required",
+			"error":         "Give either student_ids or class_id",
+		}
+	case errors.Is(err, models.ErrStudentBulkTooManyIDs):
+		return fiber.Map{
+			"translate_key": "error.student_bulk_too_many_ids",
+			"error":         "At most 1000 students can be listed at once",
+		}
+	case errors.Is(err, models.ErrStudentBulkToClassRequired):
+		return fiber.Map{
+			"translate_key": "error.student_bulk_to_class_required",
+			"error":         "to_class_id is required to move students",
+		}
+	case errors.Is(err, models.ErrTransferFutureDate):
+		return fiber.Map{
+			"translate_key": "error.transfer_future_date",
+			"error":         "The effective date may not be in the future",
+		}
+	default:
+		return fiber.Map{
+			"translate_key": "error.invalid_date_format",
+			"error":         "Invalid date format. Use YYYY-MM-DD format.",
+		}
+	}
+}
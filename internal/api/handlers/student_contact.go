@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// GetContacts godoc
+// @Summary Get student emergency contacts
+// @Description Retrieve who to call about a student, the lowest priority first, and their confidential medical note.
+// @Description Only admins, not auditors, and the homeroom teacher of the student's class may see them.
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student database ID"
+// @Success 200 {object} map[string]interface{} "Student contacts retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID"
+// @Failure 403 {object} map[string]interface{} "Only admins and the homeroom teacher of the class may see the contacts"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/record-id/{id}/contacts [get]
+func (h *studentHandler) GetContacts(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on get student contacts:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid.student.id",
+			"error":         "Invalid student ID",
+		})
+	}
+
+	student, status, errBody := h.scopedStudent(c, uint(id), "get student contacts")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	visible, status, errBody := h.canSeeEmergencyInfo(c, student.ClassesID, "get student contacts")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if !visible {
+		log.Println("error on get student contacts: not an admin or homeroom teacher of the class")
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"translate_key": "error.student_contacts_forbidden",
+			"error":         "Only admins and the homeroom teacher of the class may see the student's contacts",
+		})
+	}
+
+	info, err := h.contactRepo.GetByStudent(c.Context(), student.ID)
+	if err != nil {
+		log.Println("error on get student contacts:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_student_contacts",
+			"error":         "Failed to get student contacts",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_contacts.retrieved.successfully",
+		"message":       "Student contacts retrieved successfully",
+		"data":          info,
+	})
+}
+
+// UpdateContacts godoc
+// @Summary Replace student emergency contacts
+// @Description Replace every emergency contact of a student with the given list. A contact without a priority
+// @Description gets its position in the list. An empty list removes all contacts.
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student database ID"
+// @Param contacts body object true "Contacts with name, relationship, phone, alt_phone and priority"
+// @Success 200 {object} map[string]interface{} "Student contacts updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID or contacts"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/record-id/{id}/contacts [put]
+func (h *studentHandler) UpdateContacts(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update student contacts")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on update student contacts:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid.student.id",
+			"error":         "Invalid student ID",
+		})
+	}
+
+	var body struct {
+		Contacts []*models.StudentContact `json:"contacts"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		log.Println("error on update student contacts:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	for _, contact := range body.Contacts {
+		if contact == nil {
+			log.Println("error on update student contacts: empty contact")
+			return c.Status(fiber.StatusBadRequest).JSON(studentContactError(models.ErrStudentContactName))
+		}
+	}
+
+	if err := models.NormalizeStudentContacts(body.Contacts); err != nil {
+		log.Println("error on update student contacts:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(studentContactError(err))
+	}
+
+	student, status, errBody := h.scopedStudent(c, uint(id), "update student contacts")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.contactRepo.ReplaceContacts(c.Context(), student.ID, body.Contacts, adminID); err != nil {
+		log.Println("error on update student contacts:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_student_contacts",
+			"error":         "Failed to update student contacts",
+		})
+	}
+
+	if body.Contacts == nil {
+		body.Contacts = []*models.StudentContact{}
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_contacts.updated.successfully",
+		"message":       "Student contacts updated successfully",
+		"data":          body.Contacts,
+	})
+}
+
+// UpdateMedicalNote godoc
+// @Summary Set student medical note
+// @Description Set the confidential medical note of a student, e.g. allergies staff must know about. An empty note removes it.
+// @Tags Students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student database ID"
+// @Param note body object true "Medical note with note field"
+// @Success 200 {object} map[string]interface{} "Student medical note updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID or note"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/record-id/{id}/medical-note [put]
+func (h *studentHandler) UpdateMedicalNote(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update student medical note")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on update student medical note:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid.student.id",
+			"error":         "Invalid student ID",
+		})
+	}
+
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		log.Println("error on update student medical note:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	body.Note = strings.TrimSpace(body.Note)
+	if utf8.RuneCountInString(body.Note) > models.MaxStudentMedicalNoteLength {
+		log.Println("error on update student medical note:", models.ErrStudentMedicalNoteTooLong)
+		return c.Status(fiber.StatusBadRequest).JSON(studentContactError(models.ErrStudentMedicalNoteTooLong))
+	}
+
+	student, status, errBody := h.scopedStudent(c, uint(id), "update student medical note")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.contactRepo.SetMedicalNote(c.Context(), student.ID, body.Note, adminID); err != nil {
+		log.Println("error on update student medical note:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_student_medical_note",
+			"error":         "Failed to update student medical note",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.student_medical_note.updated.successfully",
+		"message":       "Student medical note updated successfully",
+	})
+}
+
+// canSeeEmergencyInfo reports whether the current user may see the contacts and medical note of a student in the class.
+// They need the student_contact.read permission, as on the contacts route, and the policy must allow it.
+func (h *studentHandler) canSeeEmergencyInfo(c *fiber.Ctx, classID uint, action string) (bool, int, fiber.Map) {
+	actor, status, errBody := currentActor(c, action)
+	if errBody != nil {
+		return false, status, errBody
+	}
+
+	allowed, err := h.roleRepo.HasPermission(c.Context(), actor.UserType, actor.UserID, models.PermissionStudentContactRead)
+	if err != nil {
+		log.Printf("error on %s: failed to check permission: %v\n", action, err)
+		return false, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_permission",
+			"error":         "Failed to check permission",
+		}
+	}
+
+	if !allowed {
+		return false, fiber.StatusOK, nil
+	}
+
+	visible, err := h.scopePolicy.CanSeeEmergencyInfo(c.Context(), actor, classID)
+	if err != nil {
+		log.Printf("error on %s: failed to check emergency info access: %v\n", action, err)
+		return false, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_get_student_contacts",
+			"error":         "Failed to get student contacts",
+		}
+	}
+
+	return visible, fiber.StatusOK, nil
+}
+
+// studentContactError maps an invalid contact or medical note to the body to respond with
+func studentContactError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, models.ErrTooManyStudentContacts):
+		return fiber.Map{
+			"translate_key": "error.too_many_student_contacts",
+			"error":         "A student may have at most 10 emergency contacts",
+		}
+	case errors.Is(err, models.ErrStudentContactRelationship):
+		return fiber.Map{
+			"translate_key": "error.invalid_student_contact_relationship",
+			"error":         "Every contact needs a relationship of at most 50 characters",
+		}
+	case errors.Is(err, models.ErrStudentContactPhone):
+		return fiber.Map{
+			"translate_key": "error.invalid_student_contact_phone",
+			"error":         "Every contact needs a phone, and phones must be at most 20 characters",
+		}
+	case errors.Is(err, models.ErrStudentContactPriority):
+		return fiber.Map{
+			"translate_key": "error.invalid_student_contact_priority",
+			"error":         "Contact priorities must be 1 or more",
+		}
+	case errors.Is(err, models.ErrStudentMedicalNoteTooLong):
+		return fiber.Map{
+			"translate_key": "error.student_medical_note_too_long",
+			"error":         "A medical note may be at most 2000 characters",
+		}
+	default:
+		return fiber.Map{
+			"translate_key": "error.invalid_student_contact_name",
+			"error":         "Every contact needs a name of at most 100 characters",
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/spreadsheet"
+)
+
+// studentImportColumns are the columns a student import file must have. A phone column is optional.
+var studentImportColumns = []string{"student_id", "first_name", "last_name", "email", "class"}
+
+// Import godoc
+// @Summary Import students
+// @Description Create students from a CSV or XLSX file with the columns student_id, first_name, last_name, email, class and optionally phone.
+// @Description Classes are matched by name within the current school. Every row is checked first: if any has errors nothing is imported.
+// @Description With dry_run only the checks run. Otherwise all students are created in one transaction, each with a generated
+// @Description initial password that is returned once in the response.
+// @Tags Students
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only check the file"
+// @Success 200 {object} map[string]interface{} "Student import checked"
+// @Success 201 {object} map[string]interface{} "Students imported successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid file or missing columns"
+// @Failure 422 {object} map[string]interface{} "Rows with errors, nothing imported"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/import [post]
+func (h *studentHandler) Import(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "import students")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	sheet, status, errBody := readImportSheet(c, "import students", studentImportColumns)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	rows, rowErrors := parseStudentImport(sheet)
+	if len(rows) == 0 {
+		log.Println("error on import students: no rows")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.import_empty",
+			"error":         "The file has no rows to import",
+		})
+	}
+
+	if len(rows) > maxImportRows {
+		log.Println("error on import students: too many rows:", len(rows))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.import_too_many_rows",
+			"error":         "The file has too many rows. Split it into files of at most 5000 rows.",
+		})
+	}
+
+	checkErrors, err := h.studentRepo.CheckImport(c.Context(), rows)
+	if err != nil {
+		log.Println("error on import students:", err)
+		status, body := importFailedError(err, "error.failed_to_import_students", "Failed to import students")
+		return c.Status(status).JSON(body)
+	}
+	rowErrors = append(rowErrors, checkErrors...)
+	sortImportErrors(rowErrors)
+
+	result := &models.StudentImportResult{
+		DryRun: isImportDryRun(c),
+		Rows:   len(rows),
+		Errors: rowErrors,
+	}
+
+	if result.DryRun {
+		return c.JSON(fiber.Map{
+			"translate_key": "success.student_import_checked",
+			"message":       "Student import checked",
+			"data":          result,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		log.Println("error on import students: rows with errors:", len(rowErrors))
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"translate_key": "error.student_import_invalid",
+			"error":         "Some rows have errors. Nothing was imported.",
+			"data":          result,
+		})
+	}
+
+	passwords, hashes, err := generateHashedPasswords(len(rows))
+	if err != nil {
+		log.Println("error on import students:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.password_generation_failed",
+			"error":         "Failed to generate passwords",
+		})
+	}
+
+	for i, row := range rows {
+		row.Student.Password = hashes[i]
+	}
+
+	if err := h.studentRepo.Import(c.Context(), rows, &adminID); err != nil {
+		log.Println("error on import students:", err)
+		status, body := importFailedError(err, "error.failed_to_import_students", "Failed to import students")
+		return c.Status(status).JSON(body)
+	}
+
+	result.Created = len(rows)
+	for i, row := range rows {
+		result.Credentials = append(result.Credentials, models.ImportCredential{
+			Row:       row.Row,
+			ID:        row.Student.StudentID,
+			FirstName: row.Student.FirstName,
+			LastName:  row.Student.LastName,
+			Email:     row.Student.Email,
+			Password:  passwords[i],
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.students_imported",
+		"message":       "Students imported successfully",
+		"data":          result,
+	})
+}
+
+// parseStudentImport reads the students of the sheet, skipping blank lines, and checks each row on its own
+// and against the rows above it
+func parseStudentImport(sheet *spreadsheet.Sheet) ([]*models.StudentImportRow, []models.ImportRowError) {
+	checker := newImportRowChecker()
+	var rows []*models.StudentImportRow
+
+	for i, record := range sheet.Rows {
+		if spreadsheet.IsBlank(record) {
+			continue
+		}
+
+		row := &models.StudentImportRow{
+			Row:       i + 2,
+			ClassName: sheet.Value(record, "class"),
+			Student: models.Student{
+				StudentID: sheet.Value(record, "student_id"),
+				FirstName: sheet.Value(record, "first_name"),
+				LastName:  sheet.Value(record, "last_name"),
+				Email:     sheet.Value(record, "email"),
+			},
+		}
+		if phone := sheet.Value(record, "phone"); phone != "" {
+			row.Student.Phone = &phone
+		}
+
+		checker.required(row.Row, "student_id", row.Student.StudentID, 50)
+		checker.required(row.Row, "first_name", row.Student.FirstName, 50)
+		checker.required(row.Row, "last_name", row.Student.LastName, 50)
+		checker.email(row.Row, "email", row.Student.Email, 100)
+		checker.required(row.Row, "class", row.ClassName, 100)
+		if row.Student.Phone != nil {
+			checker.maxLength(row.Row, "phone", *row.Student.Phone, 20)
+			checker.unique(row.Row, "phone", *row.Student.Phone)
+		}
+		checker.unique(row.Row, "student_id", row.Student.StudentID)
+		checker.unique(row.Row, "email", row.Student.Email)
+
+		rows = append(rows, row)
+	}
+
+	return rows, checker.errors
+}
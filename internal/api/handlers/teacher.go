@@ -16,6 +16,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/notify"
+	"github.com/michaelwp/student_attendance/internal/policy"
 	"github.com/michaelwp/student_attendance/internal/repository"
 )
 
@@ -25,6 +27,9 @@ type teacherHandler struct {
 	s3Client          *s3.Client
 	classRepo         repository.ClassRepository
 	absentRequestRepo repository.AbsentRequestRepository
+	attachmentRepo    repository.AbsentRequestAttachmentRepository
+	decider           *absentRequestDecider
+	notifier          notify.Notifier
 }
 
 // NewTeacherHandler creates a new teacher handler
@@ -34,6 +39,11 @@ func NewTeacherHandler(
 	s3Config *config.S3Config,
 	classRepo repository.ClassRepository,
 	absentRequestRepo repository.AbsentRequestRepository,
+	attachmentRepo repository.AbsentRequestAttachmentRepository,
+	categoryRepo repository.AbsentRequestCategoryRepository,
+	quotaRepo repository.AbsenceQuotaRepository,
+	absentRequestPolicy *policy.AbsentRequestPolicy,
+	notifier notify.Notifier,
 ) TeacherHandler {
 	return &teacherHandler{
 		teacherRepo:       teacherRepo,
@@ -41,6 +51,9 @@ func NewTeacherHandler(
 		s3Client:          s3Client,
 		classRepo:         classRepo,
 		absentRequestRepo: absentRequestRepo,
+		attachmentRepo:    attachmentRepo,
+		decider:           newAbsentRequestDecider(absentRequestRepo, categoryRepo, attachmentRepo, quotaRepo, absentRequestPolicy),
+		notifier:          notifier,
 	}
 }
 
@@ -357,59 +370,7 @@ func (h *teacherHandler) UploadPhoto(c *fiber.Ctx) error {
 		})
 	}
 
-	file, err := c.FormFile("photo")
-	if err != nil {
-		log.Println("error on get file from form:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.no_photo_file_provided",
-			"error":         "No photo file provided",
-		})
-	}
-
-	fileContent, err := file.Open()
-	if err != nil {
-		log.Println("error on open file content:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_open_photo_file",
-			"error":         "Failed to read photo file",
-		})
-	}
-	defer fileContent.Close()
-
-	buffer, err := io.ReadAll(fileContent)
-	if err != nil {
-		log.Println("error on get buffer from file content:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_read_file",
-			"error":         "Failed to read file",
-		})
-	}
-
-	filename := "teacher_" + idParam + "_" + strconv.FormatInt(time.Now().Unix(), 10) + filepath.Ext(file.Filename)
-	key := fmt.Sprintf("photos/teachers/%d/%s", id, filename)
-
-	if err := h.s3Config.UploadFile(h.s3Client, key, buffer); err != nil {
-		log.Println("error on upload file to S3:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_upload_file",
-			"error":         "Failed to upload file to S3",
-		})
-	}
-
-	photoPath := h.s3Config.GetObjectURL(key)
-	if err := h.teacherRepo.UpdatePhotoPath(c.Context(), uint(id), key); err != nil {
-		log.Println("error on update photo path:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_update_photo_path",
-			"error":         "Failed to update photo path",
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"translate_key": "success.photo_uploaded",
-		"message":       "Photo uploaded successfully",
-		"path":          photoPath,
-	})
+	return h.uploadPhoto(c, uint(id))
 }
 
 // GetPhoto godoc
@@ -642,6 +603,63 @@ func (h *teacherHandler) GetStats(c *fiber.Ctx) error {
 	})
 }
 
+// uploadPhoto stores the photo in the request's photo form field in S3 as the photo of the teacher with the id
+func (h *teacherHandler) uploadPhoto(c *fiber.Ctx, id uint) error {
+	file, err := c.FormFile("photo")
+	if err != nil {
+		log.Println("error on get file from form:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.no_photo_file_provided",
+			"error":         "No photo file provided",
+		})
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		log.Println("error on open file content:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_open_photo_file",
+			"error":         "Failed to read photo file",
+		})
+	}
+	defer fileContent.Close()
+
+	buffer, err := io.ReadAll(fileContent)
+	if err != nil {
+		log.Println("error on get buffer from file content:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_read_file",
+			"error":         "Failed to read file",
+		})
+	}
+
+	filename := "teacher_" + strconv.FormatUint(uint64(id), 10) + "_" + strconv.FormatInt(time.Now().Unix(), 10) + filepath.Ext(file.Filename)
+	key := fmt.Sprintf("photos/teachers/%d/%s", id, filename)
+
+	if err := h.s3Config.UploadFile(h.s3Client, key, buffer); err != nil {
+		log.Println("error on upload file to S3:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_upload_file",
+			"error":         "Failed to upload file to S3",
+		})
+	}
+
+	photoPath := h.s3Config.GetObjectURL(key)
+	if err := h.teacherRepo.UpdatePhotoPath(c.Context(), id, key); err != nil {
+		log.Println("error on update photo path:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_photo_path",
+			"error":         "Failed to update photo path",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.photo_uploaded",
+		"message":       "Photo uploaded successfully",
+		"path":          photoPath,
+	})
+}
+
 func (h *teacherHandler) updateCurrentPassword(ctx context.Context, teacherID string, password string) error {
 	round, _ := strconv.Atoi(os.Getenv("SALT"))
 	hashPassword, err := pkg.HashPassword(password, round)
@@ -867,6 +885,14 @@ func (h *teacherHandler) GetAbsentRequests(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := loadAttachments(c.Context(), h.attachmentRepo, h.s3Client, h.s3Config, requests...); err != nil {
+		log.Println("error on get absent request attachments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_attachments",
+			"error":         "Failed to get attachments",
+		})
+	}
+
 	total, err := h.absentRequestRepo.GetCountByTeacher(c.Context(), teacher.TeacherID)
 	if err != nil {
 		log.Println("error on get absent requests count:", err)
@@ -888,116 +914,92 @@ func (h *teacherHandler) GetAbsentRequests(c *fiber.Ctx) error {
 
 // ApproveAbsentRequest godoc
 // @Summary Approve absent request
-// @Description Approve a student's absent request
+// @Description Approve a student's absent request for every day in its date range
 // @Tags Teacher Dashboard
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Absent request ID"
+// @Param request body object false "Optional reviewer note shown to the student (note field)"
 // @Success 200 {object} map[string]interface{} "Request approved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request ID"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not a teacher of the request's class, or their role does not allow deciding"
 // @Failure 404 {object} map[string]interface{} "Request not found"
+// @Failure 409 {object} map[string]interface{} "Request already decided"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /absent-requests/absent-request-id/{id}/approve [put]
 func (h *teacherHandler) ApproveAbsentRequest(c *fiber.Ctx) error {
-	userID := c.Locals("userID")
-	if userID == nil {
-		log.Println("error on approve absent request: invalid user id")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"translate_key": "error.invalid_user_id",
-			"error":         "Invalid user ID",
-		})
-	}
-
-	userIDStr := userID.(string)
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		log.Println("error on approve absent request: invalid user id format:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.invalid_user_id_format",
-			"error":         "Invalid user ID format",
-		})
-	}
-
-	idParam := c.Params("id")
-	requestID, err := strconv.ParseUint(idParam, 10, 32)
-	if err != nil {
-		log.Println("error on approve absent request: invalid request id:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.invalid_request_id",
-			"error":         "Invalid request ID",
-		})
-	}
-
-	if err := h.absentRequestRepo.Approve(c.Context(), uint(requestID), uint(userIDUint)); err != nil {
-		log.Println("error on approve absent request:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_approve_request",
-			"error":         "Failed to approve request",
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"translate_key": "success.request_approved",
-		"message":       "Request approved successfully",
-	})
+	return h.decideAbsentRequest(c, models.AbsentRequestStatusApproved)
 }
 
 // RejectAbsentRequest godoc
 // @Summary Reject absent request
-// @Description Reject a student's absent request
+// @Description Reject a student's absent request for every day in its date range
 // @Tags Teacher Dashboard
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Absent request ID"
+// @Param request body object false "Optional reviewer note explaining the rejection to the student (note field)"
 // @Success 200 {object} map[string]interface{} "Request rejected successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request ID"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not a teacher of the request's class, or their role does not allow deciding"
 // @Failure 404 {object} map[string]interface{} "Request not found"
+// @Failure 409 {object} map[string]interface{} "Request already decided"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /absent-requests/absent-request-id/{id}/reject [put]
 func (h *teacherHandler) RejectAbsentRequest(c *fiber.Ctx) error {
-	userID := c.Locals("userID")
-	if userID == nil {
-		log.Println("error on reject absent request: invalid user id")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"translate_key": "error.invalid_user_id",
-			"error":         "Invalid user ID",
-		})
-	}
+	return h.decideAbsentRequest(c, models.AbsentRequestStatusRejected)
+}
 
-	userIDStr := userID.(string)
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		log.Println("error on reject absent request: invalid user id format:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"translate_key": "error.invalid_user_id_format",
-			"error":         "Invalid user ID format",
-		})
+// decideAbsentRequest approves or rejects the request in the path if the teacher's role in its class allows it
+func (h *teacherHandler) decideAbsentRequest(c *fiber.Ctx, status models.AbsentRequestStatus) error {
+	actor, errStatus, errBody := currentActor(c, "decide absent request")
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
 	}
 
-	idParam := c.Params("id")
-	requestID, err := strconv.ParseUint(idParam, 10, 32)
+	requestID, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		log.Println("error on reject absent request: invalid request id:", err)
+		log.Println("error on decide absent request: invalid request id:", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"translate_key": "error.invalid_request_id",
 			"error":         "Invalid request ID",
 		})
 	}
 
-	if err := h.absentRequestRepo.Reject(c.Context(), uint(requestID), uint(userIDUint)); err != nil {
-		log.Println("error on reject absent request:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"translate_key": "error.failed_to_reject_request",
-			"error":         "Failed to reject request",
+	// the reviewer note is optional, so an empty body is accepted
+	var body struct {
+		Note string `json:"note"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			log.Println("error on decide absent request: invalid body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_request_body",
+				"error":         "Invalid request body",
+			})
+		}
+	}
+
+	request, errStatus, errBody := h.decider.decide(c.Context(), actor, uint(requestID), status, body.Note, false)
+	if errBody != nil {
+		return c.Status(errStatus).JSON(errBody)
+	}
+
+	if status == models.AbsentRequestStatusApproved {
+		return c.JSON(fiber.Map{
+			"translate_key": "success.request_approved",
+			"message":       "Request approved successfully",
+			"data":          request,
 		})
 	}
 
 	return c.JSON(fiber.Map{
 		"translate_key": "success.request_rejected",
 		"message":       "Request rejected successfully",
+		"data":          request,
 	})
 }
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/spreadsheet"
+)
+
+// teacherImportColumns are the columns a teacher import file must have. Phone and homeroom_class columns are optional.
+var teacherImportColumns = []string{"teacher_id", "first_name", "last_name", "email"}
+
+// Import godoc
+// @Summary Import teachers
+// @Description Create teacher accounts from a CSV or XLSX file with the columns teacher_id, first_name, last_name, email and optionally
+// @Description phone and homeroom_class. A teacher with a homeroom_class becomes the homeroom teacher of that class of the current school,
+// @Description replacing its previous one. Every row is checked first: if any has errors nothing is imported. With dry_run only the checks run.
+// @Description Otherwise all teachers are created in one transaction, each with a generated one-time password returned once in the response,
+// @Description as JSON or, with format=csv, as a downloadable credentials sheet.
+// @Tags Teachers
+// @Accept multipart/form-data
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only check the file"
+// @Param format query string false "csv to download the credentials sheet"
+// @Success 200 {object} map[string]interface{} "Teacher import checked"
+// @Success 201 {object} map[string]interface{} "Teachers imported successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid file or missing columns"
+// @Failure 422 {object} map[string]interface{} "Rows with errors, nothing imported"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /teachers/import [post]
+func (h *teacherHandler) Import(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "import teachers")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	sheet, status, errBody := readImportSheet(c, "import teachers", teacherImportColumns)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	rows, rowErrors := parseTeacherImport(sheet)
+	if len(rows) == 0 {
+		log.Println("error on import teachers: no rows")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.import_empty",
+			"error":         "The file has no rows to import",
+		})
+	}
+
+	if len(rows) > maxImportRows {
+		log.Println("error on import teachers: too many rows:", len(rows))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.import_too_many_rows",
+			"error":         "The file has too many rows. Split it into files of at most 5000 rows.",
+		})
+	}
+
+	checkErrors, err := h.teacherRepo.CheckImport(c.Context(), rows)
+	if err != nil {
+		log.Println("error on import teachers:", err)
+		status, body := importFailedError(err, "error.failed_to_import_teachers", "Failed to import teachers")
+		return c.Status(status).JSON(body)
+	}
+	rowErrors = append(rowErrors, checkErrors...)
+	sortImportErrors(rowErrors)
+
+	result := &models.TeacherImportResult{
+		DryRun: isImportDryRun(c),
+		Rows:   len(rows),
+		Errors: rowErrors,
+	}
+	for _, row := range rows {
+		if row.HomeroomClassName != "" {
+			result.HomeroomsAssigned++
+		}
+	}
+
+	if result.DryRun {
+		return c.JSON(fiber.Map{
+			"translate_key": "success.teacher_import_checked",
+			"message":       "Teacher import checked",
+			"data":          result,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		log.Println("error on import teachers: rows with errors:", len(rowErrors))
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"translate_key": "error.teacher_import_invalid",
+			"error":         "Some rows have errors. Nothing was imported.",
+			"data":          result,
+		})
+	}
+
+	passwords, hashes, err := generateHashedPasswords(len(rows))
+	if err != nil {
+		log.Println("error on import teachers:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.password_generation_failed",
+			"error":         "Failed to generate passwords",
+		})
+	}
+
+	for i, row := range rows {
+		row.Teacher.Password = hashes[i]
+	}
+
+	if err := h.teacherRepo.Import(c.Context(), rows, &adminID); err != nil {
+		log.Println("error on import teachers:", err)
+		status, body := importFailedError(err, "error.failed_to_import_teachers", "Failed to import teachers")
+		return c.Status(status).JSON(body)
+	}
+
+	result.Created = len(rows)
+	for i, row := range rows {
+		result.Credentials = append(result.Credentials, models.ImportCredential{
+			Row:       row.Row,
+			ID:        row.Teacher.TeacherID,
+			FirstName: row.Teacher.FirstName,
+			LastName:  row.Teacher.LastName,
+			Email:     row.Teacher.Email,
+			Password:  passwords[i],
+		})
+	}
+
+	if c.Query("format") == "csv" {
+		records := make([][]string, len(rows))
+		for i, credential := range result.Credentials {
+			records[i] = []string{
+				strconv.Itoa(credential.Row),
+				credential.ID,
+				credential.FirstName,
+				credential.LastName,
+				credential.Email,
+				credential.Password,
+				rows[i].HomeroomClassName,
+			}
+		}
+
+		header := []string{"row", "teacher_id", "first_name", "last_name", "email", "password", "homeroom_class"}
+		return sendCredentialsSheet(c, "teacher_credentials", header, records)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.teachers_imported",
+		"message":       "Teachers imported successfully",
+		"data":          result,
+	})
+}
+
+// parseTeacherImport reads the teachers of the sheet, skipping blank lines, and checks each row on its own
+// and against the rows above it. A class can only get one homeroom teacher from the file.
+func parseTeacherImport(sheet *spreadsheet.Sheet) ([]*models.TeacherImportRow, []models.ImportRowError) {
+	checker := newImportRowChecker()
+	var rows []*models.TeacherImportRow
+
+	for i, record := range sheet.Rows {
+		if spreadsheet.IsBlank(record) {
+			continue
+		}
+
+		row := &models.TeacherImportRow{
+			Row:               i + 2,
+			HomeroomClassName: sheet.Value(record, "homeroom_class"),
+			Teacher: models.Teacher{
+				TeacherID: sheet.Value(record, "teacher_id"),
+				FirstName: sheet.Value(record, "first_name"),
+				LastName:  sheet.Value(record, "last_name"),
+				Email:     sheet.Value(record, "email"),
+			},
+		}
+		if phone := sheet.Value(record, "phone"); phone != "" {
+			row.Teacher.Phone = &phone
+		}
+
+		checker.required(row.Row, "teacher_id", row.Teacher.TeacherID, 50)
+		checker.required(row.Row, "first_name", row.Teacher.FirstName, 50)
+		checker.required(row.Row, "last_name", row.Teacher.LastName, 50)
+		checker.email(row.Row, "email", row.Teacher.Email, 100)
+		if row.Teacher.Phone != nil {
+			checker.maxLength(row.Row, "phone", *row.Teacher.Phone, 20)
+			checker.unique(row.Row, "phone", *row.Teacher.Phone)
+		}
+		checker.unique(row.Row, "teacher_id", row.Teacher.TeacherID)
+		checker.unique(row.Row, "email", row.Teacher.Email)
+		checker.unique(row.Row, "homeroom_class", row.HomeroomClassName)
+
+		rows = append(rows, row)
+	}
+
+	return rows, checker.errors
+}
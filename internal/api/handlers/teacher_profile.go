@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/notify"
+	"github.com/michaelwp/student_attendance/pkg"
+)
+
+// UpdateProfile godoc
+// @Summary Update current teacher profile
+// @Description Update the phone, email and preferred language (en or id) of the currently authenticated teacher.
+// @Description Leave a field out to keep it; send an empty phone to clear it. The phone and language apply at once.
+// @Description A new email only applies once verified: a code is sent to it, to be entered at /teacher/profile/email/verify.
+// @Description Fields reserved for admins, such as teacher_id, names and is_active, are ignored.
+// @Tags Teacher Dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body models.TeacherProfileUpdate true "Profile update data"
+// @Success 200 {object} map[string]interface{} "Profile updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Teacher not found"
+// @Failure 409 {object} map[string]interface{} "Phone or email already used by another teacher"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /teacher/profile [put]
+func (h *teacherHandler) UpdateProfile(c *fiber.Ctx) error {
+	teacherID, status, errBody := currentTeacherID(c, "update teacher profile")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var request models.TeacherProfileUpdate
+	if err := c.BodyParser(&request); err != nil {
+		log.Println("error on update teacher profile: invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		})
+	}
+
+	teacher, err := h.teacherRepo.GetByID(c.Context(), teacherID)
+	if err != nil {
+		log.Println("error on update teacher profile:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.teacher_not_found",
+			"error":         "Teacher not found",
+		})
+	}
+
+	language := teacher.PreferredLanguage
+	if request.PreferredLanguage != "" {
+		if !models.IsValidLanguage(request.PreferredLanguage) {
+			log.Println("error on update teacher profile: invalid language:", request.PreferredLanguage)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_language",
+				"error":         "Preferred language must be en or id",
+			})
+		}
+		language = request.PreferredLanguage
+	}
+
+	phone := teacher.Phone
+	if request.Phone != nil {
+		phone = nil
+		if value := strings.TrimSpace(*request.Phone); value != "" {
+			if len(value) > 20 {
+				log.Println("error on update teacher profile: phone too long")
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"translate_key": "error.invalid_phone",
+					"error":         "Phone must be at most 20 characters",
+				})
+			}
+			phone = &value
+		}
+	}
+
+	newEmail := strings.TrimSpace(request.Email)
+	changesEmail := newEmail != "" && !strings.EqualFold(newEmail, teacher.Email)
+	if changesEmail {
+		if address, err := mail.ParseAddress(newEmail); err != nil || address.Address != newEmail || len(newEmail) > 100 {
+			log.Println("error on update teacher profile: invalid email:", newEmail)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_email",
+				"error":         "Email is not a valid email address",
+			})
+		}
+
+		taken, err := h.teacherRepo.IsEmailTaken(c.Context(), newEmail, teacherID)
+		if err != nil {
+			log.Println("error on update teacher profile:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_update_profile",
+				"error":         "Failed to update profile",
+			})
+		}
+		if taken {
+			log.Println("error on update teacher profile: email taken:", newEmail)
+			status, errBody = emailChangeError(models.ErrEmailTaken)
+			return c.Status(status).JSON(errBody)
+		}
+	}
+
+	if err := h.teacherRepo.UpdateProfile(c.Context(), teacherID, phone, language); err != nil {
+		log.Println("error on update teacher profile:", err)
+		if errors.Is(err, models.ErrPhoneTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"translate_key": "error.phone_taken",
+				"error":         "Phone is already used by another teacher",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_profile",
+			"error":         "Failed to update profile",
+		})
+	}
+
+	var emailChange *models.TeacherEmailChange
+	if changesEmail {
+		emailChange, err = h.requestEmailChange(c, teacherID, newEmail)
+		if err != nil {
+			log.Println("error on update teacher profile: failed to send verification code:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_send_verification_code",
+				"error":         "The profile was updated but the verification code for the new email could not be sent",
+			})
+		}
+	}
+
+	teacher, err = h.teacherRepo.GetByID(c.Context(), teacherID)
+	if err != nil {
+		log.Println("error on update teacher profile:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_profile",
+			"error":         "Failed to update profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key":      "success.profile_updated",
+		"message":            "Profile updated successfully",
+		"data":               teacher,
+		"email_verification": emailChange,
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify new teacher email
+// @Description Enter the code sent to the new email asked for with PUT /teacher/profile to make it the teacher's email.
+// @Description The code is valid for 30 minutes; after 5 wrong codes the email change is dropped and must be asked for again.
+// @Tags Teacher Dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body object{code=string} true "Verification code"
+// @Success 200 {object} map[string]interface{} "Email verified successfully"
+// @Failure 400 {object} map[string]interface{} "Wrong or expired code"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "No email change waiting for verification"
+// @Failure 409 {object} map[string]interface{} "Email already used by another teacher"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /teacher/profile/email/verify [post]
+func (h *teacherHandler) VerifyEmail(c *fiber.Ctx) error {
+	teacherID, status, errBody := currentTeacherID(c, "verify teacher email")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var request struct {
+		Code string `json:"code"`
+	}
+
+	if err := c.BodyParser(&request); err != nil || strings.TrimSpace(request.Code) == "" {
+		log.Println("error on verify teacher email: code is required:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.verification_code_required",
+			"error":         "Verification code is required",
+		})
+	}
+
+	change, err := h.teacherRepo.GetEmailChange(c.Context(), teacherID)
+	if err != nil {
+		log.Println("error on verify teacher email:", err)
+		status, errBody = emailChangeError(err)
+		return c.Status(status).JSON(errBody)
+	}
+
+	if time.Now().After(change.ExpiresAt) {
+		log.Println("error on verify teacher email:", models.ErrEmailChangeExpired)
+		status, errBody = emailChangeError(models.ErrEmailChangeExpired)
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := pkg.ComparePasswords(change.CodeHash, strings.TrimSpace(request.Code)); err != nil {
+		log.Println("error on verify teacher email: wrong code")
+		if err := h.teacherRepo.FailEmailChange(c.Context(), teacherID); err != nil {
+			log.Println("error on verify teacher email:", err)
+		}
+		status, errBody = emailChangeError(models.ErrEmailChangeCode)
+		return c.Status(status).JSON(errBody)
+	}
+
+	email, err := h.teacherRepo.ConfirmEmailChange(c.Context(), teacherID)
+	if err != nil {
+		log.Println("error on verify teacher email:", err)
+		status, errBody = emailChangeError(err)
+		return c.Status(status).JSON(errBody)
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.email_verified",
+		"message":       "Email verified successfully",
+		"email":         email,
+	})
+}
+
+// UploadCurrentPhoto godoc
+// @Summary Upload current teacher photo
+// @Description Upload the photo of the currently authenticated teacher to S3
+// @Tags Teacher Dashboard
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param photo formData file true "Teacher photo"
+// @Success 200 {object} map[string]interface{} "Photo uploaded successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /teacher/profile/photo [put]
+func (h *teacherHandler) UploadCurrentPhoto(c *fiber.Ctx) error {
+	teacherID, status, errBody := currentTeacherID(c, "upload current teacher photo")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	return h.uploadPhoto(c, teacherID)
+}
+
+// requestEmailChange records the new email as waiting for verification and sends a code to it
+func (h *teacherHandler) requestEmailChange(c *fiber.Ctx, teacherID uint, email string) (*models.TeacherEmailChange, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	round, _ := strconv.Atoi(os.Getenv("SALT"))
+	codeHash, err := pkg.HashPassword(code, round)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash verification code: %w", err)
+	}
+
+	change := &models.TeacherEmailChange{
+		TeacherID: teacherID,
+		NewEmail:  email,
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(models.EmailChangeCodeTTL),
+	}
+	if err := h.teacherRepo.SaveEmailChange(c.Context(), change); err != nil {
+		return nil, err
+	}
+
+	err = h.notifier.Notify(c.Context(), notify.Message{
+		Recipient: email,
+		Subject:   "Verify your new email",
+		Body: fmt.Sprintf(
+			"Enter the code %s in your teacher dashboard to use this email for your account. The code is valid for %d minutes.",
+			code,
+			int(models.EmailChangeCodeTTL.Minutes()),
+		),
+		Data: map[string]interface{}{
+			"teacher_id": teacherID,
+			"expires_at": change.ExpiresAt,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// currentTeacherID reads the id of the logged-in teacher. On failure it returns the status and body to respond with.
+func currentTeacherID(c *fiber.Ctx, action string) (uint, int, fiber.Map) {
+	userID, _ := c.Locals("userID").(string)
+	if userID == "" {
+		log.Printf("error on %s: invalid user id\n", action)
+		return 0, fiber.StatusUnauthorized, fiber.Map{
+			"translate_key": "error.invalid_user_id",
+			"error":         "Invalid user ID",
+		}
+	}
+
+	teacherID, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		log.Printf("error on %s: invalid user id format: %v\n", action, err)
+		return 0, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_user_id_format",
+			"error":         "Invalid user ID format",
+		}
+	}
+
+	return uint(teacherID), fiber.StatusOK, nil
+}
+
+// emailChangeError maps an email change error to the status and body to respond with
+func emailChangeError(err error) (int, fiber.Map) {
+	switch {
+	case errors.Is(err, models.ErrEmailChangeNotFound):
+		return fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.email_change_not_found",
+			"error":         "No email change is waiting for verification",
+		}
+	case errors.Is(err, models.ErrEmailTaken):
+		return fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.email_taken",
+			"error":         "Email is already used by another teacher",
+		}
+	case errors.Is(err, models.ErrEmailChangeExpired):
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.verification_code_expired",
+			"error":         "The verification code has expired, ask for a new one",
+		}
+	case errors.Is(err, models.ErrEmailChangeCode):
+		return fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_verification_code",
+			"error":         "The verification code is wrong",
+		}
+	default:
+		return fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_verify_email",
+			"error":         "Failed to verify email",
+		}
+	}
+}
@@ -0,0 +1,681 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type timetableHandler struct {
+	subjectRepo   repository.SubjectRepository
+	timetableRepo repository.TimetableRepository
+	classRepo     repository.ClassRepository
+	teacherRepo   repository.TeacherRepository
+}
+
+// NewTimetableHandler creates a new timetable handler
+func NewTimetableHandler(
+	subjectRepo repository.SubjectRepository,
+	timetableRepo repository.TimetableRepository,
+	classRepo repository.ClassRepository,
+	teacherRepo repository.TeacherRepository,
+) TimetableHandler {
+	return &timetableHandler{
+		subjectRepo:   subjectRepo,
+		timetableRepo: timetableRepo,
+		classRepo:     classRepo,
+		teacherRepo:   teacherRepo,
+	}
+}
+
+// CreateSubject godoc
+// @Summary Create subject
+// @Description Add a subject to the current school
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subject body models.Subject true "Subject data"
+// @Success 201 {object} map[string]interface{} "Subject created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 409 {object} map[string]interface{} "Subject code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/subjects [post]
+func (h *timetableHandler) CreateSubject(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create subject")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	subject, status, errBody := h.parseSubject(c, 0)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	subject.CreatedBy = &adminID
+	if err := h.subjectRepo.Create(c.Context(), subject); err != nil {
+		log.Println("error on create subject:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_subject",
+			"error":         "Failed to create subject",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.subject_created",
+		"message":       "Subject created successfully",
+		"data":          subject,
+	})
+}
+
+// GetSubjects godoc
+// @Summary Get subjects
+// @Description Retrieve the subjects of the current school ordered by name
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Subjects retrieved successfully"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/subjects [get]
+func (h *timetableHandler) GetSubjects(c *fiber.Ctx) error {
+	subjects, err := h.subjectRepo.GetAll(c.Context())
+	if err != nil {
+		log.Println("error on get subjects:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_subjects",
+			"error":         "Failed to get subjects",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.subjects_retrieved",
+		"message":       "Subjects retrieved successfully",
+		"data":          subjects,
+	})
+}
+
+// GetSubjectByID godoc
+// @Summary Get subject by ID
+// @Description Retrieve a specific subject
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Subject ID"
+// @Success 200 {object} map[string]interface{} "Subject retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid subject ID"
+// @Failure 404 {object} map[string]interface{} "Subject not found"
+// @Router /admins/subjects/subject-id/{id} [get]
+func (h *timetableHandler) GetSubjectByID(c *fiber.Ctx) error {
+	subject, status, errBody := h.findSubject(c, "get subject by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.subject_retrieved",
+		"message":       "Subject retrieved successfully",
+		"data":          subject,
+	})
+}
+
+// UpdateSubject godoc
+// @Summary Update subject
+// @Description Update the code, name or description of a subject
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Subject ID"
+// @Param subject body models.Subject true "Subject data"
+// @Success 200 {object} map[string]interface{} "Subject updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 404 {object} map[string]interface{} "Subject not found"
+// @Failure 409 {object} map[string]interface{} "Subject code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/subjects/subject-id/{id} [put]
+func (h *timetableHandler) UpdateSubject(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update subject")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	existing, status, errBody := h.findSubject(c, "update subject")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	subject, status, errBody := h.parseSubject(c, existing.ID)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	subject.ID = existing.ID
+	subject.SchoolID = existing.SchoolID
+	subject.CreatedAt = existing.CreatedAt
+	subject.CreatedBy = existing.CreatedBy
+	subject.UpdatedBy = &adminID
+	if err := h.subjectRepo.Update(c.Context(), subject); err != nil {
+		log.Println("error on update subject:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_subject",
+			"error":         "Failed to update subject",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.subject_updated",
+		"message":       "Subject updated successfully",
+		"data":          subject,
+	})
+}
+
+// DeleteSubject godoc
+// @Summary Delete subject
+// @Description Remove a subject that no timetable slot teaches
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Subject ID"
+// @Success 200 {object} map[string]interface{} "Subject deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid subject ID"
+// @Failure 404 {object} map[string]interface{} "Subject not found"
+// @Failure 409 {object} map[string]interface{} "Timetable slots still teach the subject"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/subjects/subject-id/{id} [delete]
+func (h *timetableHandler) DeleteSubject(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete subject")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	subject, status, errBody := h.findSubject(c, "delete subject")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	inUse, err := h.subjectRepo.IsInUse(c.Context(), subject.ID)
+	if err != nil {
+		log.Println("error on delete subject:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_check_subject",
+			"error":         "Failed to check subject",
+		})
+	}
+
+	if inUse {
+		log.Println("error on delete subject: timetable slots still teach it")
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"translate_key": "error.subject_in_use",
+			"error":         "Timetable slots still teach this subject",
+		})
+	}
+
+	if err := h.subjectRepo.UpdateDeleteInfo(c.Context(), subject.ID, adminID); err != nil {
+		log.Println("error on delete subject:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.subject_not_found",
+			"error":         "Subject not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.subject_deleted",
+		"message":       "Subject deleted successfully",
+	})
+}
+
+// CreateSlot godoc
+// @Summary Create timetable slot
+// @Description Add a weekly lesson to the timetable. Neither the class nor the teacher may have another lesson at the same time.
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slot body models.TimetableSlot true "Timetable slot data"
+// @Success 201 {object} map[string]interface{} "Timetable slot created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, class, subject or teacher"
+// @Failure 409 {object} map[string]interface{} "Slot overlaps another lesson"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/timetable-slots [post]
+func (h *timetableHandler) CreateSlot(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "create timetable slot")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	slot, status, errBody := h.parseSlot(c, 0)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	slot.CreatedBy = &adminID
+	if err := h.timetableRepo.Create(c.Context(), slot); err != nil {
+		log.Println("error on create timetable slot:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_create_timetable_slot",
+			"error":         "Failed to create timetable slot",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"translate_key": "success.timetable_slot_created",
+		"message":       "Timetable slot created successfully",
+		"data":          slot,
+	})
+}
+
+// GetSlots godoc
+// @Summary Get timetable slots
+// @Description Retrieve the weekly timetable, optionally narrowed to a class, a teacher or a weekday
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param class_id query int false "Class ID"
+// @Param teacher_id query string false "Teacher ID"
+// @Param weekday query int false "ISO weekday, 1 (Monday) to 7 (Sunday)"
+// @Success 200 {object} map[string]interface{} "Timetable slots retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid filter"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/timetable-slots [get]
+func (h *timetableHandler) GetSlots(c *fiber.Ctx) error {
+	classID, err := strconv.ParseUint(c.Query("class_id", "0"), 10, 32)
+	if err != nil {
+		log.Println("error on get timetable slots:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_class_id",
+			"error":         "Invalid class ID",
+		})
+	}
+
+	weekday, err := strconv.Atoi(c.Query("weekday", "0"))
+	if err != nil || weekday < 0 || weekday > 7 {
+		log.Println("error on get timetable slots: invalid weekday", c.Query("weekday"))
+		return c.Status(fiber.StatusBadRequest).JSON(timetableError(models.ErrTimetableInvalidWeekday))
+	}
+
+	filter := &models.TimetableFilter{
+		ClassID:   uint(classID),
+		TeacherID: c.Query("teacher_id"),
+		Weekday:   weekday,
+	}
+
+	slots, err := h.timetableRepo.GetAll(c.Context(), filter)
+	if err != nil {
+		log.Println("error on get timetable slots:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_timetable_slots",
+			"error":         "Failed to get timetable slots",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.timetable_slots_retrieved",
+		"message":       "Timetable slots retrieved successfully",
+		"data":          slots,
+	})
+}
+
+// GetSlotByID godoc
+// @Summary Get timetable slot by ID
+// @Description Retrieve a specific timetable slot
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Timetable slot ID"
+// @Success 200 {object} map[string]interface{} "Timetable slot retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid timetable slot ID"
+// @Failure 404 {object} map[string]interface{} "Timetable slot not found"
+// @Router /admins/timetable-slots/slot-id/{id} [get]
+func (h *timetableHandler) GetSlotByID(c *fiber.Ctx) error {
+	slot, status, errBody := h.findSlot(c, "get timetable slot by id")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.timetable_slot_retrieved",
+		"message":       "Timetable slot retrieved successfully",
+		"data":          slot,
+	})
+}
+
+// UpdateSlot godoc
+// @Summary Update timetable slot
+// @Description Update a weekly lesson. Neither the class nor the teacher may have another lesson at the same time.
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Timetable slot ID"
+// @Param slot body models.TimetableSlot true "Timetable slot data"
+// @Success 200 {object} map[string]interface{} "Timetable slot updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body, class, subject or teacher"
+// @Failure 404 {object} map[string]interface{} "Timetable slot not found"
+// @Failure 409 {object} map[string]interface{} "Slot overlaps another lesson"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/timetable-slots/slot-id/{id} [put]
+func (h *timetableHandler) UpdateSlot(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "update timetable slot")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	existing, status, errBody := h.findSlot(c, "update timetable slot")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	slot, status, errBody := h.parseSlot(c, existing.ID)
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	slot.ID = existing.ID
+	slot.SchoolID = existing.SchoolID
+	slot.CreatedAt = existing.CreatedAt
+	slot.CreatedBy = existing.CreatedBy
+	slot.UpdatedBy = &adminID
+	if err := h.timetableRepo.Update(c.Context(), slot); err != nil {
+		log.Println("error on update timetable slot:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_update_timetable_slot",
+			"error":         "Failed to update timetable slot",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.timetable_slot_updated",
+		"message":       "Timetable slot updated successfully",
+		"data":          slot,
+	})
+}
+
+// DeleteSlot godoc
+// @Summary Delete timetable slot
+// @Description Remove a weekly lesson from the timetable. Attendance already taken in it is kept.
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Timetable slot ID"
+// @Success 200 {object} map[string]interface{} "Timetable slot deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid timetable slot ID"
+// @Failure 404 {object} map[string]interface{} "Timetable slot not found"
+// @Router /admins/timetable-slots/slot-id/{id} [delete]
+func (h *timetableHandler) DeleteSlot(c *fiber.Ctx) error {
+	adminID, status, errBody := currentAdminID(c, "delete timetable slot")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	slot, status, errBody := h.findSlot(c, "delete timetable slot")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := h.timetableRepo.UpdateDeleteInfo(c.Context(), slot.ID, adminID); err != nil {
+		log.Println("error on delete timetable slot:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.timetable_slot_not_found",
+			"error":         "Timetable slot not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.timetable_slot_deleted",
+		"message":       "Timetable slot deleted successfully",
+	})
+}
+
+// GetMySchedule godoc
+// @Summary Get my schedule
+// @Description Retrieve the lessons the authenticated teacher teaches today, or on the given date, ordered by start time
+// @Tags Teacher Dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} map[string]interface{} "Schedule retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid date format"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Teacher not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /teacher/schedule/today [get]
+func (h *timetableHandler) GetMySchedule(c *fiber.Ctx) error {
+	actor, status, errBody := currentActor(c, "get my schedule")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	date := time.Now()
+	if dateParam := c.Query("date"); dateParam != "" {
+		parsed, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			log.Println("error on get my schedule:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_date_format",
+				"error":         "Invalid date format. Use YYYY-MM-DD format.",
+			})
+		}
+		date = parsed
+	}
+
+	teacher, err := h.teacherRepo.GetByID(c.Context(), actor.UserID)
+	if err != nil {
+		log.Println("error on get my schedule:", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"translate_key": "error.teacher_not_found",
+			"error":         "Teacher not found",
+		})
+	}
+
+	weekday := models.ISOWeekday(date)
+	slots, err := h.timetableRepo.GetAll(c.Context(), &models.TimetableFilter{
+		TeacherID: teacher.TeacherID,
+		Weekday:   weekday,
+	})
+	if err != nil {
+		log.Println("error on get my schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_schedule",
+			"error":         "Failed to get schedule",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.schedule_retrieved",
+		"message":       "Schedule retrieved successfully",
+		"date":          date.Format("2006-01-02"),
+		"weekday":       weekday,
+		"data":          slots,
+	})
+}
+
+func (h *timetableHandler) findSubject(c *fiber.Ctx, action string) (*models.Subject, int, fiber.Map) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_subject_id",
+			"error":         "Invalid subject ID",
+		}
+	}
+
+	subject, err := h.subjectRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.subject_not_found",
+			"error":         "Subject not found",
+		}
+	}
+
+	return subject, fiber.StatusOK, nil
+}
+
+func (h *timetableHandler) findSlot(c *fiber.Ctx, action string) (*models.TimetableSlot, int, fiber.Map) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_timetable_slot_id",
+			"error":         "Invalid timetable slot ID",
+		}
+	}
+
+	slot, err := h.timetableRepo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Printf("error on %s: %v\n", action, err)
+		return nil, fiber.StatusNotFound, fiber.Map{
+			"translate_key": "error.timetable_slot_not_found",
+			"error":         "Timetable slot not found",
+		}
+	}
+
+	return slot, fiber.StatusOK, nil
+}
+
+// parseSubject reads the subject from the request body and checks its code is free.
+// On failure it returns the status and body to respond with.
+func (h *timetableHandler) parseSubject(c *fiber.Ctx, subjectID uint) (*models.Subject, int, fiber.Map) {
+	var subject models.Subject
+	if err := c.BodyParser(&subject); err != nil {
+		log.Println("error on parse subject:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	if err := subject.Validate(); err != nil {
+		log.Println("error on parse subject:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.subject_required",
+			"error":         "Subject code and name are required",
+		}
+	}
+
+	exists, err := h.subjectRepo.IsCodeExist(c.Context(), subject.Code, subjectID)
+	if err != nil {
+		log.Println("error on parse subject:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_subject",
+			"error":         "Failed to check subject",
+		}
+	}
+
+	if exists {
+		log.Println("error on parse subject: code already exists")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.subject_code_exists",
+			"error":         "A subject with this code already exists",
+		}
+	}
+
+	return &subject, fiber.StatusOK, nil
+}
+
+// parseSlot reads the timetable slot from the request body, checks its class, subject and teacher
+// belong to the school and that it does not overlap another lesson of the class or the teacher.
+// On failure it returns the status and body to respond with.
+func (h *timetableHandler) parseSlot(c *fiber.Ctx, slotID uint) (*models.TimetableSlot, int, fiber.Map) {
+	var slot models.TimetableSlot
+	if err := c.BodyParser(&slot); err != nil {
+		log.Println("error on parse timetable slot:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_request_body",
+			"error":         "Invalid request body",
+		}
+	}
+
+	if err := slot.Validate(); err != nil {
+		log.Println("error on parse timetable slot:", err)
+		return nil, fiber.StatusBadRequest, timetableError(err)
+	}
+
+	if _, err := h.classRepo.GetByID(c.Context(), slot.ClassID); err != nil {
+		log.Println("error on parse timetable slot:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.class_not_found",
+			"error":         "Class not found",
+		}
+	}
+
+	subject, err := h.subjectRepo.GetByID(c.Context(), slot.SubjectID)
+	if err != nil {
+		log.Println("error on parse timetable slot:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.subject_not_found",
+			"error":         "Subject not found",
+		}
+	}
+
+	teacher, err := h.teacherRepo.GetByTeacherID(c.Context(), slot.TeacherID)
+	if err == nil && teacher.SchoolID != subject.SchoolID {
+		err = errors.New("teacher and subject belong to different schools")
+	}
+
+	if err != nil {
+		log.Println("error on parse timetable slot:", err)
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.teacher_not_found",
+			"error":         "Teacher not found",
+		}
+	}
+
+	slot.ID = slotID
+	overlaps, err := h.timetableRepo.HasOverlap(c.Context(), &slot)
+	if err != nil {
+		log.Println("error on parse timetable slot:", err)
+		return nil, fiber.StatusInternalServerError, fiber.Map{
+			"translate_key": "error.failed_to_check_timetable_slot",
+			"error":         "Failed to check timetable slot",
+		}
+	}
+
+	if overlaps {
+		log.Println("error on parse timetable slot: overlaps another lesson")
+		return nil, fiber.StatusConflict, fiber.Map{
+			"translate_key": "error.timetable_slot_overlaps",
+			"error":         "The class or the teacher already has a lesson at this time",
+		}
+	}
+
+	return &slot, fiber.StatusOK, nil
+}
+
+// timetableError returns the response body for a timetable slot that failed validation
+func timetableError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, models.ErrTimetableSlotRequired):
+		return fiber.Map{
+			"translate_key": "error.timetable_slot_required",
+			"error":         "Class, subject and teacher are required",
+		}
+	case errors.Is(err, models.ErrTimetableInvalidWeekday):
+		return fiber.Map{
+			"translate_key": "error.invalid_weekday",
+			"error":         "Weekday must be between 1 (Monday) and 7 (Sunday)",
+		}
+	case errors.Is(err, models.ErrTimetableInvalidTimeSpan):
+		return fiber.Map{
+			"translate_key": "error.invalid_time_range",
+			"error":         "End time must be after start time",
+		}
+	default:
+		return fiber.Map{
+			"translate_key": "error.invalid_time_format",
+			"error":         "Invalid time format. Use HH:MM format.",
+		}
+	}
+}
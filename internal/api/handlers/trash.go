@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/config"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type trashHandler struct {
+	trashRepo repository.TrashRepository
+	s3Client  *s3.Client
+	s3Config  *config.S3Config
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashRepo repository.TrashRepository, s3Client *s3.Client, s3Config *config.S3Config) TrashHandler {
+	return &trashHandler{
+		trashRepo: trashRepo,
+		s3Client:  s3Client,
+		s3Config:  s3Config,
+	}
+}
+
+// GetDeleted godoc
+// @Summary Get deleted records
+// @Description Retrieve the deleted teachers, students, classes, attendances or absent requests of the current school,
+// @Description the most recently deleted first, with the date each will be purged on
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entity path string true "Kind of record (teachers, students, classes, attendances, absent-requests)"
+// @Param limit query int false "Number of records to return (max 100)" default(10)
+// @Param offset query int false "Number of records to skip" default(0)
+// @Success 200 {object} map[string]interface{} "Deleted records retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid kind of record"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/trash/{entity} [get]
+func (h *trashHandler) GetDeleted(c *fiber.Ctx) error {
+	entity, status, errBody := trashEntity(c, "get deleted records")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	items, err := h.trashRepo.GetDeleted(c.Context(), entity, limit, offset)
+	if err != nil {
+		log.Println("error on get deleted records:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_deleted_records",
+			"error":         "Failed to get deleted records",
+		})
+	}
+
+	total, err := h.trashRepo.GetDeletedCount(c.Context(), entity)
+	if err != nil {
+		log.Println("error on get deleted record count:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_get_deleted_records",
+			"error":         "Failed to get deleted record count",
+		})
+	}
+
+	retentionDays := trashRetentionDays()
+	for _, item := range items {
+		item.PurgeAt = item.DeletedAt.AddDate(0, 0, retentionDays)
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key":  "success.deleted_records_retrieved",
+		"message":        "Deleted records retrieved successfully",
+		"data":           items,
+		"total":          total,
+		"limit":          limit,
+		"offset":         offset,
+		"retention_days": retentionDays,
+	})
+}
+
+// Restore godoc
+// @Summary Restore deleted record
+// @Description Take a record out of the trash. It stays deleted when it would clash with live records, e.g. another student
+// @Description now uses its email, another attendance was recorded for the same day, or the class it belongs to is deleted too:
+// @Description the conflicts are returned so they can be resolved first, e.g. by restoring the class.
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entity path string true "Kind of record (teachers, students, classes, attendances, absent-requests)"
+// @Param id path int true "Record ID"
+// @Success 200 {object} map[string]interface{} "Record restored successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid kind of record or record ID"
+// @Failure 404 {object} map[string]interface{} "Record not found in the trash"
+// @Failure 409 {object} map[string]interface{} "Record conflicts with live records"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/trash/{entity}/record-id/{id}/restore [put]
+func (h *trashHandler) Restore(c *fiber.Ctx) error {
+	entity, status, errBody := trashEntity(c, "restore record")
+	if errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		log.Println("error on restore record:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"translate_key": "error.invalid_record_id",
+			"error":         "Invalid record ID",
+		})
+	}
+
+	conflicts, err := h.trashRepo.Restore(c.Context(), entity, uint(id))
+	if err != nil {
+		log.Println("error on restore record:", err)
+		switch {
+		case errors.Is(err, models.ErrTrashRestoreConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"translate_key": "error.restore_conflict",
+				"error":         "The record conflicts with live records and was not restored",
+				"data":          conflicts,
+			})
+		case errors.Is(err, models.ErrTrashNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"translate_key": "error.deleted_record_not_found",
+				"error":         "Record not found in the trash",
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_restore_record",
+				"error":         "Failed to restore record",
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.record_restored",
+		"message":       "Record restored successfully",
+	})
+}
+
+// Purge godoc
+// @Summary Purge deleted records
+// @Description Permanently remove the records of the current school deleted more than retention_days ago, which defaults to
+// @Description TRASH_RETENTION_DAYS (90). A purged student takes their attendances, absent requests, enrollments and guardian
+// @Description links along, and the files of purged attachments are deleted from storage. Classes and teachers still referenced by live records, e.g. a class with attendance history or
+// @Description the homeroom teacher of a class, are kept and listed. With dry_run nothing is removed.
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param retention_days query int false "Purge records deleted more than this many days ago"
+// @Param dry_run query bool false "Only report what would be purged"
+// @Success 200 {object} map[string]interface{} "Deleted records purged successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid retention period"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admins/trash/purge [post]
+func (h *trashHandler) Purge(c *fiber.Ctx) error {
+	retentionDays := trashRetentionDays()
+	if value := c.Query("retention_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			log.Println("error on purge deleted records: invalid retention days:", value)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"translate_key": "error.invalid_retention_days",
+				"error":         "retention_days must be a whole number of days, at least 1",
+			})
+		}
+		retentionDays = days
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	result, err := h.trashRepo.Purge(c.Context(), time.Now().AddDate(0, 0, -retentionDays), dryRun)
+	if err != nil {
+		log.Println("error on purge deleted records:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"translate_key": "error.failed_to_purge_deleted_records",
+			"error":         "Failed to purge deleted records",
+		})
+	}
+	result.RetentionDays = retentionDays
+
+	// the records are gone for good, so a file that fails to delete is only logged with the key to clean up
+	for _, filePath := range result.Files {
+		if err := h.s3Config.DeleteFile(h.s3Client, filePath); err != nil {
+			log.Printf("error on purge deleted records: failed to delete file %s: %v\n", filePath, err)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"translate_key": "success.deleted_records_purged",
+		"message":       "Deleted records purged successfully",
+		"data":          result,
+	})
+}
+
+// trashEntity reads the kind of record from the URL. On failure it returns the status and body to respond with.
+func trashEntity(c *fiber.Ctx, action string) (string, int, fiber.Map) {
+	entity := c.Params("entity")
+	if !models.IsValidTrashEntity(entity) {
+		log.Printf("error on %s: invalid entity %q\n", action, entity)
+		return "", fiber.StatusBadRequest, fiber.Map{
+			"translate_key": "error.invalid_trash_entity",
+			"error":         "Kind of record must be teachers, students, classes, attendances or absent-requests",
+		}
+	}
+
+	return entity, fiber.StatusOK, nil
+}
+
+// trashRetentionDays is how many days deleted records stay in the trash, set with TRASH_RETENTION_DAYS
+func trashRetentionDays() int {
+	if days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && days > 0 {
+		return days
+	}
+
+	return models.DefaultTrashRetentionDays
+}
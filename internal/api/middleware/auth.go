@@ -2,11 +2,15 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
 	"github.com/michaelwp/student_attendance/pkg"
 	"github.com/redis/go-redis/v9"
 )
@@ -55,7 +59,7 @@ func JWTMiddleware(redisClient *redis.Client) fiber.Handler {
 		}
 
 		claims, err := pkg.ValidateToken(token, jwtConfig)
-		if err != nil {
+		if err != nil || !hasSchool(claims) {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"translate_key": "error.invalid_token",
 				"error":         "Invalid or expired token",
@@ -85,6 +89,9 @@ func JWTMiddleware(redisClient *redis.Client) fiber.Handler {
 		// Store user information in context for use in handlers
 		c.Locals("userID", claims.UserID)
 		c.Locals("userType", claims.UserType)
+		if claims.SchoolID != 0 {
+			c.Locals(models.SchoolContextKey, claims.SchoolID)
+		}
 		c.Locals("claims", claims)
 
 		return c.Next()
@@ -124,7 +131,7 @@ func OptionalJWTMiddleware(redisClient *redis.Client) fiber.Handler {
 		}
 
 		claims, err := pkg.ValidateToken(token, jwtConfig)
-		if err != nil {
+		if err != nil || !hasSchool(claims) {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"translate_key": "error.invalid_token",
 				"error":         "Invalid or expired token",
@@ -153,12 +160,21 @@ func OptionalJWTMiddleware(redisClient *redis.Client) fiber.Handler {
 		// Store user information in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("userType", claims.UserType)
+		if claims.SchoolID != 0 {
+			c.Locals(models.SchoolContextKey, claims.SchoolID)
+		}
 		c.Locals("claims", claims)
 
 		return c.Next()
 	}
 }
 
+// hasSchool reports whether the token names the school its user works in. Only guardians,
+// who belong to the foundation, carry none; older tokens without one must log in again.
+func hasSchool(claims *pkg.Claims) bool {
+	return claims.SchoolID != 0 || claims.UserType == models.UserTypeGuardian.String()
+}
+
 // RequireUserType middleware requires specific user types
 func RequireUserType(allowedTypes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -183,3 +199,91 @@ func RequireUserType(allowedTypes ...string) fiber.Handler {
 		})
 	}
 }
+
+// RequirePermission middleware requires the user to hold the permission through one of their roles
+func RequirePermission(roleRepo repository.RoleRepository, permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(string)
+		userType, _ := c.Locals("userType").(string)
+		if userID == "" || userType == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"translate_key": "error.authentication_required",
+				"error":         "Authentication required",
+			})
+		}
+
+		userIDUint, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"translate_key": "error.invalid_user_id_format",
+				"error":         "Invalid user ID format",
+			})
+		}
+
+		allowed, err := roleRepo.HasPermission(c.Context(), userType, uint(userIDUint), permission)
+		if err != nil {
+			log.Println("error on check permission:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"translate_key": "error.failed_to_check_permission",
+				"error":         "Failed to check permission",
+			})
+		}
+
+		if !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"translate_key": "error.insufficient_permissions",
+				"error":         "Insufficient permissions for this operation",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSuperAdmin middleware requires the admin to be a super-admin
+func RequireSuperAdmin(adminRepo repository.AdminRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(string)
+		userType, _ := c.Locals("userType").(string)
+		if userID == "" || userType != models.UserTypeAdmin.String() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"translate_key": "error.super_admin_required",
+				"error":         "Only super-admins may perform this operation",
+			})
+		}
+
+		userIDUint, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"translate_key": "error.invalid_user_id_format",
+				"error":         "Invalid user ID format",
+			})
+		}
+
+		admin, err := adminRepo.GetByID(c.Context(), uint(userIDUint))
+		if err != nil {
+			log.Println("error on get super-admin:", err)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"translate_key": "error.super_admin_required",
+				"error":         "Only super-admins may perform this operation",
+			})
+		}
+
+		// a deactivated super-admin's token stays valid until it expires, but they may no longer act
+		if !admin.IsActive {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"translate_key": "error.account_deactivated",
+				"error":         "Account is deactivated",
+			})
+		}
+
+		if !admin.IsSuperAdmin {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"translate_key": "error.super_admin_required",
+				"error":         "Only super-admins may perform this operation",
+			})
+		}
+
+		return c.Next()
+	}
+}
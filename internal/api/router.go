@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/michaelwp/student_attendance/internal/config"
 	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/notify"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,6 +38,7 @@ func SetupRoutes(
 		S3Client:     s3Client,
 		S3Config:     s3Config,
 		RedisClient:  redisClient,
+		Notifier:     notify.NewNotifier(),
 	})
 
 	// Swagger documentation
@@ -59,108 +61,267 @@ func SetupRoutes(
 	// API v1 routes
 	api := app.Group("/api/v1")
 
+	// Every authenticated route declares the permission it needs; users hold permissions through the
+	// default role of their type and any roles an admin assigned them
+	can := func(permission string) fiber.Handler {
+		return middleware.RequirePermission(repos.Role, permission)
+	}
+
 	// Teacher routes
 	teachers := api.Group("/teachers", middleware.JWTMiddleware(redisClient))
-	teachers.Post("/", h.Teacher.Create)
-	teachers.Get("/all", h.Teacher.GetAll)
-	teachers.Get("/record-id/:id", h.Teacher.GetByID)
-	teachers.Get("/teacher-id/:teacherId", h.Teacher.GetByTeacherID)
-	teachers.Put("/record-id/:id", h.Teacher.Update)
-	teachers.Delete("/record-id/:id", h.Teacher.Delete)
-	teachers.Put("/record-id/:id/photo", h.Teacher.UploadPhoto)
-	teachers.Get("/record-id/:id/photo", h.Teacher.GetPhoto)
-	teachers.Put("/teacher-id/:teacherId/reset-password", h.Teacher.ResetPassword)
-	teachers.Put("/teacher-id/:teacherId/password", h.Teacher.UpdatePassword)
-	teachers.Get("/stats", h.Admin.GetStat)
+	teachers.Post("/", can(models.PermissionTeacherCreate), h.Teacher.Create)
+	teachers.Post("/import",
+		can(models.PermissionTeacherImport),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		h.Teacher.Import,
+	)
+	teachers.Get("/all", can(models.PermissionTeacherRead), h.Teacher.GetAll)
+	teachers.Get("/record-id/:id", can(models.PermissionTeacherRead), h.Teacher.GetByID)
+	teachers.Get("/teacher-id/:teacherId", can(models.PermissionTeacherRead), h.Teacher.GetByTeacherID)
+	teachers.Put("/record-id/:id", can(models.PermissionTeacherUpdate), h.Teacher.Update)
+	teachers.Delete("/record-id/:id", can(models.PermissionTeacherDelete), h.Teacher.Delete)
+	teachers.Put("/record-id/:id/photo", can(models.PermissionTeacherUpdate), h.Teacher.UploadPhoto)
+	teachers.Get("/record-id/:id/photo", can(models.PermissionTeacherRead), h.Teacher.GetPhoto)
+	teachers.Put("/teacher-id/:teacherId/reset-password", can(models.PermissionTeacherResetPassword), h.Teacher.ResetPassword)
+	teachers.Put("/teacher-id/:teacherId/password", can(models.PermissionTeacherUpdatePassword), h.Teacher.UpdatePassword)
+	teachers.Get("/stats", can(models.PermissionDashboardRead), h.Admin.GetStat)
 
 	// Class routes
 	classes := api.Group("/classes", middleware.JWTMiddleware(redisClient))
-	classes.Post("/", h.Class.Create)
-	classes.Get("/", h.Class.GetAll)
-	classes.Get("/:id", h.Class.GetByID)
-	classes.Get("/teacher-id/:teacherId", h.Class.GetByTeacher)
-	classes.Put("/:id", h.Class.Update)
-	classes.Delete("/:id", h.Class.Delete)
+	classes.Post("/", can(models.PermissionClassCreate), h.Class.Create)
+	classes.Get("/", can(models.PermissionClassRead), h.Class.GetAll)
+	classes.Get("/:id", can(models.PermissionClassRead), h.Class.GetByID)
+	classes.Get("/teacher-id/:teacherId", can(models.PermissionClassRead), h.Class.GetByTeacher)
+	classes.Put("/:id", can(models.PermissionClassUpdate), h.Class.Update)
+	classes.Delete("/:id", can(models.PermissionClassDelete), h.Class.Delete)
+	classes.Get("/:id/teachers", can(models.PermissionClassRead), h.Class.GetTeachers)
+	classes.Put("/:id/teachers", can(models.PermissionClassUpdate), middleware.RequireUserType(models.UserTypeAdmin.String()), h.Class.AssignTeacher)
+	classes.Delete("/:id/teachers/teacher-id/:teacherId", can(models.PermissionClassUpdate), middleware.RequireUserType(models.UserTypeAdmin.String()), h.Class.RemoveTeacher)
 
 	// Student routes
 	students := api.Group("/students", middleware.JWTMiddleware(redisClient))
-	students.Post("/", h.Student.Create)
-	students.Get("/all", h.Student.GetAll)
-	students.Get("/record-id/:id", h.Student.GetByID)
-	students.Get("/student-id/:studentId", h.Student.GetByStudentID)
-	students.Get("/class-id/:classId", h.Student.GetByClass)
-	students.Put("/record-id/:id", h.Student.Update)
-	students.Delete("/record-id/:id", h.Student.Delete)
-	students.Put("/record-id/:id/photo", h.Student.UploadPhoto)
-	students.Get("/record-id/:id/photo", h.Student.GetPhoto)
-	students.Put("/student-id/:studentId/reset-password", h.Student.ResetPassword)
-	students.Put("/student-id/:studentId/password", h.Student.UpdatePassword)
-	students.Get("/stats", h.Admin.GetStat)
+	students.Post("/", can(models.PermissionStudentCreate), h.Student.Create)
+	students.Post("/import",
+		can(models.PermissionStudentImport),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		h.Student.Import,
+	)
+	students.Post("/bulk",
+		can(models.PermissionStudentBulk),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		h.Student.Bulk,
+	)
+	students.Get("/bulk", can(models.PermissionStudentBulk), middleware.RequireUserType(models.UserTypeAdmin.String()), h.Student.GetBulkActions)
+	students.Get("/bulk/bulk-id/:id", can(models.PermissionStudentBulk), middleware.RequireUserType(models.UserTypeAdmin.String()), h.Student.GetBulkActionByID)
+	students.Get("/all", can(models.PermissionStudentRead), h.Student.GetAll)
+	students.Get("/record-id/:id", can(models.PermissionStudentRead), h.Student.GetByID)
+	students.Get("/student-id/:studentId", can(models.PermissionStudentRead), h.Student.GetByStudentID)
+	students.Get("/class-id/:classId", can(models.PermissionStudentRead), h.Student.GetByClass)
+	students.Put("/record-id/:id", can(models.PermissionStudentUpdate), h.Student.Update)
+	students.Delete("/record-id/:id", can(models.PermissionStudentDelete), h.Student.Delete)
+	students.Put("/record-id/:id/photo", can(models.PermissionStudentUpdate), h.Student.UploadPhoto)
+	students.Get("/record-id/:id/photo", can(models.PermissionStudentRead), h.Student.GetPhoto)
+	students.Get("/record-id/:id/enrollments", can(models.PermissionStudentRead), h.Student.GetEnrollments)
+	students.Post("/record-id/:id/transfer",
+		can(models.PermissionStudentTransfer),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		h.Student.Transfer,
+	)
+	students.Get("/record-id/:id/contacts", can(models.PermissionStudentContactRead), h.Student.GetContacts)
+	students.Put("/record-id/:id/contacts",
+		can(models.PermissionStudentContactManage),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		h.Student.UpdateContacts,
+	)
+	students.Put("/record-id/:id/medical-note",
+		can(models.PermissionStudentContactManage),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		h.Student.UpdateMedicalNote,
+	)
+	students.Put("/student-id/:studentId/reset-password", can(models.PermissionStudentResetPassword), h.Student.ResetPassword)
+	students.Put("/student-id/:studentId/password", can(models.PermissionStudentUpdatePassword), h.Student.UpdatePassword)
+	students.Get("/stats", can(models.PermissionDashboardRead), h.Admin.GetStat)
 
 	// Attendance routes
 	attendances := api.Group("/attendances", middleware.JWTMiddleware(redisClient))
-	attendances.Post("/", h.Attendance.Create)
-	attendances.Get("/all", h.Attendance.GetAll)
-	attendances.Get("/attendances-id/:id", h.Attendance.GetByID)
-	attendances.Get("/student-id/:studentId", h.Attendance.GetByStudent)
-	attendances.Get("/class-id/:classId", h.Attendance.GetByClass)
-	attendances.Get("/date-range", h.Attendance.GetByDateRange)
-	attendances.Put("/attendances-id/:id", h.Attendance.Update)
-	attendances.Delete("/attendances-id/:id", h.Attendance.Delete)
-
-	// Absent Request routes
-	absentRequests := api.Group("/absent-requests",
-		middleware.JWTMiddleware(redisClient),
-		middleware.RequireUserType(
-			models.UserTypeStudent.String(),
-			models.UserTypeTeacher.String()),
-	)
-	absentRequests.Post("/", h.AbsentRequest.Create)
-	absentRequests.Get("/absent-request-id/:id", h.AbsentRequest.GetByID)
-	absentRequests.Get("/student-id/:studentId", h.AbsentRequest.GetByStudent)
-	absentRequests.Get("/class-id/:classId", h.AbsentRequest.GetByClass)
-	absentRequests.Get("/absent-request-id/pending", h.AbsentRequest.GetPending)
-	absentRequests.Patch("/absent-request-id/:id/status", h.AbsentRequest.UpdateStatus)
-	absentRequests.Put("/absent-request-id/:id", h.AbsentRequest.UpdateByCurrentStudent)
-	absentRequests.Delete("/absent-request-id/:id", h.AbsentRequest.Delete)
-	absentRequests.Get("/current-student", h.AbsentRequest.GetByCurrentStudent)
-
-	// Admin routes
+	attendances.Post("/", can(models.PermissionAttendanceWrite), h.Attendance.Create)
+	attendances.Get("/all", can(models.PermissionAttendanceRead), h.Attendance.GetAll)
+	attendances.Get("/attendances-id/:id", can(models.PermissionAttendanceRead), h.Attendance.GetByID)
+	attendances.Get("/student-id/:studentId", can(models.PermissionAttendanceRead), h.Attendance.GetByStudent)
+	attendances.Get("/class-id/:classId", can(models.PermissionAttendanceRead), h.Attendance.GetByClass)
+	attendances.Get("/date-range", can(models.PermissionAttendanceRead), h.Attendance.GetByDateRange)
+	attendances.Put("/attendances-id/:id", can(models.PermissionAttendanceWrite), h.Attendance.Update)
+	attendances.Delete("/attendances-id/:id", can(models.PermissionAttendanceDelete), h.Attendance.Delete)
+
+	// Absent Request routes. Admins may read and decide requests here, e.g. from the escalation queue,
+	// while filing and changing a request stays with the student who owns it: those handlers act on
+	// the caller's own student record, so they also require a student token
+	studentOnly := middleware.RequireUserType(models.UserTypeStudent.String())
+	absentRequests := api.Group("/absent-requests", middleware.JWTMiddleware(redisClient))
+	absentRequests.Post("/", can(models.PermissionAbsentRequestCreate), studentOnly, h.AbsentRequest.Create)
+	absentRequests.Get("/absent-request-id/:id", can(models.PermissionAbsentRequestRead), h.AbsentRequest.GetByID)
+	absentRequests.Get("/student-id/:studentId", can(models.PermissionAbsentRequestRead), h.AbsentRequest.GetByStudent)
+	absentRequests.Get("/class-id/:classId", can(models.PermissionAbsentRequestRead), h.AbsentRequest.GetByClass)
+	absentRequests.Get("/date/:date", can(models.PermissionAbsentRequestRead), h.AbsentRequest.GetByDate)
+	absentRequests.Get("/absent-request-id/pending", can(models.PermissionAbsentRequestRead), h.AbsentRequest.GetPending)
+	absentRequests.Patch("/absent-request-id/:id/status", can(models.PermissionAbsentRequestDecide), h.AbsentRequest.UpdateStatus)
+	absentRequests.Put("/absent-request-id/:id", can(models.PermissionAbsentRequestAmend), studentOnly, h.AbsentRequest.UpdateByCurrentStudent)
+	absentRequests.Post("/absent-request-id/:id/withdraw", can(models.PermissionAbsentRequestAmend), studentOnly, h.AbsentRequest.Withdraw)
+	absentRequests.Post("/absent-request-id/:id/attachments", can(models.PermissionAbsentRequestAmend), studentOnly, h.AbsentRequest.UploadAttachments)
+	absentRequests.Delete("/absent-request-id/:id/attachments/:attachmentId", can(models.PermissionAbsentRequestAmend), studentOnly, h.AbsentRequest.DeleteAttachment)
+	absentRequests.Post("/absent-request-id/:id/comments", can(models.PermissionAbsentRequestComment), h.AbsentRequest.AddComment)
+	absentRequests.Get("/current-student", can(models.PermissionAbsentRequestRead), studentOnly, h.AbsentRequest.GetByCurrentStudent)
+	absentRequests.Get("/categories", can(models.PermissionAbsentRequestCategoryRead), h.Category.GetActive)
+
+	// Admin routes. They stay limited to admin tokens, as their handlers record the admin who acted
 	admins := api.Group("/admins",
 		middleware.JWTMiddleware(redisClient),
 		middleware.RequireUserType(models.UserTypeAdmin.String()),
 	)
-	admins.Post("/", h.Admin.Create)
-	admins.Put("/admin-id/:id", h.Admin.Update)
-	admins.Delete("/admin-id/:id", h.Admin.Delete)
-	admins.Get("/all", h.Admin.GetAll)
-	admins.Get("/admin-id/:id", h.Admin.GetByID)
-	admins.Get("/stats", h.Admin.GetStat)
-	admins.Get("/email/:email", h.Admin.GetByEmail)
-	admins.Put("/password", h.Admin.UpdatePassword)
-	admins.Put("/admin-id/:id/status", h.Admin.SetActiveStatus)
-	admins.Put("/admin-id/:id/reset-password", h.Admin.ResetPassword)
+	// Admins are managed by super-admins only, whatever their roles allow
+	superAdmin := middleware.RequireSuperAdmin(repos.Admin)
+	admins.Post("/", can(models.PermissionAdminManage), superAdmin, h.Admin.Create)
+	admins.Put("/admin-id/:id", can(models.PermissionAdminManage), superAdmin, h.Admin.Update)
+	admins.Delete("/admin-id/:id", can(models.PermissionAdminManage), superAdmin, h.Admin.Delete)
+	admins.Get("/all", can(models.PermissionAdminRead), h.Admin.GetAll)
+	admins.Get("/admin-id/:id", can(models.PermissionAdminRead), h.Admin.GetByID)
+	admins.Get("/stats", can(models.PermissionDashboardRead), h.Admin.GetStat)
+	admins.Get("/email/:email", can(models.PermissionAdminRead), h.Admin.GetByEmail)
+	admins.Put("/password", can(models.PermissionAdminUpdatePassword), h.Admin.UpdatePassword)
+	admins.Put("/admin-id/:id/status", can(models.PermissionAdminManage), superAdmin, h.Admin.SetActiveStatus)
+	admins.Put("/admin-id/:id/reset-password", can(models.PermissionAdminManage), superAdmin, h.Admin.ResetPassword)
+
+	// Attendance alert routes (admin dashboard)
+	admins.Get("/attendance-alerts", can(models.PermissionAttendanceAlertRead), h.AttendanceAlert.GetAll)
+	admins.Get("/attendance-alerts/alert-id/:id", can(models.PermissionAttendanceAlertRead), h.AttendanceAlert.GetByID)
+	admins.Put("/attendance-alerts/alert-id/:id/acknowledge", can(models.PermissionAttendanceAlertManage), h.AttendanceAlert.Acknowledge)
+	admins.Put("/attendance-alerts/alert-id/:id/resolve", can(models.PermissionAttendanceAlertManage), h.AttendanceAlert.Resolve)
+
+	// Admin decisions on absent requests, including overrides of decided requests
+	admins.Patch("/absent-requests/absent-request-id/:id/status", can(models.PermissionAbsentRequestOverride), h.AbsentRequest.UpdateStatus)
+	admins.Get("/absent-requests/escalated", can(models.PermissionAbsentRequestEscalation), h.AbsentRequest.GetEscalated)
+
+	// Absent request category routes
+	admins.Post("/absent-request-categories", can(models.PermissionAbsentRequestCategoryManage), h.Category.Create)
+	admins.Get("/absent-request-categories", can(models.PermissionAbsentRequestCategoryManage), h.Category.GetAll)
+	admins.Get("/absent-request-categories/category-id/:id", can(models.PermissionAbsentRequestCategoryManage), h.Category.GetByID)
+	admins.Put("/absent-request-categories/category-id/:id", can(models.PermissionAbsentRequestCategoryManage), h.Category.Update)
+	admins.Delete("/absent-request-categories/category-id/:id", can(models.PermissionAbsentRequestCategoryManage), h.Category.Delete)
+	admins.Get("/reports/absences-by-category", can(models.PermissionReportRead), h.Category.GetReport)
+
+	// Absence quota routes
+	admins.Post("/absence-quotas", can(models.PermissionAbsenceQuotaManage), h.Quota.Create)
+	admins.Get("/absence-quotas", can(models.PermissionAbsenceQuotaManage), h.Quota.GetAll)
+	admins.Get("/absence-quotas/quota-id/:id", can(models.PermissionAbsenceQuotaManage), h.Quota.GetByID)
+	admins.Put("/absence-quotas/quota-id/:id", can(models.PermissionAbsenceQuotaManage), h.Quota.Update)
+	admins.Delete("/absence-quotas/quota-id/:id", can(models.PermissionAbsenceQuotaManage), h.Quota.Delete)
+
+	// Guardian account routes
+	admins.Post("/guardians", can(models.PermissionGuardianManage), h.Guardian.Create)
+	admins.Get("/guardians", can(models.PermissionGuardianManage), h.Guardian.GetAll)
+	admins.Get("/guardians/guardian-id/:id", can(models.PermissionGuardianManage), h.Guardian.GetByID)
+	admins.Put("/guardians/guardian-id/:id", can(models.PermissionGuardianManage), h.Guardian.Update)
+	admins.Delete("/guardians/guardian-id/:id", can(models.PermissionGuardianManage), h.Guardian.Delete)
+	admins.Put("/guardians/guardian-id/:id/reset-password", can(models.PermissionGuardianManage), h.Guardian.ResetPassword)
+	admins.Post("/guardians/guardian-id/:id/students", can(models.PermissionGuardianManage), h.Guardian.LinkStudent)
+	admins.Delete("/guardians/guardian-id/:id/students/:studentId", can(models.PermissionGuardianManage), h.Guardian.UnlinkStudent)
+
+	// Role and permission routes. Roles are shared by every school, so only super-admins change them
+	admins.Get("/permissions", can(models.PermissionRoleManage), h.Role.GetPermissions)
+	admins.Post("/roles", can(models.PermissionRoleManage), superAdmin, h.Role.Create)
+	admins.Get("/roles", can(models.PermissionRoleManage), h.Role.GetAll)
+	admins.Get("/roles/role-id/:id", can(models.PermissionRoleManage), h.Role.GetByID)
+	admins.Put("/roles/role-id/:id", can(models.PermissionRoleManage), superAdmin, h.Role.Update)
+	admins.Delete("/roles/role-id/:id", can(models.PermissionRoleManage), superAdmin, h.Role.Delete)
+	admins.Get("/role-assignments/user-type/:userType/user-id/:userId", can(models.PermissionRoleManage), h.Role.GetUserRoles)
+	admins.Post("/role-assignments", can(models.PermissionRoleManage), h.Role.AssignRole)
+	admins.Delete("/role-assignments/user-type/:userType/user-id/:userId/role-id/:roleId", can(models.PermissionRoleManage), h.Role.UnassignRole)
+
+	// OneRoster roster exchange routes
+	admins.Get("/oneroster/export", can(models.PermissionOneRosterExport), h.OneRoster.Export)
+	admins.Post("/oneroster/import", can(models.PermissionOneRosterImport), h.OneRoster.Import)
+
+	// School routes. Schools are managed by super-admins only, whatever their roles allow
+	admins.Post("/schools", can(models.PermissionSchoolManage), superAdmin, h.School.Create)
+	admins.Get("/schools", can(models.PermissionSchoolManage), superAdmin, h.School.GetAll)
+	admins.Get("/schools/school-id/:id", can(models.PermissionSchoolManage), superAdmin, h.School.GetByID)
+	admins.Put("/schools/school-id/:id", can(models.PermissionSchoolManage), superAdmin, h.School.Update)
+
+	// Academic year, term and year-end promotion routes
+	admins.Post("/academic-years", can(models.PermissionAcademicYearManage), h.AcademicYear.Create)
+	admins.Get("/academic-years", can(models.PermissionAcademicYearManage), h.AcademicYear.GetAll)
+	admins.Get("/academic-years/academic-year-id/:id", can(models.PermissionAcademicYearManage), h.AcademicYear.GetByID)
+	admins.Put("/academic-years/academic-year-id/:id", can(models.PermissionAcademicYearManage), h.AcademicYear.Update)
+	admins.Delete("/academic-years/academic-year-id/:id", can(models.PermissionAcademicYearManage), h.AcademicYear.Delete)
+	admins.Get("/academic-years/academic-year-id/:id/classes", can(models.PermissionAcademicYearManage), h.AcademicYear.GetClasses)
+	admins.Post("/academic-years/academic-year-id/:id/terms", can(models.PermissionAcademicYearManage), h.AcademicYear.CreateTerm)
+	admins.Get("/academic-years/academic-year-id/:id/terms", can(models.PermissionAcademicYearManage), h.AcademicYear.GetTerms)
+	admins.Put("/terms/term-id/:id", can(models.PermissionAcademicYearManage), h.AcademicYear.UpdateTerm)
+	admins.Delete("/terms/term-id/:id", can(models.PermissionAcademicYearManage), h.AcademicYear.DeleteTerm)
+	admins.Post("/promotions", can(models.PermissionStudentPromote), h.AcademicYear.Promote)
+
+	// Class teacher role settings
+	admins.Get("/class-teacher-roles", can(models.PermissionRoleManage), h.Class.GetTeacherRoles)
+	admins.Put("/class-teacher-roles/:role", can(models.PermissionRoleManage), h.Class.UpdateTeacherRole)
+
+	// Subject and timetable slot routes
+	admins.Post("/subjects", can(models.PermissionTimetableManage), h.Timetable.CreateSubject)
+	admins.Get("/subjects", can(models.PermissionTimetableManage), h.Timetable.GetSubjects)
+	admins.Get("/subjects/subject-id/:id", can(models.PermissionTimetableManage), h.Timetable.GetSubjectByID)
+	admins.Put("/subjects/subject-id/:id", can(models.PermissionTimetableManage), h.Timetable.UpdateSubject)
+	admins.Delete("/subjects/subject-id/:id", can(models.PermissionTimetableManage), h.Timetable.DeleteSubject)
+	admins.Post("/timetable-slots", can(models.PermissionTimetableManage), h.Timetable.CreateSlot)
+	admins.Get("/timetable-slots", can(models.PermissionTimetableManage), h.Timetable.GetSlots)
+	admins.Get("/timetable-slots/slot-id/:id", can(models.PermissionTimetableManage), h.Timetable.GetSlotByID)
+	admins.Put("/timetable-slots/slot-id/:id", can(models.PermissionTimetableManage), h.Timetable.UpdateSlot)
+	admins.Delete("/timetable-slots/slot-id/:id", can(models.PermissionTimetableManage), h.Timetable.DeleteSlot)
+
+	// Trash routes: deleted records can be restored until they are purged
+	admins.Post("/trash/purge", can(models.PermissionTrashManage), h.Trash.Purge)
+	admins.Get("/trash/:entity", can(models.PermissionTrashManage), h.Trash.GetDeleted)
+	admins.Put("/trash/:entity/record-id/:id/restore", can(models.PermissionTrashManage), h.Trash.Restore)
 
 	// Authentication routes
 	auth := api.Group("/auth")
 	auth.Post("/login", h.Auth.Login)
 	auth.Post("/logout", middleware.JWTMiddleware(redisClient), h.Auth.Logout)
+	auth.Get("/permissions", middleware.JWTMiddleware(redisClient), h.Role.GetCurrentPermissions)
+	auth.Post("/switch-school",
+		middleware.JWTMiddleware(redisClient),
+		middleware.RequireUserType(models.UserTypeAdmin.String()),
+		can(models.PermissionSchoolManage),
+		middleware.RequireSuperAdmin(repos.Admin),
+		h.Auth.SwitchSchool,
+	)
 
 	// Student dashboard routes (student authentication required)
 	studentDashboard := api.Group("/student", middleware.JWTMiddleware(redisClient), middleware.RequireUserType(models.UserTypeStudent.String()))
-	studentDashboard.Get("/profile", h.Student.GetProfile)
-	studentDashboard.Put("/profile", h.Student.UpdateProfile)
-	studentDashboard.Put("/password", h.Student.UpdateCurrentPassword)
+	studentDashboard.Get("/profile", can(models.PermissionProfileSelf), h.Student.GetProfile)
+	studentDashboard.Put("/profile", can(models.PermissionProfileSelf), h.Student.UpdateProfile)
+	studentDashboard.Put("/password", can(models.PermissionProfileSelf), h.Student.UpdateCurrentPassword)
+
+	// Guardian portal routes (guardian authentication required), limited to the guardian's linked students
+	guardianPortal := api.Group("/guardian", middleware.JWTMiddleware(redisClient), middleware.RequireUserType(models.UserTypeGuardian.String()))
+	guardianPortal.Get("/profile", can(models.PermissionGuardianPortalAccess), h.Guardian.GetProfile)
+	guardianPortal.Put("/password", can(models.PermissionGuardianPortalAccess), h.Guardian.UpdateCurrentPassword)
+	guardianPortal.Get("/children", can(models.PermissionGuardianPortalAccess), h.Guardian.GetChildren)
+	guardianPortal.Get("/children/record-id/:id/attendances", can(models.PermissionGuardianPortalAccess), h.Guardian.GetChildAttendances)
+	guardianPortal.Get("/children/record-id/:id/absent-requests", can(models.PermissionGuardianPortalAccess), h.Guardian.GetChildAbsentRequests)
+	guardianPortal.Post("/children/record-id/:id/absent-requests", can(models.PermissionGuardianPortalAccess), h.AbsentRequest.CreateForGuardianChild)
 
 	// Teacher dashboard routes (teacher authentication required)
 	teacherDashboard := api.Group("/teacher", middleware.JWTMiddleware(redisClient), middleware.RequireUserType(models.UserTypeTeacher.String()))
-	teacherDashboard.Get("/profile", h.Teacher.GetProfile)
-	teacherDashboard.Put("/password", h.Teacher.UpdateCurrentPassword)
-	
+	teacherDashboard.Get("/profile", can(models.PermissionProfileSelf), h.Teacher.GetProfile)
+	teacherDashboard.Put("/profile", can(models.PermissionProfileSelf), h.Teacher.UpdateProfile)
+	teacherDashboard.Post("/profile/email/verify", can(models.PermissionProfileSelf), h.Teacher.VerifyEmail)
+	teacherDashboard.Put("/profile/photo", can(models.PermissionProfileSelf), h.Teacher.UploadCurrentPhoto)
+	teacherDashboard.Put("/password", can(models.PermissionProfileSelf), h.Teacher.UpdateCurrentPassword)
+	teacherDashboard.Get("/schedule/today", can(models.PermissionTimetableReadOwn), h.Timetable.GetMySchedule)
+
 	// Teacher absent request management
-	absentRequests.Get("/current-teacher", h.Teacher.GetAbsentRequests)
-	absentRequests.Put("/absent-request-id/:id/approve", h.Teacher.ApproveAbsentRequest)
-	absentRequests.Put("/absent-request-id/:id/reject", h.Teacher.RejectAbsentRequest)
+	absentRequests.Get("/current-teacher", can(models.PermissionAbsentRequestDecide), middleware.RequireUserType(models.UserTypeTeacher.String()), h.Teacher.GetAbsentRequests)
+	absentRequests.Put("/absent-request-id/:id/approve", can(models.PermissionAbsentRequestDecide), h.Teacher.ApproveAbsentRequest)
+	absentRequests.Put("/absent-request-id/:id/reject", can(models.PermissionAbsentRequestDecide), h.Teacher.RejectAbsentRequest)
 
 	// Public student attendance marking (no authentication required)
 	api.Post("/attendance/mark", h.Attendance.MarkAttendance)
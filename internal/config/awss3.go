@@ -55,6 +55,15 @@ func (s *S3Config) UploadFile(client *s3.Client, key string, body []byte) error
 	return err
 }
 
+// DeleteFile removes the S3 object
+func (s *S3Config) DeleteFile(client *s3.Client, key string) error {
+	_, err := client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: &s.BucketName,
+		Key:    &key,
+	})
+	return err
+}
+
 // GetObjectURL returns a non-signed public URL for the S3 object
 func (s *S3Config) GetObjectURL(key string) string {
 	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s/",
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/notify"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// AbsentRequestEscalationJob moves absent requests left pending past the SLA
+// into the admin escalation queue and notifies the admins responsible for it
+type AbsentRequestEscalationJob struct {
+	absentRequestRepo repository.AbsentRequestRepository
+	adminRepo         repository.AdminRepository
+	notifier          notify.Notifier
+	sla               time.Duration
+	interval          time.Duration
+	targetAdminID     uint
+}
+
+// NewAbsentRequestEscalationJob creates a new absent request escalation job configured from the environment.
+// Without ABSENT_REQUEST_ESCALATION_ADMIN_ID requests go to the shared queue and the active admins of their school are notified.
+func NewAbsentRequestEscalationJob(
+	absentRequestRepo repository.AbsentRequestRepository,
+	adminRepo repository.AdminRepository,
+	notifier notify.Notifier,
+) *AbsentRequestEscalationJob {
+	return &AbsentRequestEscalationJob{
+		absentRequestRepo: absentRequestRepo,
+		adminRepo:         adminRepo,
+		notifier:          notifier,
+		sla:               time.Duration(getEnvInt("ABSENT_REQUEST_ESCALATION_SLA_HOURS", 72)) * time.Hour,
+		interval:          time.Duration(getEnvInt("ABSENT_REQUEST_ESCALATION_INTERVAL_MINUTES", 60)) * time.Minute,
+		targetAdminID:     uint(getEnvInt("ABSENT_REQUEST_ESCALATION_ADMIN_ID", 0)),
+	}
+}
+
+// Start runs the job periodically until the context is cancelled
+func (j *AbsentRequestEscalationJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			escalated, err := j.Run(ctx, now)
+			if err != nil {
+				log.Println("error on absent request escalation job:", err)
+				continue
+			}
+
+			if escalated > 0 {
+				log.Printf("absent request escalation job escalated %d request(s)", escalated)
+			}
+		}
+	}
+}
+
+// Run escalates every request still pending past the SLA at the given time and returns how many were escalated
+func (j *AbsentRequestEscalationJob) Run(ctx context.Context, now time.Time) (int, error) {
+	var target *models.Admin
+	var escalatedTo *uint
+	if j.targetAdminID != 0 {
+		admin, err := j.adminRepo.GetByID(ctx, j.targetAdminID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get escalation admin: %w", err)
+		}
+		target = admin
+		escalatedTo = &j.targetAdminID
+	}
+
+	requests, err := j.absentRequestRepo.EscalateStale(ctx, now.Add(-j.sla), escalatedTo)
+	if err != nil {
+		return 0, err
+	}
+
+	// each school's requests go only to the admins of that school
+	bySchool := make(map[uint][]*models.AbsentRequest)
+	for _, request := range requests {
+		bySchool[request.SchoolID] = append(bySchool[request.SchoolID], request)
+	}
+
+	for schoolID, schoolRequests := range bySchool {
+		recipients, err := j.recipients(ctx, schoolID, target)
+		if err != nil {
+			log.Printf("error on get absent request escalation recipients of school %d: %v", schoolID, err)
+			continue
+		}
+
+		for _, request := range schoolRequests {
+			for _, recipient := range recipients {
+				if err := j.notifier.Notify(ctx, escalationMessage(recipient, request, now)); err != nil {
+					log.Println("error on notify absent request escalation:", err)
+				}
+			}
+		}
+	}
+
+	return len(requests), nil
+}
+
+// recipients returns the emails of the admins to notify about requests of the school: the configured admin
+// when they may see the school, otherwise every active admin of the school and every active super-admin.
+// Auditors only look, so they are never notified.
+func (j *AbsentRequestEscalationJob) recipients(ctx context.Context, schoolID uint, target *models.Admin) ([]string, error) {
+	if target != nil && (target.IsSuperAdmin || target.SchoolID == schoolID) {
+		return []string{target.Email}, nil
+	}
+
+	const pageSize = 100
+	schoolCtx := models.WithSchool(ctx, schoolID)
+
+	var recipients []string
+	for offset := 0; ; offset += pageSize {
+		admins, err := j.adminRepo.GetAll(schoolCtx, pageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get escalation admins: %w", err)
+		}
+
+		for _, admin := range admins {
+			if admin.IsActive && admin.Tier != models.AdminTierAuditor {
+				recipients = append(recipients, admin.Email)
+			}
+		}
+
+		if len(admins) < pageSize {
+			return recipients, nil
+		}
+	}
+}
+
+func escalationMessage(recipient string, request *models.AbsentRequest, now time.Time) notify.Message {
+	pendingHours := int(now.Sub(request.CreatedAt).Hours())
+
+	return notify.Message{
+		Recipient: recipient,
+		Subject:   fmt.Sprintf("Absent request #%d escalated", request.ID),
+		Body: fmt.Sprintf(
+			"The absent request of student %s for %s to %s has been pending for %d hours and needs an admin decision.",
+			request.StudentID,
+			request.StartDate.Format("2006-01-02"),
+			request.EndDate.Format("2006-01-02"),
+			pendingHours,
+		),
+		Data: map[string]interface{}{
+			"absent_request_id": request.ID,
+			"student_id":        request.StudentID,
+			"class_id":          request.ClassID,
+			"pending_hours":     pendingHours,
+		},
+	}
+}
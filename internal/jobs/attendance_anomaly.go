@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// AttendanceAnomalyJob compares each class's daily attendance with its rolling
+// baseline and raises an alert when it drops sharply
+type AttendanceAnomalyJob struct {
+	alertRepo       repository.AttendanceAlertRepository
+	dropThreshold   float64
+	baselineDays    int
+	minBaselineDays int
+	checkHour       int
+	interval        time.Duration
+}
+
+// NewAttendanceAnomalyJob creates a new attendance anomaly job configured from the environment
+func NewAttendanceAnomalyJob(alertRepo repository.AttendanceAlertRepository) *AttendanceAnomalyJob {
+	return &AttendanceAnomalyJob{
+		alertRepo:       alertRepo,
+		dropThreshold:   getEnvFloat("ATTENDANCE_ALERT_DROP_THRESHOLD", 25),
+		baselineDays:    getEnvInt("ATTENDANCE_ALERT_BASELINE_DAYS", 28),
+		minBaselineDays: getEnvInt("ATTENDANCE_ALERT_MIN_BASELINE_DAYS", 5),
+		checkHour:       getEnvInt("ATTENDANCE_ALERT_CHECK_HOUR", 10),
+		interval:        time.Duration(getEnvInt("ATTENDANCE_ALERT_INTERVAL_MINUTES", 30)) * time.Minute,
+	}
+}
+
+// Start runs the job periodically until the context is cancelled
+func (j *AttendanceAnomalyJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			// give students time to check in before judging the day
+			if now.Hour() < j.checkHour || isWeekend(now) {
+				continue
+			}
+
+			raised, resolved, err := j.Run(ctx, now)
+			if err != nil {
+				log.Println("error on attendance anomaly job:", err)
+				continue
+			}
+
+			if raised > 0 {
+				log.Printf("attendance anomaly job raised %d alert(s)", raised)
+			}
+
+			if resolved > 0 {
+				log.Printf("attendance anomaly job resolved %d recovered alert(s)", resolved)
+			}
+		}
+	}
+}
+
+// Run checks every class's attendance on the given date and returns the number of new alerts and of
+// alerts resolved because the class recovered. It runs again on every tick of the day, so the open alerts
+// of the day follow the attendance recorded after they were raised.
+func (j *AttendanceAnomalyJob) Run(ctx context.Context, date time.Time) (int, int, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	rates, err := j.alertRepo.GetClassAttendanceRates(ctx, day, j.baselineDays)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	raised, resolved := 0, 0
+	for _, rate := range rates {
+		// a school with no attendance on the day, e.g. on a holiday, has not taken attendance yet
+		if !rate.SchoolRecorded || rate.BaselineDays < j.minBaselineDays {
+			continue
+		}
+
+		drop := dropPercentage(rate.CurrentRate, rate.BaselineRate)
+		if drop < j.dropThreshold {
+			recovered, err := j.alertRepo.ResolveRecovered(ctx, rate, day, drop)
+			if err != nil {
+				log.Println("error on resolve attendance alert:", err)
+				continue
+			}
+
+			if recovered {
+				resolved++
+			}
+			continue
+		}
+
+		alert := &models.AttendanceAlert{
+			ClassID:        rate.ClassID,
+			AlertDate:      day,
+			TotalStudents:  rate.TotalStudents,
+			AttendanceRate: rate.CurrentRate,
+			BaselineRate:   rate.BaselineRate,
+			DropPercentage: drop,
+			Status:         models.AttendanceAlertStatusOpen,
+		}
+
+		created, err := j.alertRepo.Create(ctx, alert)
+		if err != nil {
+			log.Println("error on create attendance alert:", err)
+			continue
+		}
+
+		if created {
+			raised++
+		}
+	}
+
+	return raised, resolved, nil
+}
+
+// dropPercentage returns how far the current rate is below the baseline, as a percentage of the baseline
+func dropPercentage(current, baseline float64) float64 {
+	if baseline <= 0 || current >= baseline {
+		return 0
+	}
+
+	return (baseline - current) / baseline * 100
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
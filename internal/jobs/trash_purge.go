@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/michaelwp/student_attendance/internal/config"
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// TrashPurgeJob permanently removes the records of every school that have been deleted for longer than the retention period
+type TrashPurgeJob struct {
+	trashRepo     repository.TrashRepository
+	s3Client      *s3.Client
+	s3Config      *config.S3Config
+	retentionDays int
+	interval      time.Duration
+}
+
+// NewTrashPurgeJob creates a new trash purge job configured from the environment.
+// Purging cannot be undone, so it returns nil unless TRASH_RETENTION_DAYS is set;
+// without it deleted records are only purged through the admin purge endpoint.
+func NewTrashPurgeJob(trashRepo repository.TrashRepository, s3Client *s3.Client, s3Config *config.S3Config) *TrashPurgeJob {
+	retentionDays := getEnvInt("TRASH_RETENTION_DAYS", 0)
+	if retentionDays == 0 {
+		return nil
+	}
+
+	return &TrashPurgeJob{
+		trashRepo:     trashRepo,
+		s3Client:      s3Client,
+		s3Config:      s3Config,
+		retentionDays: retentionDays,
+		interval:      time.Duration(getEnvInt("TRASH_PURGE_INTERVAL_HOURS", 24)) * time.Hour,
+	}
+}
+
+// Start runs the job periodically until the context is cancelled
+func (j *TrashPurgeJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			result, err := j.Run(ctx, now)
+			if err != nil {
+				log.Println("error on trash purge job:", err)
+				continue
+			}
+
+			for _, entity := range models.TrashEntities {
+				if result.Purged[entity] > 0 {
+					log.Printf("trash purge job purged %d deleted %s", result.Purged[entity], entity)
+				}
+			}
+		}
+	}
+}
+
+// Run purges the records deleted more than the retention period before the given time,
+// then deletes the files of the purged attachments from storage
+func (j *TrashPurgeJob) Run(ctx context.Context, now time.Time) (*models.TrashPurgeResult, error) {
+	result, err := j.trashRepo.Purge(ctx, now.AddDate(0, 0, -j.retentionDays), false)
+	if err != nil {
+		return nil, err
+	}
+	result.RetentionDays = j.retentionDays
+
+	for _, filePath := range result.Files {
+		if err := j.s3Config.DeleteFile(j.s3Client, filePath); err != nil {
+			log.Printf("error on trash purge job: failed to delete file %s: %v", filePath, err)
+		}
+	}
+
+	return result, nil
+}
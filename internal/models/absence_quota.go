@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAbsenceQuotaInvalidTerm = errors.New("term name, start date and end date are required")
+	ErrAbsenceQuotaExceeded    = errors.New("approval would exceed the absence quota for the term")
+)
+
+// AbsenceQuota caps the days of one category a student may have approved within a term
+type AbsenceQuota struct {
+	ID         uint       `json:"id" db:"id"`
+	SchoolID   uint       `json:"school_id" db:"school_id"`
+	CategoryID uint       `json:"category_id" db:"category_id"`
+	TermName   string     `json:"term_name" db:"term_name"`
+	StartDate  time.Time  `json:"start_date" db:"start_date"`
+	EndDate    time.Time  `json:"end_date" db:"end_date"`
+	MaxDays    int        `json:"max_days" db:"max_days"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy  *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy  *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy  *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (AbsenceQuota) TableName() string {
+	return "absence_quotas"
+}
+
+// Usage reports how much of the quota a student has left given the days already approved and still pending
+func (q *AbsenceQuota) Usage(usedDays, pendingDays int) *AbsenceQuotaUsage {
+	remaining := q.MaxDays - usedDays
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &AbsenceQuotaUsage{
+		QuotaID:       q.ID,
+		CategoryID:    q.CategoryID,
+		TermName:      q.TermName,
+		StartDate:     q.StartDate,
+		EndDate:       q.EndDate,
+		MaxDays:       q.MaxDays,
+		UsedDays:      usedDays,
+		PendingDays:   pendingDays,
+		RemainingDays: remaining,
+	}
+}
+
+// AbsenceQuotaUsage is a student's allowance under a quota. Only approved requests count as used.
+type AbsenceQuotaUsage struct {
+	QuotaID       uint      `json:"quota_id"`
+	CategoryID    uint      `json:"category_id"`
+	TermName      string    `json:"term_name"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	MaxDays       int       `json:"max_days"`
+	UsedDays      int       `json:"used_days"`
+	PendingDays   int       `json:"pending_days"`
+	RemainingDays int       `json:"remaining_days"`
+}
+
+// AbsenceQuotaInput is used for creating and updating absence quotas with date string input
+type AbsenceQuotaInput struct {
+	CategoryID uint   `json:"category_id"`
+	TermName   string `json:"term_name"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	MaxDays    int    `json:"max_days"`
+}
+
+// ToAbsenceQuota converts AbsenceQuotaInput to AbsenceQuota
+func (in *AbsenceQuotaInput) ToAbsenceQuota() (*AbsenceQuota, error) {
+	termName := strings.TrimSpace(in.TermName)
+	if termName == "" || in.StartDate == "" || in.EndDate == "" {
+		return nil, ErrAbsenceQuotaInvalidTerm
+	}
+
+	startDate, err := time.Parse("2006-01-02", in.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	endDate, err := time.Parse("2006-01-02", in.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if endDate.Before(startDate) {
+		return nil, ErrAbsentRequestInvalidRange
+	}
+
+	return &AbsenceQuota{
+		CategoryID: in.CategoryID,
+		TermName:   termName,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		MaxDays:    in.MaxDays,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"strings"
 	"time"
 )
@@ -8,49 +9,161 @@ import (
 type AbsentRequestStatus string
 
 const (
-	AbsentRequestStatusPending  AbsentRequestStatus = "pending"
-	AbsentRequestStatusApproved AbsentRequestStatus = "approved"
-	AbsentRequestStatusRejected AbsentRequestStatus = "rejected"
+	AbsentRequestStatusPending   AbsentRequestStatus = "pending"
+	AbsentRequestStatusApproved  AbsentRequestStatus = "approved"
+	AbsentRequestStatusRejected  AbsentRequestStatus = "rejected"
+	AbsentRequestStatusWithdrawn AbsentRequestStatus = "withdrawn"
+)
+
+// AbsentRequestStatusesHoldingDates are the statuses of requests that keep other requests of their student
+// off their dates. Rejected and withdrawn requests free their dates up again.
+var AbsentRequestStatusesHoldingDates = []string{
+	string(AbsentRequestStatusPending),
+	string(AbsentRequestStatusApproved),
+}
+
+// HoldsDates reports whether a request with the status keeps other requests of its student off its dates
+func (s AbsentRequestStatus) HoldsDates() bool {
+	for _, status := range AbsentRequestStatusesHoldingDates {
+		if string(s) == status {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrAbsentRequestInvalidRange = errors.New("end date must not be before start date")
+	ErrAbsentRequestNoSchoolDays = errors.New("date range contains no school days")
+	ErrAbsentRequestNotPending   = errors.New("absent request is no longer pending")
+	ErrAbsentRequestChanged      = errors.New("absent request changed while it was being decided")
 )
 
 type AbsentRequest struct {
-	ID          uint                `json:"id" db:"id"`
-	StudentID   string              `json:"student_id" db:"student_id"`
-	ClassID     uint                `json:"class_id" db:"class_id"`
-	RequestDate time.Time           `json:"request_date" db:"request_date"`
-	Reason      string              `json:"reason" db:"reason"`
-	Status      AbsentRequestStatus `json:"status" db:"status"`
-	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at" db:"updated_at"`
-	ApprovedBy  *uint               `json:"approved_by" db:"approved_by"`
-	ApprovedAt  *time.Time          `json:"approved_at" db:"approved_at"`
-	RejectedBy  *uint               `json:"rejected_by" db:"rejected_by"`
-	RejectedAt  *time.Time          `json:"rejected_at" db:"rejected_at"`
-	DeletedAt   *time.Time          `json:"deleted_at" db:"deleted_at"`
-	DeletedBy   *uint               `json:"deleted_by" db:"deleted_by"`
+	ID                   uint                `json:"id" db:"id"`
+	SchoolID             uint                `json:"school_id" db:"school_id"`
+	StudentID            string              `json:"student_id" db:"student_id"`
+	ClassID              uint                `json:"class_id" db:"class_id"`
+	CategoryID           *uint               `json:"category_id" db:"category_id"`
+	RequestDate          time.Time           `json:"request_date" db:"request_date"`
+	StartDate            time.Time           `json:"start_date" db:"start_date"`
+	EndDate              time.Time           `json:"end_date" db:"end_date"`
+	ExcludeNonSchoolDays bool                `json:"exclude_non_school_days" db:"exclude_non_school_days"`
+	TotalDays            int                 `json:"total_days" db:"total_days"`
+	Reason               string              `json:"reason" db:"reason"`
+	Status               AbsentRequestStatus `json:"status" db:"status"`
+	Version              int                 `json:"version" db:"version"`
+	CreatedAt            time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at" db:"updated_at"`
+	ApprovedBy           *uint               `json:"approved_by" db:"approved_by"`
+	ApprovedAt           *time.Time          `json:"approved_at" db:"approved_at"`
+	RejectedBy           *uint               `json:"rejected_by" db:"rejected_by"`
+	RejectedAt           *time.Time          `json:"rejected_at" db:"rejected_at"`
+	AdminDecidedBy       *uint               `json:"admin_decided_by" db:"admin_decided_by"`
+	AdminDecidedAt       *time.Time          `json:"admin_decided_at" db:"admin_decided_at"`
+	ReviewerNote         *string             `json:"reviewer_note" db:"reviewer_note"`
+	EscalatedAt          *time.Time          `json:"escalated_at" db:"escalated_at"`
+	EscalatedTo          *uint               `json:"escalated_to" db:"escalated_to"`
+	WithdrawnAt          *time.Time          `json:"withdrawn_at" db:"withdrawn_at"`
+	QuotaExceededAt      *time.Time          `json:"quota_exceeded_at" db:"quota_exceeded_at"`
+	SubmittedByGuardian  *uint               `json:"submitted_by_guardian" db:"submitted_by_guardian"`
+	DeletedAt            *time.Time          `json:"deleted_at" db:"deleted_at"`
+	DeletedBy            *uint               `json:"deleted_by" db:"deleted_by"`
+
+	Category    *AbsentRequestCategory     `json:"category,omitempty"`
+	Attachments []*AbsentRequestAttachment `json:"attachments,omitempty"`
+	Comments    []*AbsentRequestComment    `json:"comments,omitempty"`
+	Versions    []*AbsentRequestVersion    `json:"versions,omitempty"`
 }
 
 func (AbsentRequest) TableName() string {
 	return "absent_requests"
 }
 
-// AbsentRequestCreate is used for creating absent requests with date string input
+// Days returns every day covered by the request, leaving out weekends when
+// non-school days are excluded
+func (ar *AbsentRequest) Days() []time.Time {
+	var days []time.Time
+	for day := ar.StartDate; !day.After(ar.EndDate); day = day.AddDate(0, 0, 1) {
+		if ar.ExcludeNonSchoolDays && IsNonSchoolDay(day) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// IsNonSchoolDay reports whether no lessons take place on the given day
+func IsNonSchoolDay(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
+
+// AbsentRequestCreate is used for creating absent requests with date string input.
+// A single-day request may still be sent with only request_date.
 type AbsentRequestCreate struct {
-	RequestDate string `json:"request_date"`
-	Reason      string `json:"reason"`
+	CategoryID           uint   `json:"category_id"`
+	RequestDate          string `json:"request_date"`
+	StartDate            string `json:"start_date"`
+	EndDate              string `json:"end_date"`
+	ExcludeNonSchoolDays *bool  `json:"exclude_non_school_days"`
+	Reason               string `json:"reason"`
 }
 
 // ToAbsentRequest converts AbsentRequestCreate to AbsentRequest
 func (arc *AbsentRequestCreate) ToAbsentRequest() (*AbsentRequest, error) {
-	// Parse date string (YYYY-MM-DD format)
-	requestDate, err := time.Parse("2006-01-02", arc.RequestDate)
+	startDateStr := arc.StartDate
+	if startDateStr == "" {
+		startDateStr = arc.RequestDate
+	}
+
+	// Parse date strings (YYYY-MM-DD format)
+	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AbsentRequest{
-		RequestDate: requestDate,
-		Reason:      strings.TrimSpace(arc.Reason),
-		Status:      AbsentRequestStatusPending,
-	}, nil
+	endDate := startDate
+	if arc.EndDate != "" {
+		endDate, err = time.Parse("2006-01-02", arc.EndDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	request := &AbsentRequest{
+		RequestDate:          startDate,
+		StartDate:            startDate,
+		EndDate:              endDate,
+		ExcludeNonSchoolDays: arc.ExcludeNonSchoolDays == nil || *arc.ExcludeNonSchoolDays,
+		Reason:               strings.TrimSpace(arc.Reason),
+		Status:               AbsentRequestStatusPending,
+	}
+
+	if arc.CategoryID != 0 {
+		request.CategoryID = &arc.CategoryID
+	}
+
+	if err := request.SetDateRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// SetDateRange sets the covered dates and recounts the days of absence
+func (ar *AbsentRequest) SetDateRange(startDate, endDate time.Time) error {
+	if endDate.Before(startDate) {
+		return ErrAbsentRequestInvalidRange
+	}
+
+	ar.RequestDate = startDate
+	ar.StartDate = startDate
+	ar.EndDate = endDate
+	ar.TotalDays = len(ar.Days())
+
+	if ar.TotalDays == 0 {
+		return ErrAbsentRequestNoSchoolDays
+	}
+
+	return nil
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTooManyAttachments is returned when new attachments would take an absent request over its limit
+var ErrTooManyAttachments = errors.New("too many attachments for the absent request")
+
+// AbsentRequestAttachment is a supporting document, such as a doctor's note, attached to an absent request
+type AbsentRequestAttachment struct {
+	ID              uint       `json:"id" db:"id"`
+	AbsentRequestID uint       `json:"absent_request_id" db:"absent_request_id"`
+	FileName        string     `json:"file_name" db:"file_name"`
+	FilePath        string     `json:"-" db:"file_path"`
+	ContentType     string     `json:"content_type" db:"content_type"`
+	FileSize        int64      `json:"file_size" db:"file_size"`
+	UploadedBy      uint       `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	URL             string     `json:"url,omitempty"`
+}
+
+func (AbsentRequestAttachment) TableName() string {
+	return "absent_request_attachments"
+}
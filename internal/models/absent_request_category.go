@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAbsentRequestCategoryRequired = errors.New("category is required")
+	ErrAbsentRequestCategoryInactive = errors.New("category is not available")
+	ErrAbsentRequestTooManyDays      = errors.New("request exceeds the maximum number of days for its category")
+	ErrAbsentRequestNoticeTooShort   = errors.New("request does not give the minimum notice for its category")
+	ErrAbsentRequestAttachmentNeeded = errors.New("category requires a supporting document")
+)
+
+// AbsentRequestCategory classifies absent requests and holds the rules requests of that kind must follow
+type AbsentRequestCategory struct {
+	ID                 uint       `json:"id" db:"id"`
+	SchoolID           uint       `json:"school_id" db:"school_id"`
+	Code               string     `json:"code" db:"code"`
+	Name               string     `json:"name" db:"name"`
+	Description        *string    `json:"description" db:"description"`
+	RequiresAttachment bool       `json:"requires_attachment" db:"requires_attachment"`
+	MaxDays            *int       `json:"max_days" db:"max_days"`
+	MinNoticeDays      int        `json:"min_notice_days" db:"min_notice_days"`
+	MarksExcused       bool       `json:"marks_excused" db:"marks_excused"`
+	IsActive           bool       `json:"is_active" db:"is_active"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy          *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy          *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy          *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (AbsentRequestCategory) TableName() string {
+	return "absent_request_categories"
+}
+
+// ValidateRequest checks the length and notice period of a request against the category rules.
+// today is the date the request is being submitted on.
+func (c *AbsentRequestCategory) ValidateRequest(request *AbsentRequest, today time.Time) error {
+	if !c.IsActive {
+		return ErrAbsentRequestCategoryInactive
+	}
+
+	if c.MaxDays != nil && request.TotalDays > *c.MaxDays {
+		return ErrAbsentRequestTooManyDays
+	}
+
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	startDate := time.Date(request.StartDate.Year(), request.StartDate.Month(), request.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	if c.MinNoticeDays > 0 && startDate.Before(today.AddDate(0, 0, c.MinNoticeDays)) {
+		return ErrAbsentRequestNoticeTooShort
+	}
+
+	return nil
+}
+
+// AbsentCategoryReport breaks down absent requests of one category by status
+type AbsentCategoryReport struct {
+	CategoryID       *uint  `json:"category_id" db:"category_id"`
+	CategoryCode     string `json:"category_code" db:"category_code"`
+	CategoryName     string `json:"category_name" db:"category_name"`
+	TotalRequests    int    `json:"total_requests" db:"total_requests"`
+	PendingRequests  int    `json:"pending_requests" db:"pending_requests"`
+	ApprovedRequests int    `json:"approved_requests" db:"approved_requests"`
+	RejectedRequests int    `json:"rejected_requests" db:"rejected_requests"`
+	ApprovedDays     int    `json:"approved_days" db:"approved_days"`
+}
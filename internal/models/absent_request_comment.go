@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AbsentRequestComment is a message in the thread between a student and the reviewers of an absent request
+type AbsentRequestComment struct {
+	ID              uint      `json:"id" db:"id"`
+	AbsentRequestID uint      `json:"absent_request_id" db:"absent_request_id"`
+	AuthorType      string    `json:"author_type" db:"author_type"`
+	AuthorID        uint      `json:"author_id" db:"author_id"`
+	AuthorName      string    `json:"author_name" db:"author_name"`
+	Body            string    `json:"body" db:"body"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+func (AbsentRequestComment) TableName() string {
+	return "absent_request_comments"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestAbsentRequestStatusHoldsDates(t *testing.T) {
+	tests := []struct {
+		status AbsentRequestStatus
+		want   bool
+	}{
+		{status: AbsentRequestStatusPending, want: true},
+		{status: AbsentRequestStatusApproved, want: true},
+		{status: AbsentRequestStatusRejected},
+		{status: AbsentRequestStatusWithdrawn},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.HoldsDates(); got != tt.want {
+				t.Errorf("HoldsDates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AbsentRequestVersion is an absent request as it stood before a student amendment superseded it
+type AbsentRequestVersion struct {
+	ID                   uint      `json:"id" db:"id"`
+	AbsentRequestID      uint      `json:"absent_request_id" db:"absent_request_id"`
+	Version              int       `json:"version" db:"version"`
+	CategoryID           *uint     `json:"category_id" db:"category_id"`
+	StartDate            time.Time `json:"start_date" db:"start_date"`
+	EndDate              time.Time `json:"end_date" db:"end_date"`
+	ExcludeNonSchoolDays bool      `json:"exclude_non_school_days" db:"exclude_non_school_days"`
+	TotalDays            int       `json:"total_days" db:"total_days"`
+	Reason               string    `json:"reason" db:"reason"`
+	SupersededBy         *uint     `json:"superseded_by" db:"superseded_by"`
+	SupersededAt         time.Time `json:"superseded_at" db:"superseded_at"`
+}
+
+func (AbsentRequestVersion) TableName() string {
+	return "absent_request_versions"
+}
@@ -0,0 +1,282 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Reasons an enrollment starts or ends
+const (
+	EnrollmentReasonEnrolled    = "enrolled"
+	EnrollmentReasonMoved       = "moved"
+	EnrollmentReasonPromoted    = "promoted"
+	EnrollmentReasonGraduated   = "graduated"
+	EnrollmentReasonTransferred = "transferred"
+)
+
+var (
+	ErrAcademicYearInvalidPeriod = errors.New("name, start date and end date are required")
+	ErrPromotionNoClasses        = errors.New("at least one class must be mapped")
+	ErrPromotionDuplicateClass   = errors.New("a class may only be mapped once")
+	ErrTransferSameClass         = errors.New("the student is already in the class")
+	ErrTransferBeforeEnrollment  = errors.New("the transfer date is before the student joined their current class")
+	ErrTransferFutureDate        = errors.New("the transfer date is in the future")
+	ErrPromotionBeforeEnrollment = errors.New("the promotion date is before some students joined their current class")
+)
+
+// AcademicYear is a school year. Classes belong to one, and a school has at most one current year.
+type AcademicYear struct {
+	ID        uint       `json:"id" db:"id"`
+	SchoolID  uint       `json:"school_id" db:"school_id"`
+	Name      string     `json:"name" db:"name"`
+	StartDate time.Time  `json:"start_date" db:"start_date"`
+	EndDate   time.Time  `json:"end_date" db:"end_date"`
+	IsCurrent bool       `json:"is_current" db:"is_current"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (AcademicYear) TableName() string {
+	return "academic_years"
+}
+
+// AcademicYearInput is used for creating and updating academic years with date string input
+type AcademicYearInput struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	IsCurrent bool   `json:"is_current"`
+}
+
+// ToAcademicYear converts AcademicYearInput to AcademicYear
+func (in *AcademicYearInput) ToAcademicYear() (*AcademicYear, error) {
+	name, startDate, endDate, err := parsePeriod(in.Name, in.StartDate, in.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if !endDate.After(startDate) {
+		return nil, ErrAbsentRequestInvalidRange
+	}
+
+	return &AcademicYear{
+		Name:      name,
+		StartDate: startDate,
+		EndDate:   endDate,
+		IsCurrent: in.IsCurrent,
+	}, nil
+}
+
+// Term is a part of an academic year, such as a semester or quarter
+type Term struct {
+	ID             uint       `json:"id" db:"id"`
+	AcademicYearID uint       `json:"academic_year_id" db:"academic_year_id"`
+	Name           string     `json:"name" db:"name"`
+	StartDate      time.Time  `json:"start_date" db:"start_date"`
+	EndDate        time.Time  `json:"end_date" db:"end_date"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy      *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy      *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy      *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (Term) TableName() string {
+	return "terms"
+}
+
+// TermInput is used for creating and updating terms with date string input
+type TermInput struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// ToTerm converts TermInput to Term
+func (in *TermInput) ToTerm() (*Term, error) {
+	name, startDate, endDate, err := parsePeriod(in.Name, in.StartDate, in.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if endDate.Before(startDate) {
+		return nil, ErrAbsentRequestInvalidRange
+	}
+
+	return &Term{
+		Name:      name,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}, nil
+}
+
+func parsePeriod(name, start, end string) (string, time.Time, time.Time, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || start == "" || end == "" {
+		return "", time.Time{}, time.Time{}, ErrAcademicYearInvalidPeriod
+	}
+
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+
+	return name, startDate, endDate, nil
+}
+
+// Enrollment records that a student was in a class from StartDate up to, but not including, EndDate.
+// The enrollment without an end date is the class the student is in now.
+type Enrollment struct {
+	ID             uint       `json:"id" db:"id"`
+	SchoolID       uint       `json:"school_id" db:"school_id"`
+	StudentID      uint       `json:"student_id" db:"student_id"`
+	ClassID        uint       `json:"class_id" db:"class_id"`
+	ClassName      string     `json:"class_name" db:"class_name"`
+	AcademicYearID *uint      `json:"academic_year_id" db:"academic_year_id"`
+	StartDate      time.Time  `json:"start_date" db:"start_date"`
+	EndDate        *time.Time `json:"end_date" db:"end_date"`
+	StartReason    string     `json:"start_reason" db:"start_reason"`
+	EndReason      *string    `json:"end_reason" db:"end_reason"`
+	Note           *string    `json:"note" db:"note"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	CreatedBy      *uint      `json:"created_by" db:"created_by"`
+}
+
+func (Enrollment) TableName() string {
+	return "enrollments"
+}
+
+// TransferInput moves a student to another class from an effective date
+type TransferInput struct {
+	ClassID       uint    `json:"class_id"`
+	EffectiveDate string  `json:"effective_date"`
+	Note          *string `json:"note"`
+}
+
+// Transfer is a parsed TransferInput. The effective date is the first day in the new class.
+type Transfer struct {
+	StudentID     uint
+	ClassID       uint
+	EffectiveDate time.Time
+	Note          *string
+	TransferredBy uint
+}
+
+// ToTransfer converts TransferInput to Transfer. The effective date defaults to today.
+func (in *TransferInput) ToTransfer(studentID, transferredBy uint, today time.Time) (*Transfer, error) {
+	transfer := &Transfer{
+		StudentID:     studentID,
+		ClassID:       in.ClassID,
+		EffectiveDate: today,
+		TransferredBy: transferredBy,
+	}
+
+	if in.EffectiveDate != "" {
+		date, err := time.Parse("2006-01-02", in.EffectiveDate)
+		if err != nil {
+			return nil, err
+		}
+		transfer.EffectiveDate = date
+	}
+
+	if transfer.EffectiveDate.After(today) {
+		return nil, ErrTransferFutureDate
+	}
+
+	if in.Note != nil {
+		note := strings.TrimSpace(*in.Note)
+		if note != "" {
+			transfer.Note = &note
+		}
+	}
+
+	return transfer, nil
+}
+
+// ClassPromotion maps a class to the class its students move to.
+// Students of a class without a successor graduate.
+type ClassPromotion struct {
+	FromClassID uint  `json:"from_class_id"`
+	ToClassID   *uint `json:"to_class_id"`
+}
+
+// PromotionInput is the year-end promotion requested by an admin with date string input
+type PromotionInput struct {
+	FromAcademicYearID uint             `json:"from_academic_year_id"`
+	ToAcademicYearID   uint             `json:"to_academic_year_id"`
+	Date               string           `json:"date"`
+	Classes            []ClassPromotion `json:"classes"`
+	DryRun             bool             `json:"dry_run"`
+}
+
+// ToPromotion converts PromotionInput to Promotion. Date is left zero when not given.
+func (in *PromotionInput) ToPromotion() (*Promotion, error) {
+	if len(in.Classes) == 0 {
+		return nil, ErrPromotionNoClasses
+	}
+
+	seen := make(map[uint]bool, len(in.Classes))
+	for _, class := range in.Classes {
+		if seen[class.FromClassID] {
+			return nil, ErrPromotionDuplicateClass
+		}
+		seen[class.FromClassID] = true
+	}
+
+	promotion := &Promotion{
+		FromAcademicYearID: in.FromAcademicYearID,
+		ToAcademicYearID:   in.ToAcademicYearID,
+		Classes:            in.Classes,
+		DryRun:             in.DryRun,
+	}
+
+	if in.Date != "" {
+		date, err := time.Parse("2006-01-02", in.Date)
+		if err != nil {
+			return nil, err
+		}
+		promotion.Date = date
+	}
+
+	return promotion, nil
+}
+
+// Promotion moves the students of each class to its successor on Date, the first day of their new class
+type Promotion struct {
+	FromAcademicYearID uint
+	ToAcademicYearID   uint
+	Date               time.Time
+	Classes            []ClassPromotion
+	DryRun             bool
+}
+
+// ClassPromotionResult reports how many students a class mapping moved. EnrolledAfterDate lists the
+// students who joined the class after the promotion date, which keep the whole promotion from running.
+type ClassPromotionResult struct {
+	FromClassID       uint   `json:"from_class_id"`
+	ToClassID         *uint  `json:"to_class_id"`
+	Students          int    `json:"students"`
+	EnrolledAfterDate []uint `json:"enrolled_after_date,omitempty"`
+}
+
+// PromotionResult reports the outcome of a promotion. A dry run reports what would have changed.
+type PromotionResult struct {
+	FromAcademicYearID uint                   `json:"from_academic_year_id"`
+	ToAcademicYearID   uint                   `json:"to_academic_year_id"`
+	Date               time.Time              `json:"date"`
+	DryRun             bool                   `json:"dry_run"`
+	Promoted           int                    `json:"promoted"`
+	Graduated          int                    `json:"graduated"`
+	Classes            []ClassPromotionResult `json:"classes"`
+}
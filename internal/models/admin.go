@@ -1,15 +1,35 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
+// Admin tiers. Super-admins manage admins and schools and see every school, admins run their school
+// and auditors may only look.
+const (
+	AdminTierSuperAdmin = "super_admin"
+	AdminTierAdmin      = "admin"
+	AdminTierAuditor    = "auditor"
+)
+
+var (
+	ErrInvalidAdminTier = errors.New("tier must be super_admin, admin or auditor")
+	ErrLastSuperAdmin   = errors.New("the last active super-admin cannot be deactivated, demoted or deleted")
+)
+
+// Admin is an account of the admin dashboard. IsSuperAdmin follows Tier.
 type Admin struct {
-	ID        uint       `json:"id" db:"id"`
-	Email     string     `json:"email" db:"email"`
-	Password  string     `json:"password" db:"password"`
-	LastLogin *time.Time `json:"last_login" db:"last_login"`
-	IsActive  bool       `json:"is_active" db:"is_active"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	ID           uint       `json:"id" db:"id"`
+	SchoolID     uint       `json:"school_id" db:"school_id"`
+	Email        string     `json:"email" db:"email"`
+	Password     string     `json:"password" db:"password"`
+	LastLogin    *time.Time `json:"last_login" db:"last_login"`
+	IsActive     bool       `json:"is_active" db:"is_active"`
+	Tier         string     `json:"tier" db:"tier"`
+	IsSuperAdmin bool       `json:"is_super_admin" db:"is_super_admin"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type AdminStats struct {
@@ -21,3 +41,13 @@ type AdminStats struct {
 func (Admin) TableName() string {
 	return "admins"
 }
+
+// IsValidAdminTier reports whether tier is one of the admin tiers
+func IsValidAdminTier(tier string) bool {
+	switch tier {
+	case AdminTierSuperAdmin, AdminTierAdmin, AdminTierAuditor:
+		return true
+	}
+
+	return false
+}
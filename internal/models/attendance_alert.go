@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type AttendanceAlertStatus string
+
+const (
+	AttendanceAlertStatusOpen         AttendanceAlertStatus = "open"
+	AttendanceAlertStatusAcknowledged AttendanceAlertStatus = "acknowledged"
+	AttendanceAlertStatusResolved     AttendanceAlertStatus = "resolved"
+)
+
+// AttendanceAlert records a sharp drop of a class's daily attendance against its rolling baseline
+type AttendanceAlert struct {
+	ID             uint                  `json:"id" db:"id"`
+	ClassID        uint                  `json:"class_id" db:"class_id"`
+	ClassName      string                `json:"class_name" db:"class_name"`
+	AlertDate      time.Time             `json:"alert_date" db:"alert_date"`
+	TotalStudents  int                   `json:"total_students" db:"total_students"`
+	AttendanceRate float64               `json:"attendance_rate" db:"attendance_rate"`
+	BaselineRate   float64               `json:"baseline_rate" db:"baseline_rate"`
+	DropPercentage float64               `json:"drop_percentage" db:"drop_percentage"`
+	Status         AttendanceAlertStatus `json:"status" db:"status"`
+	AcknowledgedBy *uint                 `json:"acknowledged_by" db:"acknowledged_by"`
+	AcknowledgedAt *time.Time            `json:"acknowledged_at" db:"acknowledged_at"`
+	ResolvedBy     *uint                 `json:"resolved_by" db:"resolved_by"`
+	ResolvedAt     *time.Time            `json:"resolved_at" db:"resolved_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// ClassAttendanceRate holds a class's attendance rate for a day together with its rolling baseline.
+// SchoolRecorded tells whether the class's school recorded any attendance on the day.
+type ClassAttendanceRate struct {
+	ClassID        uint    `json:"class_id" db:"class_id"`
+	TotalStudents  int     `json:"total_students" db:"total_students"`
+	CurrentRate    float64 `json:"current_rate" db:"current_rate"`
+	BaselineRate   float64 `json:"baseline_rate" db:"baseline_rate"`
+	BaselineDays   int     `json:"baseline_days" db:"baseline_days"`
+	SchoolRecorded bool    `json:"school_recorded" db:"school_recorded"`
+}
+
+func (AttendanceAlert) TableName() string {
+	return "attendance_alerts"
+}
@@ -6,8 +6,9 @@ const (
 	UserTypeAdmin UserType = iota
 	UserTypeStudent
 	UserTypeTeacher
+	UserTypeGuardian
 )
 
 func (u UserType) String() string {
-	return []string{"admin", "student", "teacher"}[u]
+	return []string{"admin", "student", "teacher", "guardian"}[u]
 }
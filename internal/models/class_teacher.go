@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Roles a teacher can hold in a class
+const (
+	ClassTeacherRoleHomeroom   = "homeroom"
+	ClassTeacherRoleCoHomeroom = "co_homeroom"
+	ClassTeacherRoleAssistant  = "assistant"
+)
+
+var (
+	ErrInvalidClassTeacherRole = errors.New("role must be homeroom, co_homeroom or assistant")
+	ErrHomeroomTeacherRequired = errors.New("a class must keep its homeroom teacher")
+)
+
+// ClassTeacherRoles lists the class teacher roles in order of seniority
+var ClassTeacherRoles = []string{
+	ClassTeacherRoleHomeroom,
+	ClassTeacherRoleCoHomeroom,
+	ClassTeacherRoleAssistant,
+}
+
+// IsValidClassTeacherRole reports whether role is one of the class teacher roles
+func IsValidClassTeacherRole(role string) bool {
+	for _, r := range ClassTeacherRoles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClassTeacher assigns a teacher to a class in a role
+type ClassTeacher struct {
+	ID        uint      `json:"id" db:"id"`
+	SchoolID  uint      `json:"school_id" db:"school_id"`
+	ClassID   uint      `json:"class_id" db:"class_id"`
+	TeacherID string    `json:"teacher_id" db:"teacher_id"`
+	Role      string    `json:"role" db:"role"`
+	FirstName string    `json:"first_name,omitempty" db:"first_name"`
+	LastName  string    `json:"last_name,omitempty" db:"last_name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreatedBy *uint     `json:"created_by" db:"created_by"`
+}
+
+func (ClassTeacher) TableName() string {
+	return "class_teachers"
+}
+
+// ClassTeacherRolePermission holds what teachers of a role may do in their classes
+type ClassTeacherRolePermission struct {
+	Role                     string     `json:"role" db:"role"`
+	CanDecideAbsentRequests  bool       `json:"can_decide_absent_requests" db:"can_decide_absent_requests"`
+	CanCommentAbsentRequests bool       `json:"can_comment_absent_requests" db:"can_comment_absent_requests"`
+	UpdatedAt                *time.Time `json:"updated_at" db:"updated_at"`
+	UpdatedBy                *uint      `json:"updated_by" db:"updated_by"`
+}
+
+func (ClassTeacherRolePermission) TableName() string {
+	return "class_teacher_role_permissions"
+}
+
+// DefaultClassTeacherRolePermission returns what a role allows until a school configures it.
+// Homeroom and co-homeroom teachers may decide absent requests, assistants may only discuss them.
+func DefaultClassTeacherRolePermission(role string) *ClassTeacherRolePermission {
+	return &ClassTeacherRolePermission{
+		Role:                     role,
+		CanDecideAbsentRequests:  role != ClassTeacherRoleAssistant,
+		CanCommentAbsentRequests: true,
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Guardian is a parent or guardian who follows and reports absences for their linked students
+type Guardian struct {
+	ID        uint       `json:"id" db:"id"`
+	FirstName string     `json:"first_name" db:"first_name"`
+	LastName  string     `json:"last_name" db:"last_name"`
+	Email     string     `json:"email" db:"email"`
+	Phone     *string    `json:"phone" db:"phone"`
+	Password  string     `json:"-" db:"password"`
+	IsActive  bool       `json:"is_active" db:"is_active"`
+	LastLogin *time.Time `json:"last_login" db:"last_login"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (Guardian) TableName() string {
+	return "guardians"
+}
+
+// GuardianInput is used for creating and updating guardians. The password is only read on create;
+// one is generated when it is left empty.
+type GuardianInput struct {
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Email     string  `json:"email"`
+	Phone     *string `json:"phone"`
+	Password  string  `json:"password"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+// GuardianStudent links a guardian to a student they may act for
+type GuardianStudent struct {
+	GuardianID   uint      `json:"guardian_id" db:"guardian_id"`
+	StudentID    uint      `json:"student_id" db:"student_id"`
+	Relationship *string   `json:"relationship" db:"relationship"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	CreatedBy    *uint     `json:"created_by" db:"created_by"`
+}
+
+func (GuardianStudent) TableName() string {
+	return "guardian_students"
+}
+
+// GuardianChild is a student linked to a guardian, as the guardian sees them
+type GuardianChild struct {
+	ID           uint    `json:"id" db:"id"`
+	StudentID    string  `json:"student_id" db:"student_id"`
+	FirstName    string  `json:"first_name" db:"first_name"`
+	LastName     string  `json:"last_name" db:"last_name"`
+	ClassID      uint    `json:"class_id" db:"class_id"`
+	ClassName    string  `json:"class_name" db:"class_name"`
+	IsActive     bool    `json:"is_active" db:"is_active"`
+	Relationship *string `json:"relationship" db:"relationship"`
+}
@@ -0,0 +1,54 @@
+package models
+
+// ImportRowError describes a problem with one row of an imported spreadsheet. Row numbers
+// count the header line so they match what the spreadsheet shows.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportCredential is the initial password generated for an imported account.
+// It is only ever returned by the import that created the account.
+type ImportCredential struct {
+	Row       int    `json:"row"`
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+// StudentImportRow is a student read from an import file, with the name of the class to enroll them in
+type StudentImportRow struct {
+	Row       int
+	ClassName string
+	Student   Student
+}
+
+// StudentImportResult summarises a student import. Nothing is created when it has errors or is a dry run.
+type StudentImportResult struct {
+	DryRun      bool               `json:"dry_run"`
+	Rows        int                `json:"rows"`
+	Created     int                `json:"created"`
+	Errors      []ImportRowError   `json:"errors"`
+	Credentials []ImportCredential `json:"credentials,omitempty"`
+}
+
+// TeacherImportRow is a teacher read from an import file, with the name of the class to make them homeroom teacher of, if any
+type TeacherImportRow struct {
+	Row               int
+	HomeroomClassName string
+	HomeroomClassID   uint
+	Teacher           Teacher
+}
+
+// TeacherImportResult summarises a teacher import. Nothing is created when it has errors or is a dry run.
+type TeacherImportResult struct {
+	DryRun            bool               `json:"dry_run"`
+	Rows              int                `json:"rows"`
+	Created           int                `json:"created"`
+	HomeroomsAssigned int                `json:"homerooms_assigned"`
+	Errors            []ImportRowError   `json:"errors"`
+	Credentials       []ImportCredential `json:"credentials,omitempty"`
+}
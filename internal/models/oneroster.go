@@ -0,0 +1,68 @@
+package models
+
+// RosterClass is a class together with the sourcedId it is exchanged under in OneRoster bundles
+type RosterClass struct {
+	Class
+	SourcedID string `json:"sourced_id" db:"sourced_id"`
+}
+
+// RosterAttendance is an attendance record together with its class sourcedId
+type RosterAttendance struct {
+	Attendance
+	ClassSourcedID string `json:"class_sourced_id" db:"class_sourced_id"`
+}
+
+// RosterEnrollment is a student's enrollment together with the sourcedIds of its class and student
+type RosterEnrollment struct {
+	Enrollment
+	ClassSourcedID   string `json:"class_sourced_id" db:"class_sourced_id"`
+	StudentSourcedID string `json:"student_sourced_id" db:"student_sourced_id"`
+}
+
+// RosterUser is a teacher or student read from a OneRoster users file
+type RosterUser struct {
+	Row            int
+	SourcedID      string
+	GivenName      string
+	FamilyName     string
+	Email          string
+	Phone          *string
+	Password       string
+	IsActive       bool
+	ClassSourcedID string
+}
+
+// RosterImportClass is a class read from a OneRoster classes file
+type RosterImportClass struct {
+	Row              int
+	SourcedID        string
+	Title            string
+	TeacherSourcedID string
+}
+
+// Roster holds everything parsed from a OneRoster bundle, ready to be upserted
+type Roster struct {
+	Teachers []*RosterUser
+	Classes  []*RosterImportClass
+	Students []*RosterUser
+	Errors   []RosterImportError
+}
+
+// RosterImportError describes a row that could not be imported
+type RosterImportError struct {
+	File      string `json:"file"`
+	Row       int    `json:"row"`
+	SourcedID string `json:"sourced_id"`
+	Error     string `json:"error"`
+}
+
+// RosterImportResult summarises a OneRoster import
+type RosterImportResult struct {
+	TeachersCreated int                 `json:"teachers_created"`
+	TeachersUpdated int                 `json:"teachers_updated"`
+	ClassesCreated  int                 `json:"classes_created"`
+	ClassesUpdated  int                 `json:"classes_updated"`
+	StudentsCreated int                 `json:"students_created"`
+	StudentsUpdated int                 `json:"students_updated"`
+	Errors          []RosterImportError `json:"errors"`
+}
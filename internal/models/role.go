@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Permission codes checked by the API routes
+const (
+	PermissionTeacherRead           = "teacher.read"
+	PermissionTeacherCreate         = "teacher.create"
+	PermissionTeacherUpdate         = "teacher.update"
+	PermissionTeacherDelete         = "teacher.delete"
+	PermissionTeacherResetPassword  = "teacher.reset_password"
+	PermissionTeacherUpdatePassword = "teacher.update_password"
+
+	PermissionClassRead   = "class.read"
+	PermissionClassCreate = "class.create"
+	PermissionClassUpdate = "class.update"
+	PermissionClassDelete = "class.delete"
+
+	PermissionStudentRead           = "student.read"
+	PermissionStudentCreate         = "student.create"
+	PermissionStudentUpdate         = "student.update"
+	PermissionStudentDelete         = "student.delete"
+	PermissionStudentResetPassword  = "student.reset_password"
+	PermissionStudentUpdatePassword = "student.update_password"
+
+	PermissionAttendanceRead   = "attendance.read"
+	PermissionAttendanceWrite  = "attendance.write"
+	PermissionAttendanceDelete = "attendance.delete"
+
+	PermissionAbsentRequestRead       = "absent_request.read"
+	PermissionAbsentRequestCreate     = "absent_request.create"
+	PermissionAbsentRequestAmend      = "absent_request.amend"
+	PermissionAbsentRequestComment    = "absent_request.comment"
+	PermissionAbsentRequestDecide     = "absent_request.decide"
+	PermissionAbsentRequestOverride   = "absent_request.override"
+	PermissionAbsentRequestEscalation = "absent_request.escalation"
+
+	PermissionAbsentRequestCategoryRead   = "absent_request_category.read"
+	PermissionAbsentRequestCategoryManage = "absent_request_category.manage"
+	PermissionAbsenceQuotaManage          = "absence_quota.manage"
+
+	PermissionAttendanceAlertRead   = "attendance_alert.read"
+	PermissionAttendanceAlertManage = "attendance_alert.manage"
+	PermissionReportRead            = "report.read"
+	PermissionDashboardRead         = "dashboard.read"
+
+	PermissionAdminRead           = "admin.read"
+	PermissionAdminManage         = "admin.manage"
+	PermissionAdminUpdatePassword = "admin.update_password"
+
+	PermissionGuardianManage       = "guardian.manage"
+	PermissionOneRosterExport      = "oneroster.export"
+	PermissionOneRosterImport      = "oneroster.import"
+	PermissionRoleManage           = "role.manage"
+	PermissionProfileSelf          = "profile.self"
+	PermissionGuardianPortalAccess = "guardian_portal.access"
+	PermissionSchoolManage         = "school.manage"
+	PermissionAcademicYearManage   = "academic_year.manage"
+	PermissionStudentPromote       = "student.promote"
+	PermissionStudentTransfer      = "student.transfer"
+	PermissionTimetableManage      = "timetable.manage"
+	PermissionTimetableReadOwn     = "timetable.read_own"
+	PermissionStudentImport        = "student.import"
+	PermissionTeacherImport        = "teacher.import"
+	PermissionStudentBulk          = "student.bulk"
+	PermissionTrashManage          = "trash.manage"
+	PermissionStudentContactRead   = "student_contact.read"
+	PermissionStudentContactManage = "student_contact.manage"
+)
+
+var (
+	ErrRoleNameRequired  = errors.New("role name is required")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrDefaultRoleLocked = errors.New("default roles cannot be renamed or deleted")
+	ErrRoleManageLockout = errors.New("the default admin role must keep the role.manage permission")
+	ErrInvalidUserType   = errors.New("invalid user type")
+)
+
+// Permission is a single action a role may allow
+type Permission struct {
+	ID          uint    `json:"id" db:"id"`
+	Code        string  `json:"code" db:"code"`
+	Description *string `json:"description" db:"description"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role groups permissions. A role with DefaultFor set is held by every user of that type.
+type Role struct {
+	ID          uint       `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description" db:"description"`
+	DefaultFor  *string    `json:"default_for" db:"default_for"`
+	Permissions []string   `json:"permissions"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy   *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy   *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy   *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RoleInput is used for creating and updating roles
+type RoleInput struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRole assigns a role to a user on top of the default role of their type
+type UserRole struct {
+	UserType  string    `json:"user_type" db:"user_type"`
+	UserID    uint      `json:"user_id" db:"user_id"`
+	RoleID    uint      `json:"role_id" db:"role_id"`
+	RoleName  string    `json:"role_name" db:"role_name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreatedBy *uint     `json:"created_by" db:"created_by"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// ParseUserType returns the user type named by s
+func ParseUserType(s string) (UserType, error) {
+	for _, userType := range []UserType{UserTypeAdmin, UserTypeStudent, UserTypeTeacher, UserTypeGuardian} {
+		if userType.String() == s {
+			return userType, nil
+		}
+	}
+
+	return 0, ErrInvalidUserType
+}
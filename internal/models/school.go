@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type schoolContextKey struct{}
+
+type allSchoolsContextKey struct{}
+
+// SchoolContextKey is the request context key holding the ID of the school the user works in.
+// Repositories limit every query to that school. A context without one reaches no school at all,
+// unless it was made by WithAllSchools.
+var SchoolContextKey = schoolContextKey{}
+
+// WithSchool limits the repositories called with the returned context to the school
+func WithSchool(ctx context.Context, schoolID uint) context.Context {
+	return context.WithValue(ctx, SchoolContextKey, schoolID)
+}
+
+// WithAllSchools lets the repositories called with the returned context reach every school.
+// It is only for work done on behalf of the super-admin across the deployment: logging in before
+// the user's school is known, guardians reaching their own account, background jobs and command line tools.
+func WithAllSchools(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allSchoolsContextKey{}, true)
+}
+
+// SchoolFromContext returns the school ctx is limited to, 0 if none, and whether ctx reaches every school
+func SchoolFromContext(ctx context.Context) (uint, bool) {
+	schoolID, _ := ctx.Value(SchoolContextKey).(uint)
+	all, _ := ctx.Value(allSchoolsContextKey{}).(bool)
+	return schoolID, all
+}
+
+var ErrSchoolRequired = errors.New("school is required")
+
+// School is a tenant of the deployment. Every admin, teacher, class, student,
+// attendance record and absent request belongs to exactly one school.
+type School struct {
+	ID        uint      `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"`
+	Name      string    `json:"name" db:"name"`
+	Address   *string   `json:"address" db:"address"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	CreatedBy *uint     `json:"created_by" db:"created_by"`
+	UpdatedBy *uint     `json:"updated_by" db:"updated_by"`
+}
+
+func (School) TableName() string {
+	return "schools"
+}
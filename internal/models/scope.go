@@ -0,0 +1,22 @@
+package models
+
+import "slices"
+
+// RecordScope is the part of the school whose records a user may see.
+// Admins see everything, teachers the students of the classes they teach
+// and students only their own records.
+type RecordScope struct {
+	All       bool
+	ClassIDs  []uint
+	StudentID string
+}
+
+// AllowsClass reports whether the records of the whole class are in scope
+func (s *RecordScope) AllowsClass(classID uint) bool {
+	return s.All || slices.Contains(s.ClassIDs, classID)
+}
+
+// AllowsStudent reports whether the records of the student, filed under the class, are in scope
+func (s *RecordScope) AllowsStudent(studentID string, classID uint) bool {
+	return s.AllowsClass(classID) || (s.StudentID != "" && s.StudentID == studentID)
+}
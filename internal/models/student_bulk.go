@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Actions that can be applied to many students at once
+const (
+	StudentBulkActionDeactivate    = "deactivate"
+	StudentBulkActionActivate      = "activate"
+	StudentBulkActionMoveClass     = "move_class"
+	StudentBulkActionDelete        = "delete"
+	StudentBulkActionResetPassword = "reset_password"
+)
+
+// Results of a bulk action on one student
+const (
+	StudentBulkItemChanged   = "changed"
+	StudentBulkItemUnchanged = "unchanged"
+	StudentBulkItemFailed    = "failed"
+)
+
+// MaxStudentBulkIDs bounds how many students a bulk action may list by ID
+const MaxStudentBulkIDs = 1000
+
+var (
+	ErrInvalidStudentBulkAction   = errors.New("action must be deactivate, activate, move_class, delete or reset_password")
+	ErrStudentBulkTargetRequired  = errors.New("either student_ids or class_id is required")
+	ErrStudentBulkTooManyIDs      = errors.New("too many student_ids")
+	ErrStudentBulkToClassRequired = errors.New("to_class_id is required to move students")
+	ErrStudentBulkNoStudents      = errors.New("no students matched")
+	ErrStudentBulkFailed          = errors.New("some students could not be changed")
+)
+
+// StudentBulkActions lists the supported bulk actions
+var StudentBulkActions = []string{
+	StudentBulkActionDeactivate,
+	StudentBulkActionActivate,
+	StudentBulkActionMoveClass,
+	StudentBulkActionDelete,
+	StudentBulkActionResetPassword,
+}
+
+// StudentBulkInput applies an action to the students listed in StudentIDs or, instead, to every student of ClassID
+type StudentBulkInput struct {
+	Action        string  `json:"action"`
+	StudentIDs    []uint  `json:"student_ids"`
+	ClassID       *uint   `json:"class_id"`
+	ToClassID     *uint   `json:"to_class_id"`
+	EffectiveDate string  `json:"effective_date"`
+	Note          *string `json:"note"`
+}
+
+// StudentBulkAction is a parsed StudentBulkInput and, once applied, the audit record of who changed which students
+type StudentBulkAction struct {
+	ID            uint              `json:"id" db:"id"`
+	SchoolID      uint              `json:"school_id" db:"school_id"`
+	Action        string            `json:"action" db:"action"`
+	StudentIDs    []uint            `json:"-"`
+	ClassID       *uint             `json:"class_id" db:"class_id"`
+	ToClassID     *uint             `json:"to_class_id" db:"to_class_id"`
+	EffectiveDate *time.Time        `json:"effective_date" db:"effective_date"`
+	Note          *string           `json:"note" db:"note"`
+	StudentCount  int               `json:"student_count" db:"student_count"`
+	ChangedCount  int               `json:"changed_count" db:"changed_count"`
+	PerformedBy   uint              `json:"performed_by" db:"performed_by"`
+	PerformedAt   time.Time         `json:"performed_at" db:"performed_at"`
+	Items         []StudentBulkItem `json:"items,omitempty"`
+}
+
+func (StudentBulkAction) TableName() string {
+	return "student_bulk_actions"
+}
+
+// StudentBulkItem is the result of a bulk action on one student. Password is only set in the
+// response of a password reset and is never stored. In the audit trail ID is 0 once the student
+// was purged from the trash; their number and name are kept.
+type StudentBulkItem struct {
+	ID               uint   `json:"id" db:"student_id"`
+	StudentID        string `json:"student_id" db:"student_number"`
+	FirstName        string `json:"first_name" db:"first_name"`
+	LastName         string `json:"last_name" db:"last_name"`
+	Status           string `json:"status" db:"status"`
+	Error            string `json:"error,omitempty"`
+	PreviousClassID  *uint  `json:"previous_class_id,omitempty" db:"previous_class_id"`
+	PreviousIsActive *bool  `json:"previous_is_active,omitempty" db:"previous_is_active"`
+	Password         string `json:"password,omitempty"`
+}
+
+func (StudentBulkItem) TableName() string {
+	return "student_bulk_action_items"
+}
+
+// IsValidStudentBulkAction reports whether action is one of the bulk actions
+func IsValidStudentBulkAction(action string) bool {
+	for _, a := range StudentBulkActions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ToStudentBulkAction converts StudentBulkInput to StudentBulkAction. The effective date of a class move defaults to today.
+func (in *StudentBulkInput) ToStudentBulkAction(performedBy uint, today time.Time) (*StudentBulkAction, error) {
+	action := &StudentBulkAction{
+		Action:      strings.TrimSpace(in.Action),
+		ClassID:     in.ClassID,
+		PerformedBy: performedBy,
+	}
+
+	if !IsValidStudentBulkAction(action.Action) {
+		return nil, ErrInvalidStudentBulkAction
+	}
+
+	if (len(in.StudentIDs) == 0) == (in.ClassID == nil) {
+		return nil, ErrStudentBulkTargetRequired
+	}
+
+	if len(in.StudentIDs) > MaxStudentBulkIDs {
+		return nil, ErrStudentBulkTooManyIDs
+	}
+
+	// the same student listed twice is changed once
+	seen := make(map[uint]bool)
+	for _, id := range in.StudentIDs {
+		if !seen[id] {
+			seen[id] = true
+			action.StudentIDs = append(action.StudentIDs, id)
+		}
+	}
+
+	if action.Action == StudentBulkActionMoveClass {
+		if in.ToClassID == nil {
+			return nil, ErrStudentBulkToClassRequired
+		}
+		action.ToClassID = in.ToClassID
+
+		effectiveDate := today
+		if in.EffectiveDate != "" {
+			date, err := time.Parse("2006-01-02", in.EffectiveDate)
+			if err != nil {
+				return nil, err
+			}
+			effectiveDate = date
+		}
+
+		if effectiveDate.After(today) {
+			return nil, ErrTransferFutureDate
+		}
+		action.EffectiveDate = &effectiveDate
+	}
+
+	if in.Note != nil {
+		note := strings.TrimSpace(*in.Note)
+		if note != "" {
+			action.Note = &note
+		}
+	}
+
+	return action, nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxStudentContacts is how many emergency contacts a student may have
+	MaxStudentContacts = 10
+	// MaxStudentMedicalNoteLength is the longest medical note, in characters
+	MaxStudentMedicalNoteLength = 2000
+)
+
+var (
+	ErrTooManyStudentContacts     = errors.New("a student may have at most 10 emergency contacts")
+	ErrStudentContactName         = errors.New("every contact needs a name of at most 100 characters")
+	ErrStudentContactRelationship = errors.New("every contact needs a relationship of at most 50 characters")
+	ErrStudentContactPhone        = errors.New("every contact needs a phone, and phones must be at most 20 characters")
+	ErrStudentContactPriority     = errors.New("contact priorities must be 1 or more")
+	ErrStudentMedicalNoteTooLong  = errors.New("a medical note may be at most 2000 characters")
+)
+
+// StudentContact is a person to call about a student, the lowest Priority first
+type StudentContact struct {
+	ID           uint      `json:"id" db:"id"`
+	StudentID    uint      `json:"student_id" db:"student_id"`
+	SchoolID     uint      `json:"school_id" db:"school_id"`
+	Name         string    `json:"name" db:"name"`
+	Relationship string    `json:"relationship" db:"relationship"`
+	Phone        string    `json:"phone" db:"phone"`
+	AltPhone     *string   `json:"alt_phone" db:"alt_phone"`
+	Priority     int       `json:"priority" db:"priority"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	CreatedBy    *uint     `json:"created_by" db:"created_by"`
+	UpdatedBy    *uint     `json:"updated_by" db:"updated_by"`
+}
+
+// StudentMedicalNote is confidential medical information about a student, e.g. allergies staff must know about
+type StudentMedicalNote struct {
+	StudentID uint      `json:"student_id" db:"student_id"`
+	Note      string    `json:"note" db:"note"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	UpdatedBy *uint     `json:"updated_by" db:"updated_by"`
+}
+
+// StudentEmergencyInfo gathers who to call about a student and their medical note, if any
+type StudentEmergencyInfo struct {
+	Contacts    []*StudentContact   `json:"contacts"`
+	MedicalNote *StudentMedicalNote `json:"medical_note"`
+}
+
+// NormalizeStudentContacts trims and checks the contacts given for a student. A contact without a priority
+// gets its position in the list, and an empty alternative phone is dropped.
+func NormalizeStudentContacts(contacts []*StudentContact) error {
+	if len(contacts) > MaxStudentContacts {
+		return ErrTooManyStudentContacts
+	}
+
+	for i, contact := range contacts {
+		contact.Name = strings.TrimSpace(contact.Name)
+		contact.Relationship = strings.TrimSpace(contact.Relationship)
+		contact.Phone = strings.TrimSpace(contact.Phone)
+		if contact.AltPhone != nil {
+			if altPhone := strings.TrimSpace(*contact.AltPhone); altPhone != "" {
+				contact.AltPhone = &altPhone
+			} else {
+				contact.AltPhone = nil
+			}
+		}
+
+		switch {
+		case contact.Name == "" || len([]rune(contact.Name)) > 100:
+			return ErrStudentContactName
+		case contact.Relationship == "" || len([]rune(contact.Relationship)) > 50:
+			return ErrStudentContactRelationship
+		case contact.Phone == "" || len(contact.Phone) > 20:
+			return ErrStudentContactPhone
+		case contact.AltPhone != nil && len(*contact.AltPhone) > 20:
+			return ErrStudentContactPhone
+		case contact.Priority < 0:
+			return ErrStudentContactPriority
+		}
+
+		if contact.Priority == 0 {
+			contact.Priority = i + 1
+		}
+	}
+
+	return nil
+}
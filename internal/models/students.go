@@ -6,6 +6,7 @@ type Student struct {
 	ID        uint       `json:"id" db:"id"`
 	StudentID string     `json:"student_id" db:"student_id"`
 	ClassesID uint       `json:"classes_id" db:"classes_id"`
+	SchoolID  uint       `json:"school_id" db:"school_id"`
 	FirstName string     `json:"first_name" db:"first_name"`
 	LastName  string     `json:"last_name" db:"last_name"`
 	Email     string     `json:"email" db:"email"`
@@ -25,9 +26,12 @@ type StudentStats struct {
 	InactiveStudents int `json:"inactive_students" db:"inactive_students"`
 }
 
+// StudentsWithClassName is a student with the name of their class. Emergency is only filled in for
+// the people allowed to see it: admins and the homeroom teacher of the class.
 type StudentsWithClassName struct {
 	Student
-	ClassName string `json:"class_name" db:"class_name"`
+	ClassName string                `json:"class_name" db:"class_name"`
+	Emergency *StudentEmergencyInfo `json:"emergency,omitempty"`
 }
 
 func (Student) TableName() string {
@@ -3,18 +3,20 @@ package models
 import "time"
 
 type Teacher struct {
-	ID        uint       `json:"id" db:"id"`
-	TeacherID string     `json:"teacher_id" db:"teacher_id"`
-	FirstName string     `json:"first_name" db:"first_name"`
-	LastName  string     `json:"last_name" db:"last_name"`
-	Email     string     `json:"email" db:"email"`
-	Phone     *string    `json:"phone" db:"phone"`
-	Password  string     `json:"-" db:"password"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
-	IsActive  bool       `json:"is_active" db:"is_active"`
-	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at"`
-	DeletedBy *uint      `json:"deleted_by" db:"deleted_by"`
+	ID                uint       `json:"id" db:"id"`
+	TeacherID         string     `json:"teacher_id" db:"teacher_id"`
+	SchoolID          uint       `json:"school_id" db:"school_id"`
+	FirstName         string     `json:"first_name" db:"first_name"`
+	LastName          string     `json:"last_name" db:"last_name"`
+	Email             string     `json:"email" db:"email"`
+	Phone             *string    `json:"phone" db:"phone"`
+	PreferredLanguage string     `json:"preferred_language" db:"preferred_language"`
+	Password          string     `json:"-" db:"password"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	IsActive          bool       `json:"is_active" db:"is_active"`
+	DeletedAt         *time.Time `json:"deleted_at" db:"deleted_at"`
+	DeletedBy         *uint      `json:"deleted_by" db:"deleted_by"`
 }
 
 type TeacherStats struct {
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Languages the web app is translated into, which teachers may pick for their dashboard
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+const (
+	// EmailChangeCodeTTL is how long the code sent to a new email stays valid
+	EmailChangeCodeTTL = 30 * time.Minute
+	// MaxEmailChangeAttempts is how many wrong codes end an email change
+	MaxEmailChangeAttempts = 5
+)
+
+var (
+	ErrInvalidLanguage     = errors.New("preferred language must be en or id")
+	ErrPhoneTaken          = errors.New("phone is already used by another teacher")
+	ErrEmailTaken          = errors.New("email is already used by another teacher")
+	ErrEmailChangeNotFound = errors.New("no email change is waiting for verification")
+	ErrEmailChangeExpired  = errors.New("the verification code has expired, ask for a new one")
+	ErrEmailChangeCode     = errors.New("the verification code is wrong")
+)
+
+// TeacherProfileUpdate holds the fields of their own profile a teacher may change. Phone and PreferredLanguage
+// apply at once; a new Email only applies once it is verified.
+type TeacherProfileUpdate struct {
+	Phone             *string `json:"phone"`
+	Email             string  `json:"email"`
+	PreferredLanguage string  `json:"preferred_language"`
+}
+
+// TeacherEmailChange is a new email waiting for the teacher to enter the code sent to it
+type TeacherEmailChange struct {
+	TeacherID uint      `json:"teacher_id" db:"teacher_id"`
+	NewEmail  string    `json:"new_email" db:"new_email"`
+	CodeHash  string    `json:"-" db:"code_hash"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsValidLanguage reports whether language is one the web app is translated into
+func IsValidLanguage(language string) bool {
+	return language == LanguageEnglish || language == LanguageIndonesian
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSubjectRequired          = errors.New("subject code and name are required")
+	ErrTimetableSlotRequired    = errors.New("class, subject and teacher are required")
+	ErrTimetableInvalidWeekday  = errors.New("weekday must be between 1 (Monday) and 7 (Sunday)")
+	ErrTimetableInvalidTime     = errors.New("start and end times must use the HH:MM format")
+	ErrTimetableInvalidTimeSpan = errors.New("end time must be after start time")
+)
+
+// Subject is a course taught at a school, such as Mathematics or Biology
+type Subject struct {
+	ID          uint       `json:"id" db:"id"`
+	SchoolID    uint       `json:"school_id" db:"school_id"`
+	Code        string     `json:"code" db:"code"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description" db:"description"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy   *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy   *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy   *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (Subject) TableName() string {
+	return "subjects"
+}
+
+// Validate trims the subject fields and checks the required ones are set
+func (s *Subject) Validate() error {
+	s.Code = strings.TrimSpace(s.Code)
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Code == "" || s.Name == "" {
+		return ErrSubjectRequired
+	}
+
+	return nil
+}
+
+// TimetableSlot is a weekly lesson: a teacher teaching a subject to a class on a weekday between two times.
+// Weekday follows ISO 8601, so Monday is 1 and Sunday is 7. Times are wall-clock times in HH:MM format.
+type TimetableSlot struct {
+	ID               uint       `json:"id" db:"id"`
+	SchoolID         uint       `json:"school_id" db:"school_id"`
+	ClassID          uint       `json:"class_id" db:"class_id"`
+	SubjectID        uint       `json:"subject_id" db:"subject_id"`
+	TeacherID        string     `json:"teacher_id" db:"teacher_id"`
+	Weekday          int        `json:"weekday" db:"weekday"`
+	StartTime        string     `json:"start_time" db:"start_time"`
+	EndTime          string     `json:"end_time" db:"end_time"`
+	Room             *string    `json:"room" db:"room"`
+	ClassName        string     `json:"class_name,omitempty" db:"class_name"`
+	SubjectCode      string     `json:"subject_code,omitempty" db:"subject_code"`
+	SubjectName      string     `json:"subject_name,omitempty" db:"subject_name"`
+	TeacherFirstName string     `json:"teacher_first_name,omitempty" db:"teacher_first_name"`
+	TeacherLastName  string     `json:"teacher_last_name,omitempty" db:"teacher_last_name"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy        *uint      `json:"created_by" db:"created_by"`
+	UpdatedBy        *uint      `json:"updated_by" db:"updated_by"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy        *uint      `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+
+func (TimetableSlot) TableName() string {
+	return "timetable_slots"
+}
+
+// Validate checks the slot fields and normalizes its times to HH:MM
+func (s *TimetableSlot) Validate() error {
+	s.TeacherID = strings.TrimSpace(s.TeacherID)
+	if s.ClassID == 0 || s.SubjectID == 0 || s.TeacherID == "" {
+		return ErrTimetableSlotRequired
+	}
+
+	if s.Weekday < 1 || s.Weekday > 7 {
+		return ErrTimetableInvalidWeekday
+	}
+
+	startTime, err := time.Parse("15:04", strings.TrimSpace(s.StartTime))
+	if err != nil {
+		return ErrTimetableInvalidTime
+	}
+
+	endTime, err := time.Parse("15:04", strings.TrimSpace(s.EndTime))
+	if err != nil {
+		return ErrTimetableInvalidTime
+	}
+
+	if !endTime.After(startTime) {
+		return ErrTimetableInvalidTimeSpan
+	}
+
+	s.StartTime = startTime.Format("15:04")
+	s.EndTime = endTime.Format("15:04")
+
+	return nil
+}
+
+// ISOWeekday returns the ISO 8601 weekday of the date, Monday being 1 and Sunday 7
+func ISOWeekday(date time.Time) int {
+	if date.Weekday() == time.Sunday {
+		return 7
+	}
+
+	return int(date.Weekday())
+}
+
+// TimetableFilter narrows the timetable slots to list. Zero values match every slot.
+type TimetableFilter struct {
+	ClassID   uint
+	TeacherID string
+	Weekday   int
+}
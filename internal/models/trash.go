@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Kinds of soft-deleted records kept in the trash, as they appear in the URL
+const (
+	TrashEntityTeachers       = "teachers"
+	TrashEntityStudents       = "students"
+	TrashEntityClasses        = "classes"
+	TrashEntityAttendances    = "attendances"
+	TrashEntityAbsentRequests = "absent-requests"
+)
+
+// DefaultTrashRetentionDays is how long deleted records stay in the trash before they are purged
+const DefaultTrashRetentionDays = 90
+
+var (
+	ErrInvalidTrashEntity   = errors.New("entity must be teachers, students, classes, attendances or absent-requests")
+	ErrTrashNotFound        = errors.New("record not found in the trash")
+	ErrTrashRestoreConflict = errors.New("record conflicts with live records")
+)
+
+// TrashEntities lists the kinds of records kept in the trash
+var TrashEntities = []string{
+	TrashEntityTeachers,
+	TrashEntityStudents,
+	TrashEntityClasses,
+	TrashEntityAttendances,
+	TrashEntityAbsentRequests,
+}
+
+// TrashItem is a soft-deleted record. Code is the teacher or student ID the record belongs to,
+// and Detail tells records of the same person apart, e.g. the date of an attendance.
+type TrashItem struct {
+	ID        uint      `json:"id" db:"id"`
+	Entity    string    `json:"entity"`
+	Code      string    `json:"code" db:"code"`
+	Name      string    `json:"name" db:"name"`
+	Detail    string    `json:"detail" db:"detail"`
+	SchoolID  uint      `json:"school_id" db:"school_id"`
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at"`
+	DeletedBy *uint     `json:"deleted_by" db:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// TrashConflict is a reason a deleted record cannot be restored
+type TrashConflict struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// TrashKept is a deleted record old enough to purge that was kept because live records still point at it
+type TrashKept struct {
+	Entity string `json:"entity"`
+	ID     uint   `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// TrashPurgeResult summarises a purge of the records deleted before Before. Cascaded counts the attendances
+// and absent requests removed with the students they belong to. Files holds the storage keys of the purged
+// attachments, which are deleted from storage once the purge is committed. Nothing is removed in a dry run.
+type TrashPurgeResult struct {
+	DryRun        bool           `json:"dry_run"`
+	RetentionDays int            `json:"retention_days"`
+	Before        time.Time      `json:"before"`
+	Purged        map[string]int `json:"purged"`
+	Cascaded      map[string]int `json:"cascaded"`
+	Kept          []TrashKept    `json:"kept"`
+	Files         []string       `json:"-"`
+}
+
+// IsValidTrashEntity reports whether entity is one of the kinds of records kept in the trash
+func IsValidTrashEntity(entity string) bool {
+	for _, e := range TrashEntities {
+		if e == entity {
+			return true
+		}
+	}
+
+	return false
+}
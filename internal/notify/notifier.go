@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Message is a notification addressed to a single recipient
+type Message struct {
+	Recipient string                 `json:"recipient"`
+	Subject   string                 `json:"subject"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// Notifier delivers messages to the people they are addressed to
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// NewNotifier creates a webhook notifier when NOTIFY_WEBHOOK_URL is set, otherwise a notifier that only logs
+func NewNotifier() Notifier {
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		return &webhookNotifier{
+			url:    url,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+
+	return &logNotifier{}
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// Notify posts the message as JSON to the webhook
+func (n *webhookNotifier) Notify(ctx context.Context, message Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to send notification: webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+type logNotifier struct{}
+
+// Notify writes the message to the log
+func (n *logNotifier) Notify(_ context.Context, message Message) error {
+	log.Printf("notification to %s: %s - %s", message.Recipient, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,262 @@
+package oneroster
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// ExportOptions limits the attendance and student enrollments included in an export. Nil dates are unbounded.
+type ExportOptions struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// Export writes a OneRoster 1.2 bulk CSV bundle as a zip archive to w
+func (s *Service) Export(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	teachers, err := s.repo.GetTeachers(ctx)
+	if err != nil {
+		return err
+	}
+
+	students, err := s.repo.GetStudents(ctx)
+	if err != nil {
+		return err
+	}
+
+	enrollments, err := s.repo.GetEnrollments(ctx, opts.StartDate, opts.EndDate)
+	if err != nil {
+		return err
+	}
+
+	classes, err := s.repo.GetClasses(ctx)
+	if err != nil {
+		return err
+	}
+
+	attendances, err := s.repo.GetAttendances(ctx, opts.StartDate, opts.EndDate)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		header  []string
+		records [][]string
+	}{
+		{fileManifest, []string{"propertyName", "value"}, manifestRecords()},
+		{fileOrgs, headerOrgs, s.orgRecords()},
+		{fileCourses, headerCourses, s.courseRecords(classes)},
+		{fileClasses, headerClasses, s.classRecords(classes)},
+		{fileUsers, headerUsers, s.userRecords(teachers, students)},
+		{fileRoles, headerRoles, s.roleRecords(teachers, students)},
+		{fileEnrollments, headerEnrollments, s.enrollmentRecords(classes, enrollments)},
+		{fileAttendance, headerAttendance, attendanceRecords(attendances)},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		if err := writeCSV(archive, file.name, file.header, file.records); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to close oneroster archive: %w", err)
+	}
+
+	return nil
+}
+
+func writeCSV(archive *zip.Writer, name string, header []string, records [][]string) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	writer := csv.NewWriter(f)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func manifestRecords() [][]string {
+	return [][]string{
+		{"manifest.version", "1.0"},
+		{"oneroster.version", "1.2"},
+		{"file.academicSessions", "absent"},
+		{"file.categories", "absent"},
+		{"file.classes", "bulk"},
+		{"file.classResources", "absent"},
+		{"file.courses", "bulk"},
+		{"file.courseResources", "absent"},
+		{"file.demographics", "absent"},
+		{"file.enrollments", "bulk"},
+		{"file.lineItemLearningObjectiveIds", "absent"},
+		{"file.lineItems", "absent"},
+		{"file.lineItemScoreScales", "absent"},
+		{"file.orgs", "bulk"},
+		{"file.resources", "absent"},
+		{"file.resultLearningObjectiveIds", "absent"},
+		{"file.results", "absent"},
+		{"file.resultScoreScales", "absent"},
+		{"file.roles", "bulk"},
+		{"file.scoreScales", "absent"},
+		{"file.userProfiles", "absent"},
+		{"file.userResources", "absent"},
+		{"file.users", "bulk"},
+		{"source.systemName", "Student Attendance"},
+		{"source.systemCode", "student_attendance"},
+	}
+}
+
+func (s *Service) orgRecords() [][]string {
+	return [][]string{
+		{s.orgSourcedID, "", "", s.orgName, "school", s.orgSourcedID, ""},
+	}
+}
+
+// courseRecords emits one course per class, since classes are not grouped into courses here
+func (s *Service) courseRecords(classes []*models.RosterClass) [][]string {
+	records := make([][]string, 0, len(classes))
+	for _, class := range classes {
+		records = append(records, []string{
+			courseSourcedID(class.SourcedID), "", formatDateTime(class.UpdatedAt), "", class.Name, class.Name,
+			"", s.orgSourcedID, "", "",
+		})
+	}
+	return records
+}
+
+func (s *Service) classRecords(classes []*models.RosterClass) [][]string {
+	records := make([][]string, 0, len(classes))
+	for _, class := range classes {
+		records = append(records, []string{
+			class.SourcedID, "", formatDateTime(class.UpdatedAt), class.Name, "", courseSourcedID(class.SourcedID),
+			class.Name, "homeroom", "", s.orgSourcedID, "", "", "", "",
+		})
+	}
+	return records
+}
+
+func (s *Service) userRecords(teachers []*models.Teacher, students []*models.Student) [][]string {
+	records := make([][]string, 0, len(teachers)+len(students))
+	for _, teacher := range teachers {
+		records = append(records, s.userRecord(teacher.TeacherID, teacher.FirstName, teacher.LastName,
+			teacher.Email, teacher.Phone, teacher.IsActive, teacher.UpdatedAt))
+	}
+	for _, student := range students {
+		records = append(records, s.userRecord(student.StudentID, student.FirstName, student.LastName,
+			student.Email, student.Phone, student.IsActive, student.UpdatedAt))
+	}
+	return records
+}
+
+func (s *Service) userRecord(sourcedID, givenName, familyName, email string, phone *string, isActive bool, updatedAt time.Time) []string {
+	return []string{
+		sourcedID, "", formatDateTime(updatedAt), strconv.FormatBool(isActive), email, "", givenName,
+		familyName, "", sourcedID, email, "", stringValue(phone), "", "", "",
+		"", "", "", "", "",
+		s.orgSourcedID, "",
+	}
+}
+
+func (s *Service) roleRecords(teachers []*models.Teacher, students []*models.Student) [][]string {
+	records := make([][]string, 0, len(teachers)+len(students))
+	for _, teacher := range teachers {
+		records = append(records, []string{
+			"role-" + teacher.TeacherID, "", formatDateTime(teacher.UpdatedAt), teacher.TeacherID, "primary", roleTeacher,
+			"", "", s.orgSourcedID, "",
+		})
+	}
+	for _, student := range students {
+		records = append(records, []string{
+			"role-" + student.StudentID, "", formatDateTime(student.UpdatedAt), student.StudentID, "primary", roleStudent,
+			"", "", s.orgSourcedID, "",
+		})
+	}
+	return records
+}
+
+// enrollmentRecords enrolls each homeroom teacher as the primary teacher of their class
+// and each student in the classes they were in, from the first to the last day in each.
+// A student's current class keeps the sourcedId it had before enrollment history was exported.
+func (s *Service) enrollmentRecords(classes []*models.RosterClass, enrollments []*models.RosterEnrollment) [][]string {
+	records := make([][]string, 0, len(classes)+len(enrollments))
+
+	for _, class := range classes {
+		records = append(records, []string{
+			enrollmentSourcedID(class.SourcedID, class.HomeroomTeacher), "", formatDateTime(class.UpdatedAt),
+			class.SourcedID, s.orgSourcedID, class.HomeroomTeacher, roleTeacher, "true", "", "",
+		})
+	}
+
+	for _, enrollment := range enrollments {
+		sourcedID := enrollmentSourcedID(enrollment.ClassSourcedID, enrollment.StudentSourcedID)
+		endDate := ""
+		if enrollment.EndDate != nil {
+			sourcedID += "-" + strconv.FormatUint(uint64(enrollment.ID), 10)
+			// enrollments end on the first day the student was no longer in the class
+			endDate = enrollment.EndDate.AddDate(0, 0, -1).Format(dateFormat)
+		}
+
+		records = append(records, []string{
+			sourcedID, "", formatDateTime(enrollment.CreatedAt),
+			enrollment.ClassSourcedID, s.orgSourcedID, enrollment.StudentSourcedID, roleStudent, "false",
+			enrollment.StartDate.Format(dateFormat), endDate,
+		})
+	}
+
+	return records
+}
+
+func attendanceRecords(attendances []*models.RosterAttendance) [][]string {
+	records := make([][]string, 0, len(attendances))
+	for _, attendance := range attendances {
+		timeOut := ""
+		if attendance.TimeOut != nil {
+			timeOut = formatDateTime(*attendance.TimeOut)
+		}
+
+		records = append(records, []string{
+			"attendance-" + strconv.FormatUint(uint64(attendance.ID), 10), "", formatDateTime(attendance.CreatedAt),
+			attendance.ClassSourcedID, attendance.StudentID, attendance.Date.Format(dateFormat),
+			string(attendance.Status), formatDateTime(attendance.TimeIn), timeOut, stringValue(attendance.Description),
+		})
+	}
+	return records
+}
+
+func courseSourcedID(classSourcedID string) string {
+	return "course-" + classSourcedID
+}
+
+func enrollmentSourcedID(classSourcedID, userSourcedID string) string {
+	return "enrollment-" + classSourcedID + "-" + userSourcedID
+}
+
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(dateTimeFormat)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,275 @@
+package oneroster
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// csvFile is a parsed CSV file whose columns are addressed by header name
+type csvFile struct {
+	columns map[string]int
+	rows    [][]string
+}
+
+func (f *csvFile) value(row []string, column string) string {
+	index, ok := f.columns[column]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
+
+// enrollments maps each class to its primary teacher and each student to their class
+type enrollments struct {
+	teachers map[string]string
+	primary  map[string]bool
+	students map[string]string
+	ended    map[string]bool
+}
+
+// Import reads a OneRoster bundle and upserts its teachers, classes and students by sourcedId
+func (s *Service) Import(ctx context.Context, r io.ReaderAt, size int64) (*models.RosterImportResult, error) {
+	if size > MaxArchiveSize {
+		return nil, fmt.Errorf("oneroster archive is larger than %d MB", MaxArchiveSize>>20)
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oneroster archive: %w", err)
+	}
+
+	files := make(map[string]*csvFile)
+	for _, name := range []string{fileUsers, fileRoles, fileClasses, fileEnrollments} {
+		file, err := readCSV(archive, name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = file
+	}
+
+	for _, name := range []string{fileUsers, fileClasses, fileEnrollments} {
+		if files[name] == nil {
+			return nil, fmt.Errorf("oneroster archive is missing %s", name)
+		}
+	}
+
+	roster := &models.Roster{}
+	roles := parseRoles(files[fileRoles])
+	enrolled := parseEnrollments(files[fileEnrollments])
+	parseUsers(roster, files[fileUsers], roles, enrolled)
+	parseClasses(roster, files[fileClasses], enrolled)
+
+	return s.repo.Import(ctx, roster, hashPassword)
+}
+
+// readCSV returns nil when the file is not part of the archive
+func readCSV(archive *zip.Reader, name string) (*csvFile, error) {
+	for _, f := range archive.File {
+		if f.Name != name && !strings.HasSuffix(f.Name, "/"+name) {
+			continue
+		}
+
+		if f.UncompressedSize64 > maxFileSize {
+			return nil, fmt.Errorf("%s is larger than %d MB", name, maxFileSize>>20)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+
+		// the size in the zip header may lie, so reading stops past the limit whatever it says
+		limited := &io.LimitedReader{R: rc, N: maxFileSize + 1}
+		reader := csv.NewReader(limited)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		if limited.N == 0 {
+			return nil, fmt.Errorf("%s is larger than %d MB", name, maxFileSize>>20)
+		}
+
+		file := &csvFile{columns: make(map[string]int)}
+		if len(records) == 0 {
+			return file, nil
+		}
+
+		for i, column := range records[0] {
+			column = strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")
+			file.columns[column] = i
+		}
+		file.rows = records[1:]
+
+		return file, nil
+	}
+
+	return nil, nil
+}
+
+// isDeleted reports rows flagged for deletion in delta bundles, which are not applied
+func isDeleted(file *csvFile, row []string) bool {
+	return strings.EqualFold(file.value(row, "status"), "tobedeleted")
+}
+
+// parseRoles returns the primary role of each user. OneRoster 1.1 bundles have no
+// roles file and carry the role on the user instead.
+func parseRoles(file *csvFile) map[string]string {
+	roles := make(map[string]string)
+	if file == nil {
+		return roles
+	}
+
+	for _, row := range file.rows {
+		if isDeleted(file, row) {
+			continue
+		}
+
+		userSourcedID := file.value(row, "userSourcedId")
+		if _, ok := roles[userSourcedID]; ok && file.value(row, "roleType") != "primary" {
+			continue
+		}
+		roles[userSourcedID] = file.value(row, "role")
+	}
+
+	return roles
+}
+
+func parseEnrollments(file *csvFile) *enrollments {
+	enrolled := &enrollments{
+		teachers: make(map[string]string),
+		primary:  make(map[string]bool),
+		students: make(map[string]string),
+		ended:    make(map[string]bool),
+	}
+
+	today := time.Now().Format(dateFormat)
+
+	for _, row := range file.rows {
+		if isDeleted(file, row) {
+			continue
+		}
+
+		classSourcedID := file.value(row, "classSourcedId")
+		userSourcedID := file.value(row, "userSourcedId")
+
+		switch file.value(row, "role") {
+		case roleTeacher:
+			// the primary teacher becomes the homeroom teacher, otherwise the first one listed
+			primary := strings.EqualFold(file.value(row, "primary"), "true")
+			if _, ok := enrolled.teachers[classSourcedID]; !ok || (primary && !enrolled.primary[classSourcedID]) {
+				enrolled.teachers[classSourcedID] = userSourcedID
+				enrolled.primary[classSourcedID] = primary
+			}
+		case roleStudent:
+			// past classes are listed with an end date; the class the student is in now wins,
+			// otherwise the first one listed
+			endDate := file.value(row, "endDate")
+			ended := endDate != "" && endDate < today
+			if _, ok := enrolled.students[userSourcedID]; !ok || (enrolled.ended[userSourcedID] && !ended) {
+				enrolled.students[userSourcedID] = classSourcedID
+				enrolled.ended[userSourcedID] = ended
+			}
+		}
+	}
+
+	return enrolled
+}
+
+func parseUsers(roster *models.Roster, file *csvFile, roles map[string]string, enrolled *enrollments) {
+	for i, row := range file.rows {
+		if isDeleted(file, row) {
+			continue
+		}
+
+		// row numbers count the header line so they match what a spreadsheet shows
+		user := &models.RosterUser{
+			Row:        i + 2,
+			SourcedID:  file.value(row, "sourcedId"),
+			GivenName:  file.value(row, "givenName"),
+			FamilyName: file.value(row, "familyName"),
+			Email:      file.value(row, "email"),
+			Password:   file.value(row, "password"),
+			IsActive:   !strings.EqualFold(file.value(row, "enabledUser"), "false"),
+		}
+		if phone := file.value(row, "phone"); phone != "" {
+			user.Phone = &phone
+		}
+
+		role, ok := roles[user.SourcedID]
+		if !ok {
+			role = file.value(row, "role")
+		}
+
+		if role != roleTeacher && role != roleStudent {
+			// administrators, guardians and other roles have no account type here
+			continue
+		}
+
+		if user.SourcedID == "" || user.GivenName == "" || user.FamilyName == "" || user.Email == "" {
+			roster.Errors = append(roster.Errors, importError(fileUsers, user.Row, user.SourcedID,
+				"sourcedId, givenName, familyName and email are required"))
+			continue
+		}
+
+		if role == roleTeacher {
+			roster.Teachers = append(roster.Teachers, user)
+			continue
+		}
+
+		user.ClassSourcedID = enrolled.students[user.SourcedID]
+		if user.ClassSourcedID == "" {
+			roster.Errors = append(roster.Errors, importError(fileUsers, user.Row, user.SourcedID,
+				"student has no class enrollment"))
+			continue
+		}
+		roster.Students = append(roster.Students, user)
+	}
+}
+
+func parseClasses(roster *models.Roster, file *csvFile, enrolled *enrollments) {
+	for i, row := range file.rows {
+		if isDeleted(file, row) {
+			continue
+		}
+
+		class := &models.RosterImportClass{
+			Row:       i + 2,
+			SourcedID: file.value(row, "sourcedId"),
+			Title:     file.value(row, "title"),
+		}
+		class.TeacherSourcedID = enrolled.teachers[class.SourcedID]
+
+		if class.SourcedID == "" || class.Title == "" {
+			roster.Errors = append(roster.Errors, importError(fileClasses, class.Row, class.SourcedID,
+				"sourcedId and title are required"))
+			continue
+		}
+
+		if class.TeacherSourcedID == "" {
+			roster.Errors = append(roster.Errors, importError(fileClasses, class.Row, class.SourcedID,
+				"class has no teacher enrollment"))
+			continue
+		}
+
+		roster.Classes = append(roster.Classes, class)
+	}
+}
+
+func importError(file string, row int, sourcedID, message string) models.RosterImportError {
+	return models.RosterImportError{
+		File:      file,
+		Row:       row,
+		SourcedID: sourcedID,
+		Error:     message,
+	}
+}
@@ -0,0 +1,100 @@
+// Package oneroster converts rosters and attendance to and from OneRoster 1.2 CSV bundles.
+package oneroster
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/michaelwp/student_attendance/internal/repository"
+	"github.com/michaelwp/student_attendance/pkg"
+)
+
+const (
+	fileManifest    = "manifest.csv"
+	fileOrgs        = "orgs.csv"
+	fileCourses     = "courses.csv"
+	fileClasses     = "classes.csv"
+	fileUsers       = "users.csv"
+	fileRoles       = "roles.csv"
+	fileEnrollments = "enrollments.csv"
+	// attendance is not part of the OneRoster rostering spec, it is shipped as an extension file
+	fileAttendance = "attendance.csv"
+
+	roleTeacher = "teacher"
+	roleStudent = "student"
+
+	dateFormat     = "2006-01-02"
+	dateTimeFormat = "2006-01-02T15:04:05.000Z"
+
+	// MaxArchiveSize is the largest OneRoster zip accepted for import
+	MaxArchiveSize = 20 << 20
+	// maxFileSize is the largest CSV read out of an archive once uncompressed,
+	// so a small zip bomb cannot exhaust memory
+	maxFileSize = 100 << 20
+)
+
+var (
+	headerOrgs = []string{"sourcedId", "status", "dateLastModified", "name", "type", "identifier", "parentSourcedId"}
+
+	headerCourses = []string{"sourcedId", "status", "dateLastModified", "schoolYearSourcedId", "title", "courseCode",
+		"grades", "orgSourcedId", "subjects", "subjectCodes"}
+
+	headerClasses = []string{"sourcedId", "status", "dateLastModified", "title", "grades", "courseSourcedId", "classCode",
+		"classType", "location", "schoolSourcedId", "termSourcedIds", "subjects", "subjectCodes", "periods"}
+
+	headerUsers = []string{"sourcedId", "status", "dateLastModified", "enabledUser", "username", "userIds", "givenName",
+		"familyName", "middleName", "identifier", "email", "sms", "phone", "agentSourcedIds", "grades", "password",
+		"userMasterIdentifier", "resourceSourcedIds", "preferredGivenName", "preferredMiddleName", "preferredFamilyName",
+		"primaryOrgSourcedId", "pronouns"}
+
+	headerRoles = []string{"sourcedId", "status", "dateLastModified", "userSourcedId", "roleType", "role", "beginDate",
+		"endDate", "orgSourcedId", "userProfileSourcedId"}
+
+	headerEnrollments = []string{"sourcedId", "status", "dateLastModified", "classSourcedId", "schoolSourcedId",
+		"userSourcedId", "role", "primary", "beginDate", "endDate"}
+
+	headerAttendance = []string{"sourcedId", "status", "dateLastModified", "classSourcedId", "userSourcedId", "date",
+		"attendanceStatus", "timeIn", "timeOut", "description"}
+)
+
+// Service exports and imports OneRoster bundles
+type Service struct {
+	repo         repository.OneRosterRepository
+	orgSourcedID string
+	orgName      string
+}
+
+// NewService creates a new OneRoster service. The school is described by
+// ONEROSTER_ORG_SOURCED_ID and ONEROSTER_ORG_NAME.
+func NewService(repo repository.OneRosterRepository) *Service {
+	orgSourcedID := os.Getenv("ONEROSTER_ORG_SOURCED_ID")
+	if orgSourcedID == "" {
+		orgSourcedID = "school"
+	}
+
+	orgName := os.Getenv("ONEROSTER_ORG_NAME")
+	if orgName == "" {
+		orgName = "School"
+	}
+
+	return &Service{
+		repo:         repo,
+		orgSourcedID: orgSourcedID,
+		orgName:      orgName,
+	}
+}
+
+// hashPassword hashes the password supplied by the SIS, or a generated one when it is empty.
+// Accounts created with a generated password have to be reset before first login.
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		generated, err := pkg.GeneratePassword(12)
+		if err != nil {
+			return "", err
+		}
+		password = generated
+	}
+
+	round, _ := strconv.Atoi(os.Getenv("SALT"))
+	return pkg.HashPassword(password, round)
+}
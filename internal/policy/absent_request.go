@@ -0,0 +1,184 @@
+// Package policy decides which users may act on which records.
+package policy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+var (
+	ErrStudentCannotDecide = errors.New("students cannot change the status of absent requests")
+	ErrNotClassTeacher     = errors.New("only teachers of the class can act on this request")
+	ErrClassRoleNotAllowed = errors.New("the teacher's role in the class does not allow this")
+	ErrAlreadyDecided      = errors.New("absent request has already been decided")
+	ErrUnknownActor        = errors.New("user is not allowed to decide absent requests")
+	ErrNotRequestOwner     = errors.New("absent request belongs to another student")
+	ErrRequestWithdrawn    = errors.New("absent request has been withdrawn")
+)
+
+// Actor is the authenticated user performing an action
+type Actor struct {
+	UserID   uint
+	UserType string
+}
+
+// AbsentRequestPolicy decides who may approve, reject, change or discuss an absent request
+type AbsentRequestPolicy struct {
+	teacherRepo      repository.TeacherRepository
+	classTeacherRepo repository.ClassTeacherRepository
+	studentRepo      repository.StudentRepository
+}
+
+// NewAbsentRequestPolicy creates a new absent request policy
+func NewAbsentRequestPolicy(
+	teacherRepo repository.TeacherRepository,
+	classTeacherRepo repository.ClassTeacherRepository,
+	studentRepo repository.StudentRepository,
+) *AbsentRequestPolicy {
+	return &AbsentRequestPolicy{
+		teacherRepo:      teacherRepo,
+		classTeacherRepo: classTeacherRepo,
+		studentRepo:      studentRepo,
+	}
+}
+
+// CanDecide reports whether the actor may approve or reject the request.
+// Teachers may only decide pending requests of their classes, and only when their role in the class allows it.
+// Admins may decide any request, but must override to change a decision already made.
+func (p *AbsentRequestPolicy) CanDecide(ctx context.Context, actor Actor, request *models.AbsentRequest, override bool) error {
+	switch actor.UserType {
+	case models.UserTypeStudent.String():
+		return ErrStudentCannotDecide
+
+	case models.UserTypeTeacher.String():
+		err := p.checkClassTeacher(ctx, actor, request, func(permission *models.ClassTeacherRolePermission) bool {
+			return permission.CanDecideAbsentRequests
+		})
+		if err != nil {
+			return err
+		}
+
+		return checkPending(request)
+
+	case models.UserTypeAdmin.String():
+		// a withdrawn request is no longer asking for anything, so not even an override decides it
+		if request.Status == models.AbsentRequestStatusWithdrawn {
+			return ErrRequestWithdrawn
+		}
+
+		if request.Status != models.AbsentRequestStatusPending && !override {
+			return ErrAlreadyDecided
+		}
+
+		return nil
+
+	default:
+		return ErrUnknownActor
+	}
+}
+
+// CanComment reports whether the actor may add to the comment thread of the request.
+// The thread is for talking a request through before it is decided, between the student
+// who filed it and the teachers of their class whose role allows it. Admins may always join in.
+func (p *AbsentRequestPolicy) CanComment(ctx context.Context, actor Actor, request *models.AbsentRequest) error {
+	if actor.UserType == models.UserTypeAdmin.String() {
+		return nil
+	}
+
+	if err := checkPending(request); err != nil {
+		return err
+	}
+
+	switch actor.UserType {
+	case models.UserTypeStudent.String():
+		return p.checkRequestOwner(ctx, actor, request)
+
+	case models.UserTypeTeacher.String():
+		return p.checkClassTeacher(ctx, actor, request, func(permission *models.ClassTeacherRolePermission) bool {
+			return permission.CanCommentAbsentRequests
+		})
+
+	default:
+		return ErrUnknownActor
+	}
+}
+
+// CanAmend reports whether the actor may edit or withdraw the request.
+// Only the student who filed it may, and only until it is decided.
+func (p *AbsentRequestPolicy) CanAmend(ctx context.Context, actor Actor, request *models.AbsentRequest) error {
+	if actor.UserType != models.UserTypeStudent.String() {
+		return ErrNotRequestOwner
+	}
+
+	if err := p.checkRequestOwner(ctx, actor, request); err != nil {
+		return err
+	}
+
+	return checkPending(request)
+}
+
+// checkRequestOwner checks the actor is the student who filed the request
+func (p *AbsentRequestPolicy) checkRequestOwner(ctx context.Context, actor Actor, request *models.AbsentRequest) error {
+	student, err := p.studentRepo.GetByID(ctx, actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	if student.StudentID != request.StudentID {
+		return ErrNotRequestOwner
+	}
+
+	return nil
+}
+
+// checkPending checks the request is still waiting for a decision
+func checkPending(request *models.AbsentRequest) error {
+	switch request.Status {
+	case models.AbsentRequestStatusPending:
+		return nil
+	case models.AbsentRequestStatusWithdrawn:
+		return ErrRequestWithdrawn
+	default:
+		return ErrAlreadyDecided
+	}
+}
+
+// checkClassTeacher checks the actor teaches the request's class in a role that allows the action
+func (p *AbsentRequestPolicy) checkClassTeacher(
+	ctx context.Context,
+	actor Actor,
+	request *models.AbsentRequest,
+	allows func(permission *models.ClassTeacherRolePermission) bool,
+) error {
+	teacher, err := p.teacherRepo.GetByID(ctx, actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := p.classTeacherRepo.GetByTeacher(ctx, teacher.TeacherID)
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		if assignment.ClassID != request.ClassID {
+			continue
+		}
+
+		permission, err := p.classTeacherRepo.GetRolePermission(ctx, assignment.SchoolID, assignment.Role)
+		if err != nil {
+			return err
+		}
+
+		if !allows(permission) {
+			return ErrClassRoleNotAllowed
+		}
+
+		return nil
+	}
+
+	return ErrNotClassTeacher
+}
@@ -0,0 +1,216 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// The fakes embed the repository interfaces and implement only what the policies call
+
+type fakeTeacherRepo struct {
+	repository.TeacherRepository
+	teachers map[uint]*models.Teacher
+}
+
+func (r *fakeTeacherRepo) GetByID(_ context.Context, id uint) (*models.Teacher, error) {
+	teacher, ok := r.teachers[id]
+	if !ok {
+		return nil, fmt.Errorf("teacher not found")
+	}
+	return teacher, nil
+}
+
+type fakeStudentRepo struct {
+	repository.StudentRepository
+	students map[uint]*models.Student
+}
+
+func (r *fakeStudentRepo) GetByID(_ context.Context, id uint) (*models.Student, error) {
+	student, ok := r.students[id]
+	if !ok {
+		return nil, fmt.Errorf("student not found")
+	}
+	return student, nil
+}
+
+type fakeClassTeacherRepo struct {
+	repository.ClassTeacherRepository
+	assignments []*models.ClassTeacher
+	permissions map[string]*models.ClassTeacherRolePermission
+}
+
+func (r *fakeClassTeacherRepo) GetByTeacher(_ context.Context, teacherID string) ([]*models.ClassTeacher, error) {
+	var assignments []*models.ClassTeacher
+	for _, assignment := range r.assignments {
+		if assignment.TeacherID == teacherID {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+func (r *fakeClassTeacherRepo) GetRolePermission(_ context.Context, _ uint, role string) (*models.ClassTeacherRolePermission, error) {
+	if permission, ok := r.permissions[role]; ok {
+		return permission, nil
+	}
+	return models.DefaultClassTeacherRolePermission(role), nil
+}
+
+// Teacher 1 is the homeroom teacher of class 10, teacher 2 its assistant and teacher 3 teaches class 20 only.
+// Student 1 filed the requests, student 2 is someone else.
+func newFixtureRepos() (*fakeTeacherRepo, *fakeClassTeacherRepo, *fakeStudentRepo) {
+	teacherRepo := &fakeTeacherRepo{teachers: map[uint]*models.Teacher{
+		1: {ID: 1, TeacherID: "T001"},
+		2: {ID: 2, TeacherID: "T002"},
+		3: {ID: 3, TeacherID: "T003"},
+	}}
+
+	classTeacherRepo := &fakeClassTeacherRepo{
+		assignments: []*models.ClassTeacher{
+			{SchoolID: 1, ClassID: 10, TeacherID: "T001", Role: models.ClassTeacherRoleHomeroom},
+			{SchoolID: 1, ClassID: 10, TeacherID: "T002", Role: models.ClassTeacherRoleAssistant},
+			{SchoolID: 1, ClassID: 20, TeacherID: "T003", Role: models.ClassTeacherRoleHomeroom},
+		},
+		permissions: map[string]*models.ClassTeacherRolePermission{},
+	}
+
+	studentRepo := &fakeStudentRepo{students: map[uint]*models.Student{
+		1: {ID: 1, StudentID: "S001", ClassesID: 10},
+		2: {ID: 2, StudentID: "S002", ClassesID: 10},
+	}}
+
+	return teacherRepo, classTeacherRepo, studentRepo
+}
+
+func absentRequestWithStatus(status models.AbsentRequestStatus) *models.AbsentRequest {
+	return &models.AbsentRequest{ID: 1, StudentID: "S001", ClassID: 10, Status: status}
+}
+
+var (
+	adminActor     = Actor{UserID: 1, UserType: models.UserTypeAdmin.String()}
+	homeroomActor  = Actor{UserID: 1, UserType: models.UserTypeTeacher.String()}
+	assistantActor = Actor{UserID: 2, UserType: models.UserTypeTeacher.String()}
+	otherTeacher   = Actor{UserID: 3, UserType: models.UserTypeTeacher.String()}
+	ownerActor     = Actor{UserID: 1, UserType: models.UserTypeStudent.String()}
+	otherStudent   = Actor{UserID: 2, UserType: models.UserTypeStudent.String()}
+	guardianActor  = Actor{UserID: 1, UserType: models.UserTypeGuardian.String()}
+)
+
+func TestAbsentRequestPolicyCanDecide(t *testing.T) {
+	tests := []struct {
+		name        string
+		actor       Actor
+		status      models.AbsentRequestStatus
+		override    bool
+		permissions map[string]*models.ClassTeacherRolePermission
+		want        error
+	}{
+		{name: "student cannot decide", actor: ownerActor, status: models.AbsentRequestStatusPending, want: ErrStudentCannotDecide},
+		{name: "guardian cannot decide", actor: guardianActor, status: models.AbsentRequestStatusPending, want: ErrUnknownActor},
+		{name: "homeroom teacher decides pending request", actor: homeroomActor, status: models.AbsentRequestStatusPending},
+		{name: "homeroom teacher cannot change a decision", actor: homeroomActor, status: models.AbsentRequestStatusApproved, want: ErrAlreadyDecided},
+		{name: "homeroom teacher cannot override", actor: homeroomActor, status: models.AbsentRequestStatusRejected, override: true, want: ErrAlreadyDecided},
+		{name: "homeroom teacher cannot decide withdrawn request", actor: homeroomActor, status: models.AbsentRequestStatusWithdrawn, want: ErrRequestWithdrawn},
+		{name: "assistant cannot decide by default", actor: assistantActor, status: models.AbsentRequestStatusPending, want: ErrClassRoleNotAllowed},
+		{
+			name:   "assistant decides when the school allows it",
+			actor:  assistantActor,
+			status: models.AbsentRequestStatusPending,
+			permissions: map[string]*models.ClassTeacherRolePermission{
+				models.ClassTeacherRoleAssistant: {Role: models.ClassTeacherRoleAssistant, CanDecideAbsentRequests: true},
+			},
+		},
+		{
+			name:   "homeroom teacher cannot decide when the school forbids it",
+			actor:  homeroomActor,
+			status: models.AbsentRequestStatusPending,
+			permissions: map[string]*models.ClassTeacherRolePermission{
+				models.ClassTeacherRoleHomeroom: {Role: models.ClassTeacherRoleHomeroom},
+			},
+			want: ErrClassRoleNotAllowed,
+		},
+		{name: "teacher of another class cannot decide", actor: otherTeacher, status: models.AbsentRequestStatusPending, want: ErrNotClassTeacher},
+		{name: "admin decides pending request", actor: adminActor, status: models.AbsentRequestStatusPending},
+		{name: "admin cannot change a decision without override", actor: adminActor, status: models.AbsentRequestStatusApproved, want: ErrAlreadyDecided},
+		{name: "admin overrides an approval", actor: adminActor, status: models.AbsentRequestStatusApproved, override: true},
+		{name: "admin overrides a rejection", actor: adminActor, status: models.AbsentRequestStatusRejected, override: true},
+		{name: "admin cannot decide withdrawn request", actor: adminActor, status: models.AbsentRequestStatusWithdrawn, want: ErrRequestWithdrawn},
+		{name: "admin cannot override withdrawn request", actor: adminActor, status: models.AbsentRequestStatusWithdrawn, override: true, want: ErrRequestWithdrawn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teacherRepo, classTeacherRepo, studentRepo := newFixtureRepos()
+			for role, permission := range tt.permissions {
+				classTeacherRepo.permissions[role] = permission
+			}
+			p := NewAbsentRequestPolicy(teacherRepo, classTeacherRepo, studentRepo)
+
+			err := p.CanDecide(context.Background(), tt.actor, absentRequestWithStatus(tt.status), tt.override)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CanDecide() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbsentRequestPolicyCanComment(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  Actor
+		status models.AbsentRequestStatus
+		want   error
+	}{
+		{name: "owner comments on pending request", actor: ownerActor, status: models.AbsentRequestStatusPending},
+		{name: "other student cannot comment", actor: otherStudent, status: models.AbsentRequestStatusPending, want: ErrNotRequestOwner},
+		{name: "owner cannot comment once decided", actor: ownerActor, status: models.AbsentRequestStatusApproved, want: ErrAlreadyDecided},
+		{name: "assistant comments by default", actor: assistantActor, status: models.AbsentRequestStatusPending},
+		{name: "teacher of another class cannot comment", actor: otherTeacher, status: models.AbsentRequestStatusPending, want: ErrNotClassTeacher},
+		{name: "teacher cannot comment on withdrawn request", actor: homeroomActor, status: models.AbsentRequestStatusWithdrawn, want: ErrRequestWithdrawn},
+		{name: "admin comments on decided request", actor: adminActor, status: models.AbsentRequestStatusRejected},
+		{name: "guardian cannot comment", actor: guardianActor, status: models.AbsentRequestStatusPending, want: ErrUnknownActor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewAbsentRequestPolicy(newFixtureRepos())
+
+			err := p.CanComment(context.Background(), tt.actor, absentRequestWithStatus(tt.status))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CanComment() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbsentRequestPolicyCanAmend(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  Actor
+		status models.AbsentRequestStatus
+		want   error
+	}{
+		{name: "owner amends pending request", actor: ownerActor, status: models.AbsentRequestStatusPending},
+		{name: "owner cannot amend decided request", actor: ownerActor, status: models.AbsentRequestStatusRejected, want: ErrAlreadyDecided},
+		{name: "owner cannot amend withdrawn request", actor: ownerActor, status: models.AbsentRequestStatusWithdrawn, want: ErrRequestWithdrawn},
+		{name: "other student cannot amend", actor: otherStudent, status: models.AbsentRequestStatusPending, want: ErrNotRequestOwner},
+		{name: "teacher cannot amend", actor: homeroomActor, status: models.AbsentRequestStatusPending, want: ErrNotRequestOwner},
+		{name: "admin cannot amend", actor: adminActor, status: models.AbsentRequestStatusPending, want: ErrNotRequestOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewAbsentRequestPolicy(newFixtureRepos())
+
+			err := p.CanAmend(context.Background(), tt.actor, absentRequestWithStatus(tt.status))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CanAmend() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
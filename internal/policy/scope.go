@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+// ErrOutOfScope is reported for records the actor may not see. Handlers answer it
+// the same way as a missing record, so they do not reveal that the record exists.
+var ErrOutOfScope = errors.New("record is outside the user's scope")
+
+// ScopePolicy works out which student records an actor may read or change
+type ScopePolicy struct {
+	teacherRepo      repository.TeacherRepository
+	classRepo        repository.ClassRepository
+	studentRepo      repository.StudentRepository
+	classTeacherRepo repository.ClassTeacherRepository
+	adminRepo        repository.AdminRepository
+}
+
+// NewScopePolicy creates a new record scope policy
+func NewScopePolicy(
+	teacherRepo repository.TeacherRepository,
+	classRepo repository.ClassRepository,
+	studentRepo repository.StudentRepository,
+	classTeacherRepo repository.ClassTeacherRepository,
+	adminRepo repository.AdminRepository,
+) *ScopePolicy {
+	return &ScopePolicy{
+		teacherRepo:      teacherRepo,
+		classRepo:        classRepo,
+		studentRepo:      studentRepo,
+		classTeacherRepo: classTeacherRepo,
+		adminRepo:        adminRepo,
+	}
+}
+
+// ScopeFor returns the records the actor may see. Admins see every record,
+// teachers the records of the classes they teach in any role and students only their own.
+// Any other user type gets an empty scope.
+func (p *ScopePolicy) ScopeFor(ctx context.Context, actor Actor) (*models.RecordScope, error) {
+	switch actor.UserType {
+	case models.UserTypeAdmin.String():
+		return &models.RecordScope{All: true}, nil
+
+	case models.UserTypeTeacher.String():
+		teacher, err := p.teacherRepo.GetByID(ctx, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		classes, err := p.classRepo.GetByTeacher(ctx, teacher.TeacherID)
+		if err != nil {
+			return nil, err
+		}
+
+		scope := &models.RecordScope{ClassIDs: make([]uint, 0, len(classes))}
+		for _, class := range classes {
+			scope.ClassIDs = append(scope.ClassIDs, class.ID)
+		}
+
+		return scope, nil
+
+	case models.UserTypeStudent.String():
+		student, err := p.studentRepo.GetByID(ctx, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.RecordScope{StudentID: student.StudentID}, nil
+
+	default:
+		return &models.RecordScope{}, nil
+	}
+}
+
+// CanSeeEmergencyInfo reports whether the actor may see the emergency contacts and medical note
+// of a student in the class. Admins may, but not read-only auditors, and so may the homeroom teacher
+// of the class. Co-homeroom teachers, assistants and teachers of other classes may not.
+func (p *ScopePolicy) CanSeeEmergencyInfo(ctx context.Context, actor Actor, classID uint) (bool, error) {
+	switch actor.UserType {
+	case models.UserTypeAdmin.String():
+		admin, err := p.adminRepo.GetByID(ctx, actor.UserID)
+		if err != nil {
+			return false, err
+		}
+
+		return admin.Tier != models.AdminTierAuditor, nil
+
+	case models.UserTypeTeacher.String():
+		teacher, err := p.teacherRepo.GetByID(ctx, actor.UserID)
+		if err != nil {
+			return false, err
+		}
+
+		classTeachers, err := p.classTeacherRepo.GetByTeacher(ctx, teacher.TeacherID)
+		if err != nil {
+			return false, err
+		}
+
+		for _, classTeacher := range classTeachers {
+			if classTeacher.ClassID != classID {
+				continue
+			}
+
+			return classTeacher.Role == models.ClassTeacherRoleHomeroom, nil
+		}
+
+		return false, nil
+
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+	"github.com/michaelwp/student_attendance/internal/repository"
+)
+
+type fakeClassRepo struct {
+	repository.ClassRepository
+	classTeachers *fakeClassTeacherRepo
+}
+
+// GetByTeacher returns the classes the teacher holds any role in, like the class_teachers join does
+func (r *fakeClassRepo) GetByTeacher(ctx context.Context, teacherID string) ([]*models.Class, error) {
+	assignments, err := r.classTeachers.GetByTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make([]*models.Class, 0, len(assignments))
+	for _, assignment := range assignments {
+		classes = append(classes, &models.Class{ID: assignment.ClassID})
+	}
+	return classes, nil
+}
+
+type fakeAdminRepo struct {
+	repository.AdminRepository
+	admins map[uint]*models.Admin
+}
+
+func (r *fakeAdminRepo) GetByID(_ context.Context, id uint) (*models.Admin, error) {
+	admin, ok := r.admins[id]
+	if !ok {
+		return nil, fmt.Errorf("admin not found")
+	}
+	return admin, nil
+}
+
+// Admin 1 is a super-admin, admin 2 a regular admin and admin 3 an auditor.
+// Teacher 4 is co-homeroom teacher of class 20, on top of the absent request fixtures.
+func newScopePolicy() *ScopePolicy {
+	teacherRepo, classTeacherRepo, studentRepo := newFixtureRepos()
+	teacherRepo.teachers[4] = &models.Teacher{ID: 4, TeacherID: "T004"}
+	classTeacherRepo.assignments = append(classTeacherRepo.assignments,
+		&models.ClassTeacher{SchoolID: 1, ClassID: 20, TeacherID: "T004", Role: models.ClassTeacherRoleCoHomeroom},
+		&models.ClassTeacher{SchoolID: 1, ClassID: 20, TeacherID: "T002", Role: models.ClassTeacherRoleAssistant},
+	)
+
+	adminRepo := &fakeAdminRepo{admins: map[uint]*models.Admin{
+		1: {ID: 1, Tier: models.AdminTierSuperAdmin},
+		2: {ID: 2, Tier: models.AdminTierAdmin},
+		3: {ID: 3, Tier: models.AdminTierAuditor},
+	}}
+
+	return NewScopePolicy(teacherRepo, &fakeClassRepo{classTeachers: classTeacherRepo}, studentRepo, classTeacherRepo, adminRepo)
+}
+
+func TestScopePolicyScopeFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor Actor
+		want  models.RecordScope
+	}{
+		{name: "admin sees every record", actor: adminActor, want: models.RecordScope{All: true}},
+		{name: "homeroom teacher sees their class", actor: homeroomActor, want: models.RecordScope{ClassIDs: []uint{10}}},
+		{name: "assistant sees every class they assist", actor: assistantActor, want: models.RecordScope{ClassIDs: []uint{10, 20}}},
+		{name: "teacher of another class sees only it", actor: otherTeacher, want: models.RecordScope{ClassIDs: []uint{20}}},
+		{name: "student sees only their own records", actor: ownerActor, want: models.RecordScope{StudentID: "S001"}},
+		{name: "guardian gets an empty scope", actor: guardianActor, want: models.RecordScope{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := newScopePolicy().ScopeFor(context.Background(), tt.actor)
+			if err != nil {
+				t.Fatalf("ScopeFor() error = %v", err)
+			}
+
+			if scope.All != tt.want.All || scope.StudentID != tt.want.StudentID || !slices.Equal(scope.ClassIDs, tt.want.ClassIDs) {
+				t.Errorf("ScopeFor() = %+v, want %+v", *scope, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopePolicyScopeForUnknownUser(t *testing.T) {
+	unknownTeacher := Actor{UserID: 99, UserType: models.UserTypeTeacher.String()}
+	if _, err := newScopePolicy().ScopeFor(context.Background(), unknownTeacher); err == nil {
+		t.Error("ScopeFor() of an unknown teacher returned no error")
+	}
+
+	unknownStudent := Actor{UserID: 99, UserType: models.UserTypeStudent.String()}
+	if _, err := newScopePolicy().ScopeFor(context.Background(), unknownStudent); err == nil {
+		t.Error("ScopeFor() of an unknown student returned no error")
+	}
+}
+
+func TestScopePolicyCanSeeEmergencyInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   Actor
+		classID uint
+		want    bool
+	}{
+		{name: "super-admin may", actor: Actor{UserID: 1, UserType: models.UserTypeAdmin.String()}, classID: 10, want: true},
+		{name: "admin may", actor: Actor{UserID: 2, UserType: models.UserTypeAdmin.String()}, classID: 10, want: true},
+		{name: "auditor may not", actor: Actor{UserID: 3, UserType: models.UserTypeAdmin.String()}, classID: 10},
+		{name: "homeroom teacher may", actor: homeroomActor, classID: 10, want: true},
+		{name: "co-homeroom teacher may not", actor: Actor{UserID: 4, UserType: models.UserTypeTeacher.String()}, classID: 20},
+		{name: "assistant may not", actor: assistantActor, classID: 10},
+		{name: "homeroom teacher of another class may not", actor: homeroomActor, classID: 20},
+		{name: "student may not", actor: ownerActor, classID: 10},
+		{name: "guardian may not", actor: guardianActor, classID: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newScopePolicy().CanSeeEmergencyInfo(context.Background(), tt.actor, tt.classID)
+			if err != nil {
+				t.Fatalf("CanSeeEmergencyInfo() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("CanSeeEmergencyInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type absenceQuotaRepository struct {
+	db *sql.DB
+}
+
+// NewAbsenceQuotaRepository creates a new absence quota repository
+func NewAbsenceQuotaRepository(db *sql.DB) AbsenceQuotaRepository {
+	return &absenceQuotaRepository{db: db}
+}
+
+// Create adds the quota to the school of the request. Its category must belong to the same school.
+func (r *absenceQuotaRepository) Create(ctx context.Context, quota *models.AbsenceQuota) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create absence quota: %w", err)
+	}
+
+	query := `
+		INSERT INTO absence_quotas (
+			school_id
+			, category_id
+			, term_name
+			, start_date
+			, end_date
+
+			, max_days
+			, created_by
+			, created_at
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		schoolID,
+		quota.CategoryID,
+		quota.TermName,
+		quota.StartDate,
+		quota.EndDate,
+
+		quota.MaxDays,
+		quota.CreatedBy,
+	).Scan(&quota.ID, &quota.SchoolID, &quota.CreatedAt, &quota.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create absence quota: %w", err)
+	}
+
+	return nil
+}
+
+func (r *absenceQuotaRepository) GetByID(ctx context.Context, id uint) (*models.AbsenceQuota, error) {
+	query := `
+		SELECT id, school_id, category_id, term_name, start_date, end_date, max_days,
+		       created_at, updated_at, created_by, updated_by
+		FROM absence_quotas
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
+
+	quota := &models.AbsenceQuota{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&quota.ID,
+		&quota.SchoolID,
+		&quota.CategoryID,
+		&quota.TermName,
+		&quota.StartDate,
+		&quota.EndDate,
+		&quota.MaxDays,
+		&quota.CreatedAt,
+		&quota.UpdatedAt,
+		&quota.CreatedBy,
+		&quota.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("absence quota not found")
+		}
+		return nil, fmt.Errorf("failed to get absence quota: %w", err)
+	}
+
+	return quota, nil
+}
+
+// GetAll returns the quotas of every category when categoryID is 0
+func (r *absenceQuotaRepository) GetAll(ctx context.Context, categoryID uint) ([]*models.AbsenceQuota, error) {
+	query := `
+		SELECT id, school_id, category_id, term_name, start_date, end_date, max_days,
+		       created_at, updated_at, created_by, updated_by
+		FROM absence_quotas
+		WHERE deleted_at IS NULL AND (category_id = $1 OR $1 = 0) AND ($2 = 0 OR school_id = $2)
+		ORDER BY start_date DESC, category_id`
+
+	rows, err := r.db.QueryContext(ctx, query, categoryID, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absence quotas: %w", err)
+	}
+	defer rows.Close()
+
+	var quotas []*models.AbsenceQuota
+	for rows.Next() {
+		quota := &models.AbsenceQuota{}
+		err := rows.Scan(
+			&quota.ID,
+			&quota.SchoolID,
+			&quota.CategoryID,
+			&quota.TermName,
+			&quota.StartDate,
+			&quota.EndDate,
+			&quota.MaxDays,
+			&quota.CreatedAt,
+			&quota.UpdatedAt,
+			&quota.CreatedBy,
+			&quota.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absence quota: %w", err)
+		}
+		quotas = append(quotas, quota)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absence quotas: %w", err)
+	}
+
+	return quotas, nil
+}
+
+func (r *absenceQuotaRepository) Update(ctx context.Context, quota *models.AbsenceQuota) error {
+	query := `
+		UPDATE absence_quotas
+		SET category_id = $2, term_name = $3, start_date = $4, end_date = $5, max_days = $6,
+		    updated_by = $7, updated_at = NOW()
+		WHERE id = $1 AND ($8 = 0 OR school_id = $8) AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		quota.ID,
+		quota.CategoryID,
+		quota.TermName,
+		quota.StartDate,
+		quota.EndDate,
+		quota.MaxDays,
+		quota.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&quota.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absence quota not found")
+		}
+		return fmt.Errorf("failed to update absence quota: %w", err)
+	}
+
+	return nil
+}
+
+func (r *absenceQuotaRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	query := `
+		UPDATE absence_quotas
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absence quota not found")
+		}
+		return fmt.Errorf("failed to update absence quota delete info: %w", err)
+	}
+
+	return nil
+}
+
+// HasOverlap reports whether another quota of the category already covers part of the term
+func (r *absenceQuotaRepository) HasOverlap(ctx context.Context, categoryID uint, startDate, endDate time.Time, excludeID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM absence_quotas
+			WHERE category_id = $1
+			  AND id <> $4
+			  AND deleted_at IS NULL
+			  AND start_date <= DATE($3)
+			  AND end_date >= DATE($2)
+			  AND ($5 = 0 OR school_id = $5)
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, categoryID, startDate, endDate, excludeID, schoolFilter(ctx)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check overlapping absence quotas: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetForDate returns the quota of the category whose term includes the date, or nil when none applies
+func (r *absenceQuotaRepository) GetForDate(ctx context.Context, categoryID uint, date time.Time) (*models.AbsenceQuota, error) {
+	query := `
+		SELECT id, school_id, category_id, term_name, start_date, end_date, max_days,
+		       created_at, updated_at, created_by, updated_by
+		FROM absence_quotas
+		WHERE category_id = $1 AND start_date <= DATE($2) AND end_date >= DATE($2) AND deleted_at IS NULL
+		  AND ($3 = 0 OR school_id = $3)
+		LIMIT 1`
+
+	quota := &models.AbsenceQuota{}
+	err := r.db.QueryRowContext(ctx, query, categoryID, date, schoolFilter(ctx)).Scan(
+		&quota.ID,
+		&quota.SchoolID,
+		&quota.CategoryID,
+		&quota.TermName,
+		&quota.StartDate,
+		&quota.EndDate,
+		&quota.MaxDays,
+		&quota.CreatedAt,
+		&quota.UpdatedAt,
+		&quota.CreatedBy,
+		&quota.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get absence quota for date: %w", err)
+	}
+
+	return quota, nil
+}
+
+// GetUsedDays adds up the student's requests of the quota's category that start within its term.
+// Approved requests are the ledger of used days; pending ones are reported separately.
+func (r *absenceQuotaRepository) GetUsedDays(ctx context.Context, quota *models.AbsenceQuota, studentID string, excludeRequestID uint) (int, int, error) {
+	query := `
+		SELECT COALESCE(SUM(total_days) FILTER (WHERE status = 'approved'), 0)
+		     , COALESCE(SUM(total_days) FILTER (WHERE status = 'pending'), 0)
+		FROM absent_requests
+		WHERE student_id = $1
+		  AND category_id = $2
+		  AND start_date BETWEEN DATE($3) AND DATE($4)
+		  AND id <> $5
+		  AND ($6 = 0 OR school_id = $6)
+		  AND deleted_at IS NULL`
+
+	var usedDays, pendingDays int
+	err := r.db.QueryRowContext(ctx, query, studentID, quota.CategoryID, quota.StartDate, quota.EndDate, excludeRequestID, schoolFilter(ctx)).
+		Scan(&usedDays, &pendingDays)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get absence quota usage: %w", err)
+	}
+
+	return usedDays, pendingDays, nil
+}
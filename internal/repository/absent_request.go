@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/michaelwp/student_attendance/internal/models"
 )
 
@@ -18,20 +20,52 @@ func NewAbsentRequestRepository(db *sql.DB) AbsentRequestRepository {
 }
 
 func (r *absentRequestRepository) Create(ctx context.Context, request *models.AbsentRequest) error {
+	// the request belongs to the school of its student
 	query := `
-		INSERT INTO absent_requests (student_id, class_id, request_date, reason, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		RETURNING id, created_at, updated_at`
+		INSERT INTO absent_requests (
+			student_id
+			, class_id
+			, category_id
+			, request_date
+			, start_date
+
+			, end_date
+			, exclude_non_school_days
+			, total_days
+			, reason
+			, status
+
+			, submitted_by_guardian
+			, created_at
+			, updated_at
+			, school_id
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW(), s.school_id
+		FROM students s
+		WHERE s.student_id = $1 AND ($12 = 0 OR s.school_id = $12)
+		RETURNING id, version, created_at, updated_at`
 
 	err := r.db.QueryRowContext(ctx, query,
 		request.StudentID,
 		request.ClassID,
+		request.CategoryID,
 		request.RequestDate,
+		request.StartDate,
+
+		request.EndDate,
+		request.ExcludeNonSchoolDays,
+		request.TotalDays,
 		request.Reason,
 		request.Status,
-	).Scan(&request.ID, &request.CreatedAt, &request.UpdatedAt)
+
+		request.SubmittedByGuardian,
+		schoolFilter(ctx),
+	).Scan(&request.ID, &request.Version, &request.CreatedAt, &request.UpdatedAt)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("student not found")
+		}
 		return fmt.Errorf("failed to create absent request: %w", err)
 	}
 
@@ -40,19 +74,41 @@ func (r *absentRequestRepository) Create(ctx context.Context, request *models.Ab
 
 func (r *absentRequestRepository) GetByID(ctx context.Context, id uint) (*models.AbsentRequest, error) {
 	query := `
-		SELECT id, student_id, class_id, request_date, reason, status, created_at, updated_at
-		FROM absent_requests WHERE id = $1`
+		SELECT id, school_id, student_id, class_id, category_id, request_date, start_date, end_date, exclude_non_school_days, total_days,
+		       reason, status, version, created_at, updated_at, approved_by, approved_at, rejected_by, rejected_at,
+		       admin_decided_by, admin_decided_at, reviewer_note, escalated_at, escalated_to, withdrawn_at,
+		       quota_exceeded_at, submitted_by_guardian
+		FROM absent_requests WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
 	request := &models.AbsentRequest{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&request.ID,
+		&request.SchoolID,
 		&request.StudentID,
 		&request.ClassID,
+		&request.CategoryID,
 		&request.RequestDate,
+		&request.StartDate,
+		&request.EndDate,
+		&request.ExcludeNonSchoolDays,
+		&request.TotalDays,
 		&request.Reason,
 		&request.Status,
+		&request.Version,
 		&request.CreatedAt,
 		&request.UpdatedAt,
+		&request.ApprovedBy,
+		&request.ApprovedAt,
+		&request.RejectedBy,
+		&request.RejectedAt,
+		&request.AdminDecidedBy,
+		&request.AdminDecidedAt,
+		&request.ReviewerNote,
+		&request.EscalatedAt,
+		&request.EscalatedTo,
+		&request.WithdrawnAt,
+		&request.QuotaExceededAt,
+		&request.SubmittedByGuardian,
 	)
 
 	if err != nil {
@@ -70,10 +126,16 @@ func (r *absentRequestRepository) GetByStudent(ctx context.Context, studentID st
 		SELECT id
 		     , student_id
 		     , class_id
+		     , category_id
 		     , request_date
+		     , start_date
+		     , end_date
+		     , exclude_non_school_days
+		     , total_days
+
 		     , reason
-		     
 		     , status
+		     , version
 		     , created_at
 		     , updated_at
 			 , approved_by
@@ -81,12 +143,19 @@ func (r *absentRequestRepository) GetByStudent(ctx context.Context, studentID st
 		
 			 , rejected_by
 			 , rejected_at
+			 , admin_decided_by
+			 , admin_decided_at
+			 , reviewer_note
+			 , escalated_at
+			 , escalated_to
+			 , withdrawn_at
+			 , submitted_by_guardian
 		FROM absent_requests 
-		WHERE student_id = $1 AND deleted_at IS NULL
+		WHERE student_id = $1 AND ($4 = 0 OR school_id = $4) AND deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, studentID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, studentID, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absent requests by student: %w", err)
 	}
@@ -99,10 +168,16 @@ func (r *absentRequestRepository) GetByStudent(ctx context.Context, studentID st
 			&request.ID,
 			&request.StudentID,
 			&request.ClassID,
+			&request.CategoryID,
 			&request.RequestDate,
-			&request.Reason,
+			&request.StartDate,
+			&request.EndDate,
+			&request.ExcludeNonSchoolDays,
+			&request.TotalDays,
 
+			&request.Reason,
 			&request.Status,
+			&request.Version,
 			&request.CreatedAt,
 			&request.UpdatedAt,
 			&request.ApprovedBy,
@@ -110,6 +185,13 @@ func (r *absentRequestRepository) GetByStudent(ctx context.Context, studentID st
 
 			&request.RejectedBy,
 			&request.RejectedAt,
+			&request.AdminDecidedBy,
+			&request.AdminDecidedAt,
+			&request.ReviewerNote,
+			&request.EscalatedAt,
+			&request.EscalatedTo,
+			&request.WithdrawnAt,
+			&request.SubmittedByGuardian,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan absent request: %w", err)
@@ -126,13 +208,14 @@ func (r *absentRequestRepository) GetByStudent(ctx context.Context, studentID st
 
 func (r *absentRequestRepository) GetByClass(ctx context.Context, classID uint, limit, offset int) ([]*models.AbsentRequest, error) {
 	query := `
-		SELECT id, student_id, class_id, request_date, reason, status, created_at, updated_at
+		SELECT id, student_id, class_id, category_id, request_date, start_date, end_date, exclude_non_school_days, total_days,
+		       reason, status, created_at, updated_at
 		FROM absent_requests 
-		WHERE class_id = $1
+		WHERE class_id = $1 AND ($4 = 0 OR school_id = $4)
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, classID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, classID, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absent requests by class: %w", err)
 	}
@@ -145,7 +228,12 @@ func (r *absentRequestRepository) GetByClass(ctx context.Context, classID uint,
 			&request.ID,
 			&request.StudentID,
 			&request.ClassID,
+			&request.CategoryID,
 			&request.RequestDate,
+			&request.StartDate,
+			&request.EndDate,
+			&request.ExcludeNonSchoolDays,
+			&request.TotalDays,
 			&request.Reason,
 			&request.Status,
 			&request.CreatedAt,
@@ -164,15 +252,18 @@ func (r *absentRequestRepository) GetByClass(ctx context.Context, classID uint,
 	return requests, nil
 }
 
-func (r *absentRequestRepository) GetByStatus(ctx context.Context, status models.AbsentRequestStatus, limit, offset int) ([]*models.AbsentRequest, error) {
+func (r *absentRequestRepository) GetByStatus(ctx context.Context, scope *models.RecordScope, status models.AbsentRequestStatus, limit, offset int) ([]*models.AbsentRequest, error) {
 	query := `
-		SELECT id, student_id, class_id, request_date, reason, status, created_at, updated_at
+		SELECT id, student_id, class_id, category_id, request_date, start_date, end_date, exclude_non_school_days, total_days,
+		       reason, status, created_at, updated_at
 		FROM absent_requests 
-		WHERE status = $1
+		WHERE status = $1 AND ($4 OR class_id = ANY($5) OR student_id = $6) AND ($7 = 0 OR school_id = $7)
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	args := append([]interface{}{status, limit, offset}, scopeArgs(scope)...)
+	args = append(args, schoolFilter(ctx))
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absent requests by status: %w", err)
 	}
@@ -185,7 +276,12 @@ func (r *absentRequestRepository) GetByStatus(ctx context.Context, status models
 			&request.ID,
 			&request.StudentID,
 			&request.ClassID,
+			&request.CategoryID,
 			&request.RequestDate,
+			&request.StartDate,
+			&request.EndDate,
+			&request.ExcludeNonSchoolDays,
+			&request.TotalDays,
 			&request.Reason,
 			&request.Status,
 			&request.CreatedAt,
@@ -204,40 +300,149 @@ func (r *absentRequestRepository) GetByStatus(ctx context.Context, status models
 	return requests, nil
 }
 
-func (r *absentRequestRepository) GetPending(ctx context.Context, limit, offset int) ([]*models.AbsentRequest, error) {
-	return r.GetByStatus(ctx, models.AbsentRequestStatusPending, limit, offset)
+func (r *absentRequestRepository) GetPending(ctx context.Context, scope *models.RecordScope, limit, offset int) ([]*models.AbsentRequest, error) {
+	return r.GetByStatus(ctx, scope, models.AbsentRequestStatusPending, limit, offset)
 }
 
-func (r *absentRequestRepository) Update(ctx context.Context, request *models.AbsentRequest) error {
+func (r *absentRequestRepository) Amend(ctx context.Context, request *models.AbsentRequest, supersededBy uint) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// keep the request as reviewers last saw it before it is overwritten
+	versionQuery := `
+		INSERT INTO absent_request_versions (
+			absent_request_id
+			, version
+			, category_id
+			, start_date
+			, end_date
+
+			, exclude_non_school_days
+			, total_days
+			, reason
+			, superseded_by
+			, superseded_at
+		)
+		SELECT id, version, category_id, start_date, end_date,
+		       exclude_non_school_days, total_days, reason, $2, NOW()
+		FROM absent_requests
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND status = 'pending' AND deleted_at IS NULL
+		FOR UPDATE`
+
+	result, err := tx.ExecContext(ctx, versionQuery, request.ID, supersededBy, schoolFilter(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to save absent request version: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrAbsentRequestNotPending
+	}
+
 	query := `
 		UPDATE absent_requests 
-		SET student_id = $2, class_id = $3, request_date = $4, reason = $5, status = $6, updated_at = NOW()
+		SET request_date = $2, start_date = $3, end_date = $4, exclude_non_school_days = $5,
+		    total_days = $6, reason = $7, category_id = $8, version = version + 1,
+		    updated_at = NOW()
 		WHERE id = $1
-		RETURNING updated_at`
+		RETURNING version, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		request.ID,
-		request.StudentID,
-		request.ClassID,
 		request.RequestDate,
+		request.StartDate,
+		request.EndDate,
+		request.ExcludeNonSchoolDays,
+		request.TotalDays,
 		request.Reason,
-		request.Status,
-	).Scan(&request.UpdatedAt)
+		request.CategoryID,
+	).Scan(&request.Version, &request.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update absent request: %w", err)
+	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit absent request amendment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *absentRequestRepository) Withdraw(ctx context.Context, id uint, studentID string) error {
+	query := `
+		UPDATE absent_requests
+		SET status = 'withdrawn', withdrawn_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND student_id = $2 AND ($3 = 0 OR school_id = $3) AND status = 'pending' AND deleted_at IS NULL
+		RETURNING withdrawn_at`
+
+	var withdrawnAt time.Time
+	err := r.db.QueryRowContext(ctx, query, id, studentID, schoolFilter(ctx)).Scan(&withdrawnAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("absent request not found")
+			return models.ErrAbsentRequestNotPending
 		}
-		return fmt.Errorf("failed to update absent request: %w", err)
+		return fmt.Errorf("failed to withdraw absent request: %w", err)
 	}
 
 	return nil
 }
 
+func (r *absentRequestRepository) GetVersions(ctx context.Context, id uint) ([]*models.AbsentRequestVersion, error) {
+	query := `
+		SELECT v.id, v.absent_request_id, v.version, v.category_id, v.start_date, v.end_date,
+		       v.exclude_non_school_days, v.total_days, v.reason, v.superseded_by, v.superseded_at
+		FROM absent_request_versions v
+		JOIN absent_requests ar ON v.absent_request_id = ar.id
+		WHERE v.absent_request_id = $1 AND ($2 = 0 OR ar.school_id = $2)
+		ORDER BY v.version`
+
+	rows, err := r.db.QueryContext(ctx, query, id, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absent request versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*models.AbsentRequestVersion
+	for rows.Next() {
+		version := &models.AbsentRequestVersion{}
+		err := rows.Scan(
+			&version.ID,
+			&version.AbsentRequestID,
+			&version.Version,
+			&version.CategoryID,
+			&version.StartDate,
+			&version.EndDate,
+			&version.ExcludeNonSchoolDays,
+			&version.TotalDays,
+			&version.Reason,
+			&version.SupersededBy,
+			&version.SupersededAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent request versions: %w", err)
+	}
+
+	return versions, nil
+}
+
 func (r *absentRequestRepository) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM absent_requests WHERE id = $1`
+	query := `DELETE FROM absent_requests WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete absent request: %w", err)
 	}
@@ -255,10 +460,10 @@ func (r *absentRequestRepository) Delete(ctx context.Context, id uint) error {
 }
 
 func (r *absentRequestRepository) GetTotalAbsentRequests(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM absent_requests WHERE deleted_at IS NULL`
+	query := `SELECT COUNT(*) FROM absent_requests WHERE ($1 = 0 OR school_id = $1) AND deleted_at IS NULL`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total absent_request: %w", err)
 	}
@@ -266,36 +471,34 @@ func (r *absentRequestRepository) GetTotalAbsentRequests(ctx context.Context) (i
 	return count, nil
 }
 
-func (r *absentRequestRepository) UpdateDeleteInfo(ctx context.Context, id uint, studentID uint, deletedBy uint) error {
+func (r *absentRequestRepository) GetCountByStudent(ctx context.Context, studentID string) (int, error) {
 	query := `
-		UPDATE absent_requests
-		SET deleted_at = NOW(), deleted_by = $2
-		WHERE id = $1 AND student_id = $3
-		RETURNING deleted_at`
+		SELECT COUNT(*)
+		FROM absent_requests
+		WHERE student_id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
-	var deletedAt string
-	err := r.db.QueryRowContext(ctx, query, id, deletedBy, studentID).Scan(&deletedAt)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, studentID, schoolFilter(ctx)).Scan(&count)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("absent_request not found")
-		}
-		return fmt.Errorf("failed to update absent_request delete info: %w", err)
+		return 0, fmt.Errorf("failed to get absent requests count by student: %w", err)
 	}
 
-	return nil
+	return count, nil
 }
 
 func (r *absentRequestRepository) GetByTeacher(ctx context.Context, teacherID string, limit, offset int) ([]*models.AbsentRequest, error) {
 	query := `
-		SELECT ar.id, ar.student_id, ar.class_id, ar.request_date, ar.reason, ar.status, 
-		       ar.created_at, ar.updated_at, ar.approved_by, ar.approved_at, ar.rejected_by, ar.rejected_at
+		SELECT ar.id, ar.student_id, ar.class_id, ar.category_id, ar.request_date, ar.start_date, ar.end_date,
+		       ar.exclude_non_school_days, ar.total_days, ar.reason, ar.status, ar.version,
+		       ar.created_at, ar.updated_at, ar.approved_by, ar.approved_at, ar.rejected_by, ar.rejected_at,
+		       ar.admin_decided_by, ar.admin_decided_at, ar.reviewer_note, ar.escalated_at, ar.escalated_to, ar.withdrawn_at
 		FROM absent_requests ar
-		JOIN classes c ON ar.class_id = c.id
-		WHERE c.homeroom_teacher = $1 AND ar.deleted_at IS NULL
+		JOIN class_teachers ct ON ct.class_id = ar.class_id AND ct.school_id = ar.school_id
+		WHERE ct.teacher_id = $1 AND ($4 = 0 OR ar.school_id = $4) AND ar.deleted_at IS NULL
 		ORDER BY ar.created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, teacherID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, teacherID, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absent requests by teacher: %w", err)
 	}
@@ -308,15 +511,27 @@ func (r *absentRequestRepository) GetByTeacher(ctx context.Context, teacherID st
 			&request.ID,
 			&request.StudentID,
 			&request.ClassID,
+			&request.CategoryID,
 			&request.RequestDate,
+			&request.StartDate,
+			&request.EndDate,
+			&request.ExcludeNonSchoolDays,
+			&request.TotalDays,
 			&request.Reason,
 			&request.Status,
+			&request.Version,
 			&request.CreatedAt,
 			&request.UpdatedAt,
 			&request.ApprovedBy,
 			&request.ApprovedAt,
 			&request.RejectedBy,
 			&request.RejectedAt,
+			&request.AdminDecidedBy,
+			&request.AdminDecidedAt,
+			&request.ReviewerNote,
+			&request.EscalatedAt,
+			&request.EscalatedTo,
+			&request.WithdrawnAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan absent request: %w", err)
@@ -335,11 +550,11 @@ func (r *absentRequestRepository) GetCountByTeacher(ctx context.Context, teacher
 	query := `
 		SELECT COUNT(*)
 		FROM absent_requests ar
-		JOIN classes c ON ar.class_id = c.id
-		WHERE c.homeroom_teacher = $1 AND ar.deleted_at IS NULL`
+		JOIN class_teachers ct ON ct.class_id = ar.class_id AND ct.school_id = ar.school_id
+		WHERE ct.teacher_id = $1 AND ($2 = 0 OR ar.school_id = $2) AND ar.deleted_at IS NULL`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, teacherID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, teacherID, schoolFilter(ctx)).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get absent requests count by teacher: %w", err)
 	}
@@ -347,40 +562,460 @@ func (r *absentRequestRepository) GetCountByTeacher(ctx context.Context, teacher
 	return count, nil
 }
 
-func (r *absentRequestRepository) Approve(ctx context.Context, id uint, teacherID uint) error {
+// Approve approves the request for the teacher and excuses the attendance of the given days.
+// If the request is no longer pending, nothing changes and ErrAbsentRequestNotPending is returned.
+func (r *absentRequestRepository) Approve(ctx context.Context, id uint, teacherID uint, note *string, excusedDays []time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		UPDATE absent_requests
-		SET status = 'approved', approved_by = $2, approved_at = NOW(), updated_at = NOW()
-		WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL
-		RETURNING updated_at`
+		SET status = 'approved', approved_by = $2, approved_at = NOW(), reviewer_note = $3, updated_at = NOW()
+		WHERE id = $1 AND ($4 = 0 OR school_id = $4) AND status = 'pending' AND deleted_at IS NULL
+		RETURNING student_id, class_id, reason, school_id`
 
-	var updatedAt string
-	err := r.db.QueryRowContext(ctx, query, id, teacherID).Scan(&updatedAt)
+	var studentID, reason string
+	var classID, schoolID uint
+	err = tx.QueryRowContext(ctx, query, id, teacherID, note, schoolFilter(ctx)).Scan(&studentID, &classID, &reason, &schoolID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("absent request not found or not pending")
+			return models.ErrAbsentRequestNotPending
 		}
 		return fmt.Errorf("failed to approve absent request: %w", err)
 	}
 
+	if err := excuseAttendances(ctx, tx, id, schoolID, studentID, classID, reason, excusedDays, teacherID, "teacher"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit absent request approval: %w", err)
+	}
+
 	return nil
 }
 
-func (r *absentRequestRepository) Reject(ctx context.Context, id uint, teacherID uint) error {
+// Reject rejects the request for the teacher.
+// If the request is no longer pending, nothing changes and ErrAbsentRequestNotPending is returned.
+func (r *absentRequestRepository) Reject(ctx context.Context, id uint, teacherID uint, note *string) error {
 	query := `
 		UPDATE absent_requests
-		SET status = 'rejected', rejected_by = $2, rejected_at = NOW(), updated_at = NOW()
-		WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL
+		SET status = 'rejected', rejected_by = $2, rejected_at = NOW(), reviewer_note = $3, updated_at = NOW()
+		WHERE id = $1 AND ($4 = 0 OR school_id = $4) AND status = 'pending' AND deleted_at IS NULL
 		RETURNING updated_at`
 
 	var updatedAt string
-	err := r.db.QueryRowContext(ctx, query, id, teacherID).Scan(&updatedAt)
+	err := r.db.QueryRowContext(ctx, query, id, teacherID, note, schoolFilter(ctx)).Scan(&updatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("absent request not found or not pending")
+			return models.ErrAbsentRequestNotPending
 		}
 		return fmt.Errorf("failed to reject absent request: %w", err)
 	}
 
 	return nil
 }
+
+// DecideByAdmin sets the status of the request as decided by an admin. From is the status the decision
+// was made against; if the request no longer has it, nothing changes and ErrAbsentRequestChanged is returned.
+func (r *absentRequestRepository) DecideByAdmin(
+	ctx context.Context,
+	id uint,
+	from, status models.AbsentRequestStatus,
+	adminID uint,
+	note *string,
+	excusedDays []time.Time,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// the row stays locked until the decision commits, so a withdrawal or a teacher's
+	// decision cannot slip in between
+	lockQuery := `
+		SELECT status
+		FROM absent_requests
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL
+		FOR UPDATE`
+
+	var current models.AbsentRequestStatus
+	if err := tx.QueryRowContext(ctx, lockQuery, id, schoolFilter(ctx)).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absent request not found")
+		}
+		return fmt.Errorf("failed to lock absent request: %w", err)
+	}
+
+	if current != from {
+		return models.ErrAbsentRequestChanged
+	}
+
+	// the teacher columns only reference teachers, so an admin decision clears them
+	// and is recorded in admin_decided_by instead
+	query := `
+		UPDATE absent_requests
+		SET status = $2,
+		    approved_by = NULL,
+		    approved_at = CASE WHEN $2 = 'approved' THEN NOW() END,
+		    rejected_by = NULL,
+		    rejected_at = CASE WHEN $2 = 'rejected' THEN NOW() END,
+		    admin_decided_by = $3,
+		    admin_decided_at = NOW(),
+		    reviewer_note = $4,
+		    updated_at = NOW()
+		WHERE id = $1 AND ($5 = 0 OR school_id = $5) AND deleted_at IS NULL
+		RETURNING student_id, class_id, reason, school_id`
+
+	var studentID, reason string
+	var classID, schoolID uint
+	err = tx.QueryRowContext(ctx, query, id, status, adminID, note, schoolFilter(ctx)).Scan(&studentID, &classID, &reason, &schoolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absent request not found")
+		}
+		return fmt.Errorf("failed to decide absent request: %w", err)
+	}
+
+	switch {
+	case status == models.AbsentRequestStatusApproved:
+		if err := excuseAttendances(ctx, tx, id, schoolID, studentID, classID, reason, excusedDays, adminID, "admin"); err != nil {
+			return err
+		}
+	case from == models.AbsentRequestStatusApproved:
+		// a revoked approval no longer excuses the days it excused itself
+		revokeQuery := `
+			UPDATE attendances
+			SET status = 'absent', excused_by_request_id = NULL, updated_at = NOW(), updated_by = $3
+			WHERE excused_by_request_id = $1 AND school_id = $2 AND status = 'excused' AND deleted_at IS NULL`
+
+		if _, err := tx.ExecContext(ctx, revokeQuery, id, schoolID, adminID); err != nil {
+			return fmt.Errorf("failed to revoke excused attendance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit absent request decision: %w", err)
+	}
+
+	return nil
+}
+
+// excuseAttendances records each day as excused attendance of the student in the school, marked
+// as excused by the request. An absence already recorded for the day becomes excused, present and late records are kept.
+func excuseAttendances(
+	ctx context.Context,
+	tx *sql.Tx,
+	requestID uint,
+	schoolID uint,
+	studentID string,
+	classID uint,
+	reason string,
+	days []time.Time,
+	decidedBy uint,
+	decidedByLevel string,
+) error {
+	updateQuery := `
+		UPDATE attendances
+		SET status = 'excused', description = COALESCE(description, $3), excused_by_request_id = $6,
+		    updated_at = NOW(), updated_by = $4
+		WHERE student_id = $1 AND school_id = $5 AND date = DATE($2) AND status = 'absent' AND deleted_at IS NULL`
+
+	insertQuery := `
+		INSERT INTO attendances (student_id, class_id, date, status, description, created_at, time_in, created_by, created_by_level,
+		                         school_id, excused_by_request_id)
+		SELECT $1, $2, DATE($3), 'excused', $4, NOW(), NOW(), $5, $6, s.school_id, $8
+		FROM students s
+		WHERE s.student_id = $1 AND s.school_id = $7 AND NOT EXISTS (
+			SELECT 1 FROM attendances
+			WHERE student_id = $1 AND school_id = $7 AND date = DATE($3) AND timetable_slot_id IS NULL AND deleted_at IS NULL
+		)`
+
+	for _, day := range days {
+		if _, err := tx.ExecContext(ctx, updateQuery, studentID, day, reason, decidedBy, schoolID, requestID); err != nil {
+			return fmt.Errorf("failed to excuse attendance: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, insertQuery, studentID, classID, day, reason, decidedBy, decidedByLevel, schoolID, requestID); err != nil {
+			return fmt.Errorf("failed to create excused attendance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *absentRequestRepository) GetByDate(ctx context.Context, scope *models.RecordScope, date time.Time, limit, offset int) ([]*models.AbsentRequest, error) {
+	// a request matches every day inside its range, not only the day it starts
+	query := `
+		SELECT id, student_id, class_id, category_id, request_date, start_date, end_date, exclude_non_school_days, total_days,
+		       reason, status, version, created_at, updated_at, approved_by, approved_at, rejected_by, rejected_at,
+		       admin_decided_by, admin_decided_at, reviewer_note, escalated_at, escalated_to, withdrawn_at
+		FROM absent_requests
+		WHERE start_date <= DATE($1) AND end_date >= DATE($1) AND deleted_at IS NULL
+		  AND ($4 OR class_id = ANY($5) OR student_id = $6) AND ($7 = 0 OR school_id = $7)
+		ORDER BY start_date, created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	args := append([]interface{}{date, limit, offset}, scopeArgs(scope)...)
+	args = append(args, schoolFilter(ctx))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absent requests by date: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.AbsentRequest
+	for rows.Next() {
+		request := &models.AbsentRequest{}
+		err := rows.Scan(
+			&request.ID,
+			&request.StudentID,
+			&request.ClassID,
+			&request.CategoryID,
+			&request.RequestDate,
+			&request.StartDate,
+			&request.EndDate,
+			&request.ExcludeNonSchoolDays,
+			&request.TotalDays,
+			&request.Reason,
+			&request.Status,
+			&request.Version,
+			&request.CreatedAt,
+			&request.UpdatedAt,
+			&request.ApprovedBy,
+			&request.ApprovedAt,
+			&request.RejectedBy,
+			&request.RejectedAt,
+			&request.AdminDecidedBy,
+			&request.AdminDecidedAt,
+			&request.ReviewerNote,
+			&request.EscalatedAt,
+			&request.EscalatedTo,
+			&request.WithdrawnAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *absentRequestRepository) HasOverlap(ctx context.Context, studentID string, startDate, endDate time.Time, excludeID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM absent_requests
+			WHERE student_id = $1
+			  AND id <> $4
+			  AND status = ANY($6)
+			  AND deleted_at IS NULL
+			  AND start_date <= DATE($3)
+			  AND end_date >= DATE($2)
+			  AND ($5 = 0 OR school_id = $5)
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, studentID, startDate, endDate, excludeID, schoolFilter(ctx),
+		pq.Array(models.AbsentRequestStatusesHoldingDates)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check overlapping absent requests: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *absentRequestRepository) GetCategoryReport(ctx context.Context, startDate, endDate time.Time) ([]*models.AbsentCategoryReport, error) {
+	// requests filed before categories existed are reported under "uncategorized"
+	query := `
+		SELECT arc.id
+		     , COALESCE(arc.code, 'uncategorized')
+		     , COALESCE(arc.name, 'Uncategorized')
+		     , COUNT(*)
+		     , COUNT(*) FILTER (WHERE ar.status = 'pending')
+		     , COUNT(*) FILTER (WHERE ar.status = 'approved')
+		     , COUNT(*) FILTER (WHERE ar.status = 'rejected')
+		     , COALESCE(SUM(ar.total_days) FILTER (WHERE ar.status = 'approved'), 0)
+		FROM absent_requests ar
+		LEFT JOIN absent_request_categories arc ON ar.category_id = arc.id
+		WHERE ar.deleted_at IS NULL AND ar.start_date <= DATE($2) AND ar.end_date >= DATE($1)
+		  AND ($3 = 0 OR ar.school_id = $3)
+		GROUP BY arc.id, arc.code, arc.name
+		ORDER BY COUNT(*) DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absent request category report: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*models.AbsentCategoryReport
+	for rows.Next() {
+		report := &models.AbsentCategoryReport{}
+		err := rows.Scan(
+			&report.CategoryID,
+			&report.CategoryCode,
+			&report.CategoryName,
+			&report.TotalRequests,
+			&report.PendingRequests,
+			&report.ApprovedRequests,
+			&report.RejectedRequests,
+			&report.ApprovedDays,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request category report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent request category report: %w", err)
+	}
+
+	return reports, nil
+}
+
+func (r *absentRequestRepository) EscalateStale(ctx context.Context, pendingSince time.Time, escalatedTo *uint) ([]*models.AbsentRequest, error) {
+	// requests are claimed in the same statement that marks them, so a request is escalated only once
+	query := `
+		UPDATE absent_requests
+		SET escalated_at = NOW(), escalated_to = $2, updated_at = NOW()
+		WHERE status = 'pending' AND deleted_at IS NULL AND escalated_at IS NULL AND created_at < $1
+		  AND ($3 = 0 OR school_id = $3)
+		RETURNING id, school_id, student_id, class_id, category_id, start_date, end_date, total_days, reason, status,
+		          created_at, updated_at, escalated_at, escalated_to`
+
+	rows, err := r.db.QueryContext(ctx, query, pendingSince, escalatedTo, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to escalate absent requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.AbsentRequest
+	for rows.Next() {
+		request := &models.AbsentRequest{}
+		err := rows.Scan(
+			&request.ID,
+			&request.SchoolID,
+			&request.StudentID,
+			&request.ClassID,
+			&request.CategoryID,
+			&request.StartDate,
+			&request.EndDate,
+			&request.TotalDays,
+			&request.Reason,
+			&request.Status,
+			&request.CreatedAt,
+			&request.UpdatedAt,
+			&request.EscalatedAt,
+			&request.EscalatedTo,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan escalated absent request: %w", err)
+		}
+		request.RequestDate = request.StartDate
+		requests = append(requests, request)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate escalated absent requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *absentRequestRepository) GetEscalated(ctx context.Context, limit, offset int) ([]*models.AbsentRequest, error) {
+	// requests held back by the absence quota wait in the same queue; once decided a request leaves it
+	query := `
+		SELECT id, student_id, class_id, category_id, request_date, start_date, end_date, exclude_non_school_days, total_days,
+		       reason, status, created_at, updated_at, reviewer_note, escalated_at, escalated_to, quota_exceeded_at
+		FROM absent_requests
+		WHERE (escalated_at IS NOT NULL OR quota_exceeded_at IS NOT NULL) AND status = 'pending' AND deleted_at IS NULL
+		  AND ($3 = 0 OR school_id = $3)
+		ORDER BY COALESCE(escalated_at, quota_exceeded_at), created_at
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get escalated absent requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.AbsentRequest
+	for rows.Next() {
+		request := &models.AbsentRequest{}
+		err := rows.Scan(
+			&request.ID,
+			&request.StudentID,
+			&request.ClassID,
+			&request.CategoryID,
+			&request.RequestDate,
+			&request.StartDate,
+			&request.EndDate,
+			&request.ExcludeNonSchoolDays,
+			&request.TotalDays,
+			&request.Reason,
+			&request.Status,
+			&request.CreatedAt,
+			&request.UpdatedAt,
+			&request.ReviewerNote,
+			&request.EscalatedAt,
+			&request.EscalatedTo,
+			&request.QuotaExceededAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *absentRequestRepository) GetEscalatedCount(ctx context.Context) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM absent_requests
+		WHERE (escalated_at IS NOT NULL OR quota_exceeded_at IS NOT NULL) AND status = 'pending' AND deleted_at IS NULL
+		  AND ($1 = 0 OR school_id = $1)`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get escalated absent requests count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *absentRequestRepository) FlagQuotaExceeded(ctx context.Context, id uint) error {
+	query := `
+		UPDATE absent_requests
+		SET quota_exceeded_at = COALESCE(quota_exceeded_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND status = 'pending' AND deleted_at IS NULL
+		RETURNING quota_exceeded_at`
+
+	var quotaExceededAt time.Time
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&quotaExceededAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absent request not found")
+		}
+		return fmt.Errorf("failed to flag absent request over quota: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type absentRequestAttachmentRepository struct {
+	db *sql.DB
+}
+
+// NewAbsentRequestAttachmentRepository creates a new absent request attachment repository
+func NewAbsentRequestAttachmentRepository(db *sql.DB) AbsentRequestAttachmentRepository {
+	return &absentRequestAttachmentRepository{db: db}
+}
+
+// CreateAll adds the attachments to their absent request in one transaction. The request is locked while its
+// attachments are counted, so concurrent uploads cannot take it over maxPerRequest; models.ErrTooManyAttachments
+// is returned instead and nothing is added.
+func (r *absentRequestAttachmentRepository) CreateAll(ctx context.Context, absentRequestID uint, attachments []*models.AbsentRequestAttachment, maxPerRequest int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM absent_requests WHERE id = $1 FOR UPDATE`, absentRequestID); err != nil {
+		return fmt.Errorf("failed to lock absent request: %w", err)
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM absent_request_attachments WHERE absent_request_id = $1 AND deleted_at IS NULL`
+	if err := tx.QueryRowContext(ctx, query, absentRequestID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to get absent request attachment count: %w", err)
+	}
+
+	if count+len(attachments) > maxPerRequest {
+		return models.ErrTooManyAttachments
+	}
+
+	query = `
+		INSERT INTO absent_request_attachments (
+			absent_request_id
+			, file_name
+			, file_path
+			, content_type
+			, file_size
+
+			, uploaded_by
+			, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at`
+
+	for _, attachment := range attachments {
+		err := tx.QueryRowContext(ctx, query,
+			absentRequestID,
+			attachment.FileName,
+			attachment.FilePath,
+			attachment.ContentType,
+			attachment.FileSize,
+
+			attachment.UploadedBy,
+		).Scan(&attachment.ID, &attachment.CreatedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to create absent request attachment: %w", err)
+		}
+		attachment.AbsentRequestID = absentRequestID
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *absentRequestAttachmentRepository) GetByID(ctx context.Context, id uint) (*models.AbsentRequestAttachment, error) {
+	query := `
+		SELECT id, absent_request_id, file_name, file_path, content_type, file_size, uploaded_by, created_at
+		FROM absent_request_attachments
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	attachment := &models.AbsentRequestAttachment{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&attachment.ID,
+		&attachment.AbsentRequestID,
+		&attachment.FileName,
+		&attachment.FilePath,
+		&attachment.ContentType,
+		&attachment.FileSize,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("absent request attachment not found")
+		}
+		return nil, fmt.Errorf("failed to get absent request attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+func (r *absentRequestAttachmentRepository) GetByAbsentRequestIDs(ctx context.Context, absentRequestIDs []uint) ([]*models.AbsentRequestAttachment, error) {
+	if len(absentRequestIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(absentRequestIDs))
+	for i, id := range absentRequestIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		SELECT id, absent_request_id, file_name, file_path, content_type, file_size, uploaded_by, created_at
+		FROM absent_request_attachments
+		WHERE absent_request_id = ANY($1) AND deleted_at IS NULL
+		ORDER BY absent_request_id, created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absent request attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*models.AbsentRequestAttachment
+	for rows.Next() {
+		attachment := &models.AbsentRequestAttachment{}
+		err := rows.Scan(
+			&attachment.ID,
+			&attachment.AbsentRequestID,
+			&attachment.FileName,
+			&attachment.FilePath,
+			&attachment.ContentType,
+			&attachment.FileSize,
+			&attachment.UploadedBy,
+			&attachment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent request attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *absentRequestAttachmentRepository) GetCountByAbsentRequest(ctx context.Context, absentRequestID uint) (int, error) {
+	query := `SELECT COUNT(*) FROM absent_request_attachments WHERE absent_request_id = $1 AND deleted_at IS NULL`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, absentRequestID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get absent request attachment count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *absentRequestAttachmentRepository) Delete(ctx context.Context, id uint) error {
+	query := `
+		UPDATE absent_request_attachments
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absent request attachment not found")
+		}
+		return fmt.Errorf("failed to delete absent request attachment: %w", err)
+	}
+
+	return nil
+}
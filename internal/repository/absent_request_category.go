@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type absentRequestCategoryRepository struct {
+	db *sql.DB
+}
+
+// NewAbsentRequestCategoryRepository creates a new absent request category repository
+func NewAbsentRequestCategoryRepository(db *sql.DB) AbsentRequestCategoryRepository {
+	return &absentRequestCategoryRepository{db: db}
+}
+
+// Create adds the category to the school of the request
+func (r *absentRequestCategoryRepository) Create(ctx context.Context, category *models.AbsentRequestCategory) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create absent request category: %w", err)
+	}
+
+	query := `
+		INSERT INTO absent_request_categories (
+			school_id
+			, code
+			, name
+			, description
+			, requires_attachment
+			, max_days
+
+			, min_notice_days
+			, marks_excused
+			, is_active
+			, created_by
+			, created_at
+
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		schoolID,
+		category.Code,
+		category.Name,
+		category.Description,
+		category.RequiresAttachment,
+		category.MaxDays,
+
+		category.MinNoticeDays,
+		category.MarksExcused,
+		category.IsActive,
+		category.CreatedBy,
+	).Scan(&category.ID, &category.SchoolID, &category.CreatedAt, &category.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create absent request category: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID also returns deleted categories, since requests filed under them still follow their rules
+func (r *absentRequestCategoryRepository) GetByID(ctx context.Context, id uint) (*models.AbsentRequestCategory, error) {
+	query := `
+		SELECT id, school_id, code, name, description, requires_attachment, max_days, min_notice_days, marks_excused,
+		       is_active, created_at, updated_at, created_by, updated_by, deleted_at, deleted_by
+		FROM absent_request_categories
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
+
+	category := &models.AbsentRequestCategory{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&category.ID,
+		&category.SchoolID,
+		&category.Code,
+		&category.Name,
+		&category.Description,
+		&category.RequiresAttachment,
+		&category.MaxDays,
+		&category.MinNoticeDays,
+		&category.MarksExcused,
+		&category.IsActive,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+		&category.CreatedBy,
+		&category.UpdatedBy,
+		&category.DeletedAt,
+		&category.DeletedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("absent request category not found")
+		}
+		return nil, fmt.Errorf("failed to get absent request category: %w", err)
+	}
+
+	return category, nil
+}
+
+func (r *absentRequestCategoryRepository) GetAll(ctx context.Context, activeOnly bool) ([]*models.AbsentRequestCategory, error) {
+	query := `
+		SELECT id, school_id, code, name, description, requires_attachment, max_days, min_notice_days, marks_excused,
+		       is_active, created_at, updated_at, created_by, updated_by
+		FROM absent_request_categories
+		WHERE deleted_at IS NULL AND (is_active OR NOT $1) AND ($2 = 0 OR school_id = $2)
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, activeOnly, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absent request categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*models.AbsentRequestCategory
+	for rows.Next() {
+		category := &models.AbsentRequestCategory{}
+		err := rows.Scan(
+			&category.ID,
+			&category.SchoolID,
+			&category.Code,
+			&category.Name,
+			&category.Description,
+			&category.RequiresAttachment,
+			&category.MaxDays,
+			&category.MinNoticeDays,
+			&category.MarksExcused,
+			&category.IsActive,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+			&category.CreatedBy,
+			&category.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent request categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *absentRequestCategoryRepository) Update(ctx context.Context, category *models.AbsentRequestCategory) error {
+	query := `
+		UPDATE absent_request_categories
+		SET code = $2, name = $3, description = $4, requires_attachment = $5, max_days = $6,
+		    min_notice_days = $7, marks_excused = $8, is_active = $9, updated_by = $10, updated_at = NOW()
+		WHERE id = $1 AND ($11 = 0 OR school_id = $11) AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		category.ID,
+		category.Code,
+		category.Name,
+		category.Description,
+		category.RequiresAttachment,
+		category.MaxDays,
+		category.MinNoticeDays,
+		category.MarksExcused,
+		category.IsActive,
+		category.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&category.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absent request category not found")
+		}
+		return fmt.Errorf("failed to update absent request category: %w", err)
+	}
+
+	return nil
+}
+
+func (r *absentRequestCategoryRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	// existing requests keep pointing at the category, so it is only hidden
+	query := `
+		UPDATE absent_request_categories
+		SET deleted_at = NOW(), deleted_by = $2, is_active = FALSE
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("absent request category not found")
+		}
+		return fmt.Errorf("failed to update absent request category delete info: %w", err)
+	}
+
+	return nil
+}
+
+// IsCodeExist reports whether another category of the school of the request uses the code
+func (r *absentRequestCategoryRepository) IsCodeExist(ctx context.Context, code string, excludeID uint) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM absent_request_categories WHERE code = $1 AND id <> $2 AND school_id = $3)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, code, excludeID, schoolFilter(ctx)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check absent request category code: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type absentRequestCommentRepository struct {
+	db *sql.DB
+}
+
+// NewAbsentRequestCommentRepository creates a new absent request comment repository
+func NewAbsentRequestCommentRepository(db *sql.DB) AbsentRequestCommentRepository {
+	return &absentRequestCommentRepository{db: db}
+}
+
+func (r *absentRequestCommentRepository) Create(ctx context.Context, comment *models.AbsentRequestComment) error {
+	query := `
+		INSERT INTO absent_request_comments (
+			absent_request_id
+			, author_type
+			, author_id
+			, body
+			, created_at
+		)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		comment.AbsentRequestID,
+		comment.AuthorType,
+		comment.AuthorID,
+		comment.Body,
+	).Scan(&comment.ID, &comment.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create absent request comment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *absentRequestCommentRepository) GetByAbsentRequest(ctx context.Context, absentRequestID uint) ([]*models.AbsentRequestComment, error) {
+	// admins have no name, so they are shown by email
+	query := `
+		SELECT arc.id
+		     , arc.absent_request_id
+		     , arc.author_type
+		     , arc.author_id
+		     , COALESCE(
+		           CASE arc.author_type
+		               WHEN 'student' THEN s.first_name || ' ' || s.last_name
+		               WHEN 'teacher' THEN t.first_name || ' ' || t.last_name
+		               WHEN 'admin' THEN a.email
+		           END, '')
+		     , arc.body
+		     , arc.created_at
+		FROM absent_request_comments arc
+		LEFT JOIN students s ON arc.author_type = 'student' AND s.id = arc.author_id
+		LEFT JOIN teachers t ON arc.author_type = 'teacher' AND t.id = arc.author_id
+		LEFT JOIN admins a ON arc.author_type = 'admin' AND a.id = arc.author_id
+		WHERE arc.absent_request_id = $1 AND arc.deleted_at IS NULL
+		ORDER BY arc.created_at, arc.id`
+
+	rows, err := r.db.QueryContext(ctx, query, absentRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absent request comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*models.AbsentRequestComment
+	for rows.Next() {
+		comment := &models.AbsentRequestComment{}
+		err := rows.Scan(
+			&comment.ID,
+			&comment.AbsentRequestID,
+			&comment.AuthorType,
+			&comment.AuthorID,
+			&comment.AuthorName,
+			&comment.Body,
+			&comment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absent request comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate absent request comments: %w", err)
+	}
+
+	return comments, nil
+}
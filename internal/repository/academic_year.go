@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type academicYearRepository struct {
+	db *sql.DB
+}
+
+// NewAcademicYearRepository creates a new academic year repository
+func NewAcademicYearRepository(db *sql.DB) AcademicYearRepository {
+	return &academicYearRepository{db: db}
+}
+
+// Create adds the academic year to the school of the request. A new current year replaces the previous one.
+func (r *academicYearRepository) Create(ctx context.Context, year *models.AcademicYear) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create academic year: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if year.IsCurrent {
+		_, err = tx.ExecContext(ctx, `UPDATE academic_years SET is_current = FALSE, updated_at = NOW() WHERE school_id = $1 AND is_current`, schoolID)
+		if err != nil {
+			return fmt.Errorf("failed to unset current academic year: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO academic_years (
+			school_id
+			, name
+			, start_date
+			, end_date
+			, is_current
+
+			, created_by
+			, created_at
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
+
+	err = tx.QueryRowContext(ctx, query,
+		schoolID,
+		year.Name,
+		year.StartDate,
+		year.EndDate,
+		year.IsCurrent,
+
+		year.CreatedBy,
+	).Scan(&year.ID, &year.SchoolID, &year.CreatedAt, &year.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create academic year: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit academic year: %w", err)
+	}
+
+	return nil
+}
+
+func (r *academicYearRepository) GetByID(ctx context.Context, id uint) (*models.AcademicYear, error) {
+	query := `
+		SELECT id, school_id, name, start_date, end_date, is_current,
+		       created_at, updated_at, created_by, updated_by
+		FROM academic_years
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
+
+	year := &models.AcademicYear{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&year.ID,
+		&year.SchoolID,
+		&year.Name,
+		&year.StartDate,
+		&year.EndDate,
+		&year.IsCurrent,
+		&year.CreatedAt,
+		&year.UpdatedAt,
+		&year.CreatedBy,
+		&year.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("academic year not found")
+		}
+		return nil, fmt.Errorf("failed to get academic year: %w", err)
+	}
+
+	return year, nil
+}
+
+func (r *academicYearRepository) GetAll(ctx context.Context) ([]*models.AcademicYear, error) {
+	query := `
+		SELECT id, school_id, name, start_date, end_date, is_current,
+		       created_at, updated_at, created_by, updated_by
+		FROM academic_years
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
+		ORDER BY start_date DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get academic years: %w", err)
+	}
+	defer rows.Close()
+
+	var years []*models.AcademicYear
+	for rows.Next() {
+		year := &models.AcademicYear{}
+		err := rows.Scan(
+			&year.ID,
+			&year.SchoolID,
+			&year.Name,
+			&year.StartDate,
+			&year.EndDate,
+			&year.IsCurrent,
+			&year.CreatedAt,
+			&year.UpdatedAt,
+			&year.CreatedBy,
+			&year.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan academic year: %w", err)
+		}
+		years = append(years, year)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate academic years: %w", err)
+	}
+
+	return years, nil
+}
+
+// Update saves the academic year. Making it current replaces the previous current year of its school.
+func (r *academicYearRepository) Update(ctx context.Context, year *models.AcademicYear) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if year.IsCurrent {
+		query := `
+			UPDATE academic_years
+			SET is_current = FALSE, updated_at = NOW()
+			WHERE school_id = (SELECT school_id FROM academic_years WHERE id = $1)
+			  AND id <> $1 AND is_current`
+
+		if _, err = tx.ExecContext(ctx, query, year.ID); err != nil {
+			return fmt.Errorf("failed to unset current academic year: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE academic_years
+		SET name = $2, start_date = $3, end_date = $4, is_current = $5,
+		    updated_by = $6, updated_at = NOW()
+		WHERE id = $1 AND ($7 = 0 OR school_id = $7) AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err = tx.QueryRowContext(ctx, query,
+		year.ID,
+		year.Name,
+		year.StartDate,
+		year.EndDate,
+		year.IsCurrent,
+		year.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&year.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("academic year not found")
+		}
+		return fmt.Errorf("failed to update academic year: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit academic year: %w", err)
+	}
+
+	return nil
+}
+
+func (r *academicYearRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	query := `
+		UPDATE academic_years
+		SET deleted_at = NOW(), deleted_by = $2, is_current = FALSE
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("academic year not found")
+		}
+		return fmt.Errorf("failed to update academic year delete info: %w", err)
+	}
+
+	return nil
+}
+
+// IsNameExist reports whether another academic year of the school already has the name
+func (r *academicYearRepository) IsNameExist(ctx context.Context, name string, excludeID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM academic_years
+			WHERE LOWER(name) = LOWER($1)
+			  AND id <> $2
+			  AND ($3 = 0 OR school_id = $3)
+			  AND deleted_at IS NULL
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, name, excludeID, schoolFilter(ctx)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check academic year name: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *academicYearRepository) CreateTerm(ctx context.Context, term *models.Term) error {
+	query := `
+		INSERT INTO terms (
+			academic_year_id
+			, name
+			, start_date
+			, end_date
+
+			, created_by
+			, created_at
+			, updated_at
+		)
+		SELECT y.id, $2, $3, $4, $5, NOW(), NOW()
+		FROM academic_years y
+		WHERE y.id = $1 AND ($6 = 0 OR y.school_id = $6) AND y.deleted_at IS NULL
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		term.AcademicYearID,
+		term.Name,
+		term.StartDate,
+		term.EndDate,
+
+		term.CreatedBy,
+		schoolFilter(ctx),
+	).Scan(&term.ID, &term.CreatedAt, &term.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("academic year not found")
+		}
+		return fmt.Errorf("failed to create term: %w", err)
+	}
+
+	return nil
+}
+
+func (r *academicYearRepository) GetTermByID(ctx context.Context, id uint) (*models.Term, error) {
+	query := `
+		SELECT t.id, t.academic_year_id, t.name, t.start_date, t.end_date,
+		       t.created_at, t.updated_at, t.created_by, t.updated_by
+		FROM terms t
+		JOIN academic_years y ON y.id = t.academic_year_id
+		WHERE t.id = $1 AND ($2 = 0 OR y.school_id = $2) AND t.deleted_at IS NULL AND y.deleted_at IS NULL`
+
+	term := &models.Term{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&term.ID,
+		&term.AcademicYearID,
+		&term.Name,
+		&term.StartDate,
+		&term.EndDate,
+		&term.CreatedAt,
+		&term.UpdatedAt,
+		&term.CreatedBy,
+		&term.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("term not found")
+		}
+		return nil, fmt.Errorf("failed to get term: %w", err)
+	}
+
+	return term, nil
+}
+
+func (r *academicYearRepository) GetTerms(ctx context.Context, academicYearID uint) ([]*models.Term, error) {
+	query := `
+		SELECT t.id, t.academic_year_id, t.name, t.start_date, t.end_date,
+		       t.created_at, t.updated_at, t.created_by, t.updated_by
+		FROM terms t
+		JOIN academic_years y ON y.id = t.academic_year_id
+		WHERE t.academic_year_id = $1 AND ($2 = 0 OR y.school_id = $2) AND t.deleted_at IS NULL
+		ORDER BY t.start_date`
+
+	rows, err := r.db.QueryContext(ctx, query, academicYearID, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get terms: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []*models.Term
+	for rows.Next() {
+		term := &models.Term{}
+		err := rows.Scan(
+			&term.ID,
+			&term.AcademicYearID,
+			&term.Name,
+			&term.StartDate,
+			&term.EndDate,
+			&term.CreatedAt,
+			&term.UpdatedAt,
+			&term.CreatedBy,
+			&term.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan term: %w", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate terms: %w", err)
+	}
+
+	return terms, nil
+}
+
+func (r *academicYearRepository) UpdateTerm(ctx context.Context, term *models.Term) error {
+	query := `
+		UPDATE terms
+		SET name = $2, start_date = $3, end_date = $4, updated_by = $5, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND academic_year_id IN (SELECT id FROM academic_years WHERE $6 = 0 OR school_id = $6)
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		term.ID,
+		term.Name,
+		term.StartDate,
+		term.EndDate,
+		term.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&term.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("term not found")
+		}
+		return fmt.Errorf("failed to update term: %w", err)
+	}
+
+	return nil
+}
+
+func (r *academicYearRepository) DeleteTerm(ctx context.Context, id uint, deletedBy uint) error {
+	query := `
+		UPDATE terms
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND academic_year_id IN (SELECT id FROM academic_years WHERE $3 = 0 OR school_id = $3)
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("term not found")
+		}
+		return fmt.Errorf("failed to update term delete info: %w", err)
+	}
+
+	return nil
+}
+
+// HasTermOverlap reports whether another term of the academic year already covers part of the period
+func (r *academicYearRepository) HasTermOverlap(ctx context.Context, academicYearID uint, startDate, endDate time.Time, excludeID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM terms
+			WHERE academic_year_id = $1
+			  AND id <> $4
+			  AND deleted_at IS NULL
+			  AND start_date <= DATE($3)
+			  AND end_date >= DATE($2)
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, academicYearID, startDate, endDate, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check overlapping terms: %w", err)
+	}
+
+	return exists, nil
+}
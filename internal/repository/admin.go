@@ -34,16 +34,23 @@ func NewAdminRepositoryWithDeps(db *sql.DB, teacherRepo TeacherRepository, stude
 }
 
 func (r *adminRepository) Create(ctx context.Context, admin *models.Admin) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO admins (email, password, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		RETURNING id, created_at, updated_at`
+		INSERT INTO admins (email, password, is_active, tier, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, school_id, is_super_admin, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = r.db.QueryRowContext(ctx, query,
 		admin.Email,
 		admin.Password,
 		admin.IsActive,
-	).Scan(&admin.ID, &admin.CreatedAt, &admin.UpdatedAt)
+		admin.Tier,
+		schoolID,
+	).Scan(&admin.ID, &admin.SchoolID, &admin.IsSuperAdmin, &admin.CreatedAt, &admin.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create admin: %w", err)
@@ -54,16 +61,19 @@ func (r *adminRepository) Create(ctx context.Context, admin *models.Admin) error
 
 func (r *adminRepository) GetByID(ctx context.Context, id uint) (*models.Admin, error) {
 	query := `
-		SELECT id, email, password, last_login, is_active, created_at, updated_at
-		FROM admins WHERE id = $1`
+		SELECT id, school_id, email, password, last_login, is_active, tier, is_super_admin, created_at, updated_at
+		FROM admins WHERE id = $1 AND ($2 = 0 OR school_id = $2 OR is_super_admin)`
 
 	admin := &models.Admin{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&admin.ID,
+		&admin.SchoolID,
 		&admin.Email,
 		&admin.Password,
 		&admin.LastLogin,
 		&admin.IsActive,
+		&admin.Tier,
+		&admin.IsSuperAdmin,
 		&admin.CreatedAt,
 		&admin.UpdatedAt,
 	)
@@ -80,16 +90,19 @@ func (r *adminRepository) GetByID(ctx context.Context, id uint) (*models.Admin,
 
 func (r *adminRepository) GetByEmail(ctx context.Context, email string) (*models.Admin, error) {
 	query := `
-		SELECT id, email, password, last_login, is_active, created_at, updated_at
-		FROM admins WHERE email = $1`
+		SELECT id, school_id, email, password, last_login, is_active, tier, is_super_admin, created_at, updated_at
+		FROM admins WHERE email = $1 AND ($2 = 0 OR school_id = $2 OR is_super_admin)`
 
 	admin := &models.Admin{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email, schoolFilter(ctx)).Scan(
 		&admin.ID,
+		&admin.SchoolID,
 		&admin.Email,
 		&admin.Password,
 		&admin.LastLogin,
 		&admin.IsActive,
+		&admin.Tier,
+		&admin.IsSuperAdmin,
 		&admin.CreatedAt,
 		&admin.UpdatedAt,
 	)
@@ -106,12 +119,13 @@ func (r *adminRepository) GetByEmail(ctx context.Context, email string) (*models
 
 func (r *adminRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Admin, error) {
 	query := `
-		SELECT id, email, password, last_login, is_active, created_at, updated_at
+		SELECT id, school_id, email, password, last_login, is_active, tier, is_super_admin, created_at, updated_at
 		FROM admins
+		WHERE ($3 = 0 OR school_id = $3 OR is_super_admin)
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get admins: %w", err)
 	}
@@ -122,10 +136,13 @@ func (r *adminRepository) GetAll(ctx context.Context, limit, offset int) ([]*mod
 		admin := &models.Admin{}
 		err := rows.Scan(
 			&admin.ID,
+			&admin.SchoolID,
 			&admin.Email,
 			&admin.Password,
 			&admin.LastLogin,
 			&admin.IsActive,
+			&admin.Tier,
+			&admin.IsSuperAdmin,
 			&admin.CreatedAt,
 			&admin.UpdatedAt,
 		)
@@ -142,18 +159,39 @@ func (r *adminRepository) GetAll(ctx context.Context, limit, offset int) ([]*mod
 	return admins, nil
 }
 
+// Update changes the email, active status and tier of the admin. An empty tier keeps the current one.
 func (r *adminRepository) Update(ctx context.Context, admin *models.Admin) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	tier := admin.Tier
+	if tier == "" {
+		err = tx.QueryRowContext(ctx, `SELECT tier FROM admins WHERE id = $1`, admin.ID).Scan(&tier)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get admin tier: %w", err)
+		}
+	}
+
+	if err := guardLastSuperAdmin(ctx, tx, admin.ID, admin.IsActive && tier == models.AdminTierSuperAdmin); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE admins 
-		SET email = $2, is_active = $3, updated_at = NOW()
-		WHERE id = $1
-		RETURNING updated_at`
+		SET email = $2, is_active = $3, tier = COALESCE(NULLIF($5, ''), tier), updated_at = NOW()
+		WHERE id = $1 AND ($4 = 0 OR school_id = $4 OR is_super_admin)
+		RETURNING tier, is_super_admin, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		admin.ID,
 		admin.Email,
 		admin.IsActive,
-	).Scan(&admin.UpdatedAt)
+		schoolFilter(ctx),
+		admin.Tier,
+	).Scan(&admin.Tier, &admin.IsSuperAdmin, &admin.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -162,13 +200,28 @@ func (r *adminRepository) Update(ctx context.Context, admin *models.Admin) error
 		return fmt.Errorf("failed to update admin: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
+// Delete removes the admin, unless they are the last active super-admin
 func (r *adminRepository) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM admins WHERE id = $1`
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := guardLastSuperAdmin(ctx, tx, id, false); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM admins WHERE id = $1 AND ($2 = 0 OR school_id = $2 OR is_super_admin)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := tx.ExecContext(ctx, query, id, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete admin: %w", err)
 	}
@@ -182,6 +235,10 @@ func (r *adminRepository) Delete(ctx context.Context, id uint) error {
 		return fmt.Errorf("admin not found")
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -189,9 +246,9 @@ func (r *adminRepository) UpdatePassword(ctx context.Context, email string, pass
 	query := `
 		UPDATE admins 
 		SET password = $2, updated_at = NOW()
-		WHERE email = $1`
+		WHERE email = $1 AND ($3 = 0 OR school_id = $3 OR is_super_admin)`
 
-	result, err := r.db.ExecContext(ctx, query, email, password)
+	result, err := r.db.ExecContext(ctx, query, email, password, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
@@ -212,9 +269,9 @@ func (r *adminRepository) UpdateLastLogin(ctx context.Context, id uint, lastLogi
 	query := `
 		UPDATE admins 
 		SET last_login = $2, updated_at = NOW()
-		WHERE id = $1`
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3 OR is_super_admin)`
 
-	result, err := r.db.ExecContext(ctx, query, id, lastLogin)
+	result, err := r.db.ExecContext(ctx, query, id, lastLogin, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to update last login: %w", err)
 	}
@@ -231,13 +288,26 @@ func (r *adminRepository) UpdateLastLogin(ctx context.Context, id uint, lastLogi
 	return nil
 }
 
+// SetActiveStatus activates or deactivates the admin, unless that would deactivate the last active super-admin
 func (r *adminRepository) SetActiveStatus(ctx context.Context, id uint, isActive bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if !isActive {
+		if err := guardLastSuperAdmin(ctx, tx, id, false); err != nil {
+			return err
+		}
+	}
+
 	query := `
 		UPDATE admins 
 		SET is_active = $2, updated_at = NOW()
-		WHERE id = $1`
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3 OR is_super_admin)`
 
-	result, err := r.db.ExecContext(ctx, query, id, isActive)
+	result, err := tx.ExecContext(ctx, query, id, isActive, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to set active status: %w", err)
 	}
@@ -251,14 +321,59 @@ func (r *adminRepository) SetActiveStatus(ctx context.Context, id uint, isActive
 		return fmt.Errorf("admin not found")
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// guardLastSuperAdmin refuses a change that leaves no active super-admin: the admin is one of them and
+// stays one only if staysActiveSuperAdmin. The active super-admins stay locked until the transaction
+// ends, so two super-admins cannot deactivate each other at the same time.
+func guardLastSuperAdmin(ctx context.Context, tx *sql.Tx, id uint, staysActiveSuperAdmin bool) error {
+	if staysActiveSuperAdmin {
+		return nil
+	}
+
+	query := `SELECT id FROM admins WHERE tier = 'super_admin' AND is_active ORDER BY id FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to lock super-admins: %w", err)
+	}
+	defer rows.Close()
+
+	isSuperAdmin := false
+	others := 0
+	for rows.Next() {
+		var superAdminID uint
+		if err := rows.Scan(&superAdminID); err != nil {
+			return fmt.Errorf("failed to scan super-admin: %w", err)
+		}
+		if superAdminID == id {
+			isSuperAdmin = true
+		} else {
+			others++
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	if isSuperAdmin && others == 0 {
+		return models.ErrLastSuperAdmin
+	}
+
 	return nil
 }
 
 func (r *adminRepository) GetTotalAdmins(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM admins`
+	query := `SELECT COUNT(*) FROM admins WHERE ($1 = 0 OR school_id = $1)`
 
 	var total int
-	err := r.db.QueryRowContext(ctx, query).Scan(&total)
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&total)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total admins: %w", err)
 	}
@@ -267,10 +382,10 @@ func (r *adminRepository) GetTotalAdmins(ctx context.Context) (int, error) {
 }
 
 func (r *adminRepository) IsAdminExist(ctx context.Context, email string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1 AND is_active = true)`
+	query := `SELECT EXISTS(SELECT 1 FROM admins WHERE email = $1 AND is_active = true AND ($2 = 0 OR school_id = $2 OR is_super_admin))`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, email).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, email, schoolFilter(ctx)).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check admin existence: %w", err)
 	}
@@ -279,10 +394,10 @@ func (r *adminRepository) IsAdminExist(ctx context.Context, email string) (bool,
 }
 
 func (r *adminRepository) GetPasswordByEmail(ctx context.Context, email string) (string, error) {
-	query := `SELECT password FROM admins WHERE email = $1`
+	query := `SELECT password FROM admins WHERE email = $1 AND ($2 = 0 OR school_id = $2 OR is_super_admin)`
 
 	var password string
-	err := r.db.QueryRowContext(ctx, query, email).Scan(&password)
+	err := r.db.QueryRowContext(ctx, query, email, schoolFilter(ctx)).Scan(&password)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("admin not found")
@@ -299,10 +414,11 @@ func (r *adminRepository) GetStats(ctx context.Context) (*models.AdminStats, err
 			COUNT(*) as total_admins,
 			COUNT(CASE WHEN is_active = true THEN 1 END) as active_admins,
 			COUNT(CASE WHEN is_active = false THEN 1 END) as inactive_admins
-		FROM admins`
+		FROM admins
+		WHERE ($1 = 0 OR school_id = $1)`
 
 	stats := &models.AdminStats{}
-	err := r.db.QueryRowContext(ctx, query).Scan(
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(
 		&stats.TotalAdmins,
 		&stats.ActiveAdmins,
 		&stats.InactiveAdmins,
@@ -342,8 +458,9 @@ func (r *adminRepository) GetDashboardStats(ctx context.Context) (*models.Dashbo
 				COUNT(*) as total_teachers,
 				COUNT(CASE WHEN is_active = true THEN 1 END) as active_teachers,
 				COUNT(CASE WHEN is_active = false THEN 1 END) as inactive_teachers
-			FROM teachers`
-		err := r.db.QueryRowContext(ctx, query).Scan(
+			FROM teachers
+			WHERE ($1 = 0 OR school_id = $1)`
+		err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(
 			&dashboardStats.TotalTeachers,
 			&dashboardStats.ActiveTeachers,
 			&dashboardStats.InactiveTeachers,
@@ -369,8 +486,9 @@ func (r *adminRepository) GetDashboardStats(ctx context.Context) (*models.Dashbo
 				COUNT(*) as total_students,
 				COUNT(CASE WHEN is_active = true THEN 1 END) as active_students,
 				COUNT(CASE WHEN is_active = false THEN 1 END) as inactive_students
-			FROM students`
-		err := r.db.QueryRowContext(ctx, query).Scan(
+			FROM students
+			WHERE ($1 = 0 OR school_id = $1)`
+		err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(
 			&dashboardStats.TotalStudents,
 			&dashboardStats.ActiveStudents,
 			&dashboardStats.InactiveStudents,
@@ -389,8 +507,8 @@ func (r *adminRepository) GetDashboardStats(ctx context.Context) (*models.Dashbo
 		dashboardStats.TotalClasses = totalClasses
 	} else {
 		// Direct query if classRepo is not available
-		query := `SELECT COUNT(*) FROM classes`
-		err := r.db.QueryRowContext(ctx, query).Scan(&dashboardStats.TotalClasses)
+		query := `SELECT COUNT(*) FROM classes WHERE ($1 = 0 OR school_id = $1)`
+		err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&dashboardStats.TotalClasses)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get total classes: %w", err)
 		}
@@ -404,8 +522,8 @@ func (r *adminRepository) GetDashboardStats(ctx context.Context) (*models.Dashbo
 			COUNT(CASE WHEN status = 'absent' THEN 1 END) as absent_today,
 			COUNT(CASE WHEN status = 'late' THEN 1 END) as late_today
 		FROM attendances 
-		WHERE DATE(date) = CURRENT_DATE`
-	err = r.db.QueryRowContext(ctx, todayQuery).Scan(
+		WHERE DATE(date) = CURRENT_DATE AND timetable_slot_id IS NULL AND ($1 = 0 OR school_id = $1)`
+	err = r.db.QueryRowContext(ctx, todayQuery, schoolFilter(ctx)).Scan(
 		&dashboardStats.TotalAttendanceToday,
 		&dashboardStats.PresentToday,
 		&dashboardStats.AbsentToday,
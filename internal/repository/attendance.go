@@ -19,6 +19,7 @@ func NewAttendanceRepository(db *sql.DB) AttendanceRepository {
 }
 
 func (r *attendanceRepository) Create(ctx context.Context, attendance *models.Attendance) error {
+	// the record belongs to the school of its student
 	query := `
 		INSERT INTO attendances (
 			student_id
@@ -26,15 +27,17 @@ func (r *attendanceRepository) Create(ctx context.Context, attendance *models.At
 			, date
 			, status
 			, description
+			, timetable_slot_id
 			
 			, created_at
 			, time_in
 			, created_by
+			, school_id
 		)
-		VALUES (
-			$1, $2, $3, $4, $5
-			, NOW(), NOW(), $6
-		)
+		SELECT $1, $2, $3, $4, $5, $6
+			, NOW(), NOW(), $7, s.school_id
+		FROM students s
+		WHERE s.student_id = $1 AND ($8 = 0 OR s.school_id = $8)
 		RETURNING id, created_at`
 
 	err := r.db.QueryRowContext(ctx, query,
@@ -43,11 +46,16 @@ func (r *attendanceRepository) Create(ctx context.Context, attendance *models.At
 		attendance.Date,
 		attendance.Status,
 		attendance.Description,
+		attendance.TimetableSlotID,
 
 		attendance.CreatedBy,
+		schoolFilter(ctx),
 	).Scan(&attendance.ID, &attendance.CreatedAt)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("student not found")
+		}
 		return fmt.Errorf("failed to create attendance: %w", err)
 	}
 
@@ -70,10 +78,11 @@ func (r *attendanceRepository) GetByID(ctx context.Context, id uint) (*models.At
 		
 			 , created_by
 			 , updated_by
-		FROM attendances WHERE id = $1 AND deleted_at IS NULL`
+			 , timetable_slot_id
+		FROM attendances WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	attendance := &models.Attendance{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&attendance.ID,
 		&attendance.StudentID,
 		&attendance.ClassID,
@@ -88,6 +97,7 @@ func (r *attendanceRepository) GetByID(ctx context.Context, id uint) (*models.At
 
 		&attendance.CreatedBy,
 		&attendance.UpdatedBy,
+		&attendance.TimetableSlotID,
 	)
 
 	if err != nil {
@@ -116,11 +126,13 @@ func (r *attendanceRepository) GetByStudentAndDate(ctx context.Context, studentI
 			
 			 , created_by
 			 , updated_by
+			 , timetable_slot_id
 		FROM attendances 
-		WHERE student_id = $1 AND DATE(date) = DATE($2) AND deleted_at IS NULL`
+		WHERE student_id = $1 AND DATE(date) = DATE($2) AND timetable_slot_id IS NULL
+		  AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL`
 
 	attendance := &models.Attendance{}
-	err := r.db.QueryRowContext(ctx, query, studentID, date).Scan(
+	err := r.db.QueryRowContext(ctx, query, studentID, date, schoolFilter(ctx)).Scan(
 		&attendance.ID,
 		&attendance.StudentID,
 		&attendance.ClassID,
@@ -135,6 +147,7 @@ func (r *attendanceRepository) GetByStudentAndDate(ctx context.Context, studentI
 
 		&attendance.CreatedBy,
 		&attendance.UpdatedBy,
+		&attendance.TimetableSlotID,
 	)
 
 	if err != nil {
@@ -163,12 +176,13 @@ func (r *attendanceRepository) GetByStudent(ctx context.Context, studentID strin
 			
 			 , created_by
 			 , updated_by
+			 , timetable_slot_id
 		FROM attendances 
-		WHERE student_id = $1 AND deleted_at IS NULL
+		WHERE student_id = $1 AND ($4 = 0 OR school_id = $4) AND deleted_at IS NULL
 		ORDER BY date DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, studentID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, studentID, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attendances by student: %w", err)
 	}
@@ -192,6 +206,7 @@ func (r *attendanceRepository) GetByStudent(ctx context.Context, studentID strin
 
 			&attendance.CreatedBy,
 			&attendance.UpdatedBy,
+			&attendance.TimetableSlotID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan attendance: %w", err)
@@ -222,12 +237,13 @@ func (r *attendanceRepository) GetByClass(ctx context.Context, classID uint, lim
 			
 			 , created_by
 			 , updated_by
+			 , timetable_slot_id
 		FROM attendances 
-		WHERE class_id = $1 AND deleted_at IS NULL
+		WHERE class_id = $1 AND ($4 = 0 OR school_id = $4) AND deleted_at IS NULL
 		ORDER BY date DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, classID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, classID, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attendances by class: %w", err)
 	}
@@ -251,6 +267,7 @@ func (r *attendanceRepository) GetByClass(ctx context.Context, classID uint, lim
 
 			&attendance.CreatedBy,
 			&attendance.UpdatedBy,
+			&attendance.TimetableSlotID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan attendance: %w", err)
@@ -265,7 +282,7 @@ func (r *attendanceRepository) GetByClass(ctx context.Context, classID uint, lim
 	return attendances, nil
 }
 
-func (r *attendanceRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time, limit, offset int) ([]*models.Attendance, error) {
+func (r *attendanceRepository) GetByDateRange(ctx context.Context, scope *models.RecordScope, startDate, endDate time.Time, limit, offset int) ([]*models.Attendance, error) {
 	query := `
 		SELECT id
 		     , student_id
@@ -281,12 +298,16 @@ func (r *attendanceRepository) GetByDateRange(ctx context.Context, startDate, en
 			
 			 , created_by
 			 , updated_by
+			 , timetable_slot_id
 		FROM attendances 
 		WHERE DATE(date) >= DATE($1) AND DATE(date) <= DATE($2) AND deleted_at IS NULL
+		  AND ($5 OR class_id = ANY($6) OR student_id = $7) AND ($8 = 0 OR school_id = $8)
 		ORDER BY date DESC
 		LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, limit, offset)
+	args := append([]interface{}{startDate, endDate, limit, offset}, scopeArgs(scope)...)
+	args = append(args, schoolFilter(ctx))
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attendances by date range: %w", err)
 	}
@@ -310,6 +331,7 @@ func (r *attendanceRepository) GetByDateRange(ctx context.Context, startDate, en
 
 			&attendance.CreatedBy,
 			&attendance.UpdatedBy,
+			&attendance.TimetableSlotID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan attendance: %w", err)
@@ -332,12 +354,13 @@ func (r *attendanceRepository) Update(ctx context.Context, attendance *models.At
 		  , date = $4
 		  , status = $5
 		  , description = $6
+		  , timetable_slot_id = $7
 		  
 		  , updated_at = NOW()
 		  , time_in = NOW()
 		  , time_out = NOW()
-		  , updated_by = $7
-		WHERE id = $1
+		  , updated_by = $8
+		WHERE id = $1 AND ($9 = 0 OR school_id = $9)
 		RETURNING updated_at`
 
 	err := r.db.QueryRowContext(ctx, query,
@@ -347,7 +370,9 @@ func (r *attendanceRepository) Update(ctx context.Context, attendance *models.At
 		attendance.Date,
 		attendance.Status,
 		attendance.Description,
+		attendance.TimetableSlotID,
 		attendance.UpdatedBy,
+		schoolFilter(ctx),
 	).Scan(&attendance.UpdatedAt)
 
 	if err != nil {
@@ -361,9 +386,9 @@ func (r *attendanceRepository) Update(ctx context.Context, attendance *models.At
 }
 
 func (r *attendanceRepository) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM attendances WHERE id = $1`
+	query := `DELETE FROM attendances WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete attendance: %w", err)
 	}
@@ -384,11 +409,11 @@ func (r *attendanceRepository) UpdateDeleteInfo(ctx context.Context, id uint, de
 	query := `
 		UPDATE attendances
 		SET deleted_at = NOW(), deleted_by = $2
-		WHERE id = $1
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING deleted_at`
 
 	var deletedAt string
-	err := r.db.QueryRowContext(ctx, query, id, deletedBy).Scan(&deletedAt)
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("attendance not found")
@@ -399,7 +424,7 @@ func (r *attendanceRepository) UpdateDeleteInfo(ctx context.Context, id uint, de
 	return nil
 }
 
-func (r *attendanceRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Attendance, error) {
+func (r *attendanceRepository) GetAll(ctx context.Context, scope *models.RecordScope, limit, offset int) ([]*models.Attendance, error) {
 	query := `
 		SELECT id
 		     , student_id
@@ -415,12 +440,15 @@ func (r *attendanceRepository) GetAll(ctx context.Context, limit, offset int) ([
 
 			 , created_by
 			 , updated_by
+			 , timetable_slot_id
 		FROM attendances
-		WHERE deleted_at IS NULL
+		WHERE deleted_at IS NULL AND ($3 OR class_id = ANY($4) OR student_id = $5) AND ($6 = 0 OR school_id = $6)
 		ORDER BY date DESC
 		LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	args := append([]interface{}{limit, offset}, scopeArgs(scope)...)
+	args = append(args, schoolFilter(ctx))
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all attendances: %w", err)
 	}
@@ -444,6 +472,7 @@ func (r *attendanceRepository) GetAll(ctx context.Context, limit, offset int) ([
 
 			&attendance.CreatedBy,
 			&attendance.UpdatedBy,
+			&attendance.TimetableSlotID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan attendance: %w", err)
@@ -458,14 +487,14 @@ func (r *attendanceRepository) GetAll(ctx context.Context, limit, offset int) ([
 	return attendances, nil
 }
 
-func (r *attendanceRepository) GetCount(ctx context.Context) (int, error) {
+func (r *attendanceRepository) GetCount(ctx context.Context, scope *models.RecordScope) (int, error) {
 	query := `
 		SELECT COUNT(*) 
 		FROM attendances 
-		WHERE deleted_at IS NULL`
+		WHERE deleted_at IS NULL AND ($1 OR class_id = ANY($2) OR student_id = $3) AND ($4 = 0 OR school_id = $4)`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, append(scopeArgs(scope), schoolFilter(ctx))...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get attendance count: %w", err)
 	}
@@ -482,10 +511,10 @@ func (r *attendanceRepository) GetAttendanceStats(ctx context.Context, studentID
 			COUNT(CASE WHEN status = 'late' THEN 1 END) as total_late,
 			COUNT(CASE WHEN status = 'excused' THEN 1 END) as total_excused
 		FROM attendances 
-		WHERE student_id = $1 AND deleted_at IS NULL`
+		WHERE student_id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	stats := &models.AttendanceWithStats{}
-	err := r.db.QueryRowContext(ctx, query, studentID).Scan(
+	err := r.db.QueryRowContext(ctx, query, studentID, schoolFilter(ctx)).Scan(
 		&stats.TotalAttendances,
 		&stats.TotalPresent,
 		&stats.TotalAbsent,
@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type attendanceAlertRepository struct {
+	db *sql.DB
+}
+
+// NewAttendanceAlertRepository creates a new attendance alert repository
+func NewAttendanceAlertRepository(db *sql.DB) AttendanceAlertRepository {
+	return &attendanceAlertRepository{db: db}
+}
+
+// Create raises the alert, or refreshes the figures of the open alert already raised for the class and date,
+// since the rest of the day's attendance may have come in since. It reports whether a new alert was raised.
+// Alerts an admin acknowledged or resolved are left as they are.
+func (r *attendanceAlertRepository) Create(ctx context.Context, alert *models.AttendanceAlert) (bool, error) {
+	query := `
+		INSERT INTO attendance_alerts (
+			class_id
+			, alert_date
+			, total_students
+			, attendance_rate
+			, baseline_rate
+
+			, drop_percentage
+			, status
+			, created_at
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (class_id, alert_date) DO UPDATE
+		SET total_students = EXCLUDED.total_students
+		  , attendance_rate = EXCLUDED.attendance_rate
+		  , baseline_rate = EXCLUDED.baseline_rate
+		  , drop_percentage = EXCLUDED.drop_percentage
+		  , updated_at = NOW()
+		WHERE attendance_alerts.status = 'open'
+		RETURNING id, created_at, updated_at, xmax = 0 AS inserted`
+
+	var inserted bool
+	err := r.db.QueryRowContext(ctx, query,
+		alert.ClassID,
+		alert.AlertDate,
+		alert.TotalStudents,
+		alert.AttendanceRate,
+		alert.BaselineRate,
+
+		alert.DropPercentage,
+		alert.Status,
+	).Scan(&alert.ID, &alert.CreatedAt, &alert.UpdatedAt, &inserted)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// an admin has already taken up the alert for this class and date
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create attendance alert: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// ResolveRecovered resolves the open alert of the class for the date once its attendance is no longer
+// far enough below the baseline, recording the figures it recovered to. It reports whether an alert was resolved.
+// Alerts resolved this way have no resolved_by.
+func (r *attendanceAlertRepository) ResolveRecovered(ctx context.Context, rate *models.ClassAttendanceRate, date time.Time, drop float64) (bool, error) {
+	query := `
+		UPDATE attendance_alerts
+		SET status = 'resolved'
+		  , total_students = $3
+		  , attendance_rate = $4
+		  , baseline_rate = $5
+		  , drop_percentage = $6
+		  , resolved_at = NOW()
+		  , updated_at = NOW()
+		WHERE class_id = $1 AND alert_date = DATE($2) AND status = 'open'`
+
+	result, err := r.db.ExecContext(ctx, query,
+		rate.ClassID,
+		date,
+		rate.TotalStudents,
+		rate.CurrentRate,
+		rate.BaselineRate,
+		drop,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve recovered attendance alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *attendanceAlertRepository) GetByID(ctx context.Context, id uint) (*models.AttendanceAlert, error) {
+	query := `
+		SELECT aa.id
+		     , aa.class_id
+		     , c.name AS class_name
+		     , aa.alert_date
+		     , aa.total_students
+
+		     , aa.attendance_rate
+		     , aa.baseline_rate
+		     , aa.drop_percentage
+		     , aa.status
+		     , aa.acknowledged_by
+
+		     , aa.acknowledged_at
+		     , aa.resolved_by
+		     , aa.resolved_at
+		     , aa.created_at
+		     , aa.updated_at
+		FROM attendance_alerts aa
+		    JOIN classes c ON aa.class_id = c.id
+		WHERE aa.id = $1 AND ($2 = 0 OR c.school_id = $2)`
+
+	alert := &models.AttendanceAlert{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&alert.ID,
+		&alert.ClassID,
+		&alert.ClassName,
+		&alert.AlertDate,
+		&alert.TotalStudents,
+
+		&alert.AttendanceRate,
+		&alert.BaselineRate,
+		&alert.DropPercentage,
+		&alert.Status,
+		&alert.AcknowledgedBy,
+
+		&alert.AcknowledgedAt,
+		&alert.ResolvedBy,
+		&alert.ResolvedAt,
+		&alert.CreatedAt,
+		&alert.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("attendance alert not found")
+		}
+		return nil, fmt.Errorf("failed to get attendance alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+func (r *attendanceAlertRepository) GetAll(ctx context.Context, status models.AttendanceAlertStatus, limit, offset int) ([]*models.AttendanceAlert, error) {
+	query := `
+		SELECT aa.id
+		     , aa.class_id
+		     , c.name AS class_name
+		     , aa.alert_date
+		     , aa.total_students
+
+		     , aa.attendance_rate
+		     , aa.baseline_rate
+		     , aa.drop_percentage
+		     , aa.status
+		     , aa.acknowledged_by
+
+		     , aa.acknowledged_at
+		     , aa.resolved_by
+		     , aa.resolved_at
+		     , aa.created_at
+		     , aa.updated_at
+		FROM attendance_alerts aa
+		    JOIN classes c ON aa.class_id = c.id
+		WHERE ($1 = '' OR aa.status = $1) AND ($4 = 0 OR c.school_id = $4)
+		ORDER BY aa.alert_date DESC, aa.drop_percentage DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*models.AttendanceAlert
+	for rows.Next() {
+		alert := &models.AttendanceAlert{}
+		err := rows.Scan(
+			&alert.ID,
+			&alert.ClassID,
+			&alert.ClassName,
+			&alert.AlertDate,
+			&alert.TotalStudents,
+
+			&alert.AttendanceRate,
+			&alert.BaselineRate,
+			&alert.DropPercentage,
+			&alert.Status,
+			&alert.AcknowledgedBy,
+
+			&alert.AcknowledgedAt,
+			&alert.ResolvedBy,
+			&alert.ResolvedAt,
+			&alert.CreatedAt,
+			&alert.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attendance alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attendance alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func (r *attendanceAlertRepository) GetCount(ctx context.Context, status models.AttendanceAlertStatus) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM attendance_alerts aa
+		    JOIN classes c ON aa.class_id = c.id
+		WHERE ($1 = '' OR aa.status = $1) AND ($2 = 0 OR c.school_id = $2)`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, status, schoolFilter(ctx)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get attendance alert count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *attendanceAlertRepository) Acknowledge(ctx context.Context, id uint, adminID uint) error {
+	query := `
+		UPDATE attendance_alerts
+		SET status = 'acknowledged', acknowledged_by = $2, acknowledged_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'open'
+		  AND class_id IN (SELECT id FROM classes WHERE $3 = 0 OR school_id = $3)
+		RETURNING updated_at`
+
+	var updatedAt string
+	err := r.db.QueryRowContext(ctx, query, id, adminID, schoolFilter(ctx)).Scan(&updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("attendance alert not found or not open")
+		}
+		return fmt.Errorf("failed to acknowledge attendance alert: %w", err)
+	}
+
+	return nil
+}
+
+func (r *attendanceAlertRepository) Resolve(ctx context.Context, id uint, adminID uint) error {
+	query := `
+		UPDATE attendance_alerts
+		SET status = 'resolved'
+		  , acknowledged_by = COALESCE(acknowledged_by, $2)
+		  , acknowledged_at = COALESCE(acknowledged_at, NOW())
+		  , resolved_by = $2
+		  , resolved_at = NOW()
+		  , updated_at = NOW()
+		WHERE id = $1 AND status IN ('open', 'acknowledged')
+		  AND class_id IN (SELECT id FROM classes WHERE $3 = 0 OR school_id = $3)
+		RETURNING updated_at`
+
+	var updatedAt string
+	err := r.db.QueryRowContext(ctx, query, id, adminID, schoolFilter(ctx)).Scan(&updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("attendance alert not found or already resolved")
+		}
+		return fmt.Errorf("failed to resolve attendance alert: %w", err)
+	}
+
+	return nil
+}
+
+func (r *attendanceAlertRepository) GetClassAttendanceRates(ctx context.Context, date time.Time, baselineDays int) ([]*models.ClassAttendanceRate, error) {
+	// Only days on which a class has attendance records count towards its baseline,
+	// so weekends and holidays do not drag the rolling average down. A school that recorded
+	// no attendance at all on the date, such as on a holiday, has nothing to judge yet.
+	query := `
+		WITH class_sizes AS (
+			SELECT s.classes_id AS class_id, c.school_id, COUNT(*) AS total_students
+			FROM students s
+			    JOIN classes c ON s.classes_id = c.id AND c.deleted_at IS NULL
+			WHERE s.deleted_at IS NULL AND s.is_active = true AND ($3 = 0 OR s.school_id = $3)
+			GROUP BY s.classes_id, c.school_id
+		), daily AS (
+			SELECT class_id
+			     , date
+			     , COUNT(CASE WHEN status IN ('present', 'late') THEN 1 END) AS attended
+			FROM attendances
+			WHERE deleted_at IS NULL
+			  AND timetable_slot_id IS NULL
+			  AND date >= DATE($1) - $2::int
+			  AND date <= DATE($1)
+			GROUP BY class_id, date
+		), recorded_schools AS (
+			SELECT DISTINCT school_id
+			FROM attendances
+			WHERE deleted_at IS NULL
+			  AND timetable_slot_id IS NULL
+			  AND date = DATE($1)
+		)
+		SELECT cs.class_id
+		     , cs.total_students
+		     , COALESCE(MAX(CASE WHEN d.date = DATE($1) THEN d.attended END), 0) * 100.0 / cs.total_students AS current_rate
+		     , COALESCE(AVG(CASE WHEN d.date < DATE($1) THEN d.attended END), 0) * 100.0 / cs.total_students AS baseline_rate
+		     , COUNT(CASE WHEN d.date < DATE($1) THEN 1 END) AS baseline_days
+		     , cs.school_id IN (SELECT school_id FROM recorded_schools) AS school_recorded
+		FROM class_sizes cs
+		    LEFT JOIN daily d ON d.class_id = cs.class_id
+		GROUP BY cs.class_id, cs.school_id, cs.total_students
+		ORDER BY cs.class_id`
+
+	rows, err := r.db.QueryContext(ctx, query, date, baselineDays, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class attendance rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*models.ClassAttendanceRate
+	for rows.Next() {
+		rate := &models.ClassAttendanceRate{}
+		err := rows.Scan(
+			&rate.ClassID,
+			&rate.TotalStudents,
+			&rate.CurrentRate,
+			&rate.BaselineRate,
+			&rate.BaselineDays,
+			&rate.SchoolRecorded,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan class attendance rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate class attendance rates: %w", err)
+	}
+
+	return rates, nil
+}
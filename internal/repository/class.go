@@ -18,35 +18,57 @@ func NewClassRepository(db *sql.DB) ClassRepository {
 }
 
 func (r *classRepository) Create(ctx context.Context, class *models.Class) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create class: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
-		INSERT INTO classes (name, homeroom_teacher, description, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		INSERT INTO classes (name, homeroom_teacher, description, academic_year_id, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		class.Name,
 		class.HomeroomTeacher,
 		class.Description,
+		class.AcademicYearID,
+		schoolID,
 	).Scan(&class.ID, &class.CreatedAt, &class.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create class: %w", err)
 	}
 
+	if err = syncHomeroomTeacher(ctx, tx, class.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit class: %w", err)
+	}
+
 	return nil
 }
 
 func (r *classRepository) GetByID(ctx context.Context, id uint) (*models.Class, error) {
 	query := `
-		SELECT id, name, homeroom_teacher, description, created_at, updated_at
-		FROM classes WHERE id = $1 AND deleted_at IS NULL`
+		SELECT id, name, homeroom_teacher, description, academic_year_id, created_at, updated_at
+		FROM classes WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	class := &models.Class{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&class.ID,
 		&class.Name,
 		&class.HomeroomTeacher,
 		&class.Description,
+		&class.AcademicYearID,
 		&class.CreatedAt,
 		&class.UpdatedAt,
 	)
@@ -63,13 +85,13 @@ func (r *classRepository) GetByID(ctx context.Context, id uint) (*models.Class,
 
 func (r *classRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Class, error) {
 	query := `
-		SELECT id, name, homeroom_teacher, description, created_at, updated_at
+		SELECT id, name, homeroom_teacher, description, academic_year_id, created_at, updated_at
 		FROM classes
-		WHERE deleted_at IS NULL
+		WHERE deleted_at IS NULL AND ($3 = 0 OR school_id = $3)
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get classes: %w", err)
 	}
@@ -83,6 +105,7 @@ func (r *classRepository) GetAll(ctx context.Context, limit, offset int) ([]*mod
 			&class.Name,
 			&class.HomeroomTeacher,
 			&class.Description,
+			&class.AcademicYearID,
 			&class.CreatedAt,
 			&class.UpdatedAt,
 		)
@@ -99,14 +122,16 @@ func (r *classRepository) GetAll(ctx context.Context, limit, offset int) ([]*mod
 	return classes, nil
 }
 
+// GetByTeacher returns the classes the teacher is assigned to in any role
 func (r *classRepository) GetByTeacher(ctx context.Context, teacherID string) ([]*models.Class, error) {
 	query := `
-		SELECT id, name, homeroom_teacher, description, created_at, updated_at
-		FROM classes 
-		WHERE homeroom_teacher = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC`
+		SELECT c.id, c.name, c.homeroom_teacher, c.description, c.academic_year_id, c.created_at, c.updated_at
+		FROM classes c
+		    JOIN class_teachers ct ON ct.class_id = c.id
+		WHERE ct.teacher_id = $1 AND ($2 = 0 OR c.school_id = $2) AND c.deleted_at IS NULL
+		ORDER BY c.created_at DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, teacherID)
+	rows, err := r.db.QueryContext(ctx, query, teacherID, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get classes by teacher: %w", err)
 	}
@@ -120,6 +145,7 @@ func (r *classRepository) GetByTeacher(ctx context.Context, teacherID string) ([
 			&class.Name,
 			&class.HomeroomTeacher,
 			&class.Description,
+			&class.AcademicYearID,
 			&class.CreatedAt,
 			&class.UpdatedAt,
 		)
@@ -136,18 +162,66 @@ func (r *classRepository) GetByTeacher(ctx context.Context, teacherID string) ([
 	return classes, nil
 }
 
+// GetByAcademicYear returns the classes of the academic year
+func (r *classRepository) GetByAcademicYear(ctx context.Context, academicYearID uint) ([]*models.Class, error) {
+	query := `
+		SELECT id, name, homeroom_teacher, description, academic_year_id, created_at, updated_at
+		FROM classes
+		WHERE academic_year_id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, academicYearID, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classes by academic year: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []*models.Class
+	for rows.Next() {
+		class := &models.Class{}
+		err := rows.Scan(
+			&class.ID,
+			&class.Name,
+			&class.HomeroomTeacher,
+			&class.Description,
+			&class.AcademicYearID,
+			&class.CreatedAt,
+			&class.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan class: %w", err)
+		}
+		classes = append(classes, class)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate classes: %w", err)
+	}
+
+	return classes, nil
+}
+
+// Update saves the class. A new homeroom teacher replaces the previous one among the teachers of the class.
 func (r *classRepository) Update(ctx context.Context, class *models.Class) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		UPDATE classes 
-		SET name = $2, homeroom_teacher = $3, description = $4, updated_at = NOW()
-		WHERE id = $1
+		SET name = $2, homeroom_teacher = $3, description = $4, academic_year_id = $5, updated_at = NOW()
+		WHERE id = $1 AND ($6 = 0 OR school_id = $6)
 		RETURNING updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		class.ID,
 		class.Name,
 		class.HomeroomTeacher,
 		class.Description,
+		class.AcademicYearID,
+		schoolFilter(ctx),
 	).Scan(&class.UpdatedAt)
 
 	if err != nil {
@@ -157,13 +231,21 @@ func (r *classRepository) Update(ctx context.Context, class *models.Class) error
 		return fmt.Errorf("failed to update class: %w", err)
 	}
 
+	if err = syncHomeroomTeacher(ctx, tx, class.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit class: %w", err)
+	}
+
 	return nil
 }
 
 func (r *classRepository) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM classes WHERE id = $1`
+	query := `DELETE FROM classes WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete class: %w", err)
 	}
@@ -181,10 +263,10 @@ func (r *classRepository) Delete(ctx context.Context, id uint) error {
 }
 
 func (r *classRepository) GetTotalClasses(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM classes WHERE deleted_at IS NULL`
+	query := `SELECT COUNT(*) FROM classes WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)`
 
 	var total int
-	err := r.db.QueryRowContext(ctx, query).Scan(&total)
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&total)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total classes: %w", err)
 	}
@@ -196,11 +278,11 @@ func (r *classRepository) UpdateDeleteInfo(ctx context.Context, id uint, deleted
 	query := `
 		UPDATE classes 
 		SET deleted_at = NOW(), deleted_by = $2
-		WHERE id = $1
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING deleted_at`
 
 	var deletedAt string
-	err := r.db.QueryRowContext(ctx, query, id, deletedBy).Scan(&deletedAt)
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("class not found")
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type classTeacherRepository struct {
+	db *sql.DB
+}
+
+// NewClassTeacherRepository creates a new class teacher repository
+func NewClassTeacherRepository(db *sql.DB) ClassTeacherRepository {
+	return &classTeacherRepository{db: db}
+}
+
+// GetByClass returns the teachers of the class, the homeroom teacher first
+func (r *classTeacherRepository) GetByClass(ctx context.Context, classID uint) ([]*models.ClassTeacher, error) {
+	query := `
+		SELECT ct.id, ct.school_id, ct.class_id, ct.teacher_id, ct.role,
+		       t.first_name, t.last_name, ct.created_at, ct.created_by
+		FROM class_teachers ct
+		    JOIN teachers t ON t.teacher_id = ct.teacher_id AND t.school_id = ct.school_id
+		WHERE ct.class_id = $1 AND ($2 = 0 OR ct.school_id = $2)
+		ORDER BY CASE ct.role WHEN 'homeroom' THEN 1 WHEN 'co_homeroom' THEN 2 ELSE 3 END, t.first_name, t.last_name`
+
+	return r.queryClassTeachers(ctx, query, classID, schoolFilter(ctx))
+}
+
+// GetByTeacher returns the classes the teacher is assigned to and their role in each
+func (r *classTeacherRepository) GetByTeacher(ctx context.Context, teacherID string) ([]*models.ClassTeacher, error) {
+	query := `
+		SELECT ct.id, ct.school_id, ct.class_id, ct.teacher_id, ct.role,
+		       t.first_name, t.last_name, ct.created_at, ct.created_by
+		FROM class_teachers ct
+		    JOIN teachers t ON t.teacher_id = ct.teacher_id AND t.school_id = ct.school_id
+		    JOIN classes c ON c.id = ct.class_id
+		WHERE ct.teacher_id = $1 AND ($2 = 0 OR ct.school_id = $2) AND c.deleted_at IS NULL
+		ORDER BY ct.class_id`
+
+	return r.queryClassTeachers(ctx, query, teacherID, schoolFilter(ctx))
+}
+
+func (r *classTeacherRepository) queryClassTeachers(ctx context.Context, query string, args ...interface{}) ([]*models.ClassTeacher, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class teachers: %w", err)
+	}
+	defer rows.Close()
+
+	var classTeachers []*models.ClassTeacher
+	for rows.Next() {
+		classTeacher := &models.ClassTeacher{}
+		err := rows.Scan(
+			&classTeacher.ID,
+			&classTeacher.SchoolID,
+			&classTeacher.ClassID,
+			&classTeacher.TeacherID,
+			&classTeacher.Role,
+			&classTeacher.FirstName,
+			&classTeacher.LastName,
+			&classTeacher.CreatedAt,
+			&classTeacher.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan class teacher: %w", err)
+		}
+		classTeachers = append(classTeachers, classTeacher)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate class teachers: %w", err)
+	}
+
+	return classTeachers, nil
+}
+
+// Assign gives the teacher a role in the class, changing the role they already hold there.
+// A new homeroom teacher replaces the previous one, who leaves the class. The homeroom teacher
+// cannot be moved to another role; another teacher must be made homeroom first.
+func (r *classTeacherRepository) Assign(ctx context.Context, classTeacher *models.ClassTeacher) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	currentRole, err := classTeacherRole(ctx, tx, classTeacher.ClassID, classTeacher.TeacherID)
+	if err != nil {
+		return err
+	}
+
+	if currentRole == models.ClassTeacherRoleHomeroom && classTeacher.Role != models.ClassTeacherRoleHomeroom {
+		return models.ErrHomeroomTeacherRequired
+	}
+
+	if classTeacher.Role == models.ClassTeacherRoleHomeroom {
+		query := `
+			UPDATE classes
+			SET homeroom_teacher = $2, updated_at = NOW()
+			WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL`
+
+		result, err := tx.ExecContext(ctx, query, classTeacher.ClassID, classTeacher.TeacherID, schoolFilter(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to update homeroom teacher: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("class not found")
+		}
+
+		if err = syncHomeroomTeacher(ctx, tx, classTeacher.ClassID); err != nil {
+			return err
+		}
+	}
+
+	// the class teacher belongs to the school of its class
+	query := `
+		INSERT INTO class_teachers (school_id, class_id, teacher_id, role, created_by, created_at)
+		SELECT c.school_id, c.id, $2, $3, $4, NOW()
+		FROM classes c
+		WHERE c.id = $1 AND ($5 = 0 OR c.school_id = $5) AND c.deleted_at IS NULL
+		ON CONFLICT (class_id, teacher_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, school_id, created_at, created_by`
+
+	err = tx.QueryRowContext(ctx, query,
+		classTeacher.ClassID,
+		classTeacher.TeacherID,
+		classTeacher.Role,
+		classTeacher.CreatedBy,
+		schoolFilter(ctx),
+	).Scan(&classTeacher.ID, &classTeacher.SchoolID, &classTeacher.CreatedAt, &classTeacher.CreatedBy)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("class not found")
+		}
+		return fmt.Errorf("failed to assign class teacher: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit class teacher: %w", err)
+	}
+
+	return nil
+}
+
+// Remove takes the teacher off the class. The homeroom teacher cannot be removed.
+func (r *classTeacherRepository) Remove(ctx context.Context, classID uint, teacherID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	role, err := classTeacherRole(ctx, tx, classID, teacherID)
+	if err != nil {
+		return err
+	}
+
+	if role == models.ClassTeacherRoleHomeroom {
+		return models.ErrHomeroomTeacherRequired
+	}
+
+	query := `DELETE FROM class_teachers WHERE class_id = $1 AND teacher_id = $2 AND ($3 = 0 OR school_id = $3)`
+
+	result, err := tx.ExecContext(ctx, query, classID, teacherID, schoolFilter(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to remove class teacher: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("class teacher not found")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit class teacher removal: %w", err)
+	}
+
+	return nil
+}
+
+// GetRolePermissions returns what each class teacher role allows in the school of the request
+func (r *classTeacherRepository) GetRolePermissions(ctx context.Context) ([]*models.ClassTeacherRolePermission, error) {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class teacher role permissions: %w", err)
+	}
+
+	permissions := make([]*models.ClassTeacherRolePermission, 0, len(models.ClassTeacherRoles))
+	for _, role := range models.ClassTeacherRoles {
+		permission, err := r.GetRolePermission(ctx, schoolID, role)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
+// GetRolePermission returns what the role allows in the school, or its defaults when the school has not configured it
+func (r *classTeacherRepository) GetRolePermission(ctx context.Context, schoolID uint, role string) (*models.ClassTeacherRolePermission, error) {
+	query := `
+		SELECT role, can_decide_absent_requests, can_comment_absent_requests, updated_at, updated_by
+		FROM class_teacher_role_permissions
+		WHERE school_id = $1 AND role = $2`
+
+	permission := &models.ClassTeacherRolePermission{}
+	err := r.db.QueryRowContext(ctx, query, schoolID, role).Scan(
+		&permission.Role,
+		&permission.CanDecideAbsentRequests,
+		&permission.CanCommentAbsentRequests,
+		&permission.UpdatedAt,
+		&permission.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.DefaultClassTeacherRolePermission(role), nil
+		}
+		return nil, fmt.Errorf("failed to get class teacher role permission: %w", err)
+	}
+
+	return permission, nil
+}
+
+// UpdateRolePermission saves what the role allows in the school of the request
+func (r *classTeacherRepository) UpdateRolePermission(ctx context.Context, permission *models.ClassTeacherRolePermission) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update class teacher role permission: %w", err)
+	}
+
+	query := `
+		INSERT INTO class_teacher_role_permissions (
+			school_id
+			, role
+			, can_decide_absent_requests
+			, can_comment_absent_requests
+
+			, updated_by
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (school_id, role) DO UPDATE
+		SET can_decide_absent_requests = EXCLUDED.can_decide_absent_requests
+		  , can_comment_absent_requests = EXCLUDED.can_comment_absent_requests
+		  , updated_by = EXCLUDED.updated_by
+		  , updated_at = NOW()
+		RETURNING updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		schoolID,
+		permission.Role,
+		permission.CanDecideAbsentRequests,
+		permission.CanCommentAbsentRequests,
+
+		permission.UpdatedBy,
+	).Scan(&permission.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update class teacher role permission: %w", err)
+	}
+
+	return nil
+}
+
+// classTeacherRole returns the role of the teacher in the class, or an empty string when they are not assigned to it
+func classTeacherRole(ctx context.Context, tx *sql.Tx, classID uint, teacherID string) (string, error) {
+	var role string
+	err := tx.QueryRowContext(ctx, `SELECT role FROM class_teachers WHERE class_id = $1 AND teacher_id = $2`, classID, teacherID).Scan(&role)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get class teacher role: %w", err)
+	}
+
+	return role, nil
+}
+
+// syncHomeroomTeacher makes the homeroom teacher of the class its only teacher in the homeroom role.
+// The previous homeroom teacher leaves the class.
+func syncHomeroomTeacher(ctx context.Context, tx *sql.Tx, classID uint) error {
+	deleteQuery := `
+		DELETE FROM class_teachers ct
+		USING classes c
+		WHERE ct.class_id = c.id AND c.id = $1 AND ct.role = 'homeroom' AND ct.teacher_id <> c.homeroom_teacher`
+
+	if _, err := tx.ExecContext(ctx, deleteQuery, classID); err != nil {
+		return fmt.Errorf("failed to remove previous homeroom teacher: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO class_teachers (school_id, class_id, teacher_id, role, created_at)
+		SELECT school_id, id, homeroom_teacher, 'homeroom', NOW()
+		FROM classes
+		WHERE id = $1
+		ON CONFLICT (class_id, teacher_id) DO UPDATE SET role = 'homeroom'`
+
+	if _, err := tx.ExecContext(ctx, insertQuery, classID); err != nil {
+		return fmt.Errorf("failed to assign homeroom teacher: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type enrollmentRepository struct {
+	db *sql.DB
+}
+
+// NewEnrollmentRepository creates a new enrollment repository
+func NewEnrollmentRepository(db *sql.DB) EnrollmentRepository {
+	return &enrollmentRepository{db: db}
+}
+
+// GetByStudent returns the classes the student has been in, the most recent first
+func (r *enrollmentRepository) GetByStudent(ctx context.Context, studentID uint) ([]*models.Enrollment, error) {
+	query := `
+		SELECT e.id, e.school_id, e.student_id, e.class_id, c.name, e.academic_year_id,
+		       e.start_date, e.end_date, e.start_reason, e.end_reason, e.note, e.created_at, e.created_by
+		FROM enrollments e
+		JOIN classes c ON c.id = e.class_id
+		WHERE e.student_id = $1 AND ($2 = 0 OR e.school_id = $2)
+		ORDER BY e.start_date DESC, e.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, studentID, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	var enrollments []*models.Enrollment
+	for rows.Next() {
+		enrollment := &models.Enrollment{}
+		err := rows.Scan(
+			&enrollment.ID,
+			&enrollment.SchoolID,
+			&enrollment.StudentID,
+			&enrollment.ClassID,
+			&enrollment.ClassName,
+			&enrollment.AcademicYearID,
+			&enrollment.StartDate,
+			&enrollment.EndDate,
+			&enrollment.StartReason,
+			&enrollment.EndReason,
+			&enrollment.Note,
+			&enrollment.CreatedAt,
+			&enrollment.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate enrollments: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+// Transfer closes the student's current enrollment on the effective date and enrolls them in the
+// new class from that date. Attendance already recorded keeps the class it was taken in.
+func (r *enrollmentRepository) Transfer(ctx context.Context, transfer *models.Transfer) (*models.Enrollment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		SELECT e.class_id, e.start_date
+		FROM enrollments e
+		JOIN students s ON s.id = e.student_id
+		WHERE e.student_id = $1 AND e.end_date IS NULL
+		  AND ($2 = 0 OR s.school_id = $2) AND s.deleted_at IS NULL
+		FOR UPDATE OF e`
+
+	var currentClassID uint
+	var startDate time.Time
+	err = tx.QueryRowContext(ctx, query, transfer.StudentID, schoolFilter(ctx)).Scan(&currentClassID, &startDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("enrollment not found")
+		}
+		return nil, fmt.Errorf("failed to get current enrollment: %w", err)
+	}
+
+	if currentClassID == transfer.ClassID {
+		return nil, models.ErrTransferSameClass
+	}
+
+	if transfer.EffectiveDate.Before(startDate) {
+		return nil, models.ErrTransferBeforeEnrollment
+	}
+
+	ids := []int64{int64(transfer.StudentID)}
+	if err := closeEnrollments(ctx, tx, ids, transfer.EffectiveDate, models.EnrollmentReasonTransferred); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE students SET classes_id = $2, updated_at = NOW() WHERE id = $1`, transfer.StudentID, transfer.ClassID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer student: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO enrollments (school_id, student_id, class_id, academic_year_id, start_date, start_reason, note, created_by)
+		SELECT c.school_id, $1, c.id, c.academic_year_id, DATE($3), $4, $5, $6
+		FROM classes c
+		WHERE c.id = $2
+		RETURNING id, school_id, student_id, class_id, academic_year_id, start_date, start_reason, note, created_at, created_by`
+
+	enrollment := &models.Enrollment{}
+	err = tx.QueryRowContext(ctx, insertQuery,
+		transfer.StudentID,
+		transfer.ClassID,
+		transfer.EffectiveDate,
+		models.EnrollmentReasonTransferred,
+		transfer.Note,
+		transfer.TransferredBy,
+	).Scan(
+		&enrollment.ID,
+		&enrollment.SchoolID,
+		&enrollment.StudentID,
+		&enrollment.ClassID,
+		&enrollment.AcademicYearID,
+		&enrollment.StartDate,
+		&enrollment.StartReason,
+		&enrollment.Note,
+		&enrollment.CreatedAt,
+		&enrollment.CreatedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open enrollment: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	return enrollment, nil
+}
+
+// Promote moves the active students of each mapped class to its successor, or graduates them when
+// it has none, in a single transaction. Their attendance stays recorded under the old class.
+// A dry run only counts the students that would move. When a student joined their class after the
+// promotion date nobody moves and models.ErrPromotionBeforeEnrollment is returned with the result
+// listing them.
+func (r *enrollmentRepository) Promote(ctx context.Context, promotion *models.Promotion, promotedBy uint) (*models.PromotionResult, error) {
+	result := &models.PromotionResult{
+		FromAcademicYearID: promotion.FromAcademicYearID,
+		ToAcademicYearID:   promotion.ToAcademicYearID,
+		Date:               promotion.Date,
+		DryRun:             promotion.DryRun,
+		Classes:            make([]models.ClassPromotionResult, 0, len(promotion.Classes)),
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// every class is read before anyone moves so a student is promoted once even when
+	// one class's successor is itself promoted
+	students := make([][]int64, len(promotion.Classes))
+	blocked := false
+	for i, class := range promotion.Classes {
+		ids, err := classStudentIDs(ctx, tx, class.FromClassID)
+		if err != nil {
+			return nil, err
+		}
+
+		enrolledAfter, err := enrolledAfterDate(ctx, tx, ids, promotion.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		students[i] = ids
+		result.Classes = append(result.Classes, models.ClassPromotionResult{
+			FromClassID:       class.FromClassID,
+			ToClassID:         class.ToClassID,
+			Students:          len(ids),
+			EnrolledAfterDate: enrolledAfter,
+		})
+		if len(enrolledAfter) > 0 {
+			blocked = true
+		}
+
+		if class.ToClassID == nil {
+			result.Graduated += len(ids)
+		} else {
+			result.Promoted += len(ids)
+		}
+	}
+
+	if promotion.DryRun {
+		return result, nil
+	}
+
+	// closing their enrollment on the date would end it before it started
+	if blocked {
+		return result, models.ErrPromotionBeforeEnrollment
+	}
+
+	for i, class := range promotion.Classes {
+		ids := students[i]
+		if len(ids) == 0 {
+			continue
+		}
+
+		if class.ToClassID == nil {
+			if err := closeEnrollments(ctx, tx, ids, promotion.Date, models.EnrollmentReasonGraduated); err != nil {
+				return nil, err
+			}
+
+			query := `UPDATE students SET is_active = FALSE, updated_at = NOW() WHERE id = ANY($1)`
+			if _, err := tx.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+				return nil, fmt.Errorf("failed to graduate students: %w", err)
+			}
+			continue
+		}
+
+		if err := closeEnrollments(ctx, tx, ids, promotion.Date, models.EnrollmentReasonPromoted); err != nil {
+			return nil, err
+		}
+
+		query := `UPDATE students SET classes_id = $2, updated_at = NOW() WHERE id = ANY($1)`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(ids), *class.ToClassID); err != nil {
+			return nil, fmt.Errorf("failed to promote students: %w", err)
+		}
+
+		err := openEnrollments(ctx, tx, ids, *class.ToClassID, promotion.Date, models.EnrollmentReasonPromoted, &promotedBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit promotion: %w", err)
+	}
+
+	return result, nil
+}
+
+// classStudentIDs locks and returns the active students of the class
+func classStudentIDs(ctx context.Context, tx *sql.Tx, classID uint) ([]int64, error) {
+	query := `
+		SELECT id FROM students
+		WHERE classes_id = $1 AND ($2 = 0 OR school_id = $2) AND is_active AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, classID, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class students: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan class student: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate class students: %w", err)
+	}
+
+	return ids, nil
+}
+
+// enrolledAfterDate returns the students whose current enrollment started after the date
+func enrolledAfterDate(ctx context.Context, tx *sql.Tx, studentIDs []int64, date time.Time) ([]uint, error) {
+	query := `
+		SELECT student_id FROM enrollments
+		WHERE student_id = ANY($1) AND end_date IS NULL AND start_date > DATE($2)
+		ORDER BY student_id`
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(studentIDs), date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan current enrollment: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate current enrollments: %w", err)
+	}
+
+	return ids, nil
+}
+
+// changeEnrollment moves the student to the class from the date. Nothing changes when the
+// student is already enrolled in it; a student without an enrollment is enrolled.
+func changeEnrollment(ctx context.Context, tx *sql.Tx, studentID, classID uint, date time.Time, createdBy *uint) error {
+	var current sql.NullInt64
+	query := `SELECT class_id FROM enrollments WHERE student_id = $1 AND end_date IS NULL FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, studentID).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get current enrollment: %w", err)
+	}
+
+	if current.Valid && uint(current.Int64) == classID {
+		return nil
+	}
+
+	ids := []int64{int64(studentID)}
+	reason := models.EnrollmentReasonEnrolled
+	if current.Valid {
+		if err := closeEnrollments(ctx, tx, ids, date, models.EnrollmentReasonMoved); err != nil {
+			return err
+		}
+		reason = models.EnrollmentReasonMoved
+	}
+
+	return openEnrollments(ctx, tx, ids, classID, date, reason, createdBy)
+}
+
+// closeEnrollments ends the open enrollments of the students on the date, the first day
+// they are no longer in the class
+func closeEnrollments(ctx context.Context, tx *sql.Tx, studentIDs []int64, date time.Time, reason string) error {
+	query := `
+		UPDATE enrollments
+		SET end_date = GREATEST(DATE($2), start_date), end_reason = $3
+		WHERE student_id = ANY($1) AND end_date IS NULL`
+
+	if _, err := tx.ExecContext(ctx, query, pq.Array(studentIDs), date, reason); err != nil {
+		return fmt.Errorf("failed to close enrollments: %w", err)
+	}
+
+	return nil
+}
+
+// openEnrollments enrolls the students in the class from the date
+func openEnrollments(ctx context.Context, tx *sql.Tx, studentIDs []int64, classID uint, date time.Time, reason string, createdBy *uint) error {
+	query := `
+		INSERT INTO enrollments (school_id, student_id, class_id, academic_year_id, start_date, start_reason, created_by)
+		SELECT c.school_id, s.id, c.id, c.academic_year_id, DATE($3), $4, $5
+		FROM UNNEST($1::INTEGER[]) AS s(id)
+		CROSS JOIN classes c
+		WHERE c.id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, pq.Array(studentIDs), classID, date, reason, createdBy); err != nil {
+		return fmt.Errorf("failed to open enrollments: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type guardianRepository struct {
+	db *sql.DB
+}
+
+// NewGuardianRepository creates a new guardian repository
+func NewGuardianRepository(db *sql.DB) GuardianRepository {
+	return &guardianRepository{db: db}
+}
+
+// guardianInSchool filters guardians on the school in the given query parameter.
+// Guardians belong to the foundation, so a school reaches the guardians of its students
+// and the guardians not linked to any student yet.
+func guardianInSchool(param int) string {
+	return fmt.Sprintf(`($%[1]d = 0
+		OR NOT EXISTS (SELECT 1 FROM guardian_students gs WHERE gs.guardian_id = guardians.id)
+		OR EXISTS (
+			SELECT 1
+			FROM guardian_students gs
+			JOIN students s ON s.id = gs.student_id
+			WHERE gs.guardian_id = guardians.id AND s.school_id = $%[1]d
+		))`, param)
+}
+
+func (r *guardianRepository) Create(ctx context.Context, guardian *models.Guardian) error {
+	query := `
+		INSERT INTO guardians (
+			first_name
+			, last_name
+			, email
+			, phone
+			, password
+
+			, is_active
+			, created_by
+			, created_at
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		guardian.FirstName,
+		guardian.LastName,
+		guardian.Email,
+		guardian.Phone,
+		guardian.Password,
+
+		guardian.IsActive,
+		guardian.CreatedBy,
+	).Scan(&guardian.ID, &guardian.CreatedAt, &guardian.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create guardian: %w", err)
+	}
+
+	return nil
+}
+
+func (r *guardianRepository) GetByID(ctx context.Context, id uint) (*models.Guardian, error) {
+	query := `
+		SELECT id, first_name, last_name, email, phone, is_active, last_login,
+		       created_at, updated_at, created_by, updated_by
+		FROM guardians
+		WHERE id = $1 AND deleted_at IS NULL AND ` + guardianInSchool(2)
+
+	guardian := &models.Guardian{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&guardian.ID,
+		&guardian.FirstName,
+		&guardian.LastName,
+		&guardian.Email,
+		&guardian.Phone,
+		&guardian.IsActive,
+		&guardian.LastLogin,
+		&guardian.CreatedAt,
+		&guardian.UpdatedAt,
+		&guardian.CreatedBy,
+		&guardian.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("guardian not found")
+		}
+		return nil, fmt.Errorf("failed to get guardian: %w", err)
+	}
+
+	return guardian, nil
+}
+
+func (r *guardianRepository) GetByEmail(ctx context.Context, email string) (*models.Guardian, error) {
+	query := `
+		SELECT id, first_name, last_name, email, phone, is_active, last_login,
+		       created_at, updated_at, created_by, updated_by
+		FROM guardians
+		WHERE email = $1 AND deleted_at IS NULL`
+
+	guardian := &models.Guardian{}
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&guardian.ID,
+		&guardian.FirstName,
+		&guardian.LastName,
+		&guardian.Email,
+		&guardian.Phone,
+		&guardian.IsActive,
+		&guardian.LastLogin,
+		&guardian.CreatedAt,
+		&guardian.UpdatedAt,
+		&guardian.CreatedBy,
+		&guardian.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("guardian not found")
+		}
+		return nil, fmt.Errorf("failed to get guardian: %w", err)
+	}
+
+	return guardian, nil
+}
+
+func (r *guardianRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Guardian, error) {
+	query := `
+		SELECT id, first_name, last_name, email, phone, is_active, last_login,
+		       created_at, updated_at, created_by, updated_by
+		FROM guardians
+		WHERE deleted_at IS NULL AND ` + guardianInSchool(3) + `
+		ORDER BY last_name, first_name
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guardians: %w", err)
+	}
+	defer rows.Close()
+
+	var guardians []*models.Guardian
+	for rows.Next() {
+		guardian := &models.Guardian{}
+		err := rows.Scan(
+			&guardian.ID,
+			&guardian.FirstName,
+			&guardian.LastName,
+			&guardian.Email,
+			&guardian.Phone,
+			&guardian.IsActive,
+			&guardian.LastLogin,
+			&guardian.CreatedAt,
+			&guardian.UpdatedAt,
+			&guardian.CreatedBy,
+			&guardian.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan guardian: %w", err)
+		}
+		guardians = append(guardians, guardian)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate guardians: %w", err)
+	}
+
+	return guardians, nil
+}
+
+func (r *guardianRepository) GetTotalGuardians(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM guardians WHERE deleted_at IS NULL AND ` + guardianInSchool(1)
+
+	var total int
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get total guardians: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *guardianRepository) Update(ctx context.Context, guardian *models.Guardian) error {
+	query := `
+		UPDATE guardians
+		SET first_name = $2, last_name = $3, email = $4, phone = $5, is_active = $6,
+		    updated_by = $7, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ` + guardianInSchool(8) + `
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		guardian.ID,
+		guardian.FirstName,
+		guardian.LastName,
+		guardian.Email,
+		guardian.Phone,
+		guardian.IsActive,
+		guardian.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&guardian.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("guardian not found")
+		}
+		return fmt.Errorf("failed to update guardian: %w", err)
+	}
+
+	return nil
+}
+
+func (r *guardianRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	query := `
+		UPDATE guardians
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL AND ` + guardianInSchool(3) + `
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("guardian not found")
+		}
+		return fmt.Errorf("failed to update guardian delete info: %w", err)
+	}
+
+	return nil
+}
+
+func (r *guardianRepository) UpdatePassword(ctx context.Context, id uint, password string) error {
+	query := `
+		UPDATE guardians
+		SET password = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ` + guardianInSchool(3)
+
+	result, err := r.db.ExecContext(ctx, query, id, password, schoolFilter(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("guardian not found")
+	}
+
+	return nil
+}
+
+func (r *guardianRepository) GetPasswordByEmail(ctx context.Context, email string) (string, error) {
+	query := `SELECT password FROM guardians WHERE email = $1 AND deleted_at IS NULL`
+
+	var password string
+	err := r.db.QueryRowContext(ctx, query, email).Scan(&password)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("guardian not found")
+		}
+		return "", fmt.Errorf("failed to get guardian password: %w", err)
+	}
+
+	return password, nil
+}
+
+func (r *guardianRepository) UpdateLastLogin(ctx context.Context, id uint, lastLogin time.Time) error {
+	query := `
+		UPDATE guardians
+		SET last_login = $2
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, id, lastLogin)
+	if err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+
+	return nil
+}
+
+// IsEmailExist reports whether another guardian already uses the email
+func (r *guardianRepository) IsEmailExist(ctx context.Context, email string, excludeID uint) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM guardians WHERE LOWER(email) = LOWER($1) AND id <> $2 AND deleted_at IS NULL)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, email, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check guardian email: %w", err)
+	}
+
+	return exists, nil
+}
+
+// LinkStudent links the guardian to the student, updating the relationship when already linked
+func (r *guardianRepository) LinkStudent(ctx context.Context, link *models.GuardianStudent) error {
+	query := `
+		INSERT INTO guardian_students (guardian_id, student_id, relationship, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (guardian_id, student_id) DO UPDATE SET relationship = EXCLUDED.relationship
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		link.GuardianID,
+		link.StudentID,
+		link.Relationship,
+		link.CreatedBy,
+	).Scan(&link.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to link guardian to student: %w", err)
+	}
+
+	return nil
+}
+
+// UnlinkStudent removes the link between the guardian and a student of the school in ctx
+func (r *guardianRepository) UnlinkStudent(ctx context.Context, guardianID, studentID uint) error {
+	query := `
+		DELETE FROM guardian_students gs
+		USING students s
+		WHERE gs.guardian_id = $1 AND gs.student_id = $2
+		  AND s.id = gs.student_id AND ($3 = 0 OR s.school_id = $3)`
+
+	result, err := r.db.ExecContext(ctx, query, guardianID, studentID, schoolFilter(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to unlink guardian from student: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("guardian student link not found")
+	}
+
+	return nil
+}
+
+// GetChildren returns the students linked to the guardian
+func (r *guardianRepository) GetChildren(ctx context.Context, guardianID uint) ([]*models.GuardianChild, error) {
+	query := `
+		SELECT s.id
+		     , s.student_id
+		     , s.classes_id
+		     , s.first_name
+		     , s.last_name
+		     , COALESCE(c.name, '') AS class_name
+		     , s.is_active
+		     , gs.relationship
+		FROM guardian_students gs
+		    JOIN students s ON s.id = gs.student_id AND s.deleted_at IS NULL
+		    LEFT JOIN classes c ON s.classes_id = c.id AND c.deleted_at IS NULL
+		WHERE gs.guardian_id = $1
+		ORDER BY s.first_name, s.last_name`
+
+	rows, err := r.db.QueryContext(ctx, query, guardianID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guardian children: %w", err)
+	}
+	defer rows.Close()
+
+	var children []*models.GuardianChild
+	for rows.Next() {
+		child := &models.GuardianChild{}
+		err := rows.Scan(
+			&child.ID,
+			&child.StudentID,
+			&child.ClassID,
+			&child.FirstName,
+			&child.LastName,
+			&child.ClassName,
+			&child.IsActive,
+			&child.Relationship,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan guardian child: %w", err)
+		}
+		children = append(children, child)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate guardian children: %w", err)
+	}
+
+	return children, nil
+}
+
+// GetChildSchool returns the school of the student when they are linked to the guardian, or false when they are not.
+// Guardians belong to the foundation, so their children may be in any school.
+func (r *guardianRepository) GetChildSchool(ctx context.Context, guardianID, studentID uint) (uint, bool, error) {
+	query := `
+		SELECT s.school_id
+		FROM guardian_students gs
+		JOIN students s ON s.id = gs.student_id
+		WHERE gs.guardian_id = $1 AND gs.student_id = $2 AND s.deleted_at IS NULL`
+
+	var schoolID uint
+	err := r.db.QueryRowContext(ctx, query, guardianID, studentID).Scan(&schoolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to check guardian student link: %w", err)
+	}
+
+	return schoolID, true, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// findClassesByName returns the IDs of the classes of the school with each of the names, lower-cased.
+// Classes of past academic years often share a name with this year's, so when the current
+// academic year has a class of that name only that one is returned.
+func findClassesByName(ctx context.Context, db *sql.DB, schoolID uint, names []string) (map[string][]uint, error) {
+	query := `
+		SELECT c.id, LOWER(c.name), COALESCE(ay.is_current, FALSE)
+		FROM classes c
+		    LEFT JOIN academic_years ay ON ay.id = c.academic_year_id AND ay.deleted_at IS NULL
+		WHERE c.school_id = $1 AND LOWER(c.name) = ANY($2) AND c.deleted_at IS NULL
+		ORDER BY c.id`
+
+	rows, err := db.QueryContext(ctx, query, schoolID, pq.Array(names))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classes by name: %w", err)
+	}
+	defer rows.Close()
+
+	classes := make(map[string][]uint)
+	current := make(map[string][]uint)
+	for rows.Next() {
+		var id uint
+		var name string
+		var isCurrent bool
+		if err := rows.Scan(&id, &name, &isCurrent); err != nil {
+			return nil, fmt.Errorf("failed to scan class: %w", err)
+		}
+
+		classes[name] = append(classes[name], id)
+		if isCurrent {
+			current[name] = append(current[name], id)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate classes: %w", err)
+	}
+
+	for name, ids := range current {
+		classes[name] = ids
+	}
+
+	return classes, nil
+}
+
+// resolveClassName picks the class with the name among those found by findClassesByName,
+// or returns why it cannot
+func resolveClassName(classes map[string][]uint, name string) (uint, string) {
+	classIDs := classes[strings.ToLower(name)]
+	switch {
+	case len(classIDs) == 0:
+		return 0, "class not found"
+	case len(classIDs) > 1:
+		return 0, "class name matches more than one class"
+	default:
+		return classIDs[0], ""
+	}
+}
+
+// findTaken runs a query returning which of the values, passed as $1, are already used
+func findTaken(ctx context.Context, db *sql.DB, query string, values []string, args ...interface{}) (map[string]bool, error) {
+	taken := make(map[string]bool)
+	if len(values) == 0 {
+		return taken, nil
+	}
+
+	rows, err := db.QueryContext(ctx, query, append([]interface{}{pq.Array(values)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing values: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan existing value: %w", err)
+		}
+		taken[value] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate existing values: %w", err)
+	}
+
+	return taken, nil
+}
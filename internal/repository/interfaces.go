@@ -25,6 +25,14 @@ type TeacherRepository interface {
 	GetStats(ctx context.Context) (*models.TeacherStats, error)
 	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
 	GetByIDWithClasses(ctx context.Context, id uint) (*models.TeacherWithClasses, error)
+	CheckImport(ctx context.Context, rows []*models.TeacherImportRow) ([]models.ImportRowError, error)
+	Import(ctx context.Context, rows []*models.TeacherImportRow, createdBy *uint) error
+	UpdateProfile(ctx context.Context, id uint, phone *string, language string) error
+	IsEmailTaken(ctx context.Context, email string, exceptID uint) (bool, error)
+	SaveEmailChange(ctx context.Context, change *models.TeacherEmailChange) error
+	GetEmailChange(ctx context.Context, teacherID uint) (*models.TeacherEmailChange, error)
+	FailEmailChange(ctx context.Context, teacherID uint) error
+	ConfirmEmailChange(ctx context.Context, teacherID uint) (string, error)
 }
 
 // ClassRepository defines the interface for class operations
@@ -33,6 +41,7 @@ type ClassRepository interface {
 	GetByID(ctx context.Context, id uint) (*models.Class, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*models.Class, error)
 	GetByTeacher(ctx context.Context, teacherID string) ([]*models.Class, error)
+	GetByAcademicYear(ctx context.Context, academicYearID uint) ([]*models.Class, error)
 	Update(ctx context.Context, class *models.Class) error
 	Delete(ctx context.Context, id uint) error
 	GetTotalClasses(ctx context.Context) (int, error)
@@ -46,18 +55,24 @@ type StudentRepository interface {
 	GetByStudentID(ctx context.Context, studentID string) (*models.Student, error)
 	GetByEmail(ctx context.Context, email string) (*models.Student, error)
 	GetByClass(ctx context.Context, classID uint) ([]*models.Student, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*models.Student, error)
+	GetByClassOn(ctx context.Context, classID uint, date time.Time) ([]*models.Student, error)
+	GetAll(ctx context.Context, scope *models.RecordScope, limit, offset int) ([]*models.Student, error)
 	Update(ctx context.Context, student *models.Student) error
 	Delete(ctx context.Context, id uint) error
 	UpdatePhotoPath(ctx context.Context, id uint, photoPath string) error
 	GetPhotoPath(ctx context.Context, id uint) (string, error)
-	GetTotalStudents(ctx context.Context) (int, error)
+	GetTotalStudents(ctx context.Context, scope *models.RecordScope) (int, error)
 	UpdatePassword(ctx context.Context, studentID string, password string) error
 	GetPasswordByStudentID(ctx context.Context, studentID string) (string, error)
 	IsStudentExist(ctx context.Context, studentID string) (bool, error)
 	GetStats(ctx context.Context) (*models.StudentStats, error)
 	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
 	GetByIDWithClassName(ctx context.Context, id uint) (*models.StudentsWithClassName, error)
+	CheckImport(ctx context.Context, rows []*models.StudentImportRow) ([]models.ImportRowError, error)
+	Import(ctx context.Context, rows []*models.StudentImportRow, createdBy *uint) error
+	ApplyBulkAction(ctx context.Context, action *models.StudentBulkAction, generatePasswords func(n int) ([]string, []string, error)) error
+	GetBulkActions(ctx context.Context, limit, offset int) ([]*models.StudentBulkAction, error)
+	GetBulkActionByID(ctx context.Context, id uint) (*models.StudentBulkAction, error)
 }
 
 // AttendanceRepository defines the interface for attendance operations
@@ -67,12 +82,12 @@ type AttendanceRepository interface {
 	GetByStudentAndDate(ctx context.Context, studentID string, date time.Time) (*models.Attendance, error)
 	GetByStudent(ctx context.Context, studentID string, limit, offset int) ([]*models.Attendance, error)
 	GetByClass(ctx context.Context, classID uint, limit, offset int) ([]*models.Attendance, error)
-	GetByDateRange(ctx context.Context, startDate, endDate time.Time, limit, offset int) ([]*models.Attendance, error)
+	GetByDateRange(ctx context.Context, scope *models.RecordScope, startDate, endDate time.Time, limit, offset int) ([]*models.Attendance, error)
 	Update(ctx context.Context, attendance *models.Attendance) error
 	Delete(ctx context.Context, id uint) error
 	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
-	GetAll(ctx context.Context, limit, offset int) ([]*models.Attendance, error)
-	GetCount(ctx context.Context) (int, error)
+	GetAll(ctx context.Context, scope *models.RecordScope, limit, offset int) ([]*models.Attendance, error)
+	GetCount(ctx context.Context, scope *models.RecordScope) (int, error)
 	GetAttendanceStats(ctx context.Context, studentID uint) (*models.AttendanceWithStats, error)
 }
 
@@ -82,16 +97,63 @@ type AbsentRequestRepository interface {
 	GetByID(ctx context.Context, id uint) (*models.AbsentRequest, error)
 	GetByStudent(ctx context.Context, studentID string, limit, offset int) ([]*models.AbsentRequest, error)
 	GetByClass(ctx context.Context, classID uint, limit, offset int) ([]*models.AbsentRequest, error)
-	GetByStatus(ctx context.Context, status models.AbsentRequestStatus, limit, offset int) ([]*models.AbsentRequest, error)
-	GetPending(ctx context.Context, limit, offset int) ([]*models.AbsentRequest, error)
-	Update(ctx context.Context, request *models.AbsentRequest) error
+	GetByStatus(ctx context.Context, scope *models.RecordScope, status models.AbsentRequestStatus, limit, offset int) ([]*models.AbsentRequest, error)
+	GetPending(ctx context.Context, scope *models.RecordScope, limit, offset int) ([]*models.AbsentRequest, error)
+	Amend(ctx context.Context, request *models.AbsentRequest, supersededBy uint) error
+	Withdraw(ctx context.Context, id uint, studentID string) error
+	GetVersions(ctx context.Context, id uint) ([]*models.AbsentRequestVersion, error)
 	Delete(ctx context.Context, id uint) error
 	GetTotalAbsentRequests(ctx context.Context) (int, error)
-	UpdateDeleteInfo(ctx context.Context, id uint, studentID uint, deletedBy uint) error
+	GetCountByStudent(ctx context.Context, studentID string) (int, error)
 	GetByTeacher(ctx context.Context, teacherID string, limit, offset int) ([]*models.AbsentRequest, error)
-	Approve(ctx context.Context, id uint, teacherID uint) error
-	Reject(ctx context.Context, id uint, teacherID uint) error
+	Approve(ctx context.Context, id uint, teacherID uint, note *string, excusedDays []time.Time) error
+	Reject(ctx context.Context, id uint, teacherID uint, note *string) error
+	DecideByAdmin(ctx context.Context, id uint, from, status models.AbsentRequestStatus, adminID uint, note *string, excusedDays []time.Time) error
 	GetCountByTeacher(ctx context.Context, teacherID string) (int, error)
+	GetByDate(ctx context.Context, scope *models.RecordScope, date time.Time, limit, offset int) ([]*models.AbsentRequest, error)
+	HasOverlap(ctx context.Context, studentID string, startDate, endDate time.Time, excludeID uint) (bool, error)
+	GetCategoryReport(ctx context.Context, startDate, endDate time.Time) ([]*models.AbsentCategoryReport, error)
+	EscalateStale(ctx context.Context, pendingSince time.Time, escalatedTo *uint) ([]*models.AbsentRequest, error)
+	GetEscalated(ctx context.Context, limit, offset int) ([]*models.AbsentRequest, error)
+	GetEscalatedCount(ctx context.Context) (int, error)
+	FlagQuotaExceeded(ctx context.Context, id uint) error
+}
+
+// AbsentRequestAttachmentRepository defines the interface for absent request attachment operations
+type AbsentRequestAttachmentRepository interface {
+	CreateAll(ctx context.Context, absentRequestID uint, attachments []*models.AbsentRequestAttachment, maxPerRequest int) error
+	GetByID(ctx context.Context, id uint) (*models.AbsentRequestAttachment, error)
+	GetByAbsentRequestIDs(ctx context.Context, absentRequestIDs []uint) ([]*models.AbsentRequestAttachment, error)
+	GetCountByAbsentRequest(ctx context.Context, absentRequestID uint) (int, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// AbsentRequestCommentRepository defines the interface for absent request comment operations
+type AbsentRequestCommentRepository interface {
+	Create(ctx context.Context, comment *models.AbsentRequestComment) error
+	GetByAbsentRequest(ctx context.Context, absentRequestID uint) ([]*models.AbsentRequestComment, error)
+}
+
+// AbsentRequestCategoryRepository defines the interface for absent request category operations
+type AbsentRequestCategoryRepository interface {
+	Create(ctx context.Context, category *models.AbsentRequestCategory) error
+	GetByID(ctx context.Context, id uint) (*models.AbsentRequestCategory, error)
+	GetAll(ctx context.Context, activeOnly bool) ([]*models.AbsentRequestCategory, error)
+	Update(ctx context.Context, category *models.AbsentRequestCategory) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	IsCodeExist(ctx context.Context, code string, excludeID uint) (bool, error)
+}
+
+// AbsenceQuotaRepository defines the interface for absence quota operations
+type AbsenceQuotaRepository interface {
+	Create(ctx context.Context, quota *models.AbsenceQuota) error
+	GetByID(ctx context.Context, id uint) (*models.AbsenceQuota, error)
+	GetAll(ctx context.Context, categoryID uint) ([]*models.AbsenceQuota, error)
+	Update(ctx context.Context, quota *models.AbsenceQuota) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	HasOverlap(ctx context.Context, categoryID uint, startDate, endDate time.Time, excludeID uint) (bool, error)
+	GetForDate(ctx context.Context, categoryID uint, date time.Time) (*models.AbsenceQuota, error)
+	GetUsedDays(ctx context.Context, quota *models.AbsenceQuota, studentID string, excludeRequestID uint) (int, int, error)
 }
 
 // AdminRepository defines the interface for admin operations
@@ -112,12 +174,165 @@ type AdminRepository interface {
 	GetDashboardStats(ctx context.Context) (*models.DashboardStats, error)
 }
 
+// GuardianRepository defines the interface for guardian operations
+type GuardianRepository interface {
+	Create(ctx context.Context, guardian *models.Guardian) error
+	GetByID(ctx context.Context, id uint) (*models.Guardian, error)
+	GetByEmail(ctx context.Context, email string) (*models.Guardian, error)
+	GetAll(ctx context.Context, limit, offset int) ([]*models.Guardian, error)
+	GetTotalGuardians(ctx context.Context) (int, error)
+	Update(ctx context.Context, guardian *models.Guardian) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	UpdatePassword(ctx context.Context, id uint, password string) error
+	GetPasswordByEmail(ctx context.Context, email string) (string, error)
+	UpdateLastLogin(ctx context.Context, id uint, lastLogin time.Time) error
+	IsEmailExist(ctx context.Context, email string, excludeID uint) (bool, error)
+	LinkStudent(ctx context.Context, link *models.GuardianStudent) error
+	UnlinkStudent(ctx context.Context, guardianID, studentID uint) error
+	GetChildren(ctx context.Context, guardianID uint) ([]*models.GuardianChild, error)
+	GetChildSchool(ctx context.Context, guardianID, studentID uint) (uint, bool, error)
+}
+
+// RoleRepository defines the interface for role and permission operations
+type RoleRepository interface {
+	GetPermissions(ctx context.Context) ([]*models.Permission, error)
+	Create(ctx context.Context, role *models.Role) error
+	GetByID(ctx context.Context, id uint) (*models.Role, error)
+	GetAll(ctx context.Context) ([]*models.Role, error)
+	Update(ctx context.Context, role *models.Role) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	IsNameExist(ctx context.Context, name string, excludeID uint) (bool, error)
+	AssignRole(ctx context.Context, userRole *models.UserRole) error
+	UnassignRole(ctx context.Context, userType string, userID, roleID uint) error
+	GetUserRoles(ctx context.Context, userType string, userID uint) ([]*models.UserRole, error)
+	HasPermission(ctx context.Context, userType string, userID uint, permission string) (bool, error)
+	GetUserPermissions(ctx context.Context, userType string, userID uint) ([]string, error)
+	IsUserExist(ctx context.Context, userType models.UserType, userID uint) (bool, error)
+}
+
+// AttendanceAlertRepository defines the interface for attendance anomaly alert operations
+type AttendanceAlertRepository interface {
+	Create(ctx context.Context, alert *models.AttendanceAlert) (bool, error)
+	ResolveRecovered(ctx context.Context, rate *models.ClassAttendanceRate, date time.Time, drop float64) (bool, error)
+	GetByID(ctx context.Context, id uint) (*models.AttendanceAlert, error)
+	GetAll(ctx context.Context, status models.AttendanceAlertStatus, limit, offset int) ([]*models.AttendanceAlert, error)
+	GetCount(ctx context.Context, status models.AttendanceAlertStatus) (int, error)
+	Acknowledge(ctx context.Context, id uint, adminID uint) error
+	Resolve(ctx context.Context, id uint, adminID uint) error
+	GetClassAttendanceRates(ctx context.Context, date time.Time, baselineDays int) ([]*models.ClassAttendanceRate, error)
+}
+
+// OneRosterRepository defines the interface for OneRoster roster exchange operations
+type OneRosterRepository interface {
+	GetTeachers(ctx context.Context) ([]*models.Teacher, error)
+	GetStudents(ctx context.Context) ([]*models.Student, error)
+	GetClasses(ctx context.Context) ([]*models.RosterClass, error)
+	GetAttendances(ctx context.Context, startDate, endDate *time.Time) ([]*models.RosterAttendance, error)
+	GetEnrollments(ctx context.Context, startDate, endDate *time.Time) ([]*models.RosterEnrollment, error)
+	Import(ctx context.Context, roster *models.Roster, hashPassword func(string) (string, error)) (*models.RosterImportResult, error)
+}
+
+// SchoolRepository defines the interface for school operations
+type SchoolRepository interface {
+	Create(ctx context.Context, school *models.School) error
+	GetByID(ctx context.Context, id uint) (*models.School, error)
+	GetAll(ctx context.Context) ([]*models.School, error)
+	Update(ctx context.Context, school *models.School) error
+	IsCodeExist(ctx context.Context, code string, excludeID uint) (bool, error)
+}
+
+// AcademicYearRepository defines the interface for academic year and term operations
+type AcademicYearRepository interface {
+	Create(ctx context.Context, year *models.AcademicYear) error
+	GetByID(ctx context.Context, id uint) (*models.AcademicYear, error)
+	GetAll(ctx context.Context) ([]*models.AcademicYear, error)
+	Update(ctx context.Context, year *models.AcademicYear) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	IsNameExist(ctx context.Context, name string, excludeID uint) (bool, error)
+	CreateTerm(ctx context.Context, term *models.Term) error
+	GetTermByID(ctx context.Context, id uint) (*models.Term, error)
+	GetTerms(ctx context.Context, academicYearID uint) ([]*models.Term, error)
+	UpdateTerm(ctx context.Context, term *models.Term) error
+	DeleteTerm(ctx context.Context, id uint, deletedBy uint) error
+	HasTermOverlap(ctx context.Context, academicYearID uint, startDate, endDate time.Time, excludeID uint) (bool, error)
+}
+
+// EnrollmentRepository defines the interface for enrollment and promotion operations
+type EnrollmentRepository interface {
+	GetByStudent(ctx context.Context, studentID uint) ([]*models.Enrollment, error)
+	Transfer(ctx context.Context, transfer *models.Transfer) (*models.Enrollment, error)
+	Promote(ctx context.Context, promotion *models.Promotion, promotedBy uint) (*models.PromotionResult, error)
+}
+
+// SubjectRepository defines the interface for subject operations
+type SubjectRepository interface {
+	Create(ctx context.Context, subject *models.Subject) error
+	GetByID(ctx context.Context, id uint) (*models.Subject, error)
+	GetAll(ctx context.Context) ([]*models.Subject, error)
+	Update(ctx context.Context, subject *models.Subject) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	IsCodeExist(ctx context.Context, code string, excludeID uint) (bool, error)
+	IsInUse(ctx context.Context, id uint) (bool, error)
+}
+
+// TimetableRepository defines the interface for timetable slot operations
+type TimetableRepository interface {
+	Create(ctx context.Context, slot *models.TimetableSlot) error
+	GetByID(ctx context.Context, id uint) (*models.TimetableSlot, error)
+	GetAll(ctx context.Context, filter *models.TimetableFilter) ([]*models.TimetableSlot, error)
+	Update(ctx context.Context, slot *models.TimetableSlot) error
+	UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error
+	HasOverlap(ctx context.Context, slot *models.TimetableSlot) (bool, error)
+}
+
+// ClassTeacherRepository defines the interface for class teacher assignment operations
+type ClassTeacherRepository interface {
+	GetByClass(ctx context.Context, classID uint) ([]*models.ClassTeacher, error)
+	GetByTeacher(ctx context.Context, teacherID string) ([]*models.ClassTeacher, error)
+	Assign(ctx context.Context, classTeacher *models.ClassTeacher) error
+	Remove(ctx context.Context, classID uint, teacherID string) error
+	GetRolePermissions(ctx context.Context) ([]*models.ClassTeacherRolePermission, error)
+	GetRolePermission(ctx context.Context, schoolID uint, role string) (*models.ClassTeacherRolePermission, error)
+	UpdateRolePermission(ctx context.Context, permission *models.ClassTeacherRolePermission) error
+}
+
+// TrashRepository defines the interface for listing, restoring and purging soft-deleted records
+type TrashRepository interface {
+	GetDeleted(ctx context.Context, entity string, limit, offset int) ([]*models.TrashItem, error)
+	GetDeletedCount(ctx context.Context, entity string) (int, error)
+	Restore(ctx context.Context, entity string, id uint) ([]models.TrashConflict, error)
+	Purge(ctx context.Context, before time.Time, dryRun bool) (*models.TrashPurgeResult, error)
+}
+
+// StudentContactRepository defines the interface for student emergency contacts and medical notes
+type StudentContactRepository interface {
+	GetByStudent(ctx context.Context, studentID uint) (*models.StudentEmergencyInfo, error)
+	ReplaceContacts(ctx context.Context, studentID uint, contacts []*models.StudentContact, updatedBy uint) error
+	SetMedicalNote(ctx context.Context, studentID uint, note string, updatedBy uint) error
+}
+
 // Repositories aggregates all repository interfaces
 type Repositories struct {
-	Teacher       TeacherRepository
-	Class         ClassRepository
-	Student       StudentRepository
-	Attendance    AttendanceRepository
-	AbsentRequest AbsentRequestRepository
-	Admin         AdminRepository
+	Teacher         TeacherRepository
+	Class           ClassRepository
+	Student         StudentRepository
+	Attendance      AttendanceRepository
+	AbsentRequest   AbsentRequestRepository
+	Attachment      AbsentRequestAttachmentRepository
+	Category        AbsentRequestCategoryRepository
+	Comment         AbsentRequestCommentRepository
+	Quota           AbsenceQuotaRepository
+	Admin           AdminRepository
+	Guardian        GuardianRepository
+	Role            RoleRepository
+	AttendanceAlert AttendanceAlertRepository
+	OneRoster       OneRosterRepository
+	School          SchoolRepository
+	AcademicYear    AcademicYearRepository
+	Enrollment      EnrollmentRepository
+	Subject         SubjectRepository
+	Timetable       TimetableRepository
+	ClassTeacher    ClassTeacherRepository
+	Trash           TrashRepository
+	StudentContact  StudentContactRepository
 }
@@ -0,0 +1,511 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+const (
+	rosterFileUsers   = "users.csv"
+	rosterFileClasses = "classes.csv"
+)
+
+type oneRosterRepository struct {
+	db *sql.DB
+}
+
+// NewOneRosterRepository creates a new OneRoster repository
+func NewOneRosterRepository(db *sql.DB) OneRosterRepository {
+	return &oneRosterRepository{db: db}
+}
+
+func (r *oneRosterRepository) GetTeachers(ctx context.Context) ([]*models.Teacher, error) {
+	query := `
+		SELECT id, teacher_id, first_name, last_name, email, phone, is_active, created_at, updated_at
+		FROM teachers
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
+		ORDER BY teacher_id`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster teachers: %w", err)
+	}
+	defer rows.Close()
+
+	var teachers []*models.Teacher
+	for rows.Next() {
+		teacher := &models.Teacher{}
+		err := rows.Scan(
+			&teacher.ID,
+			&teacher.TeacherID,
+			&teacher.FirstName,
+			&teacher.LastName,
+			&teacher.Email,
+			&teacher.Phone,
+			&teacher.IsActive,
+			&teacher.CreatedAt,
+			&teacher.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan roster teacher: %w", err)
+		}
+		teachers = append(teachers, teacher)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roster teachers: %w", err)
+	}
+
+	return teachers, nil
+}
+
+func (r *oneRosterRepository) GetStudents(ctx context.Context) ([]*models.Student, error) {
+	query := `
+		SELECT id, student_id, classes_id, first_name, last_name, email, phone, is_active, created_at, updated_at
+		FROM students
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
+		ORDER BY student_id`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster students: %w", err)
+	}
+	defer rows.Close()
+
+	var students []*models.Student
+	for rows.Next() {
+		student := &models.Student{}
+		err := rows.Scan(
+			&student.ID,
+			&student.StudentID,
+			&student.ClassesID,
+			&student.FirstName,
+			&student.LastName,
+			&student.Email,
+			&student.Phone,
+			&student.IsActive,
+			&student.CreatedAt,
+			&student.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan roster student: %w", err)
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roster students: %w", err)
+	}
+
+	return students, nil
+}
+
+func (r *oneRosterRepository) GetClasses(ctx context.Context) ([]*models.RosterClass, error) {
+	// classes created in this system have no sourcedId yet, so they are
+	// exported under a stable one derived from their id
+	query := `
+		SELECT id
+		     , COALESCE(sourced_id, 'class-' || id) AS sourced_id
+		     , name
+		     , homeroom_teacher
+		     , description
+		     , created_at
+		     , updated_at
+		FROM classes
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster classes: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []*models.RosterClass
+	for rows.Next() {
+		class := &models.RosterClass{}
+		err := rows.Scan(
+			&class.ID,
+			&class.SourcedID,
+			&class.Name,
+			&class.HomeroomTeacher,
+			&class.Description,
+			&class.CreatedAt,
+			&class.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan roster class: %w", err)
+		}
+		classes = append(classes, class)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roster classes: %w", err)
+	}
+
+	return classes, nil
+}
+
+func (r *oneRosterRepository) GetAttendances(ctx context.Context, startDate, endDate *time.Time) ([]*models.RosterAttendance, error) {
+	query := `
+		SELECT a.id
+		     , a.student_id
+		     , a.class_id
+		     , COALESCE(c.sourced_id, 'class-' || c.id) AS class_sourced_id
+		     , a.date
+
+		     , a.status
+		     , a.description
+		     , a.time_in
+		     , a.time_out
+		     , a.created_at
+		FROM attendances a
+		    JOIN classes c ON a.class_id = c.id
+		WHERE a.deleted_at IS NULL
+		  AND ($1::date IS NULL OR a.date >= $1::date)
+		  AND ($2::date IS NULL OR a.date <= $2::date)
+		  AND ($3 = 0 OR a.school_id = $3)
+		ORDER BY a.date, a.class_id, a.student_id`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster attendances: %w", err)
+	}
+	defer rows.Close()
+
+	var attendances []*models.RosterAttendance
+	for rows.Next() {
+		attendance := &models.RosterAttendance{}
+		err := rows.Scan(
+			&attendance.ID,
+			&attendance.StudentID,
+			&attendance.ClassID,
+			&attendance.ClassSourcedID,
+			&attendance.Date,
+
+			&attendance.Status,
+			&attendance.Description,
+			&attendance.TimeIn,
+			&attendance.TimeOut,
+			&attendance.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan roster attendance: %w", err)
+		}
+		attendances = append(attendances, attendance)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roster attendances: %w", err)
+	}
+
+	return attendances, nil
+}
+
+// GetEnrollments returns the student enrollments that cover part of the period. Nil dates are unbounded.
+func (r *oneRosterRepository) GetEnrollments(ctx context.Context, startDate, endDate *time.Time) ([]*models.RosterEnrollment, error) {
+	query := `
+		SELECT e.id
+		     , e.student_id
+		     , e.class_id
+		     , COALESCE(c.sourced_id, 'class-' || c.id) AS class_sourced_id
+		     , s.student_id AS student_sourced_id
+
+		     , e.start_date
+		     , e.end_date
+		     , e.created_at
+		FROM enrollments e
+		    JOIN classes c ON e.class_id = c.id
+		    JOIN students s ON e.student_id = s.id
+		WHERE c.deleted_at IS NULL AND s.deleted_at IS NULL
+		  AND (e.end_date IS NULL OR e.end_date > e.start_date)
+		  AND ($1::date IS NULL OR e.end_date IS NULL OR e.end_date > $1::date)
+		  AND ($2::date IS NULL OR e.start_date <= $2::date)
+		  AND ($3 = 0 OR e.school_id = $3)
+		ORDER BY s.student_id, e.start_date`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	var enrollments []*models.RosterEnrollment
+	for rows.Next() {
+		enrollment := &models.RosterEnrollment{}
+		err := rows.Scan(
+			&enrollment.ID,
+			&enrollment.StudentID,
+			&enrollment.ClassID,
+			&enrollment.ClassSourcedID,
+			&enrollment.StudentSourcedID,
+
+			&enrollment.StartDate,
+			&enrollment.EndDate,
+			&enrollment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan roster enrollment: %w", err)
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roster enrollments: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+// Import upserts teachers, classes and students by their sourcedId in a single transaction.
+// A row that fails is rolled back on its own and reported, the remaining rows are still imported.
+// Everything is imported into the school of the request.
+func (r *oneRosterRepository) Import(ctx context.Context, roster *models.Roster, hashPassword func(string) (string, error)) (*models.RosterImportResult, error) {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RosterImportResult{
+		Errors: append([]models.RosterImportError{}, roster.Errors...),
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin roster import: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, teacher := range roster.Teachers {
+		err := withSavepoint(ctx, tx, func() error {
+			created, err := r.upsertTeacher(ctx, tx, schoolID, teacher, hashPassword)
+			if err == nil {
+				countUpsert(created, &result.TeachersCreated, &result.TeachersUpdated)
+			}
+			return err
+		})
+		if err != nil {
+			result.Errors = append(result.Errors, rowError(rosterFileUsers, teacher.Row, teacher.SourcedID, err))
+		}
+	}
+
+	for _, class := range roster.Classes {
+		err := withSavepoint(ctx, tx, func() error {
+			created, err := r.upsertClass(ctx, tx, schoolID, class)
+			if err == nil {
+				countUpsert(created, &result.ClassesCreated, &result.ClassesUpdated)
+			}
+			return err
+		})
+		if err != nil {
+			result.Errors = append(result.Errors, rowError(rosterFileClasses, class.Row, class.SourcedID, err))
+		}
+	}
+
+	for _, student := range roster.Students {
+		err := withSavepoint(ctx, tx, func() error {
+			created, err := r.upsertStudent(ctx, tx, schoolID, student, hashPassword)
+			if err == nil {
+				countUpsert(created, &result.StudentsCreated, &result.StudentsUpdated)
+			}
+			return err
+		})
+		if err != nil {
+			result.Errors = append(result.Errors, rowError(rosterFileUsers, student.Row, student.SourcedID, err))
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit roster import: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oneRosterRepository) upsertTeacher(ctx context.Context, tx *sql.Tx, schoolID uint, teacher *models.RosterUser, hashPassword func(string) (string, error)) (bool, error) {
+	var id uint
+	var deleted bool
+	query := `SELECT id, deleted_at IS NOT NULL FROM teachers WHERE teacher_id = $1 AND school_id = $2`
+	err := tx.QueryRowContext(ctx, query, teacher.SourcedID, schoolID).Scan(&id, &deleted)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to find teacher: %w", err)
+	}
+
+	// a deleted teacher stays deleted; an admin restores them from the trash
+	if err == nil && deleted {
+		return false, fmt.Errorf("teacher %s is deleted; restore them from the trash to import them", teacher.SourcedID)
+	}
+
+	if err == nil {
+		query := `
+			UPDATE teachers
+			SET first_name = $2, last_name = $3, email = $4, phone = $5, is_active = $6, updated_at = NOW()
+			WHERE id = $1`
+
+		_, err = tx.ExecContext(ctx, query, id, teacher.GivenName, teacher.FamilyName, teacher.Email, teacher.Phone, teacher.IsActive)
+		if err != nil {
+			return false, fmt.Errorf("failed to update teacher: %w", err)
+		}
+		return false, nil
+	}
+
+	password, err := hashPassword(teacher.Password)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	query = `
+		INSERT INTO teachers (teacher_id, first_name, last_name, email, phone, password, is_active, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())`
+
+	_, err = tx.ExecContext(ctx, query, teacher.SourcedID, teacher.GivenName, teacher.FamilyName, teacher.Email, teacher.Phone, password, teacher.IsActive, schoolID)
+	if err != nil {
+		return false, fmt.Errorf("failed to create teacher: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *oneRosterRepository) upsertClass(ctx context.Context, tx *sql.Tx, schoolID uint, class *models.RosterImportClass) (bool, error) {
+	id, err := findClassBySourcedID(ctx, tx, schoolID, class.SourcedID)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to find class: %w", err)
+	}
+
+	if err == nil {
+		query := `
+			UPDATE classes
+			SET sourced_id = $2, name = $3, homeroom_teacher = $4, updated_at = NOW()
+			WHERE id = $1`
+
+		_, err = tx.ExecContext(ctx, query, id, class.SourcedID, class.Title, class.TeacherSourcedID)
+		if err != nil {
+			return false, fmt.Errorf("failed to update class: %w", err)
+		}
+		return false, syncHomeroomTeacher(ctx, tx, id)
+	}
+
+	query := `
+		INSERT INTO classes (sourced_id, name, homeroom_teacher, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id`
+
+	err = tx.QueryRowContext(ctx, query, class.SourcedID, class.Title, class.TeacherSourcedID, schoolID).Scan(&id)
+	if err != nil {
+		return false, fmt.Errorf("failed to create class: %w", err)
+	}
+
+	return true, syncHomeroomTeacher(ctx, tx, id)
+}
+
+func (r *oneRosterRepository) upsertStudent(ctx context.Context, tx *sql.Tx, schoolID uint, student *models.RosterUser, hashPassword func(string) (string, error)) (bool, error) {
+	classID, err := findClassBySourcedID(ctx, tx, schoolID, student.ClassSourcedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("class %s not found", student.ClassSourcedID)
+		}
+		return false, fmt.Errorf("failed to find class: %w", err)
+	}
+
+	var id uint
+	var deleted bool
+	query := `SELECT id, deleted_at IS NOT NULL FROM students WHERE student_id = $1 AND school_id = $2`
+	err = tx.QueryRowContext(ctx, query, student.SourcedID, schoolID).Scan(&id, &deleted)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to find student: %w", err)
+	}
+
+	// a deleted student stays deleted; an admin restores them from the trash
+	if err == nil && deleted {
+		return false, fmt.Errorf("student %s is deleted; restore them from the trash to import them", student.SourcedID)
+	}
+
+	if err == nil {
+		query := `
+			UPDATE students
+			SET classes_id = $2, first_name = $3, last_name = $4, email = $5, phone = $6, is_active = $7, updated_at = NOW()
+			WHERE id = $1`
+
+		_, err = tx.ExecContext(ctx, query, id, classID, student.GivenName, student.FamilyName, student.Email, student.Phone, student.IsActive)
+		if err != nil {
+			return false, fmt.Errorf("failed to update student: %w", err)
+		}
+
+		if err = changeEnrollment(ctx, tx, id, classID, time.Now(), nil); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	password, err := hashPassword(student.Password)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	query = `
+		INSERT INTO students (student_id, classes_id, first_name, last_name, email, phone, password, is_active, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING id`
+
+	err = tx.QueryRowContext(ctx, query, student.SourcedID, classID, student.GivenName, student.FamilyName, student.Email, student.Phone, password, student.IsActive, schoolID).Scan(&id)
+	if err != nil {
+		return false, fmt.Errorf("failed to create student: %w", err)
+	}
+
+	if err = changeEnrollment(ctx, tx, id, classID, time.Now(), nil); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// findClassBySourcedID also matches the derived sourcedId of classes that were exported before they had one
+func findClassBySourcedID(ctx context.Context, tx *sql.Tx, schoolID uint, sourcedID string) (uint, error) {
+	query := `
+		SELECT id FROM classes
+		WHERE deleted_at IS NULL AND school_id = $2
+		  AND (sourced_id = $1 OR (sourced_id IS NULL AND 'class-' || id = $1))`
+
+	var id uint
+	err := tx.QueryRowContext(ctx, query, sourcedID, schoolID).Scan(&id)
+	return id, err
+}
+
+func withSavepoint(ctx context.Context, tx *sql.Tx, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT roster_row"); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT roster_row"); rbErr != nil {
+			return fmt.Errorf("failed to roll back row: %w", rbErr)
+		}
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT roster_row")
+	return err
+}
+
+func countUpsert(created bool, createdCount, updatedCount *int) {
+	if created {
+		*createdCount++
+		return
+	}
+	*updatedCount++
+}
+
+func rowError(file string, row int, sourcedID string, err error) models.RosterImportError {
+	return models.RosterImportError{
+		File:      file,
+		Row:       row,
+		SourcedID: sourcedID,
+		Error:     err.Error(),
+	}
+}
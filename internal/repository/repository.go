@@ -11,14 +11,46 @@ func NewRepositories(db *sql.DB) *Repositories {
 	studentRepo := NewStudentRepository(db)
 	attendanceRepo := NewAttendanceRepository(db)
 	absentRequestRepo := NewAbsentRequestRepository(db)
+	attachmentRepo := NewAbsentRequestAttachmentRepository(db)
+	categoryRepo := NewAbsentRequestCategoryRepository(db)
+	commentRepo := NewAbsentRequestCommentRepository(db)
+	quotaRepo := NewAbsenceQuotaRepository(db)
 	adminRepo := NewAdminRepositoryWithDeps(db, teacherRepo, studentRepo, classRepo, attendanceRepo)
-	
+	guardianRepo := NewGuardianRepository(db)
+	roleRepo := NewRoleRepository(db)
+	attendanceAlertRepo := NewAttendanceAlertRepository(db)
+	oneRosterRepo := NewOneRosterRepository(db)
+	schoolRepo := NewSchoolRepository(db)
+	academicYearRepo := NewAcademicYearRepository(db)
+	enrollmentRepo := NewEnrollmentRepository(db)
+	subjectRepo := NewSubjectRepository(db)
+	timetableRepo := NewTimetableRepository(db)
+	classTeacherRepo := NewClassTeacherRepository(db)
+	trashRepo := NewTrashRepository(db)
+	studentContactRepo := NewStudentContactRepository(db)
+
 	return &Repositories{
-		Teacher:       teacherRepo,
-		Class:         classRepo,
-		Student:       studentRepo,
-		Attendance:    attendanceRepo,
-		AbsentRequest: absentRequestRepo,
-		Admin:         adminRepo,
+		Teacher:         teacherRepo,
+		Class:           classRepo,
+		Student:         studentRepo,
+		Attendance:      attendanceRepo,
+		AbsentRequest:   absentRequestRepo,
+		Attachment:      attachmentRepo,
+		Category:        categoryRepo,
+		Comment:         commentRepo,
+		Quota:           quotaRepo,
+		Admin:           adminRepo,
+		Guardian:        guardianRepo,
+		Role:            roleRepo,
+		AttendanceAlert: attendanceAlertRepo,
+		OneRoster:       oneRosterRepo,
+		School:          schoolRepo,
+		AcademicYear:    academicYearRepo,
+		Enrollment:      enrollmentRepo,
+		Subject:         subjectRepo,
+		Timetable:       timetableRepo,
+		ClassTeacher:    classTeacherRepo,
+		Trash:           trashRepo,
+		StudentContact:  studentContactRepo,
 	}
 }
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) GetPermissions(ctx context.Context) ([]*models.Permission, error) {
+	query := `SELECT id, code, description FROM permissions ORDER BY code`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []*models.Permission
+	for rows.Next() {
+		permission := &models.Permission{}
+		if err := rows.Scan(&permission.ID, &permission.Code, &permission.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// Create saves the role together with its permissions
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO roles (name, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRowContext(ctx, query, role.Name, role.Description, role.CreatedBy).
+		Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	if err := setRolePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id uint) (*models.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.default_for, r.created_at, r.updated_at, r.created_by, r.updated_by,
+		       COALESCE(ARRAY_AGG(p.code ORDER BY p.code) FILTER (WHERE p.code IS NOT NULL), '{}')
+		FROM roles r
+		    LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		    LEFT JOIN permissions p ON p.id = rp.permission_id
+		WHERE r.id = $1 AND r.deleted_at IS NULL
+		GROUP BY r.id`
+
+	role := &models.Role{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.DefaultFor,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+		&role.CreatedBy,
+		&role.UpdatedBy,
+		pq.Array(&role.Permissions),
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("role not found")
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) GetAll(ctx context.Context) ([]*models.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.default_for, r.created_at, r.updated_at, r.created_by, r.updated_by,
+		       COALESCE(ARRAY_AGG(p.code ORDER BY p.code) FILTER (WHERE p.code IS NOT NULL), '{}')
+		FROM roles r
+		    LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		    LEFT JOIN permissions p ON p.id = rp.permission_id
+		WHERE r.deleted_at IS NULL
+		GROUP BY r.id
+		ORDER BY r.default_for IS NULL, r.name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role := &models.Role{}
+		err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Description,
+			&role.DefaultFor,
+			&role.CreatedAt,
+			&role.UpdatedAt,
+			&role.CreatedBy,
+			&role.UpdatedBy,
+			pq.Array(&role.Permissions),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Update saves the role and replaces its permissions
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE roles
+		SET name = $2, description = $3, updated_by = $4, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err = tx.QueryRowContext(ctx, query, role.ID, role.Name, role.Description, role.UpdatedBy).Scan(&role.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("role not found")
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+
+	if err := setRolePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeleteInfo retires the role and drops its assignments
+func (r *roleRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE roles
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL AND default_for IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err = tx.QueryRowContext(ctx, query, id, deletedBy).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("role not found")
+		}
+		return fmt.Errorf("failed to update role delete info: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE role_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to remove role assignments: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role deletion: %w", err)
+	}
+
+	return nil
+}
+
+// IsNameExist reports whether another role already uses the name
+func (r *roleRepository) IsNameExist(ctx context.Context, name string, excludeID uint) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM roles WHERE LOWER(name) = LOWER($1) AND id <> $2)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, name, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check role name: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *roleRepository) AssignRole(ctx context.Context, userRole *models.UserRole) error {
+	query := `
+		INSERT INTO user_roles (user_type, user_id, role_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_type, user_id, role_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, userRole.UserType, userRole.UserID, userRole.RoleID, userRole.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) UnassignRole(ctx context.Context, userType string, userID, roleID uint) error {
+	query := `DELETE FROM user_roles WHERE user_type = $1 AND user_id = $2 AND role_id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, userType, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to unassign role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("role assignment not found")
+	}
+
+	return nil
+}
+
+// GetUserRoles returns the roles assigned to the user, not counting the default role of their type
+func (r *roleRepository) GetUserRoles(ctx context.Context, userType string, userID uint) ([]*models.UserRole, error) {
+	query := `
+		SELECT ur.user_type, ur.user_id, ur.role_id, r.name, ur.created_at, ur.created_by
+		FROM user_roles ur
+		    JOIN roles r ON r.id = ur.role_id AND r.deleted_at IS NULL
+		WHERE ur.user_type = $1 AND ur.user_id = $2
+		ORDER BY r.name`
+
+	rows, err := r.db.QueryContext(ctx, query, userType, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	var userRoles []*models.UserRole
+	for rows.Next() {
+		userRole := &models.UserRole{}
+		err := rows.Scan(
+			&userRole.UserType,
+			&userRole.UserID,
+			&userRole.RoleID,
+			&userRole.RoleName,
+			&userRole.CreatedAt,
+			&userRole.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		userRoles = append(userRoles, userRole)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate user roles: %w", err)
+	}
+
+	return userRoles, nil
+}
+
+// userDefaultRole resolves the default role a user holds, with $1 the user type and $2 the user id:
+// auditor admins hold the auditor role instead of the admin role
+const userDefaultRole = `
+	CASE WHEN $1 = 'admin' AND EXISTS (SELECT 1 FROM admins WHERE id = $2 AND tier = 'auditor')
+	     THEN 'auditor'
+	     ELSE $1
+	END`
+
+// HasPermission reports whether the default role of the user's type or one of their assigned roles allows the permission.
+// Auditors only hold their read-only default role, whatever roles they were assigned.
+func (r *roleRepository) HasPermission(ctx context.Context, userType string, userID uint, permission string) (bool, error) {
+	query := `
+		WITH holder AS (SELECT ` + userDefaultRole + ` AS default_for)
+		SELECT EXISTS (
+			SELECT 1
+			FROM roles r
+			    JOIN role_permissions rp ON rp.role_id = r.id
+			    JOIN permissions p ON p.id = rp.permission_id
+			    CROSS JOIN holder h
+			WHERE p.code = $3
+			  AND r.deleted_at IS NULL
+			  AND (r.default_for = h.default_for
+			       OR (h.default_for <> 'auditor'
+			           AND r.id IN (SELECT role_id FROM user_roles WHERE user_type = $1 AND user_id = $2)))
+		)`
+
+	var allowed bool
+	err := r.db.QueryRowContext(ctx, query, userType, userID, permission).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	return allowed, nil
+}
+
+// GetUserPermissions returns every permission the user holds through their roles
+func (r *roleRepository) GetUserPermissions(ctx context.Context, userType string, userID uint) ([]string, error) {
+	query := `
+		WITH holder AS (SELECT ` + userDefaultRole + ` AS default_for)
+		SELECT DISTINCT p.code
+		FROM roles r
+		    JOIN role_permissions rp ON rp.role_id = r.id
+		    JOIN permissions p ON p.id = rp.permission_id
+		    CROSS JOIN holder h
+		WHERE r.deleted_at IS NULL
+		  AND (r.default_for = h.default_for
+		       OR (h.default_for <> 'auditor'
+		           AND r.id IN (SELECT role_id FROM user_roles WHERE user_type = $1 AND user_id = $2)))
+		ORDER BY p.code`
+
+	rows, err := r.db.QueryContext(ctx, query, userType, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan user permission: %w", err)
+		}
+		permissions = append(permissions, code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate user permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// IsUserExist reports whether a user of the type exists with the id
+func (r *roleRepository) IsUserExist(ctx context.Context, userType models.UserType, userID uint) (bool, error) {
+	var query string
+	args := []interface{}{userID, schoolFilter(ctx)}
+	switch userType {
+	case models.UserTypeAdmin:
+		query = `SELECT EXISTS(SELECT 1 FROM admins WHERE id = $1 AND ($2 = 0 OR school_id = $2 OR is_super_admin))`
+	case models.UserTypeTeacher:
+		query = `SELECT EXISTS(SELECT 1 FROM teachers WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL)`
+	case models.UserTypeStudent:
+		query = `SELECT EXISTS(SELECT 1 FROM students WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL)`
+	case models.UserTypeGuardian:
+		// guardians belong to the foundation rather than to one school
+		query = `SELECT EXISTS(SELECT 1 FROM guardians WHERE id = $1 AND deleted_at IS NULL)`
+		args = args[:1]
+	default:
+		return false, models.ErrInvalidUserType
+	}
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+func setRolePermissions(ctx context.Context, tx *sql.Tx, roleID uint, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE code = ANY($2)
+		ON CONFLICT DO NOTHING`
+
+	if _, err := tx.ExecContext(ctx, query, roleID, pq.Array(permissions)); err != nil {
+		return fmt.Errorf("failed to set role permissions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type schoolRepository struct {
+	db *sql.DB
+}
+
+// NewSchoolRepository creates a new school repository
+func NewSchoolRepository(db *sql.DB) SchoolRepository {
+	return &schoolRepository{db: db}
+}
+
+// noSchool is the filter of a context limited to no school. No school has this ID, so the
+// queries of a request that lost its school find and change nothing instead of reaching every school.
+const noSchool = math.MaxInt32
+
+// schoolFilter returns the school the request in ctx is limited to. It is 0, reaching every school,
+// only for contexts made by models.WithAllSchools, and noSchool for a context without a school.
+// Queries on school records filter with ($n = 0 OR school_id = $n).
+func schoolFilter(ctx context.Context) uint {
+	schoolID, all := models.SchoolFromContext(ctx)
+	switch {
+	case schoolID != 0:
+		return schoolID
+	case all:
+		return 0
+	default:
+		return noSchool
+	}
+}
+
+// requireSchoolID returns the school that records created by the request in ctx belong to
+func requireSchoolID(ctx context.Context) (uint, error) {
+	schoolID, _ := models.SchoolFromContext(ctx)
+	if schoolID == 0 {
+		return 0, models.ErrSchoolRequired
+	}
+
+	return schoolID, nil
+}
+
+// Create saves the school together with the default absent request categories, which each school keeps its own copy of
+func (r *schoolRepository) Create(ctx context.Context, school *models.School) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO schools (code, name, address, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRowContext(ctx, query,
+		school.Code,
+		school.Name,
+		school.Address,
+		school.IsActive,
+		school.CreatedBy,
+	).Scan(&school.ID, &school.CreatedAt, &school.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create school: %w", err)
+	}
+
+	// the same categories the first school started with in migration 000017
+	categoriesQuery := `
+		INSERT INTO absent_request_categories (school_id, code, name, description, requires_attachment, max_days,
+		                                       min_notice_days, marks_excused, created_by)
+		VALUES ($1, 'sick', 'Sick', 'Illness or medical appointment', TRUE, NULL, 0, TRUE, $2),
+		       ($1, 'family', 'Family', 'Family matters such as weddings or bereavement', FALSE, 3, 1, TRUE, $2),
+		       ($1, 'religious', 'Religious', 'Religious observance', FALSE, NULL, 3, TRUE, $2),
+		       ($1, 'competition', 'Competition', 'Representing the school in a competition', TRUE, NULL, 7, TRUE, $2)`
+
+	if _, err := tx.ExecContext(ctx, categoriesQuery, school.ID, school.CreatedBy); err != nil {
+		return fmt.Errorf("failed to create default absent request categories: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit school: %w", err)
+	}
+
+	return nil
+}
+
+func (r *schoolRepository) GetByID(ctx context.Context, id uint) (*models.School, error) {
+	query := `
+		SELECT id, code, name, address, is_active, created_at, updated_at, created_by, updated_by
+		FROM schools
+		WHERE id = $1`
+
+	school := &models.School{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&school.ID,
+		&school.Code,
+		&school.Name,
+		&school.Address,
+		&school.IsActive,
+		&school.CreatedAt,
+		&school.UpdatedAt,
+		&school.CreatedBy,
+		&school.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("school not found")
+		}
+		return nil, fmt.Errorf("failed to get school: %w", err)
+	}
+
+	return school, nil
+}
+
+func (r *schoolRepository) GetAll(ctx context.Context) ([]*models.School, error) {
+	query := `
+		SELECT id, code, name, address, is_active, created_at, updated_at, created_by, updated_by
+		FROM schools
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schools: %w", err)
+	}
+	defer rows.Close()
+
+	var schools []*models.School
+	for rows.Next() {
+		school := &models.School{}
+		err := rows.Scan(
+			&school.ID,
+			&school.Code,
+			&school.Name,
+			&school.Address,
+			&school.IsActive,
+			&school.CreatedAt,
+			&school.UpdatedAt,
+			&school.CreatedBy,
+			&school.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan school: %w", err)
+		}
+		schools = append(schools, school)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate schools: %w", err)
+	}
+
+	return schools, nil
+}
+
+func (r *schoolRepository) Update(ctx context.Context, school *models.School) error {
+	query := `
+		UPDATE schools
+		SET code = $2, name = $3, address = $4, is_active = $5, updated_by = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		school.ID,
+		school.Code,
+		school.Name,
+		school.Address,
+		school.IsActive,
+		school.UpdatedBy,
+	).Scan(&school.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("school not found")
+		}
+		return fmt.Errorf("failed to update school: %w", err)
+	}
+
+	return nil
+}
+
+// IsCodeExist reports whether another school already uses the code
+func (r *schoolRepository) IsCodeExist(ctx context.Context, code string, excludeID uint) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM schools WHERE LOWER(code) = LOWER($1) AND id <> $2)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, code, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check school code: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"github.com/lib/pq"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// scopeArgs returns the query arguments of a record scope filter, in the order
+// (all records, class IDs, own student ID), e.g.
+//
+//	AND ($3 OR class_id = ANY($4) OR student_id = $5)
+func scopeArgs(scope *models.RecordScope) []interface{} {
+	classIDs := make([]int64, len(scope.ClassIDs))
+	for i, id := range scope.ClassIDs {
+		classIDs[i] = int64(id)
+	}
+
+	return []interface{}{scope.All, pq.Array(classIDs), scope.StudentID}
+}
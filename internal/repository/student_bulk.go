@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// ApplyBulkAction applies the action to its students in one transaction and records it in the audit trail.
+// Every student gets an item in action.Items. When any item fails, nothing is changed and
+// models.ErrStudentBulkFailed is returned with the items telling which failed and why.
+// Passwords for a reset come from generatePasswords, which returns n plain passwords and their hashes.
+func (r *studentRepository) ApplyBulkAction(
+	ctx context.Context,
+	action *models.StudentBulkAction,
+	generatePasswords func(n int) ([]string, []string, error),
+) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply student bulk action: %w", err)
+	}
+	action.SchoolID = schoolID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	items, err := lockBulkStudents(ctx, tx, schoolID, action)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return models.ErrStudentBulkNoStudents
+	}
+	action.Items = items
+
+	if action.Action == models.StudentBulkActionMoveClass {
+		if err := checkBulkMove(ctx, tx, action); err != nil {
+			return err
+		}
+	}
+
+	var changed []int64
+	failed := false
+	for i := range action.Items {
+		item := &action.Items[i]
+		if item.Status == "" {
+			item.Status = models.StudentBulkItemChanged
+			if bulkItemUnchanged(action, item) {
+				item.Status = models.StudentBulkItemUnchanged
+			}
+		}
+
+		switch item.Status {
+		case models.StudentBulkItemFailed:
+			failed = true
+		case models.StudentBulkItemChanged:
+			changed = append(changed, int64(item.ID))
+		}
+	}
+
+	if failed {
+		return models.ErrStudentBulkFailed
+	}
+
+	if len(changed) > 0 {
+		if err := applyBulkChange(ctx, tx, action, changed, generatePasswords); err != nil {
+			return err
+		}
+	}
+
+	action.StudentCount = len(action.Items)
+	action.ChangedCount = len(changed)
+	if err := recordBulkAction(ctx, tx, action); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit student bulk action: %w", err)
+	}
+
+	return nil
+}
+
+// lockBulkStudents locks the students the action reaches and returns an item for each, in the order
+// they were listed. Listed students that do not exist in the school come back as failed items.
+func lockBulkStudents(ctx context.Context, tx *sql.Tx, schoolID uint, action *models.StudentBulkAction) ([]models.StudentBulkItem, error) {
+	query := `
+		SELECT id, student_id, first_name, last_name, classes_id, is_active
+		FROM students
+		WHERE id = ANY($1) AND school_id = $2 AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE`
+	args := []interface{}{pq.Array(toInt64s(action.StudentIDs)), schoolID}
+
+	if action.ClassID != nil {
+		query = `
+			SELECT id, student_id, first_name, last_name, classes_id, is_active
+			FROM students
+			WHERE classes_id = $1 AND school_id = $2 AND deleted_at IS NULL
+			ORDER BY last_name, first_name, id
+			FOR UPDATE`
+		args = []interface{}{*action.ClassID, schoolID}
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk action students: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.StudentBulkItem
+	for rows.Next() {
+		var item models.StudentBulkItem
+		var classID uint
+		var isActive bool
+		if err := rows.Scan(&item.ID, &item.StudentID, &item.FirstName, &item.LastName, &classID, &isActive); err != nil {
+			return nil, fmt.Errorf("failed to scan bulk action student: %w", err)
+		}
+
+		item.PreviousClassID = &classID
+		item.PreviousIsActive = &isActive
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bulk action students: %w", err)
+	}
+
+	if action.ClassID != nil {
+		return items, nil
+	}
+
+	found := make(map[uint]models.StudentBulkItem, len(items))
+	for _, item := range items {
+		found[item.ID] = item
+	}
+
+	listed := make([]models.StudentBulkItem, 0, len(action.StudentIDs))
+	for _, id := range action.StudentIDs {
+		item, ok := found[id]
+		if !ok {
+			item = models.StudentBulkItem{ID: id, Status: models.StudentBulkItemFailed, Error: "student not found"}
+		}
+		listed = append(listed, item)
+	}
+
+	return listed, nil
+}
+
+// checkBulkMove fails the students who joined their current class after the effective date of the move
+func checkBulkMove(ctx context.Context, tx *sql.Tx, action *models.StudentBulkAction) error {
+	ids := make([]int64, 0, len(action.Items))
+	for _, item := range action.Items {
+		if item.Status == "" {
+			ids = append(ids, int64(item.ID))
+		}
+	}
+
+	query := `
+		SELECT student_id, start_date
+		FROM enrollments
+		WHERE student_id = ANY($1) AND end_date IS NULL
+		FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get current enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	startDates := make(map[uint]time.Time)
+	for rows.Next() {
+		var studentID uint
+		var startDate time.Time
+		if err := rows.Scan(&studentID, &startDate); err != nil {
+			return fmt.Errorf("failed to scan current enrollment: %w", err)
+		}
+		startDates[studentID] = startDate
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate current enrollments: %w", err)
+	}
+
+	for i := range action.Items {
+		item := &action.Items[i]
+		if item.Status != "" || *item.PreviousClassID == *action.ToClassID {
+			continue
+		}
+
+		if startDate, ok := startDates[item.ID]; ok && action.EffectiveDate.Before(startDate) {
+			item.Status = models.StudentBulkItemFailed
+			item.Error = models.ErrTransferBeforeEnrollment.Error()
+		}
+	}
+
+	return nil
+}
+
+// bulkItemUnchanged reports whether the student is already in the state the action would put them in
+func bulkItemUnchanged(action *models.StudentBulkAction, item *models.StudentBulkItem) bool {
+	switch action.Action {
+	case models.StudentBulkActionDeactivate:
+		return !*item.PreviousIsActive
+	case models.StudentBulkActionActivate:
+		return *item.PreviousIsActive
+	case models.StudentBulkActionMoveClass:
+		return *item.PreviousClassID == *action.ToClassID
+	default:
+		return false
+	}
+}
+
+func applyBulkChange(
+	ctx context.Context,
+	tx *sql.Tx,
+	action *models.StudentBulkAction,
+	ids []int64,
+	generatePasswords func(n int) ([]string, []string, error),
+) error {
+	switch action.Action {
+	case models.StudentBulkActionDeactivate, models.StudentBulkActionActivate:
+		query := `UPDATE students SET is_active = $2, updated_at = NOW() WHERE id = ANY($1)`
+		isActive := action.Action == models.StudentBulkActionActivate
+		if _, err := tx.ExecContext(ctx, query, pq.Array(ids), isActive); err != nil {
+			return fmt.Errorf("failed to %s students: %w", action.Action, err)
+		}
+
+	case models.StudentBulkActionDelete:
+		query := `UPDATE students SET deleted_at = NOW(), deleted_by = $2 WHERE id = ANY($1)`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(ids), action.PerformedBy); err != nil {
+			return fmt.Errorf("failed to delete students: %w", err)
+		}
+
+	case models.StudentBulkActionMoveClass:
+		if err := closeEnrollments(ctx, tx, ids, *action.EffectiveDate, models.EnrollmentReasonTransferred); err != nil {
+			return err
+		}
+
+		query := `UPDATE students SET classes_id = $2, updated_at = NOW() WHERE id = ANY($1)`
+		if _, err := tx.ExecContext(ctx, query, pq.Array(ids), *action.ToClassID); err != nil {
+			return fmt.Errorf("failed to move students: %w", err)
+		}
+
+		err := openEnrollments(ctx, tx, ids, *action.ToClassID, *action.EffectiveDate, models.EnrollmentReasonTransferred, &action.PerformedBy)
+		if err != nil {
+			return err
+		}
+
+	case models.StudentBulkActionResetPassword:
+		passwords, hashes, err := generatePasswords(len(ids))
+		if err != nil {
+			return err
+		}
+
+		query := `UPDATE students SET password = $2, updated_at = NOW() WHERE id = $1`
+		next := 0
+		for i := range action.Items {
+			item := &action.Items[i]
+			if item.Status != models.StudentBulkItemChanged {
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, query, item.ID, hashes[next]); err != nil {
+				return fmt.Errorf("failed to reset student password: %w", err)
+			}
+			item.Password = passwords[next]
+			next++
+		}
+	}
+
+	return nil
+}
+
+// recordBulkAction adds the action and its items to the audit trail
+func recordBulkAction(ctx context.Context, tx *sql.Tx, action *models.StudentBulkAction) error {
+	query := `
+		INSERT INTO student_bulk_actions (
+			school_id
+			, action
+			, class_id
+			, to_class_id
+			, effective_date
+			, note
+			, student_count
+			, changed_count
+
+			, performed_by
+			, performed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, performed_at`
+
+	err := tx.QueryRowContext(ctx, query,
+		action.SchoolID,
+		action.Action,
+		action.ClassID,
+		action.ToClassID,
+		action.EffectiveDate,
+		action.Note,
+		action.StudentCount,
+		action.ChangedCount,
+
+		action.PerformedBy,
+	).Scan(&action.ID, &action.PerformedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record student bulk action: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO student_bulk_action_items (bulk_action_id, student_id, student_number, first_name, last_name,
+		                                       status, previous_class_id, previous_is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	stmt, err := tx.PrepareContext(ctx, itemQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare student bulk action items: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, item := range action.Items {
+		if _, err := stmt.ExecContext(ctx, action.ID, item.ID, item.StudentID, item.FirstName, item.LastName,
+			item.Status, item.PreviousClassID, item.PreviousIsActive); err != nil {
+			return fmt.Errorf("failed to record student bulk action item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetBulkActions returns the bulk actions applied in the school of the request, the most recent first
+func (r *studentRepository) GetBulkActions(ctx context.Context, limit, offset int) ([]*models.StudentBulkAction, error) {
+	query := `
+		SELECT id, school_id, action, class_id, to_class_id, effective_date, note,
+		       student_count, changed_count, performed_by, performed_at
+		FROM student_bulk_actions
+		WHERE ($1 = 0 OR school_id = $1)
+		ORDER BY performed_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student bulk actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*models.StudentBulkAction
+	for rows.Next() {
+		action := &models.StudentBulkAction{}
+		err := rows.Scan(
+			&action.ID,
+			&action.SchoolID,
+			&action.Action,
+			&action.ClassID,
+			&action.ToClassID,
+			&action.EffectiveDate,
+			&action.Note,
+			&action.StudentCount,
+			&action.ChangedCount,
+			&action.PerformedBy,
+			&action.PerformedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student bulk action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate student bulk actions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// GetBulkActionByID returns the bulk action with the students it reached
+func (r *studentRepository) GetBulkActionByID(ctx context.Context, id uint) (*models.StudentBulkAction, error) {
+	query := `
+		SELECT id, school_id, action, class_id, to_class_id, effective_date, note,
+		       student_count, changed_count, performed_by, performed_at
+		FROM student_bulk_actions
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
+
+	action := &models.StudentBulkAction{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&action.ID,
+		&action.SchoolID,
+		&action.Action,
+		&action.ClassID,
+		&action.ToClassID,
+		&action.EffectiveDate,
+		&action.Note,
+		&action.StudentCount,
+		&action.ChangedCount,
+		&action.PerformedBy,
+		&action.PerformedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("student bulk action not found")
+		}
+		return nil, fmt.Errorf("failed to get student bulk action: %w", err)
+	}
+
+	itemQuery := `
+		SELECT COALESCE(i.student_id, 0), i.student_number, i.first_name, i.last_name, i.status,
+		       i.previous_class_id, i.previous_is_active
+		FROM student_bulk_action_items i
+		WHERE i.bulk_action_id = $1
+		ORDER BY i.id`
+
+	rows, err := r.db.QueryContext(ctx, itemQuery, action.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student bulk action items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.StudentBulkItem
+		err := rows.Scan(
+			&item.ID,
+			&item.StudentID,
+			&item.FirstName,
+			&item.LastName,
+			&item.Status,
+			&item.PreviousClassID,
+			&item.PreviousIsActive,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student bulk action item: %w", err)
+		}
+		action.Items = append(action.Items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate student bulk action items: %w", err)
+	}
+
+	return action, nil
+}
+
+func toInt64s(ids []uint) []int64 {
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id)
+	}
+	return values
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type studentContactRepository struct {
+	db *sql.DB
+}
+
+// NewStudentContactRepository creates a new student contact repository
+func NewStudentContactRepository(db *sql.DB) StudentContactRepository {
+	return &studentContactRepository{db: db}
+}
+
+// GetByStudent returns the emergency contacts of the student, the lowest priority first, and their medical note
+func (r *studentContactRepository) GetByStudent(ctx context.Context, studentID uint) (*models.StudentEmergencyInfo, error) {
+	query := `
+		SELECT id, student_id, school_id, name, relationship, phone, alt_phone, priority,
+		       created_at, updated_at, created_by, updated_by
+		FROM student_contacts
+		WHERE student_id = $1 AND ($2 = 0 OR school_id = $2)
+		ORDER BY priority, id`
+
+	rows, err := r.db.QueryContext(ctx, query, studentID, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student contacts: %w", err)
+	}
+	defer rows.Close()
+
+	info := &models.StudentEmergencyInfo{Contacts: []*models.StudentContact{}}
+	for rows.Next() {
+		contact := &models.StudentContact{}
+		err := rows.Scan(
+			&contact.ID,
+			&contact.StudentID,
+			&contact.SchoolID,
+			&contact.Name,
+			&contact.Relationship,
+			&contact.Phone,
+			&contact.AltPhone,
+			&contact.Priority,
+			&contact.CreatedAt,
+			&contact.UpdatedAt,
+			&contact.CreatedBy,
+			&contact.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student contact: %w", err)
+		}
+		info.Contacts = append(info.Contacts, contact)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate student contacts: %w", err)
+	}
+
+	noteQuery := `
+		SELECT student_id, note, updated_at, updated_by
+		FROM student_medical_notes
+		WHERE student_id = $1 AND ($2 = 0 OR school_id = $2)`
+
+	note := &models.StudentMedicalNote{}
+	err = r.db.QueryRowContext(ctx, noteQuery, studentID, schoolFilter(ctx)).Scan(
+		&note.StudentID,
+		&note.Note,
+		&note.UpdatedAt,
+		&note.UpdatedBy,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get student medical note: %w", err)
+	}
+	if err == nil {
+		info.MedicalNote = note
+	}
+
+	return info, nil
+}
+
+// ReplaceContacts replaces every emergency contact of the student with the given ones
+func (r *studentContactRepository) ReplaceContacts(ctx context.Context, studentID uint, contacts []*models.StudentContact, updatedBy uint) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	schoolID, err := lockContactStudent(ctx, tx, studentID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM student_contacts WHERE student_id = $1`, studentID); err != nil {
+		return fmt.Errorf("failed to delete student contacts: %w", err)
+	}
+
+	query := `
+		INSERT INTO student_contacts (student_id, school_id, name, relationship, phone, alt_phone, priority,
+		                              created_at, updated_at, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW(), $8, $8)
+		RETURNING id, created_at, updated_at`
+
+	for _, contact := range contacts {
+		contact.StudentID = studentID
+		contact.SchoolID = schoolID
+		contact.CreatedBy = &updatedBy
+		contact.UpdatedBy = &updatedBy
+
+		err := tx.QueryRowContext(ctx, query,
+			studentID,
+			schoolID,
+			contact.Name,
+			contact.Relationship,
+			contact.Phone,
+			contact.AltPhone,
+			contact.Priority,
+			updatedBy,
+		).Scan(&contact.ID, &contact.CreatedAt, &contact.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create student contact: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetMedicalNote sets the medical note of the student. An empty note removes it.
+func (r *studentContactRepository) SetMedicalNote(ctx context.Context, studentID uint, note string, updatedBy uint) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	schoolID, err := lockContactStudent(ctx, tx, studentID)
+	if err != nil {
+		return err
+	}
+
+	if note == "" {
+		_, err = tx.ExecContext(ctx, `DELETE FROM student_medical_notes WHERE student_id = $1`, studentID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO student_medical_notes (student_id, school_id, note, updated_at, updated_by)
+			VALUES ($1, $2, $3, NOW(), $4)
+			ON CONFLICT (student_id) DO UPDATE
+			    SET note = EXCLUDED.note, updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by`,
+			studentID, schoolID, note, updatedBy)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set student medical note: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// lockContactStudent locks the live student whose contacts change and returns their school
+func lockContactStudent(ctx context.Context, tx *sql.Tx, studentID uint) (uint, error) {
+	query := `
+		SELECT school_id FROM students
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL
+		FOR UPDATE`
+
+	var schoolID uint
+	err := tx.QueryRowContext(ctx, query, studentID, schoolFilter(ctx)).Scan(&schoolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("student not found")
+		}
+		return 0, fmt.Errorf("failed to lock student: %w", err)
+	}
+
+	return schoolID, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/michaelwp/student_attendance/internal/models"
 )
@@ -17,13 +19,25 @@ func NewStudentRepository(db *sql.DB) StudentRepository {
 	return &studentRepository{db: db}
 }
 
+// Create adds the student and enrolls them in their class from today
 func (r *studentRepository) Create(ctx context.Context, student *models.Student) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create student: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
-		INSERT INTO students (student_id, classes_id, first_name, last_name, email, phone, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
-		RETURNING id, created_at, updated_at`
+		INSERT INTO students (student_id, classes_id, first_name, last_name, email, phone, password, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		student.StudentID,
 		student.ClassesID,
 		student.FirstName,
@@ -31,25 +45,35 @@ func (r *studentRepository) Create(ctx context.Context, student *models.Student)
 		student.Email,
 		student.Phone,
 		student.Password,
-	).Scan(&student.ID, &student.CreatedAt, &student.UpdatedAt)
+		schoolID,
+	).Scan(&student.ID, &student.SchoolID, &student.CreatedAt, &student.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create student: %w", err)
 	}
 
+	if err = changeEnrollment(ctx, tx, student.ID, student.ClassesID, student.CreatedAt, nil); err != nil {
+		return fmt.Errorf("failed to create student: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit student: %w", err)
+	}
+
 	return nil
 }
 
 func (r *studentRepository) GetByID(ctx context.Context, id uint) (*models.Student, error) {
 	query := `
-		SELECT id, student_id, classes_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
-		FROM students WHERE id = $1 AND deleted_at IS NULL`
+		SELECT id, student_id, classes_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
+		FROM students WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	student := &models.Student{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&student.ID,
 		&student.StudentID,
 		&student.ClassesID,
+		&student.SchoolID,
 		&student.FirstName,
 		&student.LastName,
 		&student.Email,
@@ -72,14 +96,15 @@ func (r *studentRepository) GetByID(ctx context.Context, id uint) (*models.Stude
 
 func (r *studentRepository) GetByStudentID(ctx context.Context, studentID string) (*models.Student, error) {
 	query := `
-		SELECT id, student_id, classes_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
-		FROM students WHERE student_id = $1 AND deleted_at IS NULL`
+		SELECT id, student_id, classes_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
+		FROM students WHERE student_id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	student := &models.Student{}
-	err := r.db.QueryRowContext(ctx, query, studentID).Scan(
+	err := r.db.QueryRowContext(ctx, query, studentID, schoolFilter(ctx)).Scan(
 		&student.ID,
 		&student.StudentID,
 		&student.ClassesID,
+		&student.SchoolID,
 		&student.FirstName,
 		&student.LastName,
 		&student.Email,
@@ -102,14 +127,15 @@ func (r *studentRepository) GetByStudentID(ctx context.Context, studentID string
 
 func (r *studentRepository) GetByEmail(ctx context.Context, email string) (*models.Student, error) {
 	query := `
-		SELECT id, student_id, classes_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
-		FROM students WHERE email = $1 AND deleted_at IS NULL`
+		SELECT id, student_id, classes_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
+		FROM students WHERE email = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	student := &models.Student{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email, schoolFilter(ctx)).Scan(
 		&student.ID,
 		&student.StudentID,
 		&student.ClassesID,
+		&student.SchoolID,
 		&student.FirstName,
 		&student.LastName,
 		&student.Email,
@@ -132,12 +158,12 @@ func (r *studentRepository) GetByEmail(ctx context.Context, email string) (*mode
 
 func (r *studentRepository) GetByClass(ctx context.Context, classID uint) ([]*models.Student, error) {
 	query := `
-		SELECT id, student_id, classes_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
+		SELECT id, student_id, classes_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
 		FROM students 
-		WHERE classes_id = $1 AND deleted_at IS NULL
+		WHERE classes_id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL
 		ORDER BY first_name, last_name`
 
-	rows, err := r.db.QueryContext(ctx, query, classID)
+	rows, err := r.db.QueryContext(ctx, query, classID, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get students by class: %w", err)
 	}
@@ -150,6 +176,56 @@ func (r *studentRepository) GetByClass(ctx context.Context, classID uint) ([]*mo
 			&student.ID,
 			&student.StudentID,
 			&student.ClassesID,
+			&student.SchoolID,
+			&student.FirstName,
+			&student.LastName,
+			&student.Email,
+			&student.Phone,
+			&student.Password,
+			&student.CreatedAt,
+			&student.UpdatedAt,
+			&student.IsActive,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student: %w", err)
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate students: %w", err)
+	}
+
+	return students, nil
+}
+
+// GetByClassOn returns the students who were in the class on the date according to their enrollments,
+// including those who have since moved on
+func (r *studentRepository) GetByClassOn(ctx context.Context, classID uint, date time.Time) ([]*models.Student, error) {
+	query := `
+		SELECT s.id, s.student_id, s.classes_id, s.school_id, s.first_name, s.last_name, s.email, s.phone, s.password, s.created_at, s.updated_at, s.is_active
+		FROM students s
+		JOIN enrollments e ON e.student_id = s.id
+		WHERE e.class_id = $1
+		  AND e.start_date <= DATE($2)
+		  AND (e.end_date IS NULL OR e.end_date > DATE($2))
+		  AND ($3 = 0 OR s.school_id = $3) AND s.deleted_at IS NULL
+		ORDER BY s.first_name, s.last_name`
+
+	rows, err := r.db.QueryContext(ctx, query, classID, date, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get students by class on date: %w", err)
+	}
+	defer rows.Close()
+
+	var students []*models.Student
+	for rows.Next() {
+		student := &models.Student{}
+		err := rows.Scan(
+			&student.ID,
+			&student.StudentID,
+			&student.ClassesID,
+			&student.SchoolID,
 			&student.FirstName,
 			&student.LastName,
 			&student.Email,
@@ -172,15 +248,17 @@ func (r *studentRepository) GetByClass(ctx context.Context, classID uint) ([]*mo
 	return students, nil
 }
 
-func (r *studentRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Student, error) {
+func (r *studentRepository) GetAll(ctx context.Context, scope *models.RecordScope, limit, offset int) ([]*models.Student, error) {
 	query := `
-		SELECT id, student_id, classes_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
+		SELECT id, student_id, classes_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
 		FROM students
-		WHERE deleted_at IS NULL
+		WHERE deleted_at IS NULL AND ($3 OR classes_id = ANY($4) OR student_id = $5) AND ($6 = 0 OR school_id = $6)
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	args := append([]interface{}{limit, offset}, scopeArgs(scope)...)
+	args = append(args, schoolFilter(ctx))
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get students: %w", err)
 	}
@@ -193,6 +271,7 @@ func (r *studentRepository) GetAll(ctx context.Context, limit, offset int) ([]*m
 			&student.ID,
 			&student.StudentID,
 			&student.ClassesID,
+			&student.SchoolID,
 			&student.FirstName,
 			&student.LastName,
 			&student.Email,
@@ -215,14 +294,21 @@ func (r *studentRepository) GetAll(ctx context.Context, limit, offset int) ([]*m
 	return students, nil
 }
 
+// Update saves the student. Moving them to another class ends their enrollment in the old one today.
 func (r *studentRepository) Update(ctx context.Context, student *models.Student) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		UPDATE students 
 		SET student_id = $2, classes_id = $3, first_name = $4, last_name = $5, email = $6, phone = $7, updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND ($8 = 0 OR school_id = $8)
 		RETURNING updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		student.ID,
 		student.StudentID,
 		student.ClassesID,
@@ -230,6 +316,7 @@ func (r *studentRepository) Update(ctx context.Context, student *models.Student)
 		student.LastName,
 		student.Email,
 		student.Phone,
+		schoolFilter(ctx),
 	).Scan(&student.UpdatedAt)
 
 	if err != nil {
@@ -239,13 +326,21 @@ func (r *studentRepository) Update(ctx context.Context, student *models.Student)
 		return fmt.Errorf("failed to update student: %w", err)
 	}
 
+	if err = changeEnrollment(ctx, tx, student.ID, student.ClassesID, student.UpdatedAt, nil); err != nil {
+		return fmt.Errorf("failed to update student: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit student: %w", err)
+	}
+
 	return nil
 }
 
 func (r *studentRepository) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM students WHERE id = $1`
+	query := `DELETE FROM students WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete student: %w", err)
 	}
@@ -266,11 +361,11 @@ func (r *studentRepository) UpdatePhotoPath(ctx context.Context, id uint, photoP
 	query := `
 		UPDATE students 
 		SET photo_path = $2, updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING updated_at`
 
 	var updatedAt string
-	err := r.db.QueryRowContext(ctx, query, id, photoPath).Scan(&updatedAt)
+	err := r.db.QueryRowContext(ctx, query, id, photoPath, schoolFilter(ctx)).Scan(&updatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("student not found")
@@ -282,10 +377,10 @@ func (r *studentRepository) UpdatePhotoPath(ctx context.Context, id uint, photoP
 }
 
 func (r *studentRepository) GetPhotoPath(ctx context.Context, id uint) (string, error) {
-	query := `SELECT photo_path FROM students WHERE id = $1`
+	query := `SELECT photo_path FROM students WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
 	var photoPath string
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&photoPath)
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&photoPath)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("student not found")
@@ -296,11 +391,11 @@ func (r *studentRepository) GetPhotoPath(ctx context.Context, id uint) (string,
 	return photoPath, nil
 }
 
-func (r *studentRepository) GetTotalStudents(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM students WHERE deleted_at IS NULL`
+func (r *studentRepository) GetTotalStudents(ctx context.Context, scope *models.RecordScope) (int, error) {
+	query := `SELECT COUNT(*) FROM students WHERE deleted_at IS NULL AND ($1 OR classes_id = ANY($2) OR student_id = $3) AND ($4 = 0 OR school_id = $4)`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, append(scopeArgs(scope), schoolFilter(ctx))...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total students: %w", err)
 	}
@@ -312,11 +407,11 @@ func (r *studentRepository) UpdatePassword(ctx context.Context, studentID string
 	query := `
 		UPDATE students 
 		SET password = $2, updated_at = NOW()
-		WHERE student_id = $1
+		WHERE student_id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING updated_at`
 
 	var updatedAt string
-	err := r.db.QueryRowContext(ctx, query, studentID, password).Scan(&updatedAt)
+	err := r.db.QueryRowContext(ctx, query, studentID, password, schoolFilter(ctx)).Scan(&updatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("student not found")
@@ -328,10 +423,10 @@ func (r *studentRepository) UpdatePassword(ctx context.Context, studentID string
 }
 
 func (r *studentRepository) GetPasswordByStudentID(ctx context.Context, studentID string) (string, error) {
-	query := `SELECT password FROM students WHERE student_id = $1`
+	query := `SELECT password FROM students WHERE student_id = $1 AND ($2 = 0 OR school_id = $2)`
 
 	var password string
-	err := r.db.QueryRowContext(ctx, query, studentID).Scan(&password)
+	err := r.db.QueryRowContext(ctx, query, studentID, schoolFilter(ctx)).Scan(&password)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("student not found")
@@ -343,10 +438,10 @@ func (r *studentRepository) GetPasswordByStudentID(ctx context.Context, studentI
 }
 
 func (r *studentRepository) IsStudentExist(ctx context.Context, studentID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM students WHERE student_id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM students WHERE student_id = $1 AND ($2 = 0 OR school_id = $2))`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, studentID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, studentID, schoolFilter(ctx)).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check student existence: %w", err)
 	}
@@ -361,11 +456,11 @@ func (r *studentRepository) GetStats(ctx context.Context) (*models.StudentStats,
 			COUNT(CASE WHEN is_active = true THEN 1 END) as active_students,
 			COUNT(CASE WHEN is_active = false THEN 1 END) as inactive_students
 		FROM students
-		WHERE deleted_at IS NULL
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
 		`
 
 	stats := &models.StudentStats{}
-	err := r.db.QueryRowContext(ctx, query).Scan(
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(
 		&stats.TotalStudents,
 		&stats.ActiveStudents,
 		&stats.InactiveStudents,
@@ -381,11 +476,11 @@ func (r *studentRepository) UpdateDeleteInfo(ctx context.Context, id uint, delet
 	query := `
 		UPDATE students
 		SET deleted_at = NOW(), deleted_by = $2
-		WHERE id = $1
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING deleted_at`
 
 	var deletedAt string
-	err := r.db.QueryRowContext(ctx, query, id, deletedBy).Scan(&deletedAt)
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("student not found")
@@ -401,6 +496,7 @@ func (r *studentRepository) GetByIDWithClassName(ctx context.Context, id uint) (
 		SELECT s.id
 		     , s.student_id
 		     , s.classes_id
+		     , s.school_id
 		     , s.first_name
 		     , s.last_name
 		     
@@ -414,13 +510,14 @@ func (r *studentRepository) GetByIDWithClassName(ctx context.Context, id uint) (
 			 , c.name AS class_name 
 		FROM students s 
 		    LEFT JOIN classes c ON s.classes_id = c.id AND c.deleted_at IS NULL
-		WHERE s.id = $1 AND s.deleted_at IS NULL`
+		WHERE s.id = $1 AND ($2 = 0 OR s.school_id = $2) AND s.deleted_at IS NULL`
 
 	student := &models.StudentsWithClassName{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&student.ID,
 		&student.StudentID,
 		&student.ClassesID,
+		&student.SchoolID,
 		&student.FirstName,
 		&student.LastName,
 
@@ -443,3 +540,125 @@ func (r *studentRepository) GetByIDWithClassName(ctx context.Context, id uint) (
 
 	return student, nil
 }
+
+// CheckImport resolves the class named on each row within the school of the request and reports
+// the rows whose student ID, email or phone is already taken
+func (r *studentRepository) CheckImport(ctx context.Context, rows []*models.StudentImportRow) ([]models.ImportRowError, error) {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check student import: %w", err)
+	}
+
+	var classNames, studentIDs, emails, phones []string
+	for _, row := range rows {
+		classNames = append(classNames, strings.ToLower(row.ClassName))
+		studentIDs = append(studentIDs, row.Student.StudentID)
+		emails = append(emails, strings.ToLower(row.Student.Email))
+		if row.Student.Phone != nil {
+			phones = append(phones, *row.Student.Phone)
+		}
+	}
+
+	classes, err := findClassesByName(ctx, r.db, schoolID, classNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// student IDs are unique within a school, emails and phones across every school.
+	// Deleted students keep theirs, so they are checked as well.
+	takenStudentIDs, err := findTaken(ctx, r.db, `
+		SELECT student_id FROM students WHERE school_id = $2 AND student_id = ANY($1)`,
+		studentIDs, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	takenEmails, err := findTaken(ctx, r.db, `
+		SELECT LOWER(email) FROM students WHERE LOWER(email) = ANY($1)`,
+		emails)
+	if err != nil {
+		return nil, err
+	}
+
+	takenPhones, err := findTaken(ctx, r.db, `
+		SELECT phone FROM students WHERE phone = ANY($1)`,
+		phones)
+	if err != nil {
+		return nil, err
+	}
+
+	var rowErrors []models.ImportRowError
+	for _, row := range rows {
+		classID, message := resolveClassName(classes, row.ClassName)
+		if message != "" {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "class", Error: message})
+		}
+		row.Student.ClassesID = classID
+
+		if takenStudentIDs[row.Student.StudentID] {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "student_id", Error: "student ID is already used by another student"})
+		}
+		if takenEmails[strings.ToLower(row.Student.Email)] {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "email", Error: "email is already used by another student"})
+		}
+		if row.Student.Phone != nil && takenPhones[*row.Student.Phone] {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "phone", Error: "phone is already used by another student"})
+		}
+	}
+
+	return rowErrors, nil
+}
+
+// Import adds the students checked by CheckImport in one transaction and enrolls each in their class from today.
+// Either every student is created or none is.
+func (r *studentRepository) Import(ctx context.Context, rows []*models.StudentImportRow, createdBy *uint) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to import students: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO students (student_id, classes_id, first_name, last_name, email, phone, password, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare student import: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		student := &row.Student
+		err = stmt.QueryRowContext(ctx,
+			student.StudentID,
+			student.ClassesID,
+			student.FirstName,
+			student.LastName,
+			student.Email,
+			student.Phone,
+			student.Password,
+			schoolID,
+		).Scan(&student.ID, &student.SchoolID, &student.CreatedAt, &student.UpdatedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to import student on row %d: %w", row.Row, err)
+		}
+
+		if err = changeEnrollment(ctx, tx, student.ID, student.ClassesID, student.CreatedAt, createdBy); err != nil {
+			return fmt.Errorf("failed to import student on row %d: %w", row.Row, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit student import: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type subjectRepository struct {
+	db *sql.DB
+}
+
+// NewSubjectRepository creates a new subject repository
+func NewSubjectRepository(db *sql.DB) SubjectRepository {
+	return &subjectRepository{db: db}
+}
+
+// Create adds the subject to the school of the request
+func (r *subjectRepository) Create(ctx context.Context, subject *models.Subject) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create subject: %w", err)
+	}
+
+	query := `
+		INSERT INTO subjects (
+			school_id
+			, code
+			, name
+			, description
+
+			, created_by
+			, created_at
+			, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		schoolID,
+		subject.Code,
+		subject.Name,
+		subject.Description,
+
+		subject.CreatedBy,
+	).Scan(&subject.ID, &subject.SchoolID, &subject.CreatedAt, &subject.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create subject: %w", err)
+	}
+
+	return nil
+}
+
+func (r *subjectRepository) GetByID(ctx context.Context, id uint) (*models.Subject, error) {
+	query := `
+		SELECT id, school_id, code, name, description,
+		       created_at, updated_at, created_by, updated_by
+		FROM subjects
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
+
+	subject := &models.Subject{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&subject.ID,
+		&subject.SchoolID,
+		&subject.Code,
+		&subject.Name,
+		&subject.Description,
+		&subject.CreatedAt,
+		&subject.UpdatedAt,
+		&subject.CreatedBy,
+		&subject.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subject not found")
+		}
+		return nil, fmt.Errorf("failed to get subject: %w", err)
+	}
+
+	return subject, nil
+}
+
+func (r *subjectRepository) GetAll(ctx context.Context) ([]*models.Subject, error) {
+	query := `
+		SELECT id, school_id, code, name, description,
+		       created_at, updated_at, created_by, updated_by
+		FROM subjects
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subjects: %w", err)
+	}
+	defer rows.Close()
+
+	var subjects []*models.Subject
+	for rows.Next() {
+		subject := &models.Subject{}
+		err := rows.Scan(
+			&subject.ID,
+			&subject.SchoolID,
+			&subject.Code,
+			&subject.Name,
+			&subject.Description,
+			&subject.CreatedAt,
+			&subject.UpdatedAt,
+			&subject.CreatedBy,
+			&subject.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subject: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate subjects: %w", err)
+	}
+
+	return subjects, nil
+}
+
+func (r *subjectRepository) Update(ctx context.Context, subject *models.Subject) error {
+	query := `
+		UPDATE subjects
+		SET code = $2, name = $3, description = $4,
+		    updated_by = $5, updated_at = NOW()
+		WHERE id = $1 AND ($6 = 0 OR school_id = $6) AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		subject.ID,
+		subject.Code,
+		subject.Name,
+		subject.Description,
+		subject.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&subject.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("subject not found")
+		}
+		return fmt.Errorf("failed to update subject: %w", err)
+	}
+
+	return nil
+}
+
+func (r *subjectRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	query := `
+		UPDATE subjects
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("subject not found")
+		}
+		return fmt.Errorf("failed to update subject delete info: %w", err)
+	}
+
+	return nil
+}
+
+// IsCodeExist reports whether another subject of the school already has the code
+func (r *subjectRepository) IsCodeExist(ctx context.Context, code string, excludeID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM subjects
+			WHERE LOWER(code) = LOWER($1)
+			  AND id <> $2
+			  AND ($3 = 0 OR school_id = $3)
+			  AND deleted_at IS NULL
+		)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, code, excludeID, schoolFilter(ctx)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check subject code: %w", err)
+	}
+
+	return exists, nil
+}
+
+// IsInUse reports whether a timetable slot still teaches the subject
+func (r *subjectRepository) IsInUse(ctx context.Context, id uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM timetable_slots
+			WHERE subject_id = $1 AND deleted_at IS NULL
+		)`
+
+	var inUse bool
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&inUse); err != nil {
+		return false, fmt.Errorf("failed to check subject usage: %w", err)
+	}
+
+	return inUse, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/michaelwp/student_attendance/internal/models"
 )
@@ -18,19 +19,25 @@ func NewTeacherRepository(db *sql.DB) TeacherRepository {
 }
 
 func (r *teacherRepository) Create(ctx context.Context, teacher *models.Teacher) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create teacher: %w", err)
+	}
+
 	query := `
-		INSERT INTO teachers (teacher_id, first_name, last_name, email, phone, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-		RETURNING id, created_at, updated_at`
+		INSERT INTO teachers (teacher_id, first_name, last_name, email, phone, password, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, school_id, preferred_language, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = r.db.QueryRowContext(ctx, query,
 		teacher.TeacherID,
 		teacher.FirstName,
 		teacher.LastName,
 		teacher.Email,
 		teacher.Phone,
 		teacher.Password,
-	).Scan(&teacher.ID, &teacher.CreatedAt, &teacher.UpdatedAt)
+		schoolID,
+	).Scan(&teacher.ID, &teacher.SchoolID, &teacher.PreferredLanguage, &teacher.CreatedAt, &teacher.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create teacher: %w", err)
@@ -41,13 +48,14 @@ func (r *teacherRepository) Create(ctx context.Context, teacher *models.Teacher)
 
 func (r *teacherRepository) GetByID(ctx context.Context, id uint) (*models.Teacher, error) {
 	query := `
-		SELECT id, teacher_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
-		FROM teachers WHERE id = $1 AND deleted_at IS NULL`
+		SELECT id, teacher_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active, preferred_language
+		FROM teachers WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	teacher := &models.Teacher{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
 		&teacher.ID,
 		&teacher.TeacherID,
+		&teacher.SchoolID,
 		&teacher.FirstName,
 		&teacher.LastName,
 		&teacher.Email,
@@ -56,6 +64,7 @@ func (r *teacherRepository) GetByID(ctx context.Context, id uint) (*models.Teach
 		&teacher.CreatedAt,
 		&teacher.UpdatedAt,
 		&teacher.IsActive,
+		&teacher.PreferredLanguage,
 	)
 
 	if err != nil {
@@ -70,13 +79,14 @@ func (r *teacherRepository) GetByID(ctx context.Context, id uint) (*models.Teach
 
 func (r *teacherRepository) GetByTeacherID(ctx context.Context, teacherID string) (*models.Teacher, error) {
 	query := `
-		SELECT id, teacher_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
-		FROM teachers WHERE teacher_id = $1 AND deleted_at IS NULL`
+		SELECT id, teacher_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active, preferred_language
+		FROM teachers WHERE teacher_id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NULL`
 
 	teacher := &models.Teacher{}
-	err := r.db.QueryRowContext(ctx, query, teacherID).Scan(
+	err := r.db.QueryRowContext(ctx, query, teacherID, schoolFilter(ctx)).Scan(
 		&teacher.ID,
 		&teacher.TeacherID,
+		&teacher.SchoolID,
 		&teacher.FirstName,
 		&teacher.LastName,
 		&teacher.Email,
@@ -85,6 +95,7 @@ func (r *teacherRepository) GetByTeacherID(ctx context.Context, teacherID string
 		&teacher.CreatedAt,
 		&teacher.UpdatedAt,
 		&teacher.IsActive,
+		&teacher.PreferredLanguage,
 	)
 
 	if err != nil {
@@ -99,13 +110,14 @@ func (r *teacherRepository) GetByTeacherID(ctx context.Context, teacherID string
 
 func (r *teacherRepository) GetByEmail(ctx context.Context, email string) (*models.Teacher, error) {
 	query := `
-		SELECT id, teacher_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active
-		FROM teachers WHERE email = $1`
+		SELECT id, teacher_id, school_id, first_name, last_name, email, phone, password, created_at, updated_at, is_active, preferred_language
+		FROM teachers WHERE email = $1 AND ($2 = 0 OR school_id = $2)`
 
 	teacher := &models.Teacher{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email, schoolFilter(ctx)).Scan(
 		&teacher.ID,
 		&teacher.TeacherID,
+		&teacher.SchoolID,
 		&teacher.FirstName,
 		&teacher.LastName,
 		&teacher.Email,
@@ -114,6 +126,7 @@ func (r *teacherRepository) GetByEmail(ctx context.Context, email string) (*mode
 		&teacher.CreatedAt,
 		&teacher.UpdatedAt,
 		&teacher.IsActive,
+		&teacher.PreferredLanguage,
 	)
 
 	if err != nil {
@@ -130,6 +143,7 @@ func (r *teacherRepository) GetAll(ctx context.Context, limit, offset int) ([]*m
 	query := `
 		SELECT id
 		     , teacher_id
+		     , school_id
 		     , first_name
 		     , last_name
 		     , email, phone
@@ -137,12 +151,13 @@ func (r *teacherRepository) GetAll(ctx context.Context, limit, offset int) ([]*m
 		     , created_at
 		     , updated_at
 			 , is_active
+		     , preferred_language
 		FROM teachers
-		WHERE deleted_at IS NULL
+		WHERE deleted_at IS NULL AND ($3 = 0 OR school_id = $3)
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset, schoolFilter(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get teachers: %w", err)
 	}
@@ -154,6 +169,7 @@ func (r *teacherRepository) GetAll(ctx context.Context, limit, offset int) ([]*m
 		err := rows.Scan(
 			&teacher.ID,
 			&teacher.TeacherID,
+			&teacher.SchoolID,
 			&teacher.FirstName,
 			&teacher.LastName,
 			&teacher.Email,
@@ -162,6 +178,7 @@ func (r *teacherRepository) GetAll(ctx context.Context, limit, offset int) ([]*m
 			&teacher.CreatedAt,
 			&teacher.UpdatedAt,
 			&teacher.IsActive,
+			&teacher.PreferredLanguage,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan teacher: %w", err)
@@ -180,7 +197,7 @@ func (r *teacherRepository) Update(ctx context.Context, teacher *models.Teacher)
 	query := `
 		UPDATE teachers 
 		SET teacher_id = $2, first_name = $3, last_name = $4, email = $5, phone = $6, updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND ($7 = 0 OR school_id = $7)
 		RETURNING updated_at`
 
 	err := r.db.QueryRowContext(ctx, query,
@@ -190,6 +207,7 @@ func (r *teacherRepository) Update(ctx context.Context, teacher *models.Teacher)
 		teacher.LastName,
 		teacher.Email,
 		teacher.Phone,
+		schoolFilter(ctx),
 	).Scan(&teacher.UpdatedAt)
 
 	if err != nil {
@@ -203,9 +221,9 @@ func (r *teacherRepository) Update(ctx context.Context, teacher *models.Teacher)
 }
 
 func (r *teacherRepository) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM teachers WHERE id = $1`
+	query := `DELETE FROM teachers WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, schoolFilter(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete teacher: %w", err)
 	}
@@ -226,11 +244,11 @@ func (r *teacherRepository) UpdatePhotoPath(ctx context.Context, id uint, photoP
 	query := `
 		UPDATE teachers 
 		SET photo_path = $2, updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING updated_at`
 
 	var updatedAt string
-	err := r.db.QueryRowContext(ctx, query, id, photoPath).Scan(&updatedAt)
+	err := r.db.QueryRowContext(ctx, query, id, photoPath, schoolFilter(ctx)).Scan(&updatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -243,10 +261,10 @@ func (r *teacherRepository) UpdatePhotoPath(ctx context.Context, id uint, photoP
 }
 
 func (r *teacherRepository) GetPhotoPath(ctx context.Context, id uint) (string, error) {
-	query := `SELECT photo_path FROM teachers WHERE id = $1`
+	query := `SELECT photo_path FROM teachers WHERE id = $1 AND ($2 = 0 OR school_id = $2)`
 
 	var photoPath string
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&photoPath)
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&photoPath)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("teacher not found")
@@ -258,10 +276,10 @@ func (r *teacherRepository) GetPhotoPath(ctx context.Context, id uint) (string,
 }
 
 func (r *teacherRepository) GetTotalTeachers(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM teachers WHERE deleted_at IS NULL`
+	query := `SELECT COUNT(*) FROM teachers WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total teachers: %w", err)
 	}
@@ -273,11 +291,11 @@ func (r *teacherRepository) UpdatePassword(ctx context.Context, teacherID string
 	query := `
 		UPDATE teachers
 		SET password = $2, updated_at = NOW()
-		WHERE teacher_id = $1
+		WHERE teacher_id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING updated_at`
 
 	var updatedAt string
-	err := r.db.QueryRowContext(ctx, query, teacherID, password).Scan(&updatedAt)
+	err := r.db.QueryRowContext(ctx, query, teacherID, password, schoolFilter(ctx)).Scan(&updatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("teacher not found")
@@ -289,10 +307,10 @@ func (r *teacherRepository) UpdatePassword(ctx context.Context, teacherID string
 }
 
 func (r *teacherRepository) GetPasswordByTeacherID(ctx context.Context, teacherID string) (string, error) {
-	query := `SELECT password FROM teachers WHERE teacher_id = $1`
+	query := `SELECT password FROM teachers WHERE teacher_id = $1 AND ($2 = 0 OR school_id = $2)`
 
 	var password string
-	err := r.db.QueryRowContext(ctx, query, teacherID).Scan(&password)
+	err := r.db.QueryRowContext(ctx, query, teacherID, schoolFilter(ctx)).Scan(&password)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("teacher not found")
@@ -304,10 +322,10 @@ func (r *teacherRepository) GetPasswordByTeacherID(ctx context.Context, teacherI
 }
 
 func (r *teacherRepository) IsTeacherExist(ctx context.Context, teacherID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM teachers WHERE teacher_id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM teachers WHERE teacher_id = $1 AND ($2 = 0 OR school_id = $2))`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, teacherID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, teacherID, schoolFilter(ctx)).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check teacher existence: %w", err)
 	}
@@ -322,11 +340,11 @@ func (r *teacherRepository) GetStats(ctx context.Context) (*models.TeacherStats,
 			COUNT(CASE WHEN is_active = true THEN 1 END) as active_teachers,
 			COUNT(CASE WHEN is_active = false THEN 1 END) as inactive_teachers
 		FROM teachers
-		WHERE deleted_at IS NULL
+		WHERE deleted_at IS NULL AND ($1 = 0 OR school_id = $1)
 		`
 
 	stats := &models.TeacherStats{}
-	err := r.db.QueryRowContext(ctx, query).Scan(
+	err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(
 		&stats.TotalTeachers,
 		&stats.ActiveTeachers,
 		&stats.InactiveTeachers,
@@ -342,11 +360,11 @@ func (r *teacherRepository) UpdateDeleteInfo(ctx context.Context, id uint, delet
 	query := `
 		UPDATE teachers 
 		SET deleted_at = NOW(), deleted_by = $2
-		WHERE id = $1
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3)
 		RETURNING deleted_at`
 
 	var deletedAt string
-	err := r.db.QueryRowContext(ctx, query, id, deletedBy).Scan(&deletedAt)
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("teacher not found")
@@ -366,12 +384,13 @@ func (r *teacherRepository) GetByIDWithClasses(ctx context.Context, id uint) (*m
 
 	// Get teacher's classes
 	classesQuery := `
-		SELECT id, name, homeroom_teacher, description, created_at, updated_at
-		FROM classes 
-		WHERE homeroom_teacher = $1 AND deleted_at IS NULL
-		ORDER BY name`
+		SELECT c.id, c.name, c.homeroom_teacher, c.description, c.created_at, c.updated_at
+		FROM classes c
+		    JOIN class_teachers ct ON ct.class_id = c.id
+		WHERE ct.teacher_id = $1 AND ct.school_id = $2 AND c.deleted_at IS NULL
+		ORDER BY c.name`
 
-	rows, err := r.db.QueryContext(ctx, classesQuery, teacher.TeacherID)
+	rows, err := r.db.QueryContext(ctx, classesQuery, teacher.TeacherID, teacher.SchoolID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get teacher's classes: %w", err)
 	}
@@ -403,10 +422,11 @@ func (r *teacherRepository) GetByIDWithClasses(ctx context.Context, id uint) (*m
 		SELECT COUNT(s.id)
 		FROM students s
 		JOIN classes c ON s.classes_id = c.id
-		WHERE c.homeroom_teacher = $1 AND s.deleted_at IS NULL AND c.deleted_at IS NULL`
+		JOIN class_teachers ct ON ct.class_id = c.id
+		WHERE ct.teacher_id = $1 AND ct.school_id = $2 AND s.deleted_at IS NULL AND c.deleted_at IS NULL`
 
 	var totalStudents int
-	err = r.db.QueryRowContext(ctx, studentCountQuery, teacher.TeacherID).Scan(&totalStudents)
+	err = r.db.QueryRowContext(ctx, studentCountQuery, teacher.TeacherID, teacher.SchoolID).Scan(&totalStudents)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total students count: %w", err)
 	}
@@ -416,10 +436,11 @@ func (r *teacherRepository) GetByIDWithClasses(ctx context.Context, id uint) (*m
 		SELECT COUNT(ar.id)
 		FROM absent_requests ar
 		JOIN classes c ON ar.class_id = c.id
-		WHERE c.homeroom_teacher = $1 AND ar.status = 'pending' AND ar.deleted_at IS NULL AND c.deleted_at IS NULL`
+		JOIN class_teachers ct ON ct.class_id = c.id
+		WHERE ct.teacher_id = $1 AND ct.school_id = $2 AND ar.status = 'pending' AND ar.deleted_at IS NULL AND c.deleted_at IS NULL`
 
 	var pendingRequests int
-	err = r.db.QueryRowContext(ctx, pendingRequestsQuery, teacher.TeacherID).Scan(&pendingRequests)
+	err = r.db.QueryRowContext(ctx, pendingRequestsQuery, teacher.TeacherID, teacher.SchoolID).Scan(&pendingRequests)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending requests count: %w", err)
 	}
@@ -431,3 +452,148 @@ func (r *teacherRepository) GetByIDWithClasses(ctx context.Context, id uint) (*m
 		PendingRequests: pendingRequests,
 	}, nil
 }
+
+// CheckImport resolves the homeroom class named on each row within the school of the request and reports
+// the rows whose teacher ID or email is already taken, as IsTeacherExist and the unique email and phone would
+func (r *teacherRepository) CheckImport(ctx context.Context, rows []*models.TeacherImportRow) ([]models.ImportRowError, error) {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check teacher import: %w", err)
+	}
+
+	var classNames, teacherIDs, emails, phones []string
+	for _, row := range rows {
+		if row.HomeroomClassName != "" {
+			classNames = append(classNames, strings.ToLower(row.HomeroomClassName))
+		}
+		teacherIDs = append(teacherIDs, row.Teacher.TeacherID)
+		emails = append(emails, strings.ToLower(row.Teacher.Email))
+		if row.Teacher.Phone != nil {
+			phones = append(phones, *row.Teacher.Phone)
+		}
+	}
+
+	classes, err := findClassesByName(ctx, r.db, schoolID, classNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// teacher IDs are unique within a school, emails and phones across every school.
+	// Deleted teachers keep theirs, so they are checked as well.
+	takenTeacherIDs, err := findTaken(ctx, r.db, `
+		SELECT teacher_id FROM teachers WHERE school_id = $2 AND teacher_id = ANY($1)`,
+		teacherIDs, schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	takenEmails, err := findTaken(ctx, r.db, `
+		SELECT LOWER(email) FROM teachers WHERE LOWER(email) = ANY($1)`,
+		emails)
+	if err != nil {
+		return nil, err
+	}
+
+	takenPhones, err := findTaken(ctx, r.db, `
+		SELECT phone FROM teachers WHERE phone = ANY($1)`,
+		phones)
+	if err != nil {
+		return nil, err
+	}
+
+	var rowErrors []models.ImportRowError
+	for _, row := range rows {
+		if row.HomeroomClassName != "" {
+			classID, message := resolveClassName(classes, row.HomeroomClassName)
+			if message != "" {
+				rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "homeroom_class", Error: message})
+			}
+			row.HomeroomClassID = classID
+		}
+
+		if takenTeacherIDs[row.Teacher.TeacherID] {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "teacher_id", Error: "teacher ID is already used by another teacher"})
+		}
+		if takenEmails[strings.ToLower(row.Teacher.Email)] {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "email", Error: "email is already used by another teacher"})
+		}
+		if row.Teacher.Phone != nil && takenPhones[*row.Teacher.Phone] {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Row, Field: "phone", Error: "phone is already used by another teacher"})
+		}
+	}
+
+	return rowErrors, nil
+}
+
+// Import adds the teachers checked by CheckImport in one transaction and makes each the homeroom teacher
+// of the class named for them, replacing its previous homeroom teacher. Either every teacher is created or none is.
+func (r *teacherRepository) Import(ctx context.Context, rows []*models.TeacherImportRow, createdBy *uint) error {
+	schoolID, err := requireSchoolID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to import teachers: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO teachers (teacher_id, first_name, last_name, email, phone, password, school_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, school_id, created_at, updated_at`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare teacher import: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		teacher := &row.Teacher
+		err = stmt.QueryRowContext(ctx,
+			teacher.TeacherID,
+			teacher.FirstName,
+			teacher.LastName,
+			teacher.Email,
+			teacher.Phone,
+			teacher.Password,
+			schoolID,
+		).Scan(&teacher.ID, &teacher.SchoolID, &teacher.CreatedAt, &teacher.UpdatedAt)
+
+		if err != nil {
+			return fmt.Errorf("failed to import teacher on row %d: %w", row.Row, err)
+		}
+	}
+
+	for _, row := range rows {
+		if row.HomeroomClassID == 0 {
+			continue
+		}
+
+		query := `
+			UPDATE classes
+			SET homeroom_teacher = $2, updated_at = NOW()
+			WHERE id = $1 AND school_id = $3 AND deleted_at IS NULL`
+
+		if _, err = tx.ExecContext(ctx, query, row.HomeroomClassID, row.Teacher.TeacherID, schoolID); err != nil {
+			return fmt.Errorf("failed to assign homeroom class on row %d: %w", row.Row, err)
+		}
+
+		if err = syncHomeroomTeacher(ctx, tx, row.HomeroomClassID); err != nil {
+			return fmt.Errorf("failed to assign homeroom class on row %d: %w", row.Row, err)
+		}
+
+		query = `UPDATE class_teachers SET created_by = $3 WHERE class_id = $1 AND teacher_id = $2`
+		if _, err = tx.ExecContext(ctx, query, row.HomeroomClassID, row.Teacher.TeacherID, createdBy); err != nil {
+			return fmt.Errorf("failed to assign homeroom class on row %d: %w", row.Row, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit teacher import: %w", err)
+	}
+
+	return nil
+}
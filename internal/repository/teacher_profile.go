@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+// UpdateProfile sets the phone and preferred language a teacher chose for themselves. A nil phone clears it.
+func (r *teacherRepository) UpdateProfile(ctx context.Context, id uint, phone *string, language string) error {
+	if phone != nil {
+		var taken bool
+		err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teachers WHERE phone = $1 AND id <> $2)`,
+			*phone, id).Scan(&taken)
+		if err != nil {
+			return fmt.Errorf("failed to check phone: %w", err)
+		}
+		if taken {
+			return models.ErrPhoneTaken
+		}
+	}
+
+	query := `
+		UPDATE teachers
+		SET phone = $2, preferred_language = $3, updated_at = NOW()
+		WHERE id = $1 AND ($4 = 0 OR school_id = $4) AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, phone, language, schoolFilter(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update teacher profile: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("teacher not found")
+	}
+
+	return nil
+}
+
+// IsEmailTaken reports whether a teacher other than the one with exceptID uses the email, in any case.
+// Deleted teachers count, as they keep their email until they are purged.
+func (r *teacherRepository) IsEmailTaken(ctx context.Context, email string, exceptID uint) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM teachers WHERE LOWER(email) = LOWER($1) AND id <> $2)`
+
+	var taken bool
+	err := r.db.QueryRowContext(ctx, query, email, exceptID).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	return taken, nil
+}
+
+// SaveEmailChange records a new email waiting for verification, replacing the one the teacher asked for before
+func (r *teacherRepository) SaveEmailChange(ctx context.Context, change *models.TeacherEmailChange) error {
+	query := `
+		INSERT INTO teacher_email_changes (teacher_id, new_email, code_hash, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, 0, $4, NOW())
+		ON CONFLICT (teacher_id) DO UPDATE
+		    SET new_email = EXCLUDED.new_email, code_hash = EXCLUDED.code_hash, attempts = 0,
+		        expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+		RETURNING attempts, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		change.TeacherID,
+		change.NewEmail,
+		change.CodeHash,
+		change.ExpiresAt,
+	).Scan(&change.Attempts, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	return nil
+}
+
+// GetEmailChange returns the new email of the teacher waiting for verification
+func (r *teacherRepository) GetEmailChange(ctx context.Context, teacherID uint) (*models.TeacherEmailChange, error) {
+	query := `
+		SELECT teacher_id, new_email, code_hash, attempts, expires_at, created_at
+		FROM teacher_email_changes WHERE teacher_id = $1`
+
+	change := &models.TeacherEmailChange{}
+	err := r.db.QueryRowContext(ctx, query, teacherID).Scan(
+		&change.TeacherID,
+		&change.NewEmail,
+		&change.CodeHash,
+		&change.Attempts,
+		&change.ExpiresAt,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrEmailChangeNotFound
+		}
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	return change, nil
+}
+
+// FailEmailChange counts a wrong code. The change is dropped once MaxEmailChangeAttempts wrong codes were entered.
+func (r *teacherRepository) FailEmailChange(ctx context.Context, teacherID uint) error {
+	query := `
+		UPDATE teacher_email_changes SET attempts = attempts + 1
+		WHERE teacher_id = $1
+		RETURNING attempts`
+
+	var attempts int
+	err := r.db.QueryRowContext(ctx, query, teacherID).Scan(&attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ErrEmailChangeNotFound
+		}
+		return fmt.Errorf("failed to record email change attempt: %w", err)
+	}
+
+	if attempts >= models.MaxEmailChangeAttempts {
+		if _, err := r.db.ExecContext(ctx, `DELETE FROM teacher_email_changes WHERE teacher_id = $1`, teacherID); err != nil {
+			return fmt.Errorf("failed to drop email change: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange makes the verified new email the teacher's email and returns it
+func (r *teacherRepository) ConfirmEmailChange(ctx context.Context, teacherID uint) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var newEmail string
+	var expiresAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT new_email, expires_at FROM teacher_email_changes WHERE teacher_id = $1 FOR UPDATE`,
+		teacherID).Scan(&newEmail, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", models.ErrEmailChangeNotFound
+		}
+		return "", fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	if time.Now().After(expiresAt) {
+		return "", models.ErrEmailChangeExpired
+	}
+
+	// another teacher may have taken the email since the code was sent
+	var taken bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teachers WHERE LOWER(email) = LOWER($1) AND id <> $2)`,
+		newEmail, teacherID).Scan(&taken)
+	if err != nil {
+		return "", fmt.Errorf("failed to check email: %w", err)
+	}
+	if taken {
+		return "", models.ErrEmailTaken
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE teachers SET email = $2, updated_at = NOW()
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL`,
+		teacherID, newEmail, schoolFilter(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to update teacher email: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return "", fmt.Errorf("teacher not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM teacher_email_changes WHERE teacher_id = $1`, teacherID); err != nil {
+		return "", fmt.Errorf("failed to delete email change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return newEmail, nil
+}
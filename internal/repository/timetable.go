@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type timetableRepository struct {
+	db *sql.DB
+}
+
+// NewTimetableRepository creates a new timetable repository
+func NewTimetableRepository(db *sql.DB) TimetableRepository {
+	return &timetableRepository{db: db}
+}
+
+// Create adds the slot to the school of its class
+func (r *timetableRepository) Create(ctx context.Context, slot *models.TimetableSlot) error {
+	query := `
+		INSERT INTO timetable_slots (
+			class_id
+			, subject_id
+			, teacher_id
+			, weekday
+			, start_time
+			, end_time
+			, room
+
+			, created_by
+			, created_at
+			, updated_at
+			, school_id
+		)
+		SELECT $1, $2, $3, $4, $5::time, $6::time, $7
+			, $8, NOW(), NOW(), c.school_id
+		FROM classes c
+		WHERE c.id = $1 AND ($9 = 0 OR c.school_id = $9) AND c.deleted_at IS NULL
+		RETURNING id, school_id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		slot.ClassID,
+		slot.SubjectID,
+		slot.TeacherID,
+		slot.Weekday,
+		slot.StartTime,
+		slot.EndTime,
+		slot.Room,
+
+		slot.CreatedBy,
+		schoolFilter(ctx),
+	).Scan(&slot.ID, &slot.SchoolID, &slot.CreatedAt, &slot.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("class not found")
+		}
+		return fmt.Errorf("failed to create timetable slot: %w", err)
+	}
+
+	return nil
+}
+
+func (r *timetableRepository) GetByID(ctx context.Context, id uint) (*models.TimetableSlot, error) {
+	query := `
+		SELECT ts.id, ts.school_id, ts.class_id, ts.subject_id, ts.teacher_id, ts.weekday,
+		       TO_CHAR(ts.start_time, 'HH24:MI'), TO_CHAR(ts.end_time, 'HH24:MI'), ts.room,
+		       c.name, sub.code, sub.name, t.first_name, t.last_name,
+		       ts.created_at, ts.updated_at, ts.created_by, ts.updated_by
+		FROM timetable_slots ts
+		    JOIN classes c ON c.id = ts.class_id
+		    JOIN subjects sub ON sub.id = ts.subject_id
+		    JOIN teachers t ON t.teacher_id = ts.teacher_id AND t.school_id = ts.school_id
+		WHERE ts.id = $1 AND ($2 = 0 OR ts.school_id = $2) AND ts.deleted_at IS NULL`
+
+	slot := &models.TimetableSlot{}
+	err := r.db.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(
+		&slot.ID,
+		&slot.SchoolID,
+		&slot.ClassID,
+		&slot.SubjectID,
+		&slot.TeacherID,
+		&slot.Weekday,
+		&slot.StartTime,
+		&slot.EndTime,
+		&slot.Room,
+		&slot.ClassName,
+		&slot.SubjectCode,
+		&slot.SubjectName,
+		&slot.TeacherFirstName,
+		&slot.TeacherLastName,
+		&slot.CreatedAt,
+		&slot.UpdatedAt,
+		&slot.CreatedBy,
+		&slot.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("timetable slot not found")
+		}
+		return nil, fmt.Errorf("failed to get timetable slot: %w", err)
+	}
+
+	return slot, nil
+}
+
+// GetAll returns the slots matching the filter ordered by weekday and start time
+func (r *timetableRepository) GetAll(ctx context.Context, filter *models.TimetableFilter) ([]*models.TimetableSlot, error) {
+	query := `
+		SELECT ts.id, ts.school_id, ts.class_id, ts.subject_id, ts.teacher_id, ts.weekday,
+		       TO_CHAR(ts.start_time, 'HH24:MI'), TO_CHAR(ts.end_time, 'HH24:MI'), ts.room,
+		       c.name, sub.code, sub.name, t.first_name, t.last_name,
+		       ts.created_at, ts.updated_at, ts.created_by, ts.updated_by
+		FROM timetable_slots ts
+		    JOIN classes c ON c.id = ts.class_id
+		    JOIN subjects sub ON sub.id = ts.subject_id
+		    JOIN teachers t ON t.teacher_id = ts.teacher_id AND t.school_id = ts.school_id
+		WHERE ts.deleted_at IS NULL
+		  AND ($1 = 0 OR ts.class_id = $1)
+		  AND ($2 = '' OR ts.teacher_id = $2)
+		  AND ($3 = 0 OR ts.weekday = $3)
+		  AND ($4 = 0 OR ts.school_id = $4)
+		ORDER BY ts.weekday, ts.start_time, c.name`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.ClassID, filter.TeacherID, filter.Weekday, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timetable slots: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []*models.TimetableSlot
+	for rows.Next() {
+		slot := &models.TimetableSlot{}
+		err := rows.Scan(
+			&slot.ID,
+			&slot.SchoolID,
+			&slot.ClassID,
+			&slot.SubjectID,
+			&slot.TeacherID,
+			&slot.Weekday,
+			&slot.StartTime,
+			&slot.EndTime,
+			&slot.Room,
+			&slot.ClassName,
+			&slot.SubjectCode,
+			&slot.SubjectName,
+			&slot.TeacherFirstName,
+			&slot.TeacherLastName,
+			&slot.CreatedAt,
+			&slot.UpdatedAt,
+			&slot.CreatedBy,
+			&slot.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timetable slot: %w", err)
+		}
+		slots = append(slots, slot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate timetable slots: %w", err)
+	}
+
+	return slots, nil
+}
+
+func (r *timetableRepository) Update(ctx context.Context, slot *models.TimetableSlot) error {
+	query := `
+		UPDATE timetable_slots
+		SET class_id = $2, subject_id = $3, teacher_id = $4, weekday = $5,
+		    start_time = $6::time, end_time = $7::time, room = $8,
+		    updated_by = $9, updated_at = NOW()
+		WHERE id = $1 AND ($10 = 0 OR school_id = $10) AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		slot.ID,
+		slot.ClassID,
+		slot.SubjectID,
+		slot.TeacherID,
+		slot.Weekday,
+		slot.StartTime,
+		slot.EndTime,
+		slot.Room,
+		slot.UpdatedBy,
+		schoolFilter(ctx),
+	).Scan(&slot.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("timetable slot not found")
+		}
+		return fmt.Errorf("failed to update timetable slot: %w", err)
+	}
+
+	return nil
+}
+
+func (r *timetableRepository) UpdateDeleteInfo(ctx context.Context, id uint, deletedBy uint) error {
+	query := `
+		UPDATE timetable_slots
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND ($3 = 0 OR school_id = $3) AND deleted_at IS NULL
+		RETURNING deleted_at`
+
+	var deletedAt string
+	err := r.db.QueryRowContext(ctx, query, id, deletedBy, schoolFilter(ctx)).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("timetable slot not found")
+		}
+		return fmt.Errorf("failed to update timetable slot delete info: %w", err)
+	}
+
+	return nil
+}
+
+// HasOverlap reports whether the class or the teacher of the slot already has another lesson
+// on the same weekday that overlaps its times
+func (r *timetableRepository) HasOverlap(ctx context.Context, slot *models.TimetableSlot) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM timetable_slots ts
+			    JOIN classes c ON c.id = $2
+			WHERE ts.id <> $1
+			  AND ts.weekday = $4
+			  AND (ts.class_id = $2 OR (ts.teacher_id = $3 AND ts.school_id = c.school_id))
+			  AND ts.start_time < $6::time
+			  AND ts.end_time > $5::time
+			  AND ts.deleted_at IS NULL
+		)`
+
+	var overlaps bool
+	err := r.db.QueryRowContext(ctx, query,
+		slot.ID,
+		slot.ClassID,
+		slot.TeacherID,
+		slot.Weekday,
+		slot.StartTime,
+		slot.EndTime,
+	).Scan(&overlaps)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check timetable overlap: %w", err)
+	}
+
+	return overlaps, nil
+}
@@ -0,0 +1,695 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/michaelwp/student_attendance/internal/models"
+)
+
+type trashRepository struct {
+	db *sql.DB
+}
+
+// NewTrashRepository creates a new trash repository
+func NewTrashRepository(db *sql.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// trashTables maps the kinds of records in the trash to their tables
+var trashTables = map[string]string{
+	models.TrashEntityTeachers:       "teachers",
+	models.TrashEntityStudents:       "students",
+	models.TrashEntityClasses:        "classes",
+	models.TrashEntityAttendances:    "attendances",
+	models.TrashEntityAbsentRequests: "absent_requests",
+}
+
+// trashListQueries select the deleted records of each kind as (id, code, name, detail, school_id, deleted_at, deleted_by)
+var trashListQueries = map[string]string{
+	models.TrashEntityTeachers: `
+		SELECT id, teacher_id, first_name || ' ' || last_name, email, school_id, deleted_at, deleted_by
+		FROM teachers
+		WHERE deleted_at IS NOT NULL AND ($1 = 0 OR school_id = $1)
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $2 OFFSET $3`,
+	models.TrashEntityStudents: `
+		SELECT s.id, s.student_id, s.first_name || ' ' || s.last_name, COALESCE(c.name, ''), s.school_id, s.deleted_at, s.deleted_by
+		FROM students s
+		    LEFT JOIN classes c ON c.id = s.classes_id
+		WHERE s.deleted_at IS NOT NULL AND ($1 = 0 OR s.school_id = $1)
+		ORDER BY s.deleted_at DESC, s.id DESC
+		LIMIT $2 OFFSET $3`,
+	models.TrashEntityClasses: `
+		SELECT c.id, c.homeroom_teacher, c.name, COALESCE(y.name, ''), c.school_id, c.deleted_at, c.deleted_by
+		FROM classes c
+		    LEFT JOIN academic_years y ON y.id = c.academic_year_id
+		WHERE c.deleted_at IS NOT NULL AND ($1 = 0 OR c.school_id = $1)
+		ORDER BY c.deleted_at DESC, c.id DESC
+		LIMIT $2 OFFSET $3`,
+	models.TrashEntityAttendances: `
+		SELECT a.id, a.student_id, COALESCE(s.first_name || ' ' || s.last_name, ''),
+		       TO_CHAR(a.date, 'YYYY-MM-DD') || ' ' || a.status, a.school_id, a.deleted_at, a.deleted_by
+		FROM attendances a
+		    LEFT JOIN students s ON s.student_id = a.student_id AND s.school_id = a.school_id
+		WHERE a.deleted_at IS NOT NULL AND ($1 = 0 OR a.school_id = $1)
+		ORDER BY a.deleted_at DESC, a.id DESC
+		LIMIT $2 OFFSET $3`,
+	models.TrashEntityAbsentRequests: `
+		SELECT ar.id, ar.student_id, COALESCE(s.first_name || ' ' || s.last_name, ''),
+		       TO_CHAR(ar.start_date, 'YYYY-MM-DD') || ' to ' || TO_CHAR(ar.end_date, 'YYYY-MM-DD') || ' ' || ar.status,
+		       ar.school_id, ar.deleted_at, ar.deleted_by
+		FROM absent_requests ar
+		    LEFT JOIN students s ON s.student_id = ar.student_id AND s.school_id = ar.school_id
+		WHERE ar.deleted_at IS NOT NULL AND ($1 = 0 OR ar.school_id = $1)
+		ORDER BY ar.deleted_at DESC, ar.id DESC
+		LIMIT $2 OFFSET $3`,
+}
+
+func (r *trashRepository) GetDeleted(ctx context.Context, entity string, limit, offset int) ([]*models.TrashItem, error) {
+	query, ok := trashListQueries[entity]
+	if !ok {
+		return nil, models.ErrInvalidTrashEntity
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, schoolFilter(ctx), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted %s: %w", entity, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*models.TrashItem
+	for rows.Next() {
+		item := &models.TrashItem{Entity: entity}
+		err := rows.Scan(
+			&item.ID,
+			&item.Code,
+			&item.Name,
+			&item.Detail,
+			&item.SchoolID,
+			&item.DeletedAt,
+			&item.DeletedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deleted %s: %w", entity, err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deleted %s: %w", entity, err)
+	}
+
+	return items, nil
+}
+
+func (r *trashRepository) GetDeletedCount(ctx context.Context, entity string) (int, error) {
+	table, ok := trashTables[entity]
+	if !ok {
+		return 0, models.ErrInvalidTrashEntity
+	}
+
+	query := `SELECT COUNT(*) FROM ` + table + ` WHERE deleted_at IS NOT NULL AND ($1 = 0 OR school_id = $1)`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, schoolFilter(ctx)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count deleted %s: %w", entity, err)
+	}
+
+	return count, nil
+}
+
+// trashCheck is a query telling whether restoring a record would clash with the live records
+type trashCheck struct {
+	field   string
+	message string
+	query   string
+	args    []interface{}
+}
+
+// Restore takes a record out of the trash. When live records conflict with it, e.g. another student now
+// uses its email or its class is deleted as well, it stays deleted and the conflicts are returned.
+func (r *trashRepository) Restore(ctx context.Context, entity string, id uint) ([]models.TrashConflict, error) {
+	table, ok := trashTables[entity]
+	if !ok {
+		return nil, models.ErrInvalidTrashEntity
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var checks []trashCheck
+	switch entity {
+	case models.TrashEntityTeachers:
+		checks, err = teacherRestoreChecks(ctx, tx, id)
+	case models.TrashEntityStudents:
+		checks, err = studentRestoreChecks(ctx, tx, id)
+	case models.TrashEntityClasses:
+		checks, err = classRestoreChecks(ctx, tx, id)
+	case models.TrashEntityAttendances:
+		checks, err = attendanceRestoreChecks(ctx, tx, id)
+	case models.TrashEntityAbsentRequests:
+		checks, err = absentRequestRestoreChecks(ctx, tx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []models.TrashConflict{}
+	for _, check := range checks {
+		var conflict bool
+		if err := tx.QueryRowContext(ctx, check.query, check.args...).Scan(&conflict); err != nil {
+			return nil, fmt.Errorf("failed to check %s conflicts: %w", check.field, err)
+		}
+
+		if conflict {
+			conflicts = append(conflicts, models.TrashConflict{Field: check.field, Error: check.message})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return conflicts, models.ErrTrashRestoreConflict
+	}
+
+	query := `UPDATE ` + table + ` SET deleted_at = NULL, deleted_by = NULL WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return nil, fmt.Errorf("failed to restore %s: %w", entity, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil, nil
+}
+
+// teacherRestoreChecks locks the deleted teacher. Teacher IDs, emails and phones stay unique in the table
+// while a teacher is deleted, so only case-insensitive email matches can clash.
+func teacherRestoreChecks(ctx context.Context, tx *sql.Tx, id uint) ([]trashCheck, error) {
+	query := `
+		SELECT email
+		FROM teachers
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NOT NULL
+		FOR UPDATE`
+
+	var email string
+	if err := tx.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&email); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted teacher %d: %w", id, models.ErrTrashNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deleted teacher: %w", err)
+	}
+
+	return []trashCheck{
+		{
+			field:   "email",
+			message: "another teacher uses this email",
+			query:   `SELECT EXISTS(SELECT 1 FROM teachers WHERE LOWER(email) = LOWER($1) AND id <> $2 AND deleted_at IS NULL)`,
+			args:    []interface{}{email, id},
+		},
+	}, nil
+}
+
+// studentRestoreChecks locks the deleted student, who must not clash with another student's email
+// and whose class must not be deleted
+func studentRestoreChecks(ctx context.Context, tx *sql.Tx, id uint) ([]trashCheck, error) {
+	query := `
+		SELECT email, classes_id
+		FROM students
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NOT NULL
+		FOR UPDATE`
+
+	var email string
+	var classID uint
+	if err := tx.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&email, &classID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted student %d: %w", id, models.ErrTrashNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deleted student: %w", err)
+	}
+
+	return []trashCheck{
+		{
+			field:   "email",
+			message: "another student uses this email",
+			query:   `SELECT EXISTS(SELECT 1 FROM students WHERE LOWER(email) = LOWER($1) AND id <> $2 AND deleted_at IS NULL)`,
+			args:    []interface{}{email, id},
+		},
+		{
+			field:   "class_id",
+			message: "the class of the student is deleted, restore it first",
+			query:   `SELECT EXISTS(SELECT 1 FROM classes WHERE id = $1 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{classID},
+		},
+	}, nil
+}
+
+// classRestoreChecks locks the deleted class, whose homeroom teacher and academic year must be live and
+// whose name must not be taken by another class of the same school and year
+func classRestoreChecks(ctx context.Context, tx *sql.Tx, id uint) ([]trashCheck, error) {
+	query := `
+		SELECT name, homeroom_teacher, academic_year_id, school_id
+		FROM classes
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NOT NULL
+		FOR UPDATE`
+
+	var name, homeroomTeacher string
+	var academicYearID sql.NullInt64
+	var schoolID uint
+	err := tx.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&name, &homeroomTeacher, &academicYearID, &schoolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted class %d: %w", id, models.ErrTrashNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deleted class: %w", err)
+	}
+
+	return []trashCheck{
+		{
+			field:   "name",
+			message: "another class of the same academic year has this name",
+			query: `
+				SELECT EXISTS(
+					SELECT 1 FROM classes
+					WHERE LOWER(name) = LOWER($1) AND id <> $2 AND school_id = $3
+					  AND academic_year_id IS NOT DISTINCT FROM $4 AND deleted_at IS NULL
+				)`,
+			args: []interface{}{name, id, schoolID, academicYearID},
+		},
+		{
+			field:   "homeroom_teacher",
+			message: "the homeroom teacher of the class is deleted, restore them first",
+			query:   `SELECT EXISTS(SELECT 1 FROM teachers WHERE teacher_id = $1 AND school_id = $2 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{homeroomTeacher, schoolID},
+		},
+		{
+			field:   "academic_year_id",
+			message: "the academic year of the class is deleted",
+			query:   `SELECT EXISTS(SELECT 1 FROM academic_years WHERE id = $1 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{academicYearID},
+		},
+	}, nil
+}
+
+// attendanceRestoreChecks locks the deleted attendance, whose student, class and lesson must be live and
+// which must not duplicate an attendance recorded since for the same student, day and lesson
+func attendanceRestoreChecks(ctx context.Context, tx *sql.Tx, id uint) ([]trashCheck, error) {
+	query := `
+		SELECT student_id, class_id, date, timetable_slot_id, school_id
+		FROM attendances
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NOT NULL
+		FOR UPDATE`
+
+	var studentID string
+	var classID, schoolID uint
+	var date time.Time
+	var timetableSlotID sql.NullInt64
+	err := tx.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&studentID, &classID, &date, &timetableSlotID, &schoolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted attendance %d: %w", id, models.ErrTrashNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deleted attendance: %w", err)
+	}
+
+	return []trashCheck{
+		{
+			field:   "date",
+			message: "the student already has an attendance for this day",
+			query: `
+				SELECT EXISTS(
+					SELECT 1 FROM attendances
+					WHERE student_id = $1 AND school_id = $2 AND DATE(date) = DATE($3)
+					  AND timetable_slot_id IS NOT DISTINCT FROM $4 AND id <> $5 AND deleted_at IS NULL
+				)`,
+			args: []interface{}{studentID, schoolID, date, timetableSlotID, id},
+		},
+		{
+			field:   "student_id",
+			message: "the student is deleted, restore them first",
+			query:   `SELECT EXISTS(SELECT 1 FROM students WHERE student_id = $1 AND school_id = $2 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{studentID, schoolID},
+		},
+		{
+			field:   "class_id",
+			message: "the class is deleted, restore it first",
+			query:   `SELECT EXISTS(SELECT 1 FROM classes WHERE id = $1 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{classID},
+		},
+		{
+			field:   "timetable_slot_id",
+			message: "the lesson is no longer in the timetable",
+			query:   `SELECT EXISTS(SELECT 1 FROM timetable_slots WHERE id = $1 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{timetableSlotID},
+		},
+	}, nil
+}
+
+// absentRequestRestoreChecks locks the deleted absent request, whose student and class must be live and
+// which, unless rejected, must not overlap another request of the student
+func absentRequestRestoreChecks(ctx context.Context, tx *sql.Tx, id uint) ([]trashCheck, error) {
+	query := `
+		SELECT student_id, class_id, start_date, end_date, status, school_id
+		FROM absent_requests
+		WHERE id = $1 AND ($2 = 0 OR school_id = $2) AND deleted_at IS NOT NULL
+		FOR UPDATE`
+
+	var studentID string
+	var status models.AbsentRequestStatus
+	var classID, schoolID uint
+	var startDate, endDate time.Time
+	err := tx.QueryRowContext(ctx, query, id, schoolFilter(ctx)).Scan(&studentID, &classID, &startDate, &endDate, &status, &schoolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted absent request %d: %w", id, models.ErrTrashNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deleted absent request: %w", err)
+	}
+
+	checks := []trashCheck{
+		{
+			field:   "student_id",
+			message: "the student is deleted, restore them first",
+			query:   `SELECT EXISTS(SELECT 1 FROM students WHERE student_id = $1 AND school_id = $2 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{studentID, schoolID},
+		},
+		{
+			field:   "class_id",
+			message: "the class is deleted, restore it first",
+			query:   `SELECT EXISTS(SELECT 1 FROM classes WHERE id = $1 AND deleted_at IS NOT NULL)`,
+			args:    []interface{}{classID},
+		},
+	}
+
+	if status.HoldsDates() {
+		checks = append(checks, trashCheck{
+			field:   "start_date",
+			message: "the student has another absent request for these dates",
+			query: `
+				SELECT EXISTS(
+					SELECT 1 FROM absent_requests
+					WHERE student_id = $1 AND school_id = $2 AND id <> $3 AND status = ANY($6)
+					  AND deleted_at IS NULL AND start_date <= $5 AND end_date >= $4
+				)`,
+			args: []interface{}{studentID, schoolID, id, startDate, endDate, pq.Array(models.AbsentRequestStatusesHoldingDates)},
+		})
+	}
+
+	return checks, nil
+}
+
+// Purge permanently removes the records deleted before the given time, in one transaction.
+// A purged student takes their attendances, absent requests, enrollments and guardian links along,
+// and leaves the bulk action audit trail without a link to them.
+// The result lists the files of the purged attachments for the caller to delete from storage.
+// Classes and teachers that live records still point at are kept and reported instead.
+// In a dry run everything is rolled back, so the result shows what a purge would do.
+func (r *trashRepository) Purge(ctx context.Context, before time.Time, dryRun bool) (*models.TrashPurgeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result := &models.TrashPurgeResult{
+		DryRun:   dryRun,
+		Before:   before,
+		Purged:   make(map[string]int),
+		Cascaded: map[string]int{models.TrashEntityAttendances: 0, models.TrashEntityAbsentRequests: 0},
+		Kept:     []models.TrashKept{},
+	}
+
+	// students go first so their classes can follow, and classes before teachers for their homerooms
+	steps := []func(context.Context, *sql.Tx, time.Time, *models.TrashPurgeResult) error{
+		purgeStudents,
+		purgeAbsentRequests,
+		purgeAttendances,
+		purgeClasses,
+		purgeTeachers,
+	}
+	for _, step := range steps {
+		if err := step(ctx, tx, before, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		result.Files = nil
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// purgeCandidates locks the records the query selects as (id, reason to keep) and returns those without a reason,
+// adding the others to the kept records of the result
+func purgeCandidates(ctx context.Context, tx *sql.Tx, entity, query string, before time.Time, result *models.TrashPurgeResult) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, before, schoolFilter(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s to purge: %w", entity, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var reason string
+		if err := rows.Scan(&id, &reason); err != nil {
+			return nil, fmt.Errorf("failed to scan %s to purge: %w", entity, err)
+		}
+
+		if reason != "" {
+			result.Kept = append(result.Kept, models.TrashKept{Entity: entity, ID: uint(id), Reason: reason})
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate %s to purge: %w", entity, err)
+	}
+
+	result.Purged[entity] = len(ids)
+	return ids, nil
+}
+
+// execPurge runs the queries in order with the IDs as their only argument and returns the rows the last one affected
+func execPurge(ctx context.Context, tx *sql.Tx, entity string, ids []int64, queries ...string) (int, error) {
+	var affected int64
+	for _, query := range queries {
+		result, err := tx.ExecContext(ctx, query, pq.Array(ids))
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", entity, err)
+		}
+
+		if affected, err = result.RowsAffected(); err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+	}
+
+	return int(affected), nil
+}
+
+// purgeAttachments deletes the attachments the query selects with the IDs in $1 and adds their files to the result
+func purgeAttachments(ctx context.Context, tx *sql.Tx, entity string, ids []int64, where string, result *models.TrashPurgeResult) error {
+	rows, err := tx.QueryContext(ctx, `DELETE FROM absent_request_attachments WHERE `+where+` RETURNING file_path`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to purge %s attachments: %w", entity, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var filePath string
+		if err := rows.Scan(&filePath); err != nil {
+			return fmt.Errorf("failed to scan purged attachment: %w", err)
+		}
+		result.Files = append(result.Files, filePath)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate purged attachments: %w", err)
+	}
+
+	return nil
+}
+
+// absentRequestsOfStudents selects the absent requests of the students with the IDs in $1
+const absentRequestsOfStudents = `
+	SELECT ar.id
+	FROM absent_requests ar
+	    JOIN students s ON s.student_id = ar.student_id AND s.school_id = ar.school_id
+	WHERE s.id = ANY($1)`
+
+func purgeStudents(ctx context.Context, tx *sql.Tx, before time.Time, result *models.TrashPurgeResult) error {
+	query := `
+		SELECT id, ''
+		FROM students
+		WHERE deleted_at < $1 AND ($2 = 0 OR school_id = $2)
+		FOR UPDATE`
+
+	ids, err := purgeCandidates(ctx, tx, models.TrashEntityStudents, query, before, result)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	err = purgeAttachments(ctx, tx, models.TrashEntityStudents, ids,
+		`uploaded_by = ANY($1) OR absent_request_id IN (`+absentRequestsOfStudents+`)`, result)
+	if err != nil {
+		return err
+	}
+
+	requests, err := execPurge(ctx, tx, models.TrashEntityStudents, ids,
+		`DELETE FROM absent_request_comments WHERE absent_request_id IN (`+absentRequestsOfStudents+`)`,
+		`DELETE FROM absent_request_versions WHERE absent_request_id IN (`+absentRequestsOfStudents+`)`,
+		`UPDATE absent_request_versions SET superseded_by = NULL WHERE superseded_by = ANY($1)`,
+		`UPDATE absent_requests SET deleted_by = NULL WHERE deleted_by = ANY($1)`,
+		`UPDATE attendances SET excused_by_request_id = NULL WHERE excused_by_request_id IN (`+absentRequestsOfStudents+`)`,
+		`DELETE FROM absent_requests WHERE id IN (`+absentRequestsOfStudents+`)`,
+	)
+	if err != nil {
+		return err
+	}
+	result.Cascaded[models.TrashEntityAbsentRequests] += requests
+
+	attendances, err := execPurge(ctx, tx, models.TrashEntityStudents, ids, `
+		DELETE FROM attendances a
+		USING students s
+		WHERE s.student_id = a.student_id AND s.school_id = a.school_id AND s.id = ANY($1)`,
+	)
+	if err != nil {
+		return err
+	}
+	result.Cascaded[models.TrashEntityAttendances] += attendances
+
+	_, err = execPurge(ctx, tx, models.TrashEntityStudents, ids,
+		`DELETE FROM enrollments WHERE student_id = ANY($1)`,
+		`DELETE FROM guardian_students WHERE student_id = ANY($1)`,
+		`UPDATE student_bulk_action_items SET student_id = NULL WHERE student_id = ANY($1)`,
+		`DELETE FROM student_contacts WHERE student_id = ANY($1)`,
+		`DELETE FROM student_medical_notes WHERE student_id = ANY($1)`,
+		`DELETE FROM user_roles WHERE user_type = 'student' AND user_id = ANY($1)`,
+		`DELETE FROM students WHERE id = ANY($1)`,
+	)
+	return err
+}
+
+func purgeAbsentRequests(ctx context.Context, tx *sql.Tx, before time.Time, result *models.TrashPurgeResult) error {
+	query := `
+		SELECT id, ''
+		FROM absent_requests
+		WHERE deleted_at < $1 AND ($2 = 0 OR school_id = $2)
+		FOR UPDATE`
+
+	ids, err := purgeCandidates(ctx, tx, models.TrashEntityAbsentRequests, query, before, result)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	if err := purgeAttachments(ctx, tx, models.TrashEntityAbsentRequests, ids, `absent_request_id = ANY($1)`, result); err != nil {
+		return err
+	}
+
+	_, err = execPurge(ctx, tx, models.TrashEntityAbsentRequests, ids,
+		`DELETE FROM absent_request_comments WHERE absent_request_id = ANY($1)`,
+		`DELETE FROM absent_request_versions WHERE absent_request_id = ANY($1)`,
+		`UPDATE attendances SET excused_by_request_id = NULL WHERE excused_by_request_id = ANY($1)`,
+		`DELETE FROM absent_requests WHERE id = ANY($1)`,
+	)
+	return err
+}
+
+func purgeAttendances(ctx context.Context, tx *sql.Tx, before time.Time, result *models.TrashPurgeResult) error {
+	query := `
+		SELECT id, ''
+		FROM attendances
+		WHERE deleted_at < $1 AND ($2 = 0 OR school_id = $2)
+		FOR UPDATE`
+
+	ids, err := purgeCandidates(ctx, tx, models.TrashEntityAttendances, query, before, result)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	_, err = execPurge(ctx, tx, models.TrashEntityAttendances, ids, `DELETE FROM attendances WHERE id = ANY($1)`)
+	return err
+}
+
+// purgeClasses removes the deleted classes nothing live points at any more, with their alerts,
+// teacher assignments and timetable slots. Attendance history keeps a class until it is purged itself.
+func purgeClasses(ctx context.Context, tx *sql.Tx, before time.Time, result *models.TrashPurgeResult) error {
+	query := `
+		SELECT c.id,
+		       CASE
+		           WHEN EXISTS(SELECT 1 FROM students s WHERE s.classes_id = c.id)
+		               THEN 'students are still in the class'
+		           WHEN EXISTS(SELECT 1 FROM enrollments e WHERE e.class_id = c.id)
+		               THEN 'students were enrolled in the class'
+		           WHEN EXISTS(SELECT 1 FROM attendances a WHERE a.class_id = c.id)
+		               OR EXISTS(SELECT 1 FROM attendances a JOIN timetable_slots ts ON ts.id = a.timetable_slot_id WHERE ts.class_id = c.id)
+		               THEN 'the class has attendance'
+		           WHEN EXISTS(SELECT 1 FROM absent_requests ar WHERE ar.class_id = c.id)
+		               THEN 'the class has absent requests'
+		           WHEN EXISTS(SELECT 1 FROM student_bulk_actions b WHERE b.class_id = c.id OR b.to_class_id = c.id)
+		               OR EXISTS(SELECT 1 FROM student_bulk_action_items i WHERE i.previous_class_id = c.id)
+		               THEN 'the class is in the student bulk action audit trail'
+		           ELSE ''
+		       END
+		FROM classes c
+		WHERE c.deleted_at < $1 AND ($2 = 0 OR c.school_id = $2)
+		FOR UPDATE OF c`
+
+	ids, err := purgeCandidates(ctx, tx, models.TrashEntityClasses, query, before, result)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	_, err = execPurge(ctx, tx, models.TrashEntityClasses, ids,
+		`DELETE FROM attendance_alerts WHERE class_id = ANY($1)`,
+		`DELETE FROM timetable_slots WHERE class_id = ANY($1)`,
+		`DELETE FROM classes WHERE id = ANY($1)`,
+	)
+	return err
+}
+
+// purgeTeachers removes the deleted teachers who are no longer homeroom teacher of a class or in the timetable,
+// with their class assignments. Absent requests they decided keep the decision without the teacher.
+func purgeTeachers(ctx context.Context, tx *sql.Tx, before time.Time, result *models.TrashPurgeResult) error {
+	query := `
+		SELECT t.id,
+		       CASE
+		           WHEN EXISTS(SELECT 1 FROM classes c WHERE c.homeroom_teacher = t.teacher_id AND c.school_id = t.school_id)
+		               THEN 'the teacher is still the homeroom teacher of a class'
+		           WHEN EXISTS(SELECT 1 FROM timetable_slots ts WHERE ts.teacher_id = t.teacher_id AND ts.school_id = t.school_id)
+		               THEN 'the teacher is still in the timetable'
+		           ELSE ''
+		       END
+		FROM teachers t
+		WHERE t.deleted_at < $1 AND ($2 = 0 OR t.school_id = $2)
+		FOR UPDATE OF t`
+
+	ids, err := purgeCandidates(ctx, tx, models.TrashEntityTeachers, query, before, result)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	_, err = execPurge(ctx, tx, models.TrashEntityTeachers, ids,
+		`DELETE FROM class_teachers ct
+		 USING teachers t
+		 WHERE ct.teacher_id = t.teacher_id AND ct.school_id = t.school_id AND t.id = ANY($1)`,
+		`UPDATE absent_requests SET approved_by = NULL WHERE approved_by = ANY($1)`,
+		`UPDATE absent_requests SET rejected_by = NULL WHERE rejected_by = ANY($1)`,
+		`DELETE FROM user_roles WHERE user_type = 'teacher' AND user_id = ANY($1)`,
+		`DELETE FROM teachers WHERE id = ANY($1)`,
+	)
+	return err
+}
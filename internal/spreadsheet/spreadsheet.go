@@ -0,0 +1,100 @@
+// Package spreadsheet reads the first sheet of a CSV or XLSX upload as a table whose
+// columns are addressed by their header name.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnsupportedFormat = errors.New("file must be a .csv or .xlsx spreadsheet")
+
+// Sheet is a table read from a spreadsheet. Rows are the lines below the header, so
+// the spreadsheet row number of Rows[i] is i+2.
+type Sheet struct {
+	columns map[string]int
+	Rows    [][]string
+}
+
+// Has reports whether the sheet has the column
+func (s *Sheet) Has(column string) bool {
+	_, ok := s.columns[column]
+	return ok
+}
+
+// Value returns the trimmed cell of the row in the column, or an empty string when the sheet has no such column
+func (s *Sheet) Value(row []string, column string) string {
+	index, ok := s.columns[column]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
+
+// IsBlank reports whether every cell of the row is empty, like the trailing lines spreadsheets often keep
+func IsBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Read parses data as a CSV or XLSX file, chosen by the extension of filename
+func Read(filename string, data []byte) (*Sheet, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(data)
+	case ".xlsx":
+		records, err = readXLSX(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &Sheet{columns: make(map[string]int)}
+	if len(records) == 0 {
+		return sheet, nil
+	}
+
+	for i, column := range records[0] {
+		column = normalizeHeader(column)
+		if _, ok := sheet.columns[column]; !ok && column != "" {
+			sheet.columns[column] = i
+		}
+	}
+	sheet.Rows = records[1:]
+
+	return sheet, nil
+}
+
+// normalizeHeader turns headers such as "Student ID" or "first-name" into student_id and first_name
+func normalizeHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+	return strings.Join(strings.FieldsFunc(header, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	return records, nil
+}
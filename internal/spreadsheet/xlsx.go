@@ -0,0 +1,225 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	fileWorkbook      = "xl/workbook.xml"
+	fileWorkbookRels  = "xl/_rels/workbook.xml.rels"
+	fileSharedStrings = "xl/sharedStrings.xml"
+	fileFirstSheet    = "xl/worksheets/sheet1.xml"
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a string item, either plain or made of rich text runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX returns the cells of the first worksheet as text. Rows and cells the file
+// leaves out are filled with empty strings so indexes match the spreadsheet grid.
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %w", err)
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if _, err := decodeXML(archive, fileSharedStrings, &sharedStrings); err != nil {
+		return nil, err
+	}
+
+	sheetFile, err := firstSheet(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var worksheet xlsxWorksheet
+	found, err := decodeXML(archive, sheetFile, &worksheet)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("xlsx file has no worksheet")
+	}
+
+	var records [][]string
+	for _, row := range worksheet.Rows {
+		index := len(records)
+		if row.Number > 0 {
+			index = row.Number - 1
+		}
+		for len(records) <= index {
+			records = append(records, nil)
+		}
+
+		var cells []string
+		for _, cell := range row.Cells {
+			column := len(cells)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+
+			if cells[column], err = cellValue(cell.Type, cell.Value, cell.Inline, sharedStrings.Items); err != nil {
+				return nil, fmt.Errorf("invalid xlsx cell %s: %w", cell.Ref, err)
+			}
+		}
+		records[index] = cells
+	}
+
+	return records, nil
+}
+
+// firstSheet returns the archive path of the first sheet listed in the workbook
+func firstSheet(archive *zip.Reader) (string, error) {
+	var workbook xlsxWorkbook
+	var relationships xlsxRelationships
+
+	found, err := decodeXML(archive, fileWorkbook, &workbook)
+	if err != nil || !found || len(workbook.Sheets) == 0 {
+		return fileFirstSheet, err
+	}
+
+	found, err = decodeXML(archive, fileWorkbookRels, &relationships)
+	if err != nil || !found {
+		return fileFirstSheet, err
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID != workbook.Sheets[0].RelationshipID {
+			continue
+		}
+		// targets are relative to xl/ unless they start at the archive root
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+
+	return fileFirstSheet, nil
+}
+
+// decodeXML reports false when the file is not part of the archive
+func decodeXML(archive *zip.Reader, name string, v interface{}) (bool, error) {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return false, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+
+		if err := xml.NewDecoder(rc).Decode(v); err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func cellValue(cellType, value string, inline xlsxText, sharedStrings []xlsxText) (string, error) {
+	switch cellType {
+	case "s":
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return "", fmt.Errorf("unknown shared string %q", value)
+		}
+		return sharedStrings[index].String(), nil
+	case "inlineStr":
+		return inline.String(), nil
+	case "b":
+		if value == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case "", "n":
+		// numbers such as phone numbers may be stored in exponent form
+		if strings.ContainsAny(value, "eE") {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				return strconv.FormatFloat(number, 'f', -1, 64), nil
+			}
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// columnIndex converts the column letters of a cell reference such as "AB12" to a zero-based index
+func columnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+
+	if letters == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+
+	return index - 1, nil
+}
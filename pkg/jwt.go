@@ -10,6 +10,7 @@ import (
 type Claims struct {
 	UserID   string `json:"user_id"`
 	UserType string `json:"user_type"`
+	SchoolID uint   `json:"school_id"`
 	jwt.RegisteredClaims
 }
 
@@ -18,10 +19,11 @@ type JWTConfig struct {
 	TokenDuration time.Duration
 }
 
-func GenerateToken(userID, userType string, config JWTConfig) (string, error) {
+func GenerateToken(userID, userType string, schoolID uint, config JWTConfig) (string, error) {
 	claims := &Claims{
 		UserID:   userID,
 		UserType: userType,
+		SchoolID: schoolID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.TokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -1,9 +1,9 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useTranslation } from 'react-i18next';
 import { useForm } from 'react-hook-form';
 import { useToast } from '../utils/toast-helpers';
 import { absentRequestApi } from '../services/api';
-import type { AbsentRequestFormData } from '../types/models';
+import type { AbsentRequestCategory, AbsentRequestFormData } from '../types/models';
 
 interface StudentAbsentRequestFormProps {
   onRequestCreated: () => void;
@@ -13,21 +13,38 @@ export const StudentAbsentRequestForm: React.FC<StudentAbsentRequestFormProps> =
   const { t } = useTranslation();
   const { showSuccess, showError } = useToast();
   const [isSubmitting, setIsSubmitting] = useState(false);
+  const [categories, setCategories] = useState<AbsentRequestCategory[]>([]);
 
   const {
     register,
     handleSubmit,
     formState: { errors },
     reset,
+    watch,
   } = useForm<AbsentRequestFormData>();
 
+  useEffect(() => {
+    absentRequestApi
+      .getCategories()
+      .then((response) => setCategories(response.data || []))
+      .catch((error) => console.error('Failed to load absent request categories:', error));
+  }, []);
+
+  const selectedCategory = categories.find((category) => category.id === Number(watch('category_id')));
+
   const onSubmit = async (data: AbsentRequestFormData) => {
     try {
       setIsSubmitting(true);
-      await absentRequestApi.create(data);
+      const response = await absentRequestApi.create({ ...data, category_id: Number(data.category_id) });
       showSuccess(
         t('student_page.request_created'),
-        t('student_page.request_created_success')
+        response.quota
+          ? t('student_page.request_created_with_quota', {
+              remaining: response.quota.remaining_days,
+              max: response.quota.max_days,
+              term: response.quota.term_name,
+            })
+          : t('student_page.request_created_success')
       );
       reset();
       onRequestCreated();
@@ -62,6 +79,29 @@ export const StudentAbsentRequestForm: React.FC<StudentAbsentRequestFormProps> =
         </div>
 
         <form onSubmit={handleSubmit(onSubmit)} className="space-y-4">
+          <div>
+            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              {t('student_page.category')} *
+            </label>
+            <select
+              {...register('category_id', { required: t('validation.required') })}
+              className="block w-full border border-gray-300 dark:border-gray-600 rounded-md px-3 py-2 bg-white dark:bg-gray-700 text-gray-900 dark:text-gray-100 focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+            >
+              <option value="">{t('student_page.category_placeholder')}</option>
+              {categories.map((category) => (
+                <option key={category.id} value={category.id}>
+                  {category.name}
+                </option>
+              ))}
+            </select>
+            {selectedCategory?.requires_attachment && (
+              <p className="mt-1 text-sm text-gray-500 dark:text-gray-400">{t('student_page.category_requires_attachment')}</p>
+            )}
+            {errors.category_id && (
+              <p className="mt-1 text-sm text-red-600 dark:text-red-400">{errors.category_id.message}</p>
+            )}
+          </div>
+
           <div>
             <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
               {t('student_page.request_date')} *
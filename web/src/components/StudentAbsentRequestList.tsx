@@ -10,7 +10,7 @@ export interface StudentAbsentRequestListHandle {
 
 export const StudentAbsentRequestList = forwardRef<StudentAbsentRequestListHandle>((_props, ref) => {
   const { t } = useTranslation();
-  const { showError } = useToast();
+  const { showSuccess, showError } = useToast();
   const [requests, setRequests] = useState<AbsentRequest[]>([]);
   const [loading, setLoading] = useState(false);
   const [pagination, setPagination] = useState({
@@ -50,6 +50,22 @@ export const StudentAbsentRequestList = forwardRef<StudentAbsentRequestListHandl
     }
   };
 
+  const handleWithdraw = async (id: number) => {
+    if (!window.confirm(t('student_page.withdraw_confirm'))) return;
+
+    try {
+      await absentRequestApi.withdraw(id);
+      showSuccess(t('student_page.request_withdrawn'), t('student_page.request_withdrawn_success'));
+      fetchRequests();
+    } catch (error: unknown) {
+      console.error('Failed to withdraw absent request:', error);
+      showError(
+        t('common.error'),
+        (error as Error)?.message || t('student_page.request_withdraw_failed')
+      );
+    }
+  };
+
   const getStatusBadge = (status: string) => {
     const statusMap = {
       pending: {
@@ -66,6 +82,11 @@ export const StudentAbsentRequestList = forwardRef<StudentAbsentRequestListHandl
         color: 'bg-red-100 text-red-800 dark:bg-red-900/50 dark:text-red-300',
         icon: '❌',
         label: t('student_page.status_rejected')
+      },
+      withdrawn: {
+        color: 'bg-gray-100 text-gray-800 dark:bg-gray-700 dark:text-gray-300',
+        icon: '↩️',
+        label: t('student_page.status_withdrawn')
       }
     };
 
@@ -201,6 +222,14 @@ export const StudentAbsentRequestList = forwardRef<StudentAbsentRequestListHandl
                       )}
                     </div>
                   </div>
+                  {request.status === 'pending' && (
+                    <button
+                      onClick={() => handleWithdraw(request.id)}
+                      className="ml-4 px-3 py-1 text-sm border border-gray-300 dark:border-gray-600 rounded text-gray-700 dark:text-gray-300 hover:bg-gray-50 dark:hover:bg-gray-700"
+                    >
+                      {t('student_page.withdraw')}
+                    </button>
+                  )}
                 </div>
               </div>
             ))}
@@ -17,7 +17,15 @@ import type {
   DashboardStats,
   StudentProfile,
   TeacherProfile,
+  TeacherProfileUpdateData,
+  StudentContact,
+  StudentContactFormData,
+  StudentEmergencyInfo,
+  TeacherEmailChange,
   AbsentRequest,
+  AbsentRequestCategory,
+  AbsentRequestComment,
+  AbsenceQuotaUsage,
   AbsentRequestFormData,
 } from '../types/models';
 
@@ -258,6 +266,18 @@ export const studentsApi = {
     apiService.request<PasswordResetResponse>(`/students/student-id/${studentId}/reset-password`, {
       method: 'PUT',
     }),
+  getContacts: (id: number) =>
+    apiService.request<ApiResponse<StudentEmergencyInfo>>(`/students/record-id/${id}/contacts`),
+  updateContacts: (id: number, contacts: StudentContactFormData[]) =>
+    apiService.request<ApiResponse<StudentContact[]>>(`/students/record-id/${id}/contacts`, {
+      method: 'PUT',
+      body: JSON.stringify({ contacts }),
+    }),
+  updateMedicalNote: (id: number, note: string) =>
+    apiService.request<ApiResponse<null>>(`/students/record-id/${id}/medical-note`, {
+      method: 'PUT',
+      body: JSON.stringify({ note }),
+    }),
 };
 
 // Classes API
@@ -423,6 +443,24 @@ export const studentDashboardApi = {
 export const teacherDashboardApi = {
   getProfile: () =>
     apiService.request<ApiResponse<TeacherProfile>>('/teacher/profile'),
+  updateProfile: (data: TeacherProfileUpdateData) =>
+    apiService.request<ApiResponse<Teacher> & { email_verification: TeacherEmailChange | null }>('/teacher/profile', {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    }),
+  verifyEmail: (code: string) =>
+    apiService.request<ApiResponse<null> & { email: string }>('/teacher/profile/email/verify', {
+      method: 'POST',
+      body: JSON.stringify({ code }),
+    }),
+  uploadPhoto: (photo: File) => {
+    const formData = new FormData();
+    formData.append('photo', photo);
+    return apiService.request<ApiResponse<null> & { path: string }>('/teacher/profile/photo', {
+      method: 'PUT',
+      body: formData,
+    });
+  },
   updatePassword: (data: PasswordUpdateData) =>
     apiService.request<ApiResponse<null>>('/teacher/password', {
       method: 'PUT',
@@ -437,18 +475,27 @@ export const teacherDashboardApi = {
       `/absent-requests/current-teacher${query ? `?${query}` : ''}`
     );
   },
-  approveAbsentRequest: (id: number) =>
+  approveAbsentRequest: (id: number, note?: string) =>
     apiService.request<ApiResponse<AbsentRequest>>(`/absent-requests/absent-request-id/${id}/approve`, {
       method: 'PUT',
+      body: JSON.stringify({ note }),
     }),
-  rejectAbsentRequest: (id: number) =>
+  rejectAbsentRequest: (id: number, note?: string) =>
     apiService.request<ApiResponse<AbsentRequest>>(`/absent-requests/absent-request-id/${id}/reject`, {
       method: 'PUT',
+      body: JSON.stringify({ note }),
+    }),
+  addComment: (id: number, body: string) =>
+    apiService.request<ApiResponse<AbsentRequestComment>>(`/absent-requests/absent-request-id/${id}/comments`, {
+      method: 'POST',
+      body: JSON.stringify({ body }),
     }),
 };
 
 // Absent Request API (authenticated student endpoints)
 export const absentRequestApi = {
+  getCategories: () =>
+    apiService.request<ApiResponse<AbsentRequestCategory[]>>('/absent-requests/categories'),
   getMyRequests: (params?: { limit?: number; offset?: number }) => {
     const searchParams = new URLSearchParams();
     if (params?.limit) searchParams.set('limit', params.limit.toString());
@@ -459,7 +506,7 @@ export const absentRequestApi = {
     );
   },
   create: (data: AbsentRequestFormData) =>
-    apiService.request<ApiResponse<AbsentRequest>>('/absent-requests', {
+    apiService.request<ApiResponse<AbsentRequest> & { quota?: AbsenceQuotaUsage | null }>('/absent-requests', {
       method: 'POST',
       body: JSON.stringify(data),
     }),
@@ -468,4 +515,8 @@ export const absentRequestApi = {
       method: 'PUT',
       body: JSON.stringify(data),
     }),
+  withdraw: (id: number) =>
+    apiService.request<ApiResponse<AbsentRequest>>(`/absent-requests/absent-request-id/${id}/withdraw`, {
+      method: 'POST',
+    }),
 };
\ No newline at end of file
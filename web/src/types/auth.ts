@@ -12,6 +12,7 @@ export interface LoginResponse {
   token: string;
   user_type: UserType;
   user_id: string;
+  school_id: number;
   expires_at: number;
 }
 
@@ -8,10 +8,12 @@ export interface BaseModel {
 // Teacher model
 export interface Teacher extends BaseModel {
   teacher_id: string;
+  school_id: number;
   first_name: string;
   last_name: string;
   email: string;
   phone: string;
+  preferred_language: Language;
   photo_path?: string;
   is_active: boolean;
 }
@@ -20,12 +22,50 @@ export interface Teacher extends BaseModel {
 export interface Student extends BaseModel {
   student_id: string;
   classes_id: number;
+  school_id: number;
   first_name: string;
   last_name: string;
   email: string;
   phone: string;
   photo_path?: string;
   is_active: boolean;
+  class_name?: string;
+  emergency?: StudentEmergencyInfo;
+}
+
+// Student emergency contact, the lowest priority is called first
+export interface StudentContact extends BaseModel {
+  student_id: number;
+  school_id: number;
+  name: string;
+  relationship: string;
+  phone: string;
+  alt_phone: string | null;
+  priority: number;
+  created_by: number | null;
+  updated_by: number | null;
+}
+
+// Confidential medical note of a student
+export interface StudentMedicalNote {
+  student_id: number;
+  note: string;
+  updated_at: string;
+  updated_by: number | null;
+}
+
+// Emergency information, only returned to admins and the homeroom teacher
+export interface StudentEmergencyInfo {
+  contacts: StudentContact[];
+  medical_note: StudentMedicalNote | null;
+}
+
+export interface StudentContactFormData {
+  name: string;
+  relationship: string;
+  phone: string;
+  alt_phone?: string;
+  priority?: number;
 }
 
 // Class model
@@ -33,13 +73,19 @@ export interface Class extends BaseModel {
   name: string;
   homeroom_teacher: string;
   description: string;
+  academic_year_id?: number | null;
 }
 
+export type AdminTier = 'super_admin' | 'admin' | 'auditor';
+
 // Admin model
 export interface Admin extends BaseModel {
+  school_id: number;
   email: string;
   last_login?: string;
   is_active: boolean;
+  tier: AdminTier;
+  is_super_admin: boolean;
 }
 
 // Attendance model
@@ -49,6 +95,7 @@ export interface Attendance extends BaseModel {
   date: string;
   status: AttendanceStatus;
   description?: string;
+  timetable_slot_id?: number | null;
 }
 
 // Attendance status enum
@@ -87,6 +134,7 @@ export interface TeacherFormData {
 export interface StudentFormData {
   student_id: string;
   classes_id: number;
+  school_id: number;
   first_name: string;
   last_name: string;
   email: string;
@@ -99,6 +147,7 @@ export interface ClassFormData {
   name: string;
   homeroom_teacher: string;
   description: string;
+  academic_year_id?: number | null;
 }
 
 export interface AdminFormData {
@@ -106,6 +155,7 @@ export interface AdminFormData {
   password?: string;
   retype_password?: string;
   is_active: boolean;
+  tier?: AdminTier;
 }
 
 export interface AttendanceFormData {
@@ -181,6 +231,23 @@ export interface TeacherProfile extends Teacher {
   pending_requests?: number;
 }
 
+export type Language = 'en' | 'id';
+
+// Fields of their own profile a teacher may change; a new email applies once verified
+export interface TeacherProfileUpdateData {
+  phone?: string;
+  email?: string;
+  preferred_language?: Language;
+}
+
+export interface TeacherEmailChange {
+  teacher_id: number;
+  new_email: string;
+  attempts: number;
+  expires_at: string;
+  created_at: string;
+}
+
 export interface AttendanceStats {
   total_days?: number;
   present_days?: number;
@@ -192,18 +259,356 @@ export interface AttendanceStats {
 
 // Absent request model
 export interface AbsentRequest extends BaseModel {
+  school_id?: number;
   student_id: string;
+  category_id?: number;
   request_date: string;
+  start_date: string;
+  end_date: string;
+  exclude_non_school_days: boolean;
+  total_days: number;
   reason: string;
-  status: 'pending' | 'approved' | 'rejected';
+  status: 'pending' | 'approved' | 'rejected' | 'withdrawn';
+  version: number;
   approved_by?: number;
   approved_at?: string;
   rejected_by?: number;
   rejected_at?: string;
+  admin_decided_by?: number;
+  admin_decided_at?: string;
+  reviewer_note?: string;
+  escalated_at?: string;
+  escalated_to?: number;
+  withdrawn_at?: string;
+  quota_exceeded_at?: string;
+  submitted_by_guardian?: number;
+  category?: AbsentRequestCategory;
+  attachments?: AbsentRequestAttachment[];
+  comments?: AbsentRequestComment[];
+  versions?: AbsentRequestVersion[];
+}
+
+// Parent or guardian account linked to one or more students
+export interface Guardian {
+  id: number;
+  first_name: string;
+  last_name: string;
+  email: string;
+  phone?: string;
+  is_active: boolean;
+  last_login?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+// Student linked to a guardian, as listed in the guardian portal
+export interface GuardianChild {
+  id: number;
+  student_id: string;
+  first_name: string;
+  last_name: string;
+  class_id: number;
+  class_name: string;
+  is_active: boolean;
+  relationship?: string;
+}
+
+// Student's allowance under the absence quota of a category and term
+export interface AbsenceQuotaUsage {
+  quota_id: number;
+  category_id: number;
+  term_name: string;
+  start_date: string;
+  end_date: string;
+  max_days: number;
+  used_days: number;
+  pending_days: number;
+  remaining_days: number;
+}
+
+// Absent request as it stood before a student amendment superseded it
+export interface AbsentRequestVersion {
+  id: number;
+  absent_request_id: number;
+  version: number;
+  category_id?: number;
+  start_date: string;
+  end_date: string;
+  exclude_non_school_days: boolean;
+  total_days: number;
+  reason: string;
+  superseded_by?: number;
+  superseded_at: string;
+}
+
+// Message in the thread between a student and the reviewers of an absent request
+export interface AbsentRequestComment {
+  id: number;
+  absent_request_id: number;
+  author_type: 'student' | 'teacher' | 'admin';
+  author_id: number;
+  author_name: string;
+  body: string;
+  created_at: string;
+}
+
+// Admin-managed absent request category and the rules requests under it follow
+export interface AbsentRequestCategory extends BaseModel {
+  school_id: number;
+  code: string;
+  name: string;
+  description?: string;
+  requires_attachment: boolean;
+  max_days?: number;
+  min_notice_days: number;
+  marks_excused: boolean;
+  is_active: boolean;
+}
+
+// Supporting document attached to an absent request
+export interface AbsentRequestAttachment {
+  id: number;
+  absent_request_id: number;
+  file_name: string;
+  content_type: string;
+  file_size: number;
+  uploaded_by: number;
+  created_at: string;
+  url?: string;
 }
 
 // Absent request form data
 export interface AbsentRequestFormData {
+  category_id: number;
   request_date: string;
+  start_date?: string;
+  end_date?: string;
+  exclude_non_school_days?: boolean;
   reason: string;
-}
\ No newline at end of file
+}
+
+// School year that classes belong to; a school has at most one current year
+export interface AcademicYear extends BaseModel {
+  school_id: number;
+  name: string;
+  start_date: string;
+  end_date: string;
+  is_current: boolean;
+}
+
+// Part of an academic year, such as a semester
+export interface Term extends BaseModel {
+  academic_year_id: number;
+  name: string;
+  start_date: string;
+  end_date: string;
+}
+
+// Class a student was in; end_date is the first day they were no longer in it
+export interface Enrollment {
+  id: number;
+  school_id: number;
+  student_id: number;
+  class_id: number;
+  class_name: string;
+  academic_year_id?: number | null;
+  start_date: string;
+  end_date?: string | null;
+  start_reason: 'enrolled' | 'moved' | 'promoted' | 'transferred';
+  end_reason?: 'moved' | 'promoted' | 'graduated' | 'transferred' | null;
+  note?: string | null;
+  created_at: string;
+}
+
+// Mid-year class change; effective_date defaults to today
+export interface TransferFormData {
+  class_id: number;
+  effective_date?: string;
+  note?: string;
+}
+
+// Year-end promotion; a class without to_class_id graduates its students
+export interface PromotionFormData {
+  from_academic_year_id: number;
+  to_academic_year_id: number;
+  date?: string;
+  classes: { from_class_id: number; to_class_id?: number | null }[];
+  dry_run?: boolean;
+}
+
+export interface PromotionResult {
+  from_academic_year_id: number;
+  to_academic_year_id: number;
+  date: string;
+  dry_run: boolean;
+  promoted: number;
+  graduated: number;
+  classes: { from_class_id: number; to_class_id?: number | null; students: number; enrolled_after_date?: number[] }[];
+}
+
+// Subject taught at a school
+export interface Subject extends BaseModel {
+  school_id: number;
+  code: string;
+  name: string;
+  description?: string | null;
+}
+
+// Weekly lesson; weekday runs from 1 (Monday) to 7 (Sunday), times are HH:MM
+export interface TimetableSlot extends BaseModel {
+  school_id: number;
+  class_id: number;
+  subject_id: number;
+  teacher_id: string;
+  weekday: number;
+  start_time: string;
+  end_time: string;
+  room?: string | null;
+  class_name?: string;
+  subject_code?: string;
+  subject_name?: string;
+  teacher_first_name?: string;
+  teacher_last_name?: string;
+}
+
+export interface TimetableSlotFormData {
+  class_id: number;
+  subject_id: number;
+  teacher_id: string;
+  weekday: number;
+  start_time: string;
+  end_time: string;
+  room?: string;
+}
+
+// Role of a teacher in a class
+export type ClassTeacherRole = 'homeroom' | 'co_homeroom' | 'assistant';
+
+export interface ClassTeacher {
+  id: number;
+  school_id: number;
+  class_id: number;
+  teacher_id: string;
+  role: ClassTeacherRole;
+  first_name?: string;
+  last_name?: string;
+  created_at: string;
+  created_by?: number | null;
+}
+
+// What teachers of a class role may do in the classes they teach
+export interface ClassTeacherRolePermission {
+  role: ClassTeacherRole;
+  can_decide_absent_requests: boolean;
+  can_comment_absent_requests: boolean;
+  updated_at?: string | null;
+  updated_by?: number | null;
+}
+
+// Problem with one row of an imported spreadsheet; row counts the header line
+export interface ImportRowError {
+  row: number;
+  field?: string;
+  error: string;
+}
+
+// Initial password of an imported account, only returned by the import that created it
+export interface ImportCredential {
+  row: number;
+  id: string;
+  first_name: string;
+  last_name: string;
+  email: string;
+  password: string;
+}
+
+// Result of POST /students/import; nothing is created on a dry run or when there are errors
+export interface StudentImportResult {
+  dry_run: boolean;
+  rows: number;
+  created: number;
+  errors: ImportRowError[];
+  credentials?: ImportCredential[];
+}
+
+// Result of POST /teachers/import; homerooms_assigned counts the teachers given a homeroom class
+export interface TeacherImportResult {
+  dry_run: boolean;
+  rows: number;
+  created: number;
+  homerooms_assigned: number;
+  errors: ImportRowError[];
+  credentials?: ImportCredential[];
+}
+
+export type StudentBulkActionType = 'deactivate' | 'activate' | 'move_class' | 'delete' | 'reset_password';
+
+export interface StudentBulkInput {
+  action: StudentBulkActionType;
+  student_ids?: number[];
+  class_id?: number;
+  to_class_id?: number;
+  effective_date?: string;
+  note?: string;
+}
+
+export interface StudentBulkItem {
+  id: number; // 0 once the student was purged from the trash
+  student_id: string;
+  first_name: string;
+  last_name: string;
+  status: 'changed' | 'unchanged' | 'failed';
+  error?: string;
+  previous_class_id?: number;
+  previous_is_active?: boolean;
+  password?: string;
+}
+
+export interface StudentBulkAction {
+  id: number;
+  school_id: number;
+  action: StudentBulkActionType;
+  class_id?: number;
+  to_class_id?: number;
+  effective_date?: string;
+  note?: string;
+  student_count: number;
+  changed_count: number;
+  performed_by: number;
+  performed_at: string;
+  items?: StudentBulkItem[];
+}
+
+export type TrashEntity = 'teachers' | 'students' | 'classes' | 'attendances' | 'absent-requests';
+
+export interface TrashItem {
+  id: number;
+  entity: TrashEntity;
+  code: string;
+  name: string;
+  detail: string;
+  school_id: number;
+  deleted_at: string;
+  deleted_by?: number;
+  purge_at: string;
+}
+
+export interface TrashConflict {
+  field: string;
+  error: string;
+}
+
+export interface TrashKept {
+  entity: TrashEntity;
+  id: number;
+  reason: string;
+}
+
+export interface TrashPurgeResult {
+  dry_run: boolean;
+  retention_days: number;
+  before: string;
+  purged: Partial<Record<TrashEntity, number>>;
+  cascaded: Partial<Record<TrashEntity, number>>;
+  kept: TrashKept[];
+}